@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	httpadapter "empoweredpixels/internal/adapter/http"
+	"empoweredpixels/internal/adapter/http/handlers"
+	"empoweredpixels/internal/adapter/http/middleware"
 	"empoweredpixels/internal/adapter/ws"
 	"empoweredpixels/internal/config"
 	"empoweredpixels/internal/infra/db"
@@ -19,27 +24,37 @@ import (
 	inventoryusecase "empoweredpixels/internal/usecase/inventory"
 	leaguesusecase "empoweredpixels/internal/usecase/leagues"
 	matchesusecase "empoweredpixels/internal/usecase/matches"
+	matchmakingusecase "empoweredpixels/internal/usecase/matchmaking"
+	notificationsusecase "empoweredpixels/internal/usecase/notifications"
 	rewardsusecase "empoweredpixels/internal/usecase/rewards"
 	rosterusecase "empoweredpixels/internal/usecase/roster"
 	seasonsusecase "empoweredpixels/internal/usecase/seasons"
 	shopusecase "empoweredpixels/internal/usecase/shop"
+	standingsusecase "empoweredpixels/internal/usecase/standings"
+	tournamentsusecase "empoweredpixels/internal/usecase/tournaments"
+	skillsusecase "empoweredpixels/internal/usecase/skills"
 	attunementusecase "empoweredpixels/internal/usecase/attunement"
 	weaponsusecase "empoweredpixels/internal/usecase/weapons"
 	dailyusecase "empoweredpixels/internal/usecase/daily"
 	leaderboardusecase "empoweredpixels/internal/usecase/leaderboard"
 	eventsusecase "empoweredpixels/internal/usecase/events"
 	"empoweredpixels/internal/mcp"
+
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
 func main() {
 	cfg := config.FromEnv()
 
+	// SIGTERM and interrupts start a graceful shutdown
+	ctx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stopSignals()
+
 	database, err := db.Connect(context.Background(), cfg.DatabaseURL)
 	if err != nil {
 		log.Printf("database error: %v", err)
 		os.Exit(1)
 	}
-	defer database.Pool.Close()
 
 	migrationsDir := filepath.Join("internal", "infra", "db", "migrations")
 	if err := db.ApplyMigrations(context.Background(), database.Pool, migrationsDir); err != nil {
@@ -68,6 +83,7 @@ func main() {
 		experienceRepo,
 		configurationRepo,
 		squadRepo,
+		repositories.NewLevelHistoryRepository(database.Pool),
 		time.Now,
 	)
 
@@ -84,61 +100,195 @@ func main() {
 	matchRegistrationRepo := repositories.NewMatchRegistrationRepository(database.Pool)
 	matchResultRepo := repositories.NewMatchResultRepository(database.Pool)
 	matchScoreRepo := repositories.NewMatchScoreRepository(database.Pool)
-	engineClient := engine.NewClient(engine.Config{BaseURL: cfg.EngineURL})
-	matchHub := ws.NewMatchHub()
+	combatRepo := repositories.NewCombatRepository(database.Pool)
+	// The remote engine is only offered when it is configured
+	var remoteEngine matchesusecase.CombatEngine
+	if cfg.EngineURL != "" {
+		remoteEngine = engine.NewClient(engine.Config{
+			BaseURL: cfg.EngineURL,
+			Timeout: cfg.EngineTimeout,
+			Retries: cfg.EngineRetries,
+		})
+	}
+	matchHub := ws.NewMatchHub(func(token string) (int64, bool) {
+		return middleware.ParseUserID(token, []byte(cfg.JWTSecret))
+	}, cfg.AllowedOrigins)
+	notificationRepo := repositories.NewNotificationRepository(database.Pool)
+	notificationService := notificationsusecase.NewService(notificationRepo, matchHub, time.Now)
+	rewardService.SetNotifier(notificationService)
+	weaponRepo := repositories.NewWeaponRepository(database.Pool)
+	weaponService := weaponsusecase.NewService(weaponRepo)
+
+	// The skills repository is written against database/sql
+	skillsDB := stdlib.OpenDB(*database.Pool.Config().ConnConfig)
+	skillRepo := repositories.NewSkillsPostgres(skillsDB)
+	skillService := skillsusecase.NewService(skillRepo, fighterRepo)
+
+	// Attunement service initialization
+	attunementRepo := repositories.NewAttunementRepository(database.Pool)
+	attunementService := attunementusecase.NewService(attunementRepo)
 	matchService := matchesusecase.NewService(
 		matchRepo,
 		matchTeamRepo,
 		matchRegistrationRepo,
 		matchResultRepo,
 		matchScoreRepo,
+		combatRepo,
 		fighterRepo,
 		inventoryService,
 		rewardService,
 		rosterService,
-		engineClient,
+		weaponService,
+		skillRepo,
+		attunementService,
+		remoteEngine,
 		matchHub,
 		time.Now,
 	)
+	matchService.SetDefaultEngine(cfg.CombatEngine)
+	matchService.SetQueue(repositories.NewMatchRunRepository(database.Pool))
+	matchService.SetSettlement(db.NewUnitOfWork(database.Pool), repositories.NewMatchSettlementRepository(database.Pool))
+	matchService.SetNotifier(notificationService)
+	ratingRepo := repositories.NewRatingRepository(database.Pool)
+	matchService.SetRatings(ratingRepo)
+	matchHub.SetCatchUp(matchService.LiveCatchUp)
+
+	// Periodic jobs run on the one instance holding the jobs advisory lock
+	scheduler := jobs.NewScheduler(
+		db.NewLeaderLock(database.Pool, "empoweredpixels:jobs"),
+		repositories.NewJobRunRepository(database.Pool),
+	)
 
 	leagueRepo := repositories.NewLeagueRepository(database.Pool)
 	leagueSubRepo := repositories.NewLeagueSubscriptionRepository(database.Pool)
 	leagueMatchRepo := repositories.NewLeagueMatchRepository(database.Pool)
 	leagueService := leaguesusecase.NewService(leagueRepo, leagueSubRepo, leagueMatchRepo, fighterRepo, time.Now)
-	leagueJob := jobs.NewLeagueJob(matchService, leagueRepo, leagueSubRepo, leagueMatchRepo, fighterRepo, 4*time.Hour)
-	leagueJob.Start()
+	// Every minute the job runs the leagues whose own schedule came due
+	leagueJob := jobs.NewLeagueJob(matchService, leagueRepo, leagueSubRepo, leagueMatchRepo, fighterRepo, time.Minute)
+	leagueJob.SetSchedule(leagueService)
+	leagueJob.SetNotifier(notificationService)
+	matchService.OnMatchFinished(leagueJob.MatchFinished)
+
+	// Leagues run tournaments whose rounds advance as their matches finish
+	tournamentRepo := repositories.NewTournamentRepository(database.Pool)
+	tournamentService := tournamentsusecase.NewService(
+		tournamentRepo,
+		leagueRepo,
+		leagueSubRepo,
+		leagueMatchRepo,
+		fighterRepo,
+		ratingRepo,
+		matchService,
+		rewardService,
+		db.NewUnitOfWork(database.Pool),
+		time.Now,
+	)
+	matchService.OnMatchFinished(tournamentService.MatchFinished)
+	leagueService.SetChampions(tournamentRepo)
+	leagueJob.SetTournaments(tournamentService)
+	scheduler.Register(leagueJob)
+	tournamentJob := jobs.NewTournamentJob(tournamentService, time.Minute)
+	scheduler.Register(tournamentJob)
+
+	// Started matches are executed from the queue, after requeueing the ones
+	// a previous process left running. The signal doesn't cancel them; the
+	// shutdown lets running matches finish within its budget.
+	matchQueueJob := jobs.NewMatchQueueJob(matchService, cfg.MatchWorkers, time.Second, time.Minute)
+	matchQueueJob.Start(context.Background())
 
 	lobbyCleanupJob := jobs.NewLobbyCleanupJob(matchService, 60, 5*time.Minute)
-	lobbyCleanupJob.Start()
+	scheduler.Register(lobbyCleanupJob)
+
+	matchmakingService := matchmakingusecase.NewService(
+		repositories.NewMatchmakingRepository(database.Pool),
+		ratingRepo,
+		fighterRepo,
+		matchService,
+		db.NewUnitOfWork(database.Pool),
+		matchHub,
+		time.Now,
+	)
+	matchmakingJob := jobs.NewMatchmakingJob(matchmakingService, 2*time.Second)
+	scheduler.Register(matchmakingJob)
 
 	seasonSummaryRepo := repositories.NewSeasonSummaryRepository(database.Pool)
 	seasonService := seasonsusecase.NewService(seasonSummaryRepo)
+	seasonService.SetLadder(
+		repositories.NewSeasonRepository(database.Pool),
+		repositories.NewLadderRepository(database.Pool),
+		ratingRepo,
+		userRepo,
+		rewardService,
+		db.NewUnitOfWork(database.Pool),
+	)
+	matchService.SetLadder(seasonService)
 
-	weaponRepo := repositories.NewWeaponRepository(database.Pool)
-	weaponService := weaponsusecase.NewService(weaponRepo)
+	// Ranked seasons roll over and idle fighters decay hourly. The leader
+	// starts the first season as soon as it leads.
+	seasonJob := jobs.NewSeasonInitiatorJob(seasonService, time.Hour)
+	scheduler.Register(seasonJob)
+
+	// League matches add their results to the standings of the ranked season
+	standingsService := standingsusecase.NewService(
+		repositories.NewStandingRepository(database.Pool),
+		leagueRepo,
+		leagueMatchRepo,
+		fighterRepo,
+		matchService,
+		db.NewUnitOfWork(database.Pool),
+		time.Now,
+	)
+	standingsService.SetSeasons(seasonService)
+	matchService.OnMatchFinished(standingsService.MatchFinished)
 
 	// Shop service initialization
 	shopRepo := repositories.NewShopRepository(database.Pool)
 	goldRepo := repositories.NewPlayerGoldRepository(database.Pool)
 	txRepo := repositories.NewTransactionRepository(database.Pool)
 	shopService := shopusecase.NewService(shopRepo, goldRepo, txRepo, weaponService, shopusecase.NewSimulatedPaymentProvider())
+	rosterService.SetAttributes(repositories.NewAttributeRepository(database.Pool), goldRepo, txRepo, db.NewUnitOfWork(database.Pool))
+	leagueService.SetEntryFees(goldRepo, txRepo, db.NewUnitOfWork(database.Pool))
 
-	// Attunement service initialization
-	attunementRepo := repositories.NewAttunementRepository(database.Pool)
-	attunementService := attunementusecase.NewService(attunementRepo)
 
 	// Daily reward service initialization
 	dailyRepo := repositories.NewDailyRewardRepository(database.Pool)
 	dailyService := dailyusecase.NewService(dailyRepo, goldRepo)
+	dailyService.SetNotifier(notificationService)
+	loginRewardJob := jobs.NewLoginRewardJob(dailyService, 15*time.Minute)
+	scheduler.Register(loginRewardJob)
 
 	// Leaderboard service initialization
 	leaderboardRepo := repositories.NewLeaderboardRepository(database.Pool)
 	achievementRepo := repositories.NewAchievementRepository(database.Pool)
 	leaderboardService := leaderboardusecase.NewService(leaderboardRepo, achievementRepo, userRepo, fighterRepo, goldRepo)
+	leaderboardService.SetNotifier(notificationService)
+	leaderboardService.SetLeaguePoints(standingsService)
+	leaderboardService.SetUnitOfWork(db.NewUnitOfWork(database.Pool))
+	leaderboardJob := jobs.NewLeaderboardJob(leaderboardService, time.Hour)
+	scheduler.Register(leaderboardJob)
 
 	// Event service initialization
 	eventRepo := repositories.NewEventRepository(database.Pool)
 	eventService := eventsusecase.NewService(eventRepo)
+	eventService.SetNotifier(notificationService)
+	weekendEventJob := jobs.NewWeekendEventJob(eventService, 5*time.Minute)
+	scheduler.Register(weekendEventJob)
+
+	scheduler.Start(ctx)
+
+	// Instances take traffic while the database is reachable and migrated
+	readiness := handlers.NewReadiness()
+	readiness.AddCheck("database", database.Pool.Ping)
+	readiness.AddCheck("migrations", func(ctx context.Context) error {
+		pending, err := db.PendingMigrations(ctx, database.Pool, migrationsDir)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d migrations pending", len(pending))
+		}
+		return nil
+	})
 
 	mcpFilter := mcp.NewFairnessFilter(100, 1*time.Minute)
 	mcpHandler := mcp.NewMCPHandler(mcpFilter, identityService, rosterService, inventoryService, leagueService, matchService, rewardService)
@@ -153,27 +303,110 @@ func main() {
 			MatchService:     matchService,
 			InventoryService: inventoryService,
 			WeaponService:    weaponService,
-			SkillService:        nil,
+			SkillService:        skillService,
 			ShopService:         shopService,
 			AttunementService:   attunementService,
 			DailyService:        dailyService,
 			LeagueService:       leagueService,
 			LeagueJob:        leagueJob,
+			JobScheduler:     scheduler,
 			RewardService:    rewardService,
 			SeasonService:    seasonService,
+			TournamentService: tournamentService,
+			StandingsService:  standingsService,
 			MatchHub:         matchHub,
 			MCPHandler:         mcpHandler,
 			MCPAuditLogger:     mcpAuditLogger,
 			MCPFilter:          mcpFilter,
 			LeaderboardService: leaderboardService,
 			EventService:       eventService,
+			NotificationService: notificationService,
+			MatchmakingService:  matchmakingService,
+			Readiness:           readiness,
 		}),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	log.Printf("api listening on %s", cfg.HTTPAddress)
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("api listening on %s", cfg.HTTPAddress)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	failed := false
+	select {
+	case <-ctx.Done():
+		log.Printf("shutting down")
+	case err := <-serverErr:
 		log.Printf("server error: %v", err)
+		failed = true
+	}
+	// A second signal kills the process
+	stopSignals()
+
+	// Failing readiness first leaves load balancers time to stop sending
+	// requests before the server stops taking them
+	readiness.Drain()
+	if !failed {
+		time.Sleep(cfg.ReadinessDrainDelay)
+	}
+
+	// Every phase gets a budget of its own. The database is closed only when
+	// nothing using it is left running.
+	drained := true
+	// Stop taking requests and let the running ones finish
+	drained = shutdownWithin(cfg.ShutdownTimeout, "http server", server.Shutdown) && drained
+	// Scheduled jobs are cancelled
+	drained = stopWithin(cfg.ShutdownTimeout, "job scheduler", scheduler.Stop) && drained
+	// Matches being executed run to their end, and live streams play out,
+	// until their budget runs out. Both return once their work did: matches
+	// cut short are recovered by the sweep of another instance, and streams
+	// cut short reveal their outcome right away.
+	shutdownWithin(cfg.ShutdownTimeout, "match queue", matchQueueJob.Shutdown)
+	shutdownWithin(cfg.ShutdownTimeout, "live matches", matchService.Shutdown)
+	// Spectators stay connected until the streams ended. WebSocket
+	// connections were hijacked from the server, so the hub closes them.
+	drained = shutdownWithin(cfg.ShutdownTimeout, "websockets", matchHub.Shutdown) && drained
+	if drained {
+		_ = skillsDB.Close()
+		database.Pool.Close()
+	} else {
+		log.Printf("shutdown: leaving the database open to work still running")
+	}
+	log.Printf("shutdown complete")
+
+	if failed {
 		os.Exit(1)
 	}
 }
+
+// shutdownWithin calls shutdown with a context ending after timeout and
+// reports whether it finished in time.
+func shutdownWithin(timeout time.Duration, name string, shutdown func(ctx context.Context) error) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		log.Printf("shutdown: %s: %v", name, err)
+		return false
+	}
+	return true
+}
+
+// stopWithin calls stop and reports whether it returned within timeout. A
+// stop still running is left to the process exit.
+func stopWithin(timeout time.Duration, name string, stop func()) bool {
+	done := make(chan struct{})
+	go func() {
+		stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		log.Printf("shutdown: %s did not stop in time", name)
+		return false
+	}
+}
@@ -1,9 +1,81 @@
 package handlers
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
 
+	"empoweredpixels/internal/adapter/http/responses"
+)
+
+// Health reports the process is alive. It doesn't look at its dependencies,
+// so that a database outage doesn't get live instances restarted.
 func Health() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+// readinessTimeout bounds the checks of one readiness probe.
+const readinessTimeout = 2 * time.Second
+
+// Readiness reports whether the instance takes traffic: it is not draining
+// and every check passes.
+type Readiness struct {
+	checks   []readinessCheck
+	draining atomic.Bool
+}
+
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type readinessDto struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// AddCheck adds a check that returns why the instance can't take traffic.
+func (r *Readiness) AddCheck(name string, check func(ctx context.Context) error) {
+	r.checks = append(r.checks, readinessCheck{name: name, check: check})
+}
+
+// Drain fails every probe from now on, so that load balancers stop sending
+// requests to an instance shutting down.
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+// Handler answers 200 when the instance is ready and 503 with the failed
+// checks otherwise.
+func (r *Readiness) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), readinessTimeout)
+		defer cancel()
+
+		dto := readinessDto{Status: "ready", Checks: make(map[string]string, len(r.checks))}
+		status := http.StatusOK
+		if r.draining.Load() {
+			dto.Status = "draining"
+			status = http.StatusServiceUnavailable
+		}
+		for _, c := range r.checks {
+			if err := c.check(ctx); err != nil {
+				dto.Checks[c.name] = err.Error()
+				if status == http.StatusOK {
+					dto.Status = "unavailable"
+					status = http.StatusServiceUnavailable
+				}
+				continue
+			}
+			dto.Checks[c.name] = "ok"
+		}
+		responses.JSON(w, status, dto)
+	}
+}
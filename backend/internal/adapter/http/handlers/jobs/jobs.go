@@ -0,0 +1,134 @@
+package jobs
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"empoweredpixels/internal/adapter/http/responses"
+	jobsdomain "empoweredpixels/internal/domain/jobs"
+	"empoweredpixels/internal/infra/jobs"
+)
+
+// MaxRunsPageSize caps how many runs of a job are listed.
+const MaxRunsPageSize = 100
+
+type Handler struct {
+	scheduler *jobs.Scheduler
+}
+
+func NewHandler(scheduler *jobs.Scheduler) *Handler {
+	return &Handler{scheduler: scheduler}
+}
+
+type jobRunDto struct {
+	ID         int64     `json:"id,omitempty"`
+	Job        string    `json:"job"`
+	Trigger    string    `json:"trigger"`
+	Instance   string    `json:"instance"`
+	Started    time.Time `json:"started"`
+	DurationMS int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type jobDto struct {
+	Name            string     `json:"name"`
+	IntervalSeconds float64    `json:"intervalSeconds"`
+	Running         bool       `json:"running"`
+	LastRun         *jobRunDto `json:"lastRun,omitempty"`
+}
+
+type jobListDto struct {
+	Instance string   `json:"instance"`
+	Leader   bool     `json:"leader"`
+	Jobs     []jobDto `json:"jobs"`
+}
+
+// List handles GET /admin/jobs. Running tells whether the instance that
+// answers runs the job; the last run is the latest of any instance.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.scheduler.Jobs(r.Context())
+	if err != nil {
+		log.Printf("job list error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	dto := jobListDto{
+		Instance: h.scheduler.Instance(),
+		Leader:   h.scheduler.Leader(),
+		Jobs:     make([]jobDto, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		job := jobDto{
+			Name:            status.Name,
+			IntervalSeconds: status.Interval.Seconds(),
+			Running:         status.Running,
+		}
+		if status.LastRun != nil {
+			run := mapRun(*status.LastRun)
+			job.LastRun = &run
+		}
+		dto.Jobs = append(dto.Jobs, job)
+	}
+	responses.JSON(w, http.StatusOK, dto)
+}
+
+// Runs handles GET /admin/jobs/{name}/runs. Query parameters: limit.
+func (h *Handler) Runs(w http.ResponseWriter, r *http.Request, name string) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > MaxRunsPageSize {
+		limit = MaxRunsPageSize
+	}
+
+	runs, err := h.scheduler.Runs(r.Context(), name, limit)
+	if err != nil {
+		if err == jobs.ErrUnknownJob {
+			responses.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("job runs error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	items := make([]jobRunDto, 0, len(runs))
+	for _, run := range runs {
+		items = append(items, mapRun(run))
+	}
+	responses.JSON(w, http.StatusOK, items)
+}
+
+// Trigger handles POST /admin/jobs/{name}/run. The job runs on the instance
+// that answers, leader or not, and the response is its run.
+func (h *Handler) Trigger(w http.ResponseWriter, r *http.Request, name string) {
+	run, err := h.scheduler.Trigger(r.Context(), name)
+	if err != nil {
+		switch err {
+		case jobs.ErrUnknownJob:
+			responses.Error(w, http.StatusNotFound, err.Error())
+		case jobs.ErrJobRunning:
+			responses.Error(w, http.StatusConflict, err.Error())
+		case jobs.ErrSchedulerStopped:
+			responses.Error(w, http.StatusServiceUnavailable, err.Error())
+		default:
+			log.Printf("job trigger error: %v", err)
+			responses.Error(w, http.StatusInternalServerError, "server error")
+		}
+		return
+	}
+	responses.JSON(w, http.StatusOK, mapRun(*run))
+}
+
+func mapRun(run jobsdomain.Run) jobRunDto {
+	return jobRunDto{
+		ID:         run.ID,
+		Job:        run.Job,
+		Trigger:    run.Trigger,
+		Instance:   run.Instance,
+		Started:    run.Started,
+		DurationMS: run.Duration.Milliseconds(),
+		Error:      run.Error,
+	}
+}
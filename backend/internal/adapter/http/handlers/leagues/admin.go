@@ -0,0 +1,123 @@
+package leagues
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"empoweredpixels/internal/adapter/http/responses"
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/tournaments"
+	leaguesusecase "empoweredpixels/internal/usecase/leagues"
+)
+
+type leagueAdminDto struct {
+	Name          string          `json:"name"`
+	Options       leagueConfigDto `json:"options"`
+	IsDeactivated bool            `json:"isDeactivated"`
+}
+
+// AdminList handles GET /admin/league. Deactivated leagues are included.
+func (h *Handler) AdminList(w http.ResponseWriter, r *http.Request) {
+	leaguesList, err := h.service.ListAll(r.Context())
+	if err != nil {
+		log.Printf("league admin list error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	result := make([]leagueDto, 0, len(leaguesList))
+	for _, league := range leaguesList {
+		result = append(result, mapLeague(league))
+	}
+	responses.JSON(w, http.StatusOK, result)
+}
+
+// AdminCreate handles POST /admin/league.
+func (h *Handler) AdminCreate(w http.ResponseWriter, r *http.Request) {
+	var payload leagueAdminDto
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	league, err := h.service.Create(r.Context(), payload.Name, toLeagueConfig(payload.Options), payload.IsDeactivated)
+	if err != nil {
+		h.adminError(w, "create", err)
+		return
+	}
+	responses.JSON(w, http.StatusCreated, mapLeague(*league))
+}
+
+// AdminUpdate handles PUT /admin/league/{id}.
+func (h *Handler) AdminUpdate(w http.ResponseWriter, r *http.Request, id string) {
+	leagueID, err := strconv.Atoi(id)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid league")
+		return
+	}
+
+	var payload leagueAdminDto
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	league, err := h.service.Update(r.Context(), leagueID, payload.Name, toLeagueConfig(payload.Options), payload.IsDeactivated)
+	if err != nil {
+		h.adminError(w, "update", err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, mapLeague(*league))
+}
+
+// AdminDelete handles DELETE /admin/league/{id}.
+func (h *Handler) AdminDelete(w http.ResponseWriter, r *http.Request, id string) {
+	leagueID, err := strconv.Atoi(id)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid league")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), leagueID); err != nil {
+		h.adminError(w, "delete", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) adminError(w http.ResponseWriter, action string, err error) {
+	switch err {
+	case leaguesusecase.ErrInvalidLeague:
+		responses.Error(w, http.StatusNotFound, err.Error())
+	case leagues.ErrInvalidConfig, leagues.ErrInvalidSchedule:
+		responses.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("league admin %s error: %v", action, err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+	}
+}
+
+func toLeagueConfig(dto leagueConfigDto) leagues.Config {
+	config := leagues.Config{
+		Schedule:           dto.Schedule,
+		Format:             tournaments.Format(dto.Format),
+		MinFighters:        dto.MinFighters,
+		MaxFighters:        dto.MaxFighters,
+		MinLevel:           dto.MinLevel,
+		MaxLevel:           dto.MaxLevel,
+		MaxPowerlevel:      dto.MaxPowerlevel,
+		EntryFee:           dto.EntryFee,
+		MaxFightersPerUser: dto.MaxFightersPerUser,
+		TeamSize:           dto.TeamSize,
+		PlacementPoints:    dto.PlacementPoints,
+		KillPoints:         dto.KillPoints,
+		BotCount:           dto.BotCount,
+		BotPowerlevel:      dto.BotPowerlevel,
+	}
+	for _, w := range dto.Deactivations {
+		config.Deactivations = append(config.Deactivations, leagues.Window{From: w.From, To: w.To})
+	}
+	return config
+}
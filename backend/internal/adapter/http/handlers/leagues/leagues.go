@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"empoweredpixels/internal/adapter/http/middleware"
 	"empoweredpixels/internal/adapter/http/responses"
@@ -21,9 +22,34 @@ func NewHandler(service *leaguesusecase.Service) *Handler {
 }
 
 type leagueDto struct {
-	ID      int             `json:"id"`
-	Name    string          `json:"name"`
-	Options json.RawMessage `json:"options"`
+	ID            int             `json:"id"`
+	Name          string          `json:"name"`
+	Options       leagueConfigDto `json:"options"`
+	IsDeactivated bool            `json:"isDeactivated"`
+	LastRun       *time.Time      `json:"lastRun,omitempty"`
+}
+
+type leagueConfigDto struct {
+	Schedule           string            `json:"schedule"`
+	Format             string            `json:"format"`
+	MinFighters        int               `json:"minFighters"`
+	MaxFighters        int               `json:"maxFighters"`
+	MinLevel           int               `json:"minLevel"`
+	MaxLevel           int               `json:"maxLevel"`
+	MaxPowerlevel      int               `json:"maxPowerlevel"`
+	EntryFee           int               `json:"entryFee"`
+	MaxFightersPerUser int               `json:"maxFightersPerUser"`
+	TeamSize           int               `json:"teamSize"`
+	PlacementPoints    []int             `json:"placementPoints"`
+	KillPoints         int               `json:"killPoints"`
+	BotCount           *int              `json:"botCount,omitempty"`
+	BotPowerlevel      *int              `json:"botPowerlevel,omitempty"`
+	Deactivations      []leagueWindowDto `json:"deactivations"`
+}
+
+type leagueWindowDto struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
 }
 
 type leagueDetailDto struct {
@@ -32,7 +58,11 @@ type leagueDetailDto struct {
 }
 
 type leagueLastWinnerDto struct {
-	LeagueID int `json:"leagueId"`
+	LeagueID     int        `json:"leagueId"`
+	TournamentID string     `json:"tournamentId,omitempty"`
+	FighterID    string     `json:"fighterId,omitempty"`
+	UserID       int64      `json:"userId,omitempty"`
+	Completed    *time.Time `json:"completed,omitempty"`
 }
 
 type leagueSubscriptionDto struct {
@@ -45,17 +75,6 @@ type leagueMatchDto struct {
 	MatchID  string `json:"matchId"`
 }
 
-type leagueHighscoreDto struct {
-	FighterID   string `json:"fighterId"`
-	FighterName string `json:"fighterName"`
-	Username    string `json:"username"`
-	Score       int    `json:"score"`
-}
-
-type leagueHighscoreOptionsDto struct {
-	LastMatches int `json:"lastMatches"`
-}
-
 type pagingOptions struct {
 	Page     int `json:"page"`
 	PageSize int `json:"pageSize"`
@@ -124,7 +143,21 @@ func (h *Handler) LastWinner(w http.ResponseWriter, r *http.Request, id string)
 		return
 	}
 
-	responses.JSON(w, http.StatusOK, leagueLastWinnerDto{LeagueID: leagueID})
+	tournament, champion, err := h.service.LastWinner(r.Context(), leagueID)
+	if err != nil {
+		log.Printf("league last winner error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	dto := leagueLastWinnerDto{LeagueID: leagueID}
+	if tournament != nil && champion != nil {
+		dto.TournamentID = tournament.ID
+		dto.FighterID = champion.FighterID
+		dto.UserID = champion.UserID
+		dto.Completed = tournament.Completed
+	}
+	responses.JSON(w, http.StatusOK, dto)
 }
 
 func (h *Handler) Subscribe(w http.ResponseWriter, r *http.Request) {
@@ -142,9 +175,16 @@ func (h *Handler) Subscribe(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.service.Subscribe(r.Context(), userID, payload.LeagueID, payload.FighterID); err != nil {
 		switch err {
-		case leaguesusecase.ErrInvalidLeague, leaguesusecase.ErrInvalidFighter:
+		case leaguesusecase.ErrInvalidLeague, leaguesusecase.ErrInvalidFighter, leaguesusecase.ErrLeagueInactive,
+			leagues.ErrLevelTooLow, leagues.ErrLevelTooHigh, leagues.ErrPowerTooHigh:
 			responses.Error(w, http.StatusBadRequest, err.Error())
 			return
+		case leaguesusecase.ErrLeagueFull, leaguesusecase.ErrFighterLimit:
+			responses.Error(w, http.StatusConflict, err.Error())
+			return
+		case leaguesusecase.ErrInsufficientGold:
+			responses.Error(w, http.StatusPaymentRequired, err.Error())
+			return
 		default:
 			log.Printf("league subscribe error: %v", err)
 			responses.Error(w, http.StatusInternalServerError, "server error")
@@ -258,16 +298,35 @@ func (h *Handler) Matches(w http.ResponseWriter, r *http.Request, id string) {
 	})
 }
 
-func (h *Handler) Highscores(w http.ResponseWriter, r *http.Request, id string) {
-	responses.JSON(w, http.StatusOK, []leagueHighscoreDto{})
-}
-
 func mapLeague(league leagues.League) leagueDto {
-	return leagueDto{
-		ID:      league.ID,
-		Name:    league.Name,
-		Options: league.Options,
+	config := league.Config
+	dto := leagueDto{
+		ID:   league.ID,
+		Name: league.Name,
+		Options: leagueConfigDto{
+			Schedule:           config.Schedule,
+			Format:             string(config.Format),
+			MinFighters:        config.MinFighters,
+			MaxFighters:        config.MaxFighters,
+			MinLevel:           config.MinLevel,
+			MaxLevel:           config.MaxLevel,
+			MaxPowerlevel:      config.MaxPowerlevel,
+			EntryFee:           config.EntryFee,
+			MaxFightersPerUser: config.MaxFightersPerUser,
+			TeamSize:           config.TeamSize,
+			PlacementPoints:    config.PlacementPoints,
+			KillPoints:         config.KillPoints,
+			BotCount:           config.BotCount,
+			BotPowerlevel:      config.BotPowerlevel,
+			Deactivations:      make([]leagueWindowDto, 0, len(config.Deactivations)),
+		},
+		IsDeactivated: league.IsDeactivated,
+		LastRun:       league.LastRun,
+	}
+	for _, w := range config.Deactivations {
+		dto.Options.Deactivations = append(dto.Options.Deactivations, leagueWindowDto{From: w.From, To: w.To})
 	}
+	return dto
 }
 
 func mapSubscriptions(subs []leagues.LeagueSubscription) []leagueSubscriptionDto {
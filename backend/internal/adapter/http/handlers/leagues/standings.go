@@ -0,0 +1,199 @@
+package leagues
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"empoweredpixels/internal/adapter/http/responses"
+	"empoweredpixels/internal/domain/leagues"
+	standingsusecase "empoweredpixels/internal/usecase/standings"
+)
+
+// MaxStandingsPageSize caps the page size of a standings table and the
+// number of highscores.
+const MaxStandingsPageSize = 100
+
+type StandingsHandler struct {
+	service *standingsusecase.Service
+}
+
+func NewStandingsHandler(service *standingsusecase.Service) *StandingsHandler {
+	return &StandingsHandler{service: service}
+}
+
+type standingDto struct {
+	Position    int       `json:"position"`
+	FighterID   string    `json:"fighterId"`
+	FighterName string    `json:"fighterName"`
+	UserID      int64     `json:"userId"`
+	Username    string    `json:"username"`
+	Points      int       `json:"points"`
+	Matches     int       `json:"matches"`
+	Wins        int       `json:"wins"`
+	Kills       int       `json:"kills"`
+	Deaths      int       `json:"deaths"`
+	DamageDealt int       `json:"damageDealt"`
+	Updated     time.Time `json:"updated"`
+}
+
+type standingsTableDto struct {
+	Season int `json:"season"`
+	pageDto[standingDto]
+}
+
+type leagueHighscoreDto struct {
+	FighterID   string `json:"fighterId"`
+	FighterName string `json:"fighterName"`
+	Username    string `json:"username"`
+	Score       int    `json:"score"`
+}
+
+type leagueHighscoreOptionsDto struct {
+	LastMatches int `json:"lastMatches"`
+	Limit       int `json:"limit"`
+}
+
+// Table handles GET /league/{id}/standings. Query parameters: season (the
+// current season when missing), page and pageSize.
+func (h *StandingsHandler) Table(w http.ResponseWriter, r *http.Request, id string) {
+	leagueID, err := strconv.Atoi(id)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid league")
+		return
+	}
+
+	query := r.URL.Query()
+	var season *int
+	if value := query.Get("season"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			responses.Error(w, http.StatusBadRequest, "invalid season")
+			return
+		}
+		season = &parsed
+	}
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageSize <= 0 {
+		pageSize = standingsusecase.DefaultPageSize
+	}
+	if pageSize > MaxStandingsPageSize {
+		pageSize = MaxStandingsPageSize
+	}
+
+	table, err := h.service.Table(r.Context(), leagueID, season, page, pageSize)
+	if err != nil {
+		if err == standingsusecase.ErrInvalidLeague {
+			responses.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("league standings error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	items := make([]standingDto, 0, len(table.Standings))
+	for _, s := range table.Standings {
+		items = append(items, mapStanding(s))
+	}
+	responses.JSON(w, http.StatusOK, standingsTableDto{
+		Season: table.Season,
+		pageDto: pageDto[standingDto]{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalCount: table.TotalCount,
+			Items:      items,
+		},
+	})
+}
+
+// Seasons handles GET /league/{id}/standings/seasons.
+func (h *StandingsHandler) Seasons(w http.ResponseWriter, r *http.Request, id string) {
+	leagueID, err := strconv.Atoi(id)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid league")
+		return
+	}
+
+	list, err := h.service.Seasons(r.Context(), leagueID)
+	if err != nil {
+		if err == standingsusecase.ErrInvalidLeague {
+			responses.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("league standing seasons error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if list == nil {
+		list = []int{}
+	}
+	responses.JSON(w, http.StatusOK, list)
+}
+
+// Highscores handles POST /league/{id}/highscores. The points over the latest
+// lastMatches matches are the score, or the points of the current season
+// when lastMatches is 0.
+func (h *StandingsHandler) Highscores(w http.ResponseWriter, r *http.Request, id string) {
+	leagueID, err := strconv.Atoi(id)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid league")
+		return
+	}
+
+	var payload leagueHighscoreOptionsDto
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			responses.Error(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+	if payload.Limit <= 0 || payload.Limit > MaxStandingsPageSize {
+		payload.Limit = MaxStandingsPageSize
+	}
+
+	list, err := h.service.Highscores(r.Context(), leagueID, payload.LastMatches, payload.Limit)
+	if err != nil {
+		if err == standingsusecase.ErrInvalidLeague {
+			responses.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("league highscores error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	items := make([]leagueHighscoreDto, 0, len(list))
+	for _, s := range list {
+		items = append(items, leagueHighscoreDto{
+			FighterID:   s.FighterID,
+			FighterName: s.FighterName,
+			Username:    s.Username,
+			Score:       s.Points,
+		})
+	}
+	responses.JSON(w, http.StatusOK, items)
+}
+
+func mapStanding(s leagues.Standing) standingDto {
+	return standingDto{
+		Position:    s.Position,
+		FighterID:   s.FighterID,
+		FighterName: s.FighterName,
+		UserID:      s.UserID,
+		Username:    s.Username,
+		Points:      s.Points,
+		Matches:     s.Matches,
+		Wins:        s.Wins,
+		Kills:       s.Kills,
+		Deaths:      s.Deaths,
+		DamageDealt: s.DamageDealt,
+		Updated:     s.Updated,
+	}
+}
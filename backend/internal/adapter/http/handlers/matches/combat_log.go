@@ -0,0 +1,162 @@
+package matches
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"empoweredpixels/internal/adapter/http/responses"
+	"empoweredpixels/internal/domain/matches"
+	matchesusecase "empoweredpixels/internal/usecase/matches"
+)
+
+type combatEventDto struct {
+	Round   int             `json:"round"`
+	Tick    int             `json:"tick"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type battleScoreDto struct {
+	FighterID   string `json:"fighterId"`
+	IsBot       bool   `json:"isBot"`
+	Kills       int    `json:"kills"`
+	Deaths      int    `json:"deaths"`
+	Assists     int    `json:"assists"`
+	DamageDealt int    `json:"damageDealt"`
+	DamageTaken int    `json:"damageTaken"`
+	Healed      int    `json:"healed"`
+}
+
+type battleSummaryDto struct {
+	MatchID      string           `json:"matchId"`
+	WinnerID     *string          `json:"winnerId"`
+	WinnerTeamID *string          `json:"winnerTeamId"`
+	WinnerIDs    []string         `json:"winnerIds"`
+	TotalRounds  int              `json:"totalRounds"`
+	TotalDamage  int              `json:"totalDamage"`
+	TotalHealed  int              `json:"totalHealed"`
+	Scores       []battleScoreDto `json:"scores"`
+}
+
+// Events handles GET /match/{id}/events. Query parameters: fighterId, type
+// (comma separated or repeated), fromRound, toRound, page and pageSize.
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request, id string) {
+	query := r.URL.Query()
+	filter := matches.CombatLogFilter{FighterID: query.Get("fighterId")}
+	for _, value := range query["type"] {
+		for _, eventType := range strings.Split(value, ",") {
+			if eventType = strings.TrimSpace(eventType); eventType != "" {
+				filter.EventTypes = append(filter.EventTypes, eventType)
+			}
+		}
+	}
+
+	var err error
+	if filter.FromRound, err = optionalInt(query.Get("fromRound")); err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid fromRound")
+		return
+	}
+	if filter.ToRound, err = optionalInt(query.Get("toRound")); err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid toRound")
+		return
+	}
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = matchesusecase.DefaultCombatLogPageSize
+	}
+	if pageSize > matchesusecase.MaxCombatLogPageSize {
+		pageSize = matchesusecase.MaxCombatLogPageSize
+	}
+
+	entries, total, err := h.service.CombatLog(r.Context(), id, filter, page, pageSize)
+	if err != nil {
+		switch err {
+		case matchesusecase.ErrCombatLogDisabled:
+			responses.Error(w, http.StatusServiceUnavailable, err.Error())
+			return
+		case matchesusecase.ErrMatchLive:
+			responses.Error(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Printf("match events error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	items := make([]combatEventDto, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, combatEventDto{
+			Round:   entry.Round,
+			Tick:    entry.Tick,
+			Type:    entry.EventType,
+			Payload: entry.Payload,
+		})
+	}
+	responses.JSON(w, http.StatusOK, pageDto[combatEventDto]{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: total,
+		Items:      items,
+	})
+}
+
+// Summary handles GET /match/{id}/summary.
+func (h *Handler) Summary(w http.ResponseWriter, r *http.Request, id string) {
+	summary, err := h.service.BattleSummary(r.Context(), id)
+	if err != nil {
+		switch err {
+		case matchesusecase.ErrInvalidMatch:
+			responses.Error(w, http.StatusNotFound, "match summary not found")
+		case matchesusecase.ErrCombatLogDisabled:
+			responses.Error(w, http.StatusServiceUnavailable, err.Error())
+		case matchesusecase.ErrMatchLive:
+			responses.Error(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("match summary error: %v", err)
+			responses.Error(w, http.StatusInternalServerError, "server error")
+		}
+		return
+	}
+
+	scores := make([]battleScoreDto, 0, len(summary.Scores))
+	for _, score := range summary.Scores {
+		scores = append(scores, battleScoreDto{
+			FighterID:   score.FighterID,
+			IsBot:       score.IsBot,
+			Kills:       score.Kills,
+			Deaths:      score.Deaths,
+			Assists:     score.Assists,
+			DamageDealt: score.DamageDealt,
+			DamageTaken: score.DamageTaken,
+			Healed:      score.Healed,
+		})
+	}
+	responses.JSON(w, http.StatusOK, battleSummaryDto{
+		MatchID:      summary.MatchID,
+		WinnerID:     summary.WinnerID,
+		WinnerTeamID: summary.WinnerTeamID,
+		WinnerIDs:    summary.WinnerIDs,
+		TotalRounds:  summary.TotalRounds,
+		TotalDamage:  summary.TotalDamage,
+		TotalHealed:  summary.TotalHealed,
+		Scores:       scores,
+	})
+}
+
+func optionalInt(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -34,6 +34,10 @@ type matchOptionsDto struct {
 	MoveOrder          string   `json:"moveOrder"`
 	WinCondition       string   `json:"winCondition"`
 	StaleCondition     string   `json:"staleCondition"`
+	Live               bool     `json:"live"`
+	RoundIntervalMs    *int     `json:"roundIntervalMs"`
+	SpectatorDelayMs   *int     `json:"spectatorDelayMs"`
+	Engine             string   `json:"engine"`
 }
 
 type matchDto struct {
@@ -82,11 +86,15 @@ type pageDto[T any] struct {
 }
 
 type matchScoreFighterDto struct {
-	MatchID      string `json:"matchId"`
-	FighterID    string `json:"fighterId"`
-	TotalKills   int    `json:"totalKills"`
-	TotalDeaths  int    `json:"totalDeaths"`
-	TotalAssists int    `json:"totalAssists"`
+	MatchID          string `json:"matchId"`
+	FighterID        string `json:"fighterId"`
+	IsBot            bool   `json:"isBot"`
+	TotalKills       int    `json:"totalKills"`
+	TotalDeaths      int    `json:"totalDeaths"`
+	TotalAssists     int    `json:"totalAssists"`
+	TotalDamageDealt int    `json:"totalDamageDealt"`
+	TotalDamageTaken int    `json:"totalDamageTaken"`
+	TotalHealed      int    `json:"totalHealed"`
 }
 
 func (h *Handler) GetDefaultOptions(w http.ResponseWriter, r *http.Request) {
@@ -122,8 +130,16 @@ func (h *Handler) CreateMatch(w http.ResponseWriter, r *http.Request) {
 		BotCount:           payload.BotCount,
 		BotPowerlevel:      payload.BotPowerlevel,
 		AutoStart:          payload.AutoStart,
+		Live:               payload.Live,
+		RoundIntervalMs:    payload.RoundIntervalMs,
+		SpectatorDelayMs:   payload.SpectatorDelayMs,
+		Engine:             payload.Engine,
 	})
 	if err != nil {
+		if err == matchesusecase.ErrUnknownEngine {
+			responses.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		log.Printf("match create error: %v", err)
 		responses.Error(w, http.StatusInternalServerError, "server error")
 		return
@@ -366,20 +382,24 @@ func (h *Handler) LeaveTeam(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// Start queues the match for execution. The outcome arrives over the match
+// channel.
 func (h *Handler) Start(w http.ResponseWriter, r *http.Request, id string) {
-	if err := h.service.ExecuteMatch(r.Context(), id); err != nil {
+	if err := h.service.StartMatch(r.Context(), id); err != nil {
 		switch err {
 		case matchesusecase.ErrInvalidMatch, matchesusecase.ErrMatchNotLobby, matchesusecase.ErrNotEnoughFighters:
-			log.Printf("ExecuteMatch error: %v", err)
 			responses.Error(w, http.StatusBadRequest, err.Error())
 			return
+		case matchesusecase.ErrServiceStopped:
+			responses.Error(w, http.StatusServiceUnavailable, err.Error())
+			return
 		default:
-			log.Printf("ExecuteMatch error: %v", err)
+			log.Printf("StartMatch error: %v", err)
 			responses.Error(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(http.StatusAccepted)
 }
 
 func (h *Handler) RoundTicks(w http.ResponseWriter, r *http.Request, id string) {
@@ -399,9 +419,33 @@ func (h *Handler) RoundTicks(w http.ResponseWriter, r *http.Request, id string)
 	_, _ = w.Write(data)
 }
 
+func (h *Handler) VerifyReplay(w http.ResponseWriter, r *http.Request, id string) {
+	verification, err := h.service.VerifyReplay(r.Context(), id)
+	if err != nil {
+		switch err {
+		case matchesusecase.ErrInvalidMatch, matchesusecase.ErrReplayUnavailable:
+			responses.Error(w, http.StatusBadRequest, err.Error())
+			return
+		case matchesusecase.ErrMatchLive:
+			responses.Error(w, http.StatusConflict, err.Error())
+			return
+		default:
+			log.Printf("match replay verify error: %v", err)
+			responses.Error(w, http.StatusInternalServerError, "server error")
+			return
+		}
+	}
+
+	responses.JSON(w, http.StatusOK, verification)
+}
+
 func (h *Handler) FighterScores(w http.ResponseWriter, r *http.Request, id string) {
 	scores, err := h.service.FighterScores(r.Context(), id)
 	if err != nil {
+		if err == matchesusecase.ErrMatchLive {
+			responses.Error(w, http.StatusConflict, err.Error())
+			return
+		}
 		log.Printf("match fighter scores error: %v", err)
 		responses.Error(w, http.StatusInternalServerError, "server error")
 		return
@@ -410,11 +454,15 @@ func (h *Handler) FighterScores(w http.ResponseWriter, r *http.Request, id strin
 	result := make([]matchScoreFighterDto, 0, len(scores))
 	for _, score := range scores {
 		result = append(result, matchScoreFighterDto{
-			MatchID:      score.MatchID,
-			FighterID:    score.FighterID,
-			TotalKills:   score.TotalKills,
-			TotalDeaths:  score.TotalDeaths,
-			TotalAssists: score.TotalAssists,
+			MatchID:          score.MatchID,
+			FighterID:        score.FighterID,
+			IsBot:            score.IsBot,
+			TotalKills:       score.TotalKills,
+			TotalDeaths:      score.TotalDeaths,
+			TotalAssists:     score.TotalAssists,
+			TotalDamageDealt: score.TotalDamageDealt,
+			TotalDamageTaken: score.TotalDamageTaken,
+			TotalHealed:      score.TotalHealed,
 		})
 	}
 	responses.JSON(w, http.StatusOK, result)
@@ -505,3 +553,13 @@ func (h *Handler) GetOnlinePlayers(w http.ResponseWriter, r *http.Request) {
 		"onlinePlayers": count,
 	})
 }
+
+func (h *Handler) QueueMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, err := h.service.QueueMetrics(r.Context())
+	if err != nil {
+		log.Printf("match queue metrics error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	responses.JSON(w, http.StatusOK, metrics)
+}
@@ -0,0 +1,162 @@
+package matchmaking
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"empoweredpixels/internal/adapter/http/middleware"
+	"empoweredpixels/internal/adapter/http/responses"
+	matchmakingusecase "empoweredpixels/internal/usecase/matchmaking"
+)
+
+type Handler struct {
+	service *matchmakingusecase.Service
+}
+
+func NewHandler(service *matchmakingusecase.Service) *Handler {
+	return &Handler{service: service}
+}
+
+type queueStatusDto struct {
+	FighterID     string     `json:"fighterId"`
+	Status        string     `json:"status"`
+	Rating        float64    `json:"rating"`
+	Power         int        `json:"power"`
+	QueuedAt      time.Time  `json:"queuedAt"`
+	MatchID       *string    `json:"matchId"`
+	MatchedAt     *time.Time `json:"matchedAt"`
+	WaitedSeconds int        `json:"waitedSeconds"`
+	EtaSeconds    int        `json:"etaSeconds"`
+	RatingWindow  float64    `json:"ratingWindow"`
+	PowerWindow   float64    `json:"powerWindow"`
+	Waiting       int        `json:"waiting"`
+}
+
+type ratingDto struct {
+	FighterID    string  `json:"fighterId"`
+	Rating       float64 `json:"rating"`
+	Deviation    float64 `json:"deviation"`
+	Volatility   float64 `json:"volatility"`
+	Conservative float64 `json:"conservative"`
+	Matches      int     `json:"matches"`
+	Wins         int     `json:"wins"`
+	Losses       int     `json:"losses"`
+}
+
+// Enqueue handles POST /matchmaking/queue with the fighter to queue.
+func (h *Handler) Enqueue(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	var payload struct {
+		FighterID string `json:"fighterId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	status, err := h.service.Enqueue(r.Context(), userID, payload.FighterID)
+	if err != nil {
+		switch err {
+		case matchmakingusecase.ErrInvalidFighter:
+			responses.Error(w, http.StatusBadRequest, err.Error())
+		case matchmakingusecase.ErrAlreadyQueued:
+			responses.Error(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("matchmaking enqueue error: %v", err)
+			responses.Error(w, http.StatusInternalServerError, "server error")
+		}
+		return
+	}
+
+	responses.JSON(w, http.StatusAccepted, toQueueStatusDto(status))
+}
+
+// Status handles GET /matchmaking/queue: the user's latest ticket and the
+// estimated remaining wait.
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	status, err := h.service.Status(r.Context(), userID)
+	if err != nil {
+		log.Printf("matchmaking status error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if status == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, toQueueStatusDto(status))
+}
+
+// Cancel handles DELETE /matchmaking/queue.
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := h.service.Cancel(r.Context(), userID); err != nil {
+		if err == matchmakingusecase.ErrNotQueued {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		log.Printf("matchmaking cancel error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Rating handles GET /matchmaking/rating/{fighterId}.
+func (h *Handler) Rating(w http.ResponseWriter, r *http.Request, fighterID string) {
+	current, err := h.service.Rating(r.Context(), fighterID)
+	if err != nil {
+		log.Printf("matchmaking rating error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, ratingDto{
+		FighterID:    current.FighterID,
+		Rating:       current.Rating,
+		Deviation:    current.Deviation,
+		Volatility:   current.Volatility,
+		Conservative: current.Conservative(),
+		Matches:      current.Matches,
+		Wins:         current.Wins,
+		Losses:       current.Losses,
+	})
+}
+
+func toQueueStatusDto(status *matchmakingusecase.Status) queueStatusDto {
+	ticket := status.Ticket
+	return queueStatusDto{
+		FighterID:     ticket.FighterID,
+		Status:        ticket.Status,
+		Rating:        ticket.Rating,
+		Power:         ticket.Power,
+		QueuedAt:      ticket.QueuedAt,
+		MatchID:       ticket.MatchID,
+		MatchedAt:     ticket.MatchedAt,
+		WaitedSeconds: int(status.Waited.Seconds()),
+		EtaSeconds:    int(status.Estimate.Seconds()),
+		RatingWindow:  status.Window.Rating,
+		PowerWindow:   status.Window.Power,
+		Waiting:       status.Waiting,
+	}
+}
@@ -0,0 +1,162 @@
+package notifications
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"empoweredpixels/internal/adapter/http/middleware"
+	"empoweredpixels/internal/adapter/http/responses"
+	notificationsusecase "empoweredpixels/internal/usecase/notifications"
+)
+
+type Handler struct {
+	service *notificationsusecase.Service
+}
+
+func NewHandler(service *notificationsusecase.Service) *Handler {
+	return &Handler{service: service}
+}
+
+type notificationDto struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Title   string          `json:"title"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Read    bool            `json:"read"`
+	ReadAt  *time.Time      `json:"readAt"`
+	Created time.Time       `json:"created"`
+}
+
+type inboxDto struct {
+	Page        int               `json:"page"`
+	PageSize    int               `json:"pageSize"`
+	TotalCount  int               `json:"totalCount"`
+	UnreadCount int               `json:"unreadCount"`
+	Items       []notificationDto `json:"items"`
+}
+
+type countDto struct {
+	Count int `json:"count"`
+}
+
+// List handles GET /notifications. Query parameters: unread (only unread
+// notifications when true), page and pageSize.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+	unreadOnly, _ := strconv.ParseBool(query.Get("unread"))
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = notificationsusecase.DefaultPageSize
+	}
+	if pageSize > notificationsusecase.MaxPageSize {
+		pageSize = notificationsusecase.MaxPageSize
+	}
+
+	items, total, err := h.service.List(r.Context(), userID, unreadOnly, page, pageSize)
+	if err != nil {
+		log.Printf("notifications list error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	unread, err := h.service.UnreadCount(r.Context(), userID)
+	if err != nil {
+		log.Printf("notifications count error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	result := make([]notificationDto, 0, len(items))
+	for _, item := range items {
+		data := item.Data
+		if len(data) == 0 {
+			data = []byte("{}")
+		}
+		result = append(result, notificationDto{
+			ID:      item.ID,
+			Type:    item.Type,
+			Title:   item.Title,
+			Message: item.Message,
+			Data:    data,
+			Read:    item.Read(),
+			ReadAt:  item.ReadAt,
+			Created: item.Created,
+		})
+	}
+
+	responses.JSON(w, http.StatusOK, inboxDto{
+		Page:        page,
+		PageSize:    pageSize,
+		TotalCount:  total,
+		UnreadCount: unread,
+		Items:       result,
+	})
+}
+
+// UnreadCount handles GET /notifications/unread-count.
+func (h *Handler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	count, err := h.service.UnreadCount(r.Context(), userID)
+	if err != nil {
+		log.Printf("notifications count error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	responses.JSON(w, http.StatusOK, countDto{Count: count})
+}
+
+// MarkRead handles POST /notifications/{id}/read.
+func (h *Handler) MarkRead(w http.ResponseWriter, r *http.Request, id string) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := h.service.MarkRead(r.Context(), userID, id); err != nil {
+		if err == notificationsusecase.ErrNotificationNotFound {
+			responses.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("notification read error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllRead handles POST /notifications/read-all and returns how many
+// notifications were unread.
+func (h *Handler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	count, err := h.service.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		log.Printf("notifications read-all error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	responses.JSON(w, http.StatusOK, countDto{Count: count})
+}
@@ -0,0 +1,157 @@
+package roster
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"empoweredpixels/internal/adapter/http/middleware"
+	"empoweredpixels/internal/adapter/http/responses"
+	"empoweredpixels/internal/domain/roster"
+	rosterusecase "empoweredpixels/internal/usecase/roster"
+)
+
+type attributesDto struct {
+	Power          int `json:"power"`
+	ConditionPower int `json:"conditionPower"`
+	Precision      int `json:"precision"`
+	Ferocity       int `json:"ferocity"`
+	Accuracy       int `json:"accuracy"`
+	Agility        int `json:"agility"`
+	Armor          int `json:"armor"`
+	Vitality       int `json:"vitality"`
+	ParryChance    int `json:"parryChance"`
+	HealingPower   int `json:"healingPower"`
+	Speed          int `json:"speed"`
+	Vision         int `json:"vision"`
+}
+
+type derivedStatsDto struct {
+	MaxHP             int     `json:"maxHp"`
+	MoveDistance      float64 `json:"moveDistance"`
+	Initiative        int     `json:"initiative"`
+	VisionRange       float64 `json:"visionRange"`
+	DodgeChance       int     `json:"dodgeChance"`
+	ParryChance       int     `json:"parryChance"`
+	CritChance        int     `json:"critChance"`
+	CritMultiplier    float64 `json:"critMultiplier"`
+	DamageTaken       float64 `json:"damageTaken"`
+	ConditionDamage   float64 `json:"conditionDamage"`
+	HealingMultiplier float64 `json:"healingMultiplier"`
+}
+
+type attributeSheetDto struct {
+	FighterID  string          `json:"fighterId"`
+	Level      int             `json:"level"`
+	Points     int             `json:"points"`
+	Available  int             `json:"available"`
+	Cap        int             `json:"cap"`
+	Respecs    int             `json:"respecs"`
+	RespecCost int             `json:"respecCost"`
+	Allocated  attributesDto   `json:"allocated"`
+	Stats      attributesDto   `json:"stats"`
+	Derived    derivedStatsDto `json:"derived"`
+}
+
+func (h *FighterHandler) GetAttributes(w http.ResponseWriter, r *http.Request, id string) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	sheet, err := h.service.Attributes(r.Context(), userID, id)
+	h.writeAttributes(w, sheet, err)
+}
+
+func (h *FighterHandler) PreviewAttributes(w http.ResponseWriter, r *http.Request, id string) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	var payload attributesDto
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	sheet, err := h.service.PreviewAttributes(r.Context(), userID, id, roster.Attributes(payload))
+	h.writeAttributes(w, sheet, err)
+}
+
+func (h *FighterHandler) AllocateAttributes(w http.ResponseWriter, r *http.Request, id string) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	var payload attributesDto
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	sheet, err := h.service.AllocateAttributes(r.Context(), userID, id, roster.Attributes(payload))
+	h.writeAttributes(w, sheet, err)
+}
+
+func (h *FighterHandler) RespecAttributes(w http.ResponseWriter, r *http.Request, id string) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	sheet, err := h.service.RespecAttributes(r.Context(), userID, id)
+	h.writeAttributes(w, sheet, err)
+}
+
+func (h *FighterHandler) writeAttributes(w http.ResponseWriter, sheet *rosterusecase.AttributeSheet, err error) {
+	if err != nil {
+		switch err {
+		case rosterusecase.ErrInvalidAllocation, rosterusecase.ErrNotEnoughPoints, rosterusecase.ErrAttributeCap, rosterusecase.ErrNothingToRespec:
+			responses.Error(w, http.StatusBadRequest, err.Error())
+		case rosterusecase.ErrInsufficientGold:
+			responses.Error(w, http.StatusPaymentRequired, err.Error())
+		default:
+			log.Printf("roster attributes error: %v", err)
+			responses.Error(w, http.StatusInternalServerError, "server error")
+		}
+		return
+	}
+	if sheet == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	fighter := sheet.Fighter
+	derived := sheet.Derived
+	responses.JSON(w, http.StatusOK, attributeSheetDto{
+		FighterID:  fighter.ID,
+		Level:      fighter.Level,
+		Points:     sheet.Points,
+		Available:  sheet.Available,
+		Cap:        sheet.Cap,
+		Respecs:    sheet.Allocation.Respecs,
+		RespecCost: sheet.RespecCost,
+		Allocated:  attributesDto(sheet.Allocation.Points),
+		Stats: attributesDto{
+			Power:          fighter.Power,
+			ConditionPower: fighter.ConditionPower,
+			Precision:      fighter.Precision,
+			Ferocity:       fighter.Ferocity,
+			Accuracy:       fighter.Accuracy,
+			Agility:        fighter.Agility,
+			Armor:          fighter.Armor,
+			Vitality:       fighter.Vitality,
+			ParryChance:    fighter.ParryChance,
+			HealingPower:   fighter.HealingPower,
+			Speed:          fighter.Speed,
+			Vision:         fighter.Vision,
+		},
+		Derived: derivedStatsDto(derived),
+	})
+}
@@ -42,6 +42,14 @@ type fighterDto struct {
 	Created        string  `json:"created"`
 }
 
+type levelUpDto struct {
+	FromLevel  int            `json:"fromLevel"`
+	ToLevel    int            `json:"toLevel"`
+	Experience int            `json:"experience"`
+	Gains      map[string]int `json:"gains"`
+	Created    string         `json:"created"`
+}
+
 type fighterNameDto struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -75,7 +83,7 @@ func (h *FighterHandler) List(w http.ResponseWriter, r *http.Request) {
 	result := make([]fighterDto, 0, len(fighters))
 	for _, fighter := range fighters {
 		exp, _ := h.service.GetExperience(r.Context(), fighter.ID)
-		level, current, next := calculateLevel(exp.Experience)
+		level, current, next := roster.LevelForExperience(exp.Experience)
 		result = append(result, fighterDto{
 			ID:             fighter.ID,
 			Name:           fighter.Name,
@@ -122,7 +130,7 @@ func (h *FighterHandler) Get(w http.ResponseWriter, r *http.Request, id string)
 	}
 
 	exp, _ := h.service.GetExperience(r.Context(), fighter.ID)
-	level, current, next := calculateLevel(exp.Experience)
+	level, current, next := roster.LevelForExperience(exp.Experience)
 	responses.JSON(w, http.StatusOK, fighterDto{
 		ID:             fighter.ID,
 		Name:           fighter.Name,
@@ -194,7 +202,7 @@ func (h *FighterHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	exp, _ := h.service.GetExperience(r.Context(), fighter.ID)
-	level, current, next := calculateLevel(exp.Experience)
+	level, current, next := roster.LevelForExperience(exp.Experience)
 	responses.JSON(w, http.StatusOK, fighterDto{
 		ID:             fighter.ID,
 		Name:           fighter.Name,
@@ -255,7 +263,7 @@ func (h *FighterHandler) GetExperience(w http.ResponseWriter, r *http.Request, i
 		return
 	}
 
-	level, current, next := calculateLevel(exp.Experience)
+	level, current, next := roster.LevelForExperience(exp.Experience)
 	responses.JSON(w, http.StatusOK, fighterExperienceDto{
 		Level:      level,
 		CurrentExp: current,
@@ -263,6 +271,45 @@ func (h *FighterHandler) GetExperience(w http.ResponseWriter, r *http.Request, i
 	})
 }
 
+func (h *FighterHandler) GetLevelHistory(w http.ResponseWriter, r *http.Request, id string) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	fighter, err := h.service.Get(r.Context(), userID, id)
+	if err != nil || fighter == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	history, err := h.service.LevelHistory(r.Context(), id, 0)
+	if err != nil {
+		log.Printf("roster level history error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	result := make([]levelUpDto, 0, len(history))
+	for _, levelUp := range history {
+		result = append(result, levelUpDto{
+			FromLevel:  levelUp.FromLevel,
+			ToLevel:    levelUp.ToLevel,
+			Experience: levelUp.Experience,
+			Gains: map[string]int{
+				"power":    levelUp.Gains.Power,
+				"vitality": levelUp.Gains.Vitality,
+				"accuracy": levelUp.Gains.Accuracy,
+				"agility":  levelUp.Gains.Agility,
+				"armor":    levelUp.Gains.Armor,
+			},
+			Created: levelUp.Created.Format(timeLayout),
+		})
+	}
+	responses.JSON(w, http.StatusOK, result)
+}
+
 func (h *FighterHandler) GetConfiguration(w http.ResponseWriter, r *http.Request, id string) {
 	userID, ok := middleware.UserID(r.Context())
 	if !ok {
@@ -327,15 +374,3 @@ func (h *FighterHandler) UpdateConfiguration(w http.ResponseWriter, r *http.Requ
 }
 
 const timeLayout = "2006-01-02T15:04:05Z07:00"
-
-func calculateLevel(exp int) (level int, current int, next int) {
-	level = 1
-	next = 100
-	current = exp
-	for current >= next {
-		current -= next
-		level++
-		next = 100 * level
-	}
-	return level, current, next
-}
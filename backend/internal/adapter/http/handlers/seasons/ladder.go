@@ -0,0 +1,120 @@
+package seasons
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"empoweredpixels/internal/adapter/http/responses"
+	"empoweredpixels/internal/domain/seasons"
+	seasonsusecase "empoweredpixels/internal/usecase/seasons"
+)
+
+type seasonDto struct {
+	SeasonId  int       `json:"seasonId"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+type seriesDto struct {
+	Kind   string `json:"kind"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Needed int    `json:"needed"`
+}
+
+type placementDto struct {
+	Position     int        `json:"position,omitempty"`
+	FighterId    string     `json:"fighterId"`
+	UserId       int64      `json:"userId"`
+	Division     string     `json:"division"`
+	Rating       float64    `json:"rating"`
+	Conservative float64    `json:"conservative"`
+	Series       *seriesDto `json:"series,omitempty"`
+	LastMatch    time.Time  `json:"lastMatch"`
+}
+
+// Current handles GET /season/current.
+func (h *Handler) Current(w http.ResponseWriter, r *http.Request) {
+	season, err := h.service.CurrentSeason(r.Context())
+	if err != nil {
+		if err == seasonsusecase.ErrNoSeason {
+			responses.Error(w, http.StatusNotFound, "no season running")
+			return
+		}
+		log.Printf("season current error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, seasonDto{
+		SeasonId:  season.SeasonID,
+		StartDate: season.StartDate,
+		EndDate:   season.EndDate,
+	})
+}
+
+// Ladder handles GET /season/ladder. Query parameters: page and pageSize.
+func (h *Handler) Ladder(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > seasonsusecase.MaxLadderPageSize {
+		pageSize = seasonsusecase.DefaultLadderPageSize
+	}
+
+	placements, err := h.service.Ladder(r.Context(), page, pageSize)
+	if err != nil {
+		log.Printf("season ladder error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	items := make([]placementDto, 0, len(placements))
+	for i, placement := range placements {
+		dto := toPlacementDto(placement)
+		dto.Position = (page-1)*pageSize + i + 1
+		items = append(items, dto)
+	}
+	responses.JSON(w, http.StatusOK, items)
+}
+
+// Placement handles GET /season/ladder/{fighterId}.
+func (h *Handler) Placement(w http.ResponseWriter, r *http.Request, fighterID string) {
+	placement, err := h.service.Placement(r.Context(), fighterID)
+	if err != nil {
+		log.Printf("season placement error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	if placement == nil {
+		responses.Error(w, http.StatusNotFound, "fighter is not ranked")
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, toPlacementDto(*placement))
+}
+
+func toPlacementDto(placement seasons.Placement) placementDto {
+	dto := placementDto{
+		FighterId:    placement.Entry.FighterID,
+		UserId:       placement.Entry.UserID,
+		Division:     string(placement.Entry.Division),
+		Rating:       placement.Rating.Rating,
+		Conservative: placement.Rating.Conservative(),
+		LastMatch:    placement.Entry.LastMatch,
+	}
+	if series := placement.Entry.Series; series != nil {
+		dto.Series = &seriesDto{
+			Kind:   string(series.Kind),
+			Wins:   series.Wins,
+			Losses: series.Losses,
+			Needed: seasons.SeriesWinsNeeded,
+		}
+	}
+	return dto
+}
@@ -0,0 +1,199 @@
+package tournaments
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"empoweredpixels/internal/adapter/http/responses"
+	"empoweredpixels/internal/domain/tournaments"
+	tournamentsusecase "empoweredpixels/internal/usecase/tournaments"
+)
+
+// MaxHistoryPageSize caps the page size of the tournament history.
+const MaxHistoryPageSize = 50
+
+type Handler struct {
+	service *tournamentsusecase.Service
+}
+
+func NewHandler(service *tournamentsusecase.Service) *Handler {
+	return &Handler{service: service}
+}
+
+type tournamentDto struct {
+	ID        string     `json:"id"`
+	LeagueID  int        `json:"leagueId"`
+	Format    string     `json:"format"`
+	Status    string     `json:"status"`
+	Round     int        `json:"round"`
+	Rounds    int        `json:"rounds,omitempty"`
+	Created   time.Time  `json:"created"`
+	Completed *time.Time `json:"completed,omitempty"`
+}
+
+type entrantDto struct {
+	FighterID  string  `json:"fighterId"`
+	UserID     int64   `json:"userId"`
+	Seed       int     `json:"seed"`
+	Rating     float64 `json:"rating"`
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	Draws      int     `json:"draws"`
+	Byes       int     `json:"byes"`
+	Score      float64 `json:"score"`
+	Eliminated int     `json:"eliminated,omitempty"`
+	Placement  int     `json:"placement,omitempty"`
+}
+
+type pairingDto struct {
+	ID       string  `json:"id"`
+	Round    int     `json:"round"`
+	Bracket  string  `json:"bracket"`
+	Slot     int     `json:"slot"`
+	FighterA string  `json:"fighterA"`
+	FighterB *string `json:"fighterB,omitempty"`
+	MatchID  *string `json:"matchId,omitempty"`
+	WinnerID *string `json:"winnerId,omitempty"`
+	Status   string  `json:"status"`
+}
+
+type bracketDto struct {
+	Tournament tournamentDto `json:"tournament"`
+	Entrants   []entrantDto  `json:"entrants"`
+	Pairings   []pairingDto  `json:"pairings"`
+}
+
+type startTournamentDto struct {
+	Format string `json:"format"`
+}
+
+// Bracket handles GET /tournament/{id}.
+func (h *Handler) Bracket(w http.ResponseWriter, r *http.Request, id string) {
+	bracket, err := h.service.Bracket(r.Context(), id)
+	if err != nil {
+		if err == tournamentsusecase.ErrInvalidTournament {
+			responses.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("tournament bracket error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	dto := bracketDto{
+		Tournament: mapTournament(bracket.Tournament),
+		Entrants:   make([]entrantDto, 0, len(bracket.Entrants)),
+		Pairings:   make([]pairingDto, 0, len(bracket.Pairings)),
+	}
+	for _, e := range bracket.Entrants {
+		dto.Entrants = append(dto.Entrants, entrantDto{
+			FighterID:  e.FighterID,
+			UserID:     e.UserID,
+			Seed:       e.Seed,
+			Rating:     e.Rating,
+			Wins:       e.Wins,
+			Losses:     e.Losses,
+			Draws:      e.Draws,
+			Byes:       e.Byes,
+			Score:      e.Score(),
+			Eliminated: e.Eliminated,
+			Placement:  e.Placement,
+		})
+	}
+	for _, p := range bracket.Pairings {
+		dto.Pairings = append(dto.Pairings, pairingDto{
+			ID:       p.ID,
+			Round:    p.Round,
+			Bracket:  p.Bracket,
+			Slot:     p.Slot,
+			FighterA: p.FighterA,
+			FighterB: p.FighterB,
+			MatchID:  p.MatchID,
+			WinnerID: p.WinnerID,
+			Status:   p.Status,
+		})
+	}
+	responses.JSON(w, http.StatusOK, dto)
+}
+
+// History handles GET /league/{id}/tournaments. Query parameters: page and
+// pageSize.
+func (h *Handler) History(w http.ResponseWriter, r *http.Request, id string) {
+	leagueID, err := strconv.Atoi(id)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid league")
+		return
+	}
+
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageSize > MaxHistoryPageSize {
+		pageSize = MaxHistoryPageSize
+	}
+
+	list, err := h.service.History(r.Context(), leagueID, page, pageSize)
+	if err != nil {
+		log.Printf("tournament history error: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	items := make([]tournamentDto, 0, len(list))
+	for _, t := range list {
+		items = append(items, mapTournament(t))
+	}
+	responses.JSON(w, http.StatusOK, items)
+}
+
+// Start handles POST /league/{id}/tournament. The format defaults to single
+// elimination.
+func (h *Handler) Start(w http.ResponseWriter, r *http.Request, id string) {
+	leagueID, err := strconv.Atoi(id)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "invalid league")
+		return
+	}
+
+	payload := startTournamentDto{Format: string(tournaments.FormatSingleElimination)}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			responses.Error(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	t, err := h.service.Start(r.Context(), leagueID, tournaments.Format(payload.Format))
+	if err != nil {
+		switch err {
+		case tournamentsusecase.ErrInvalidLeague, tournamentsusecase.ErrInvalidFormat, tournamentsusecase.ErrNotEnoughEntrants:
+			responses.Error(w, http.StatusBadRequest, err.Error())
+			return
+		case tournamentsusecase.ErrTournamentRunning:
+			responses.Error(w, http.StatusConflict, err.Error())
+			return
+		default:
+			log.Printf("tournament start error: %v", err)
+			responses.Error(w, http.StatusInternalServerError, "server error")
+			return
+		}
+	}
+
+	responses.JSON(w, http.StatusOK, mapTournament(*t))
+}
+
+func mapTournament(t tournaments.Tournament) tournamentDto {
+	return tournamentDto{
+		ID:        t.ID,
+		LeagueID:  t.LeagueID,
+		Format:    string(t.Format),
+		Status:    t.Status,
+		Round:     t.Round,
+		Rounds:    t.Rounds,
+		Created:   t.Created,
+		Completed: t.Completed,
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import "net/http"
+
+// RequireAdmin lets only the given users through to next. Everyone else,
+// signed in or not, gets 403.
+func RequireAdmin(next http.Handler, adminUserIDs []int64) http.Handler {
+	admins := make(map[int64]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserID(r.Context())
+		if !ok || !admins[userID] {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -20,31 +20,7 @@ func WithUserID(next http.Handler, secret []byte) http.Handler {
 			return
 		}
 
-		tokenString := strings.TrimPrefix(auth, "Bearer ")
-		token, err := jwt.Parse(tokenString, func(t *jwt.Token) (any, error) {
-			if t.Method != jwt.SigningMethodHS512 {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return secret, nil
-		})
-		if err != nil || !token.Valid {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		value, ok := claims["name"]
-		if !ok {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		userID, ok := toInt64(value)
+		userID, ok := ParseUserID(strings.TrimPrefix(auth, "Bearer "), secret)
 		if !ok {
 			next.ServeHTTP(w, r)
 			return
@@ -55,6 +31,32 @@ func WithUserID(next http.Handler, secret []byte) http.Handler {
 	})
 }
 
+// ParseUserID validates an HS512 signed token and returns the user ID from
+// its "name" claim.
+func ParseUserID(tokenString string, secret []byte) (int64, bool) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (any, error) {
+		if t.Method != jwt.SigningMethodHS512 {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return secret, nil
+	})
+	if err != nil || !token.Valid {
+		return 0, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+
+	value, ok := claims["name"]
+	if !ok {
+		return 0, false
+	}
+
+	return toInt64(value)
+}
+
 func UserID(ctx context.Context) (int64, bool) {
 	value := ctx.Value(userIDKey)
 	if value == nil {
@@ -10,12 +10,16 @@ import (
 	"empoweredpixels/internal/adapter/http/handlers"
 	mcphandlers "empoweredpixels/internal/adapter/http/handlers"
 	inventoryhandlers "empoweredpixels/internal/adapter/http/handlers/inventory"
+	jobhandlers "empoweredpixels/internal/adapter/http/handlers/jobs"
 	leaguehandlers "empoweredpixels/internal/adapter/http/handlers/leagues"
 	matchhandlers "empoweredpixels/internal/adapter/http/handlers/matches"
+	matchmakinghandlers "empoweredpixels/internal/adapter/http/handlers/matchmaking"
+	notificationhandlers "empoweredpixels/internal/adapter/http/handlers/notifications"
 	rewardhandlers "empoweredpixels/internal/adapter/http/handlers/rewards"
 	rosterhandlers "empoweredpixels/internal/adapter/http/handlers/roster"
 	seasonhandlers "empoweredpixels/internal/adapter/http/handlers/seasons"
 	shophandlers "empoweredpixels/internal/adapter/http/handlers/shop"
+	tournamenthandlers "empoweredpixels/internal/adapter/http/handlers/tournaments"
 	attunementhandlers "empoweredpixels/internal/adapter/http/handlers/attunement"
 	dailyhandlers "empoweredpixels/internal/adapter/http/handlers/daily"
 	leaderboardhandlers "empoweredpixels/internal/adapter/http/handlers/leaderboard"
@@ -33,10 +37,14 @@ import (
 	inventoryusecase "empoweredpixels/internal/usecase/inventory"
 	leaguesusecase "empoweredpixels/internal/usecase/leagues"
 	matchesusecase "empoweredpixels/internal/usecase/matches"
+	matchmakingusecase "empoweredpixels/internal/usecase/matchmaking"
+	notificationsusecase "empoweredpixels/internal/usecase/notifications"
 	rewardsusecase "empoweredpixels/internal/usecase/rewards"
 	rosterusecase "empoweredpixels/internal/usecase/roster"
 	seasonsusecase "empoweredpixels/internal/usecase/seasons"
 	shopusecase "empoweredpixels/internal/usecase/shop"
+	standingsusecase "empoweredpixels/internal/usecase/standings"
+	tournamentsusecase "empoweredpixels/internal/usecase/tournaments"
 	attunementusecase "empoweredpixels/internal/usecase/attunement"
 	weaponsusecase "empoweredpixels/internal/usecase/weapons"
 	skillsusecase "empoweredpixels/internal/usecase/skills"
@@ -56,14 +64,20 @@ type Dependencies struct {
 	SkillService     *skillsusecase.Service
 	LeagueService    *leaguesusecase.Service
 	LeagueJob        *jobs.LeagueJob
+	JobScheduler     *jobs.Scheduler
+	Readiness        *handlers.Readiness
 	RewardService    *rewardsusecase.Service
 	SeasonService       *seasonsusecase.Service
+	TournamentService   *tournamentsusecase.Service
+	StandingsService    *standingsusecase.Service
 	ShopService         *shopusecase.Service
 	AttunementService   *attunementusecase.Service
 	DailyService        *dailyusecase.Service
 	LeaderboardService  *leaderboardusecase.Service
 	EventService        *eventsusecase.Service
 	GuildService        *guildsusecase.Service
+	NotificationService *notificationsusecase.Service
+	MatchmakingService  *matchmakingusecase.Service
 	MatchHub            *ws.MatchHub
 	MCPHandler       *mcp.MCPHandler
 	MCPAuditLogger   *mcp.AuditLogger
@@ -88,6 +102,11 @@ func NewRouter(deps Dependencies) http.Handler {
 	r.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
 		handlers.Health().ServeHTTP(w, r)
 	}).Methods("GET")
+	// Readiness checks the dependencies, unlike the liveness of /health
+	if deps.Readiness != nil {
+		r.HandleFunc("/ready", deps.Readiness.Handler()).Methods("GET")
+		r.HandleFunc("/api/ready", deps.Readiness.Handler()).Methods("GET")
+	}
 
 	// API Routes
 	api := r.PathPrefix("/api").Subrouter()
@@ -95,6 +114,12 @@ func NewRouter(deps Dependencies) http.Handler {
 		return authMiddleware(next)
 	})
 
+	// Admin routes are for the users the config names only
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(func(next http.Handler) http.Handler {
+		return middleware.RequireAdmin(next, deps.Config.AdminUserIDs)
+	})
+
 	if deps.IdentityService != nil {
 		authHandler := handlers.NewAuthHandler(deps.IdentityService)
 		registerHandler := handlers.NewRegisterHandler(deps.IdentityService)
@@ -120,6 +145,21 @@ func NewRouter(deps Dependencies) http.Handler {
 		api.HandleFunc("/fighter/{id}/experience", func(w http.ResponseWriter, r *http.Request) {
 			h.GetExperience(w, r, mux.Vars(r)["id"])
 		}).Methods("GET")
+		api.HandleFunc("/fighter/{id}/levels", func(w http.ResponseWriter, r *http.Request) {
+			h.GetLevelHistory(w, r, mux.Vars(r)["id"])
+		}).Methods("GET")
+		api.HandleFunc("/fighter/{id}/attributes", func(w http.ResponseWriter, r *http.Request) {
+			h.GetAttributes(w, r, mux.Vars(r)["id"])
+		}).Methods("GET")
+		api.HandleFunc("/fighter/{id}/attributes", func(w http.ResponseWriter, r *http.Request) {
+			h.AllocateAttributes(w, r, mux.Vars(r)["id"])
+		}).Methods("POST")
+		api.HandleFunc("/fighter/{id}/attributes/preview", func(w http.ResponseWriter, r *http.Request) {
+			h.PreviewAttributes(w, r, mux.Vars(r)["id"])
+		}).Methods("POST")
+		api.HandleFunc("/fighter/{id}/attributes/respec", func(w http.ResponseWriter, r *http.Request) {
+			h.RespecAttributes(w, r, mux.Vars(r)["id"])
+		}).Methods("POST")
 		api.HandleFunc("/fighter/{id}/configuration", func(w http.ResponseWriter, r *http.Request) {
 			h.GetConfiguration(w, r, mux.Vars(r)["id"])
 		}).Methods("GET")
@@ -137,6 +177,7 @@ func NewRouter(deps Dependencies) http.Handler {
 		h := matchhandlers.NewHandler(deps.MatchService)
 		api.HandleFunc("/match/quick-join", h.QuickJoin).Methods("POST")
 		api.HandleFunc("/match/online-players", h.GetOnlinePlayers).Methods("GET")
+		api.HandleFunc("/match/queue/metrics", h.QueueMetrics).Methods("GET")
 		api.HandleFunc("/match/{id}", func(w http.ResponseWriter, r *http.Request) {
 			h.GetMatch(w, r, mux.Vars(r)["id"])
 		}).Methods("GET")
@@ -157,6 +198,15 @@ func NewRouter(deps Dependencies) http.Handler {
 		api.HandleFunc("/match/{id}/fighterscores", func(w http.ResponseWriter, r *http.Request) {
 			h.FighterScores(w, r, mux.Vars(r)["id"])
 		}).Methods("GET")
+		api.HandleFunc("/match/{id}/replay/verify", func(w http.ResponseWriter, r *http.Request) {
+			h.VerifyReplay(w, r, mux.Vars(r)["id"])
+		}).Methods("GET")
+		api.HandleFunc("/match/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+			h.Events(w, r, mux.Vars(r)["id"])
+		}).Methods("GET")
+		api.HandleFunc("/match/{id}/summary", func(w http.ResponseWriter, r *http.Request) {
+			h.Summary(w, r, mux.Vars(r)["id"])
+		}).Methods("GET")
 		api.HandleFunc("/match/quick-join", h.QuickJoin).Methods("POST")
 	}
 
@@ -206,9 +256,14 @@ func NewRouter(deps Dependencies) http.Handler {
 		api.HandleFunc("/league/{id}/matches", func(w http.ResponseWriter, r *http.Request) {
 			h.Matches(w, r, mux.Vars(r)["id"])
 		}).Methods("POST")
-		api.HandleFunc("/league/{id}/highscores", func(w http.ResponseWriter, r *http.Request) {
-			h.Highscores(w, r, mux.Vars(r)["id"])
-		}).Methods("POST")
+		admin.HandleFunc("/league", h.AdminList).Methods("GET")
+		admin.HandleFunc("/league", h.AdminCreate).Methods("POST")
+		admin.HandleFunc("/league/{id}", func(w http.ResponseWriter, r *http.Request) {
+			h.AdminUpdate(w, r, mux.Vars(r)["id"])
+		}).Methods("PUT")
+		admin.HandleFunc("/league/{id}", func(w http.ResponseWriter, r *http.Request) {
+			h.AdminDelete(w, r, mux.Vars(r)["id"])
+		}).Methods("DELETE")
 		if deps.LeagueJob != nil {
 			api.HandleFunc("/league/{id}/run", func(w http.ResponseWriter, r *http.Request) {
 				runLeagueJob(w, r, mux.Vars(r)["id"], deps.LeagueJob)
@@ -216,6 +271,17 @@ func NewRouter(deps Dependencies) http.Handler {
 		}
 	}
 
+	if deps.JobScheduler != nil {
+		h := jobhandlers.NewHandler(deps.JobScheduler)
+		admin.HandleFunc("/jobs", h.List).Methods("GET")
+		admin.HandleFunc("/jobs/{name}/runs", func(w http.ResponseWriter, r *http.Request) {
+			h.Runs(w, r, mux.Vars(r)["name"])
+		}).Methods("GET")
+		admin.HandleFunc("/jobs/{name}/run", func(w http.ResponseWriter, r *http.Request) {
+			h.Trigger(w, r, mux.Vars(r)["name"])
+		}).Methods("POST")
+	}
+
 	if deps.RewardService != nil {
 		h := rewardhandlers.NewHandler(deps.RewardService)
 		api.HandleFunc("/reward", h.List).Methods("GET")
@@ -226,6 +292,37 @@ func NewRouter(deps Dependencies) http.Handler {
 	if deps.SeasonService != nil {
 		h := seasonhandlers.NewHandler(deps.SeasonService)
 		api.HandleFunc("/season/summary", h.Summary).Methods("POST")
+		api.HandleFunc("/season/current", h.Current).Methods("GET")
+		api.HandleFunc("/season/ladder", h.Ladder).Methods("GET")
+		api.HandleFunc("/season/ladder/{fighterId}", func(w http.ResponseWriter, r *http.Request) {
+			h.Placement(w, r, mux.Vars(r)["fighterId"])
+		}).Methods("GET")
+	}
+
+	if deps.StandingsService != nil {
+		h := leaguehandlers.NewStandingsHandler(deps.StandingsService)
+		api.HandleFunc("/league/{id}/standings", func(w http.ResponseWriter, r *http.Request) {
+			h.Table(w, r, mux.Vars(r)["id"])
+		}).Methods("GET")
+		api.HandleFunc("/league/{id}/standings/seasons", func(w http.ResponseWriter, r *http.Request) {
+			h.Seasons(w, r, mux.Vars(r)["id"])
+		}).Methods("GET")
+		api.HandleFunc("/league/{id}/highscores", func(w http.ResponseWriter, r *http.Request) {
+			h.Highscores(w, r, mux.Vars(r)["id"])
+		}).Methods("POST")
+	}
+
+	if deps.TournamentService != nil {
+		h := tournamenthandlers.NewHandler(deps.TournamentService)
+		api.HandleFunc("/tournament/{id}", func(w http.ResponseWriter, r *http.Request) {
+			h.Bracket(w, r, mux.Vars(r)["id"])
+		}).Methods("GET")
+		api.HandleFunc("/league/{id}/tournaments", func(w http.ResponseWriter, r *http.Request) {
+			h.History(w, r, mux.Vars(r)["id"])
+		}).Methods("GET")
+		api.HandleFunc("/league/{id}/tournament", func(w http.ResponseWriter, r *http.Request) {
+			h.Start(w, r, mux.Vars(r)["id"])
+		}).Methods("POST")
 	}
 
 	if deps.WeaponService != nil {
@@ -301,6 +398,26 @@ func NewRouter(deps Dependencies) http.Handler {
 		api.HandleFunc("/achievement/{id}/claim", h.ClaimAchievement).Methods("POST")
 	}
 
+	if deps.NotificationService != nil {
+		h := notificationhandlers.NewHandler(deps.NotificationService)
+		api.HandleFunc("/notifications", h.List).Methods("GET")
+		api.HandleFunc("/notifications/unread-count", h.UnreadCount).Methods("GET")
+		api.HandleFunc("/notifications/read-all", h.MarkAllRead).Methods("POST")
+		api.HandleFunc("/notifications/{id}/read", func(w http.ResponseWriter, r *http.Request) {
+			h.MarkRead(w, r, mux.Vars(r)["id"])
+		}).Methods("POST")
+	}
+
+	if deps.MatchmakingService != nil {
+		h := matchmakinghandlers.NewHandler(deps.MatchmakingService)
+		api.HandleFunc("/matchmaking/queue", h.Status).Methods("GET")
+		api.HandleFunc("/matchmaking/queue", h.Enqueue).Methods("POST")
+		api.HandleFunc("/matchmaking/queue", h.Cancel).Methods("DELETE")
+		api.HandleFunc("/matchmaking/rating/{fighterId}", func(w http.ResponseWriter, r *http.Request) {
+			h.Rating(w, r, mux.Vars(r)["fighterId"])
+		}).Methods("GET")
+	}
+
 	if deps.GuildService != nil {
 		h := guildhandlers.NewHandler(deps.GuildService)
 		api.HandleFunc("/guilds", h.List).Methods("GET")
@@ -336,7 +453,8 @@ func runLeagueJob(w http.ResponseWriter, r *http.Request, idStr string, job *job
 	}
 	if err := job.RunLeague(r.Context(), leagueID); err != nil {
 		switch err {
-		case jobs.ErrLeagueNotFound, jobs.ErrNoSubscriptions:
+		case jobs.ErrLeagueNotFound, jobs.ErrNoSubscriptions, jobs.ErrLeagueInactive, jobs.ErrNotEnoughFighters,
+			tournamentsusecase.ErrNotEnoughEntrants, tournamentsusecase.ErrInvalidFormat:
 			responses.Error(w, http.StatusBadRequest, err.Error())
 			return
 		default:
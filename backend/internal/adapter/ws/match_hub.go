@@ -1,44 +1,313 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// Time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+	// Time allowed to read the next pong message from the peer
+	pongWait = 60 * time.Second
+	// Pings are sent at this period, which must be less than pongWait
+	pingPeriod = pongWait * 9 / 10
+	// Largest message accepted from a client
+	maxMessageSize = 4096
+	// Messages queued per connection before it counts as a slow consumer
+	sendBufferSize = 256
+)
+
+// Authenticator validates an access token and returns the user it belongs to.
+type Authenticator func(token string) (userID int64, ok bool)
+
+// MatchHub fans match and user events out to WebSocket connections. Every
+// connection is authenticated and owns a buffered send queue drained by its
+// own writer goroutine, so a slow client never blocks a broadcast; clients
+// whose queue overflows are disconnected.
 type MatchHub struct {
-	upgrader websocket.Upgrader
+	upgrader     websocket.Upgrader
+	authenticate Authenticator
+
 	mu       sync.RWMutex
-	clients  map[*websocket.Conn]string
+	channels map[string]map[*client]struct{}
+	catchUp  func(matchID string) (any, bool)
+	// clients holds every connection and closing refuses new ones once the
+	// hub shuts down; both are guarded by mu
+	clients map[*client]struct{}
+	closing bool
+	// pumps counts the reader and writer goroutines of the connections
+	pumps sync.WaitGroup
 }
 
-func NewMatchHub() *MatchHub {
+type client struct {
+	hub    *MatchHub
+	conn   *websocket.Conn
+	userID int64
+	send   chan []byte
+	// channels and closed are guarded by hub.mu
+	channels map[string]struct{}
+	closed   bool
+}
+
+// NewMatchHub creates a hub that authenticates connections with authenticate
+// and accepts browser connections from allowedOrigins. Without allowed
+// origins only same-host pages may connect; "*" allows every origin.
+func NewMatchHub(authenticate Authenticator, allowedOrigins []string) *MatchHub {
 	return &MatchHub{
 		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool { return true },
+			CheckOrigin: checkOrigin(allowedOrigins),
 		},
-		clients: make(map[*websocket.Conn]string),
+		authenticate: authenticate,
+		channels:     make(map[string]map[*client]struct{}),
+		clients:      make(map[*client]struct{}),
+	}
+}
+
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			// Not a browser
+			return true
+		}
+		if len(allowedOrigins) == 0 {
+			u, err := url.Parse(origin)
+			return err == nil && strings.EqualFold(u.Host, r.Host)
+		}
+		for _, allowed := range allowedOrigins {
+			if allowed == "*" || strings.EqualFold(allowed, origin) {
+				return true
+			}
+		}
+		return false
 	}
 }
 
+func matchChannel(matchID string) string { return "match:" + matchID }
+
+func userChannel(userID int64) string { return "user:" + strconv.FormatInt(userID, 10) }
+
+// SetCatchUp installs the source of the message sent to new subscribers of a
+// match that is already being streamed.
+func (h *MatchHub) SetCatchUp(catchUp func(matchID string) (any, bool)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.catchUp = catchUp
+}
+
+// matchMessage is a client request. Channel "user" is the authenticated
+// user's own channel; without a channel the request is about MatchID.
+// Unsubscribing without either leaves every match.
 type matchMessage struct {
 	Action  string `json:"action"`
 	MatchID string `json:"matchId"`
+	Channel string `json:"channel"`
 }
 
+// ServeHTTP upgrades an authenticated request. The token is taken from the
+// Authorization header or, since browsers cannot set headers on WebSocket
+// requests, from the access_token query parameter.
 func (h *MatchHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("access_token")
+	}
+	userID, ok := h.authenticate(token)
+	if token == "" || !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	h.mu.RLock()
+	closing := h.closing
+	h.mu.RUnlock()
+	if closing {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
 	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
 
-	h.register(conn, "")
-	defer h.unregister(conn)
+	c := &client{
+		hub:      h,
+		conn:     conn,
+		userID:   userID,
+		send:     make(chan []byte, sendBufferSize),
+		channels: make(map[string]struct{}),
+	}
+	h.mu.Lock()
+	if h.closing {
+		h.mu.Unlock()
+		_ = conn.WriteControl(websocket.CloseMessage, goingAway, time.Now().Add(writeWait))
+		_ = conn.Close()
+		return
+	}
+	h.clients[c] = struct{}{}
+	h.pumps.Add(2)
+	h.mu.Unlock()
+
+	go c.writePump()
+	c.readPump()
+}
+
+// goingAway is the close message of the connections the hub drops when it
+// shuts down.
+var goingAway = websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+
+// Shutdown refuses new connections, closes every connection with a going
+// away message and waits for them to end or ctx to be done.
+func (h *MatchHub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	clients := make([]*client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range clients {
+		h.remove(c)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Broadcast sends payload to every subscriber of the match.
+func (h *MatchHub) Broadcast(matchID string, payload any) {
+	h.publish(matchChannel(matchID), payload)
+}
+
+// SendToUser sends payload to every connection subscribed to the user's
+// channel.
+func (h *MatchHub) SendToUser(userID int64, payload any) {
+	h.publish(userChannel(userID), payload)
+}
+
+func (h *MatchHub) publish(channel string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	var slow []*client
+	h.mu.RLock()
+	for c := range h.channels[channel] {
+		select {
+		case c.send <- data:
+		default:
+			slow = append(slow, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, c := range slow {
+		h.remove(c)
+	}
+}
+
+func (h *MatchHub) subscribe(c *client, channel string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if c.closed {
+		return
+	}
+	if h.channels[channel] == nil {
+		h.channels[channel] = make(map[*client]struct{})
+	}
+	h.channels[channel][c] = struct{}{}
+	c.channels[channel] = struct{}{}
+}
+
+// unsubscribe removes the client from the channels matching the predicate.
+func (h *MatchHub) unsubscribe(c *client, match func(channel string) bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unsubscribeLocked(c, match)
+}
+
+func (h *MatchHub) unsubscribeLocked(c *client, match func(channel string) bool) {
+	for channel := range c.channels {
+		if !match(channel) {
+			continue
+		}
+		delete(c.channels, channel)
+		delete(h.channels[channel], c)
+		if len(h.channels[channel]) == 0 {
+			delete(h.channels, channel)
+		}
+	}
+}
+
+// remove drops the client from every channel and closes its send queue,
+// which makes the writer goroutine close the connection.
+func (h *MatchHub) remove(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unsubscribeLocked(c, func(string) bool { return true })
+	delete(h.clients, c)
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// enqueue queues a message for the client alone, evicting it when its queue
+// is full.
+func (c *client) enqueue(payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	c.hub.mu.RLock()
+	if c.closed {
+		c.hub.mu.RUnlock()
+		return
+	}
+	select {
+	case c.send <- data:
+		c.hub.mu.RUnlock()
+	default:
+		c.hub.mu.RUnlock()
+		c.hub.remove(c)
+	}
+}
+
+func (c *client) readPump() {
+	defer func() {
+		c.hub.remove(c)
+		_ = c.conn.Close()
+		c.hub.pumps.Done()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
 
 	for {
-		_, data, err := conn.ReadMessage()
+		_, data, err := c.conn.ReadMessage()
 		if err != nil {
 			return
 		}
@@ -47,39 +316,85 @@ func (h *MatchHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if err := json.Unmarshal(data, &msg); err != nil {
 			continue
 		}
+		c.handle(msg)
+	}
+}
 
-		if msg.Action == "subscribe" && msg.MatchID != "" {
-			h.register(conn, msg.MatchID)
-			_ = conn.WriteJSON(map[string]string{"status": "subscribed", "matchId": msg.MatchID})
+func (c *client) handle(msg matchMessage) {
+	switch msg.Action {
+	case "subscribe":
+		switch {
+		case msg.Channel == "user":
+			c.hub.subscribe(c, userChannel(c.userID))
+			c.enqueue(map[string]any{"status": "subscribed", "channel": "user", "userId": c.userID})
+		case msg.MatchID != "":
+			c.hub.subscribe(c, matchChannel(msg.MatchID))
+			c.enqueue(map[string]string{"status": "subscribed", "matchId": msg.MatchID})
+			c.sendCatchUp(msg.MatchID)
 		}
-		if msg.Action == "unsubscribe" {
-			h.register(conn, "")
-			_ = conn.WriteJSON(map[string]string{"status": "unsubscribed"})
+	case "unsubscribe":
+		switch {
+		case msg.Channel == "user":
+			own := userChannel(c.userID)
+			c.hub.unsubscribe(c, func(channel string) bool { return channel == own })
+			c.enqueue(map[string]string{"status": "unsubscribed", "channel": "user"})
+		case msg.MatchID != "":
+			target := matchChannel(msg.MatchID)
+			c.hub.unsubscribe(c, func(channel string) bool { return channel == target })
+			c.enqueue(map[string]string{"status": "unsubscribed", "matchId": msg.MatchID})
+		default:
+			c.hub.unsubscribe(c, func(channel string) bool { return strings.HasPrefix(channel, "match:") })
+			c.enqueue(map[string]string{"status": "unsubscribed"})
 		}
 	}
 }
 
-func (h *MatchHub) Broadcast(matchID string, payload any) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	for conn, subscribed := range h.clients {
-		if subscribed != matchID {
-			continue
-		}
-		_ = conn.WriteJSON(payload)
+func (c *client) sendCatchUp(matchID string) {
+	c.hub.mu.RLock()
+	catchUp := c.hub.catchUp
+	c.hub.mu.RUnlock()
+	if catchUp == nil {
+		return
+	}
+	if payload, ok := catchUp(matchID); ok {
+		c.enqueue(payload)
 	}
 }
 
-func (h *MatchHub) register(conn *websocket.Conn, matchID string) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	h.clients[conn] = matchID
-}
+// writePump is the connection's only writer. It drains the send queue and
+// keeps the connection alive with pings.
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+		c.hub.pumps.Done()
+	}()
 
-func (h *MatchHub) unregister(conn *websocket.Conn) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	delete(h.clients, conn)
-	_ = conn.Close()
+	for {
+		select {
+		case data, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.hub.mu.RLock()
+				message := []byte{}
+				if c.hub.closing {
+					message = goingAway
+				}
+				c.hub.mu.RUnlock()
+				_ = c.conn.WriteMessage(websocket.CloseMessage, message)
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.hub.remove(c)
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.hub.remove(c)
+				return
+			}
+		}
+	}
 }
@@ -0,0 +1,166 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func testAuthenticator(token string) (int64, bool) {
+	switch token {
+	case "alice":
+		return 1, true
+	case "bob":
+		return 2, true
+	}
+	return 0, false
+}
+
+func dial(t *testing.T, server *httptest.Server, token string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?access_token=" + token
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func request(t *testing.T, conn *websocket.Conn, msg matchMessage) {
+	t.Helper()
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// Wait for the acknowledgement so the subscription is in place
+	var ack map[string]any
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(&ack); err != nil {
+		t.Fatalf("read ack: %v", err)
+	}
+}
+
+func expectMessage(t *testing.T, conn *websocket.Conn, wantType string) map[string]any {
+	t.Helper()
+	var msg map[string]any
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if msg["type"] != wantType {
+		t.Fatalf("expected %s, got %v", wantType, msg)
+	}
+	return msg
+}
+
+func TestMatchHub_RejectsUnauthenticated(t *testing.T) {
+	server := httptest.NewServer(NewMatchHub(testAuthenticator, nil))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?access_token=mallory", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an invalid token, got %v", err)
+	}
+}
+
+func TestMatchHub_RejectsForeignOrigin(t *testing.T) {
+	server := httptest.NewServer(NewMatchHub(testAuthenticator, []string{"https://empoweredpixels.example"}))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?access_token=alice"
+	header := http.Header{"Origin": []string{"https://evil.example"}}
+	if _, _, err := websocket.DefaultDialer.Dial(url, header); err == nil {
+		t.Fatal("expected a foreign origin to be rejected")
+	}
+	header.Set("Origin", "https://empoweredpixels.example")
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("expected an allowed origin to connect: %v", err)
+	}
+	_ = conn.Close()
+}
+
+func TestMatchHub_MultipleMatchesAndUserChannel(t *testing.T) {
+	hub := NewMatchHub(testAuthenticator, nil)
+	hub.SetCatchUp(func(matchID string) (any, bool) {
+		return map[string]any{"type": "matchCatchUp", "matchId": matchID}, matchID == "live"
+	})
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	alice := dial(t, server, "alice")
+	request(t, alice, matchMessage{Action: "subscribe", MatchID: "one"})
+	request(t, alice, matchMessage{Action: "subscribe", MatchID: "live"})
+	expectMessage(t, alice, "matchCatchUp")
+	request(t, alice, matchMessage{Action: "subscribe", Channel: "user"})
+
+	bob := dial(t, server, "bob")
+	request(t, bob, matchMessage{Action: "subscribe", Channel: "user"})
+
+	hub.Broadcast("one", map[string]any{"type": "first"})
+	hub.Broadcast("other", map[string]any{"type": "ignored"})
+	hub.Broadcast("live", map[string]any{"type": "second"})
+	hub.SendToUser(1, map[string]any{"type": "notification"})
+	hub.SendToUser(2, map[string]any{"type": "bobOnly"})
+
+	expectMessage(t, alice, "first")
+	expectMessage(t, alice, "second")
+	expectMessage(t, alice, "notification")
+	expectMessage(t, bob, "bobOnly")
+
+	request(t, alice, matchMessage{Action: "unsubscribe", MatchID: "one"})
+	hub.Broadcast("one", map[string]any{"type": "ignored"})
+	hub.Broadcast("live", map[string]any{"type": "third"})
+	expectMessage(t, alice, "third")
+}
+
+func TestMatchHub_EvictsSlowConsumers(t *testing.T) {
+	hub := NewMatchHub(testAuthenticator, nil)
+	// A client without a writer never drains its queue
+	slow := &client{hub: hub, send: make(chan []byte, 1), channels: make(map[string]struct{})}
+	hub.subscribe(slow, matchChannel("match"))
+
+	hub.Broadcast("match", map[string]any{"type": "fits"})
+	hub.Broadcast("match", map[string]any{"type": "overflows"})
+
+	if !slow.closed {
+		t.Fatal("expected the slow client to be evicted")
+	}
+	if _, ok := hub.channels[matchChannel("match")]; ok {
+		t.Fatal("expected the slow client to be unsubscribed")
+	}
+	// Further broadcasts must not touch the closed queue
+	hub.Broadcast("match", map[string]any{"type": "after"})
+}
+
+func TestMatchHub_Shutdown(t *testing.T) {
+	hub := NewMatchHub(testAuthenticator, nil)
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	conn := dial(t, server, "alice")
+	request(t, conn, matchMessage{Action: "subscribe", Channel: "user"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := hub.Shutdown(ctx); err != nil {
+		t.Fatalf("expected the connections closed before the deadline, got %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("expected a going away close, got %v", err)
+	}
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?access_token=bob"
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 once shut down, got %v", err)
+	}
+}
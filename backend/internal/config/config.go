@@ -1,6 +1,11 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
 	HTTPAddress string
@@ -8,6 +13,22 @@ type Config struct {
 	JWTSecret   string
 	TokenDays   int
 	EngineURL   string
+	// CombatEngine is the default engine of matches: "local" or "remote"
+	CombatEngine  string
+	EngineTimeout time.Duration
+	EngineRetries int
+	// MatchWorkers is the number of matches executed at the same time
+	MatchWorkers int
+	// AllowedOrigins may open WebSocket connections from a browser
+	AllowedOrigins []string
+	// AdminUserIDs are the users allowed on the admin endpoints
+	AdminUserIDs []int64
+	// ReadinessDrainDelay is how long a shutdown keeps serving after failing
+	// readiness, for load balancers to stop sending requests
+	ReadinessDrainDelay time.Duration
+	// ShutdownTimeout bounds every phase of a shutdown: requests,
+	// connections, jobs, queued matches and live streams each get as long
+	ShutdownTimeout time.Duration
 }
 
 func FromEnv() Config {
@@ -30,11 +51,74 @@ func FromEnv() Config {
 
 	engineURL := os.Getenv("EP_ENGINE_URL")
 
+	combatEngine := os.Getenv("EP_COMBAT_ENGINE")
+	if combatEngine == "" {
+		combatEngine = "local"
+		if engineURL != "" {
+			combatEngine = "remote"
+		}
+	}
+
+	engineTimeout, err := time.ParseDuration(os.Getenv("EP_ENGINE_TIMEOUT"))
+	if err != nil || engineTimeout <= 0 {
+		engineTimeout = 10 * time.Second
+	}
+
+	engineRetries, err := strconv.Atoi(os.Getenv("EP_ENGINE_RETRIES"))
+	if err != nil || engineRetries < 0 {
+		engineRetries = 2
+	}
+
+	matchWorkers, err := strconv.Atoi(os.Getenv("EP_MATCH_WORKERS"))
+	if err != nil || matchWorkers < 1 {
+		matchWorkers = 4
+	}
+
+	origins := os.Getenv("EP_ALLOWED_ORIGINS")
+	if origins == "" {
+		origins = "http://localhost:5173"
+	}
+	var allowedOrigins []string
+	for _, origin := range strings.Split(origins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowedOrigins = append(allowedOrigins, origin)
+		}
+	}
+
+	var adminUserIDs []int64
+	for _, id := range strings.Split(os.Getenv("EP_ADMIN_USER_IDS"), ",") {
+		if userID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64); err == nil {
+			adminUserIDs = append(adminUserIDs, userID)
+		}
+	}
+
+	drainDelay, err := time.ParseDuration(os.Getenv("EP_READINESS_DRAIN_DELAY"))
+	if err != nil || drainDelay < 0 {
+		drainDelay = 5 * time.Second
+	}
+
+	shutdownTimeout, err := time.ParseDuration(os.Getenv("EP_SHUTDOWN_TIMEOUT"))
+	if err != nil || shutdownTimeout <= 0 {
+		shutdownTimeout = 10 * time.Second
+	}
+
 	return Config{
 		HTTPAddress: address,
 		DatabaseURL: databaseURL,
 		JWTSecret:   jwtSecret,
 		TokenDays:   tokenDays,
 		EngineURL:   engineURL,
+
+		CombatEngine:  combatEngine,
+		EngineTimeout: engineTimeout,
+		EngineRetries: engineRetries,
+
+		MatchWorkers: matchWorkers,
+
+		AllowedOrigins: allowedOrigins,
+		AdminUserIDs:   adminUserIDs,
+
+		ReadinessDrainDelay: drainDelay,
+		ShutdownTimeout:     shutdownTimeout,
 	}
 }
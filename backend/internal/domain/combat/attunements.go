@@ -1,15 +1,26 @@
 package combat
 
 import (
-	"encoding/json"
 	"math/rand"
+	"strings"
+
+	"empoweredpixels/internal/domain/attunement"
+)
+
+// Elemental advantage multipliers applied to damage between attuned entities
+const (
+	StrongMultiplier = 1.25
+	WeakMultiplier   = 0.8
 )
 
+// ProcChance is the chance of an attunement's on-attack effect per hit.
+const ProcChance = 0.1
+
 type Attunement interface {
 	ID() string
 	StrongAgainst() string
 	WeakAgainst() string
-	OnAttack(attacker *Entity, target *Entity) []Tick
+	OnAttack(attacker *Entity, target *Entity, rng *rand.Rand) []Tick
 }
 
 type BaseAttunement struct {
@@ -22,28 +33,162 @@ func (a *BaseAttunement) ID() string            { return a.id }
 func (a *BaseAttunement) StrongAgainst() string { return a.strongAgainst }
 func (a *BaseAttunement) WeakAgainst() string   { return a.weakAgainst }
 
+// ElementalBonus holds the percentage bonuses a player's attunement levels
+// grant in combat, see attunement.GetBonus.
+type ElementalBonus struct {
+	Power     float64 `json:"power"`
+	Defense   float64 `json:"defense"`
+	Speed     float64 `json:"speed"`
+	Precision float64 `json:"precision"`
+}
+
 type FireAttunement struct{ BaseAttunement }
 
-func (a *FireAttunement) OnAttack(attacker *Entity, target *Entity) []Tick {
-	if rand.Float64() > 0.1 { // 10% chance
+// OnAttack sets the target on fire.
+func (a *FireAttunement) OnAttack(attacker *Entity, target *Entity, rng *rand.Rand) []Tick {
+	if target.CurrentHP <= 0 || rng.Float64() > ProcChance {
 		return nil
 	}
-	// Apply Burn (dummy condition for now)
-	event := map[string]string{
-		"fighterId": attacker.ID,
-		"targetId":  target.ID,
-		"condition": "Burn",
-	}
-	p, _ := json.Marshal(event)
-	return []Tick{{Type: "conditionApplied", Payload: p}}
+	return []Tick{target.ApplyCondition(attacker, ConditionBurn, 3, 3)}
 }
 
 func NewFireAttunement() Attunement {
 	return &FireAttunement{BaseAttunement{
-		id:            "Fire",
-		strongAgainst: "Wind",
-		weakAgainst:   "Water",
+		id:            string(attunement.Fire),
+		strongAgainst: string(attunement.Air),
+		weakAgainst:   string(attunement.Water),
+	}}
+}
+
+type WaterAttunement struct{ BaseAttunement }
+
+// OnAttack freezes the target in place.
+func (a *WaterAttunement) OnAttack(attacker *Entity, target *Entity, rng *rand.Rand) []Tick {
+	if target.CurrentHP <= 0 || rng.Float64() > ProcChance {
+		return nil
+	}
+	return []Tick{target.ApplyCondition(attacker, ConditionRoot, 2, 0)}
+}
+
+func NewWaterAttunement() Attunement {
+	return &WaterAttunement{BaseAttunement{
+		id:            string(attunement.Water),
+		strongAgainst: string(attunement.Fire),
+		weakAgainst:   string(attunement.Earth),
+	}}
+}
+
+type EarthAttunement struct{ BaseAttunement }
+
+// OnAttack hardens the attacker's skin into a shield.
+func (a *EarthAttunement) OnAttack(attacker *Entity, target *Entity, rng *rand.Rand) []Tick {
+	if rng.Float64() > ProcChance {
+		return nil
+	}
+	return []Tick{attacker.ApplyCondition(attacker, ConditionShield, 3, 10)}
+}
+
+func NewEarthAttunement() Attunement {
+	return &EarthAttunement{BaseAttunement{
+		id:            string(attunement.Earth),
+		strongAgainst: string(attunement.Water),
+		weakAgainst:   string(attunement.Air),
+	}}
+}
+
+type AirAttunement struct{ BaseAttunement }
+
+// OnAttack wraps the attacker in an evasion cloud.
+func (a *AirAttunement) OnAttack(attacker *Entity, target *Entity, rng *rand.Rand) []Tick {
+	if rng.Float64() > ProcChance {
+		return nil
+	}
+	return []Tick{attacker.ApplyCondition(attacker, ConditionEvasion, 2, 30)}
+}
+
+func NewAirAttunement() Attunement {
+	return &AirAttunement{BaseAttunement{
+		id:            string(attunement.Air),
+		strongAgainst: string(attunement.Earth),
+		weakAgainst:   string(attunement.Fire),
+	}}
+}
+
+type LightAttunement struct{ BaseAttunement }
+
+// OnAttack heals the attacker for a small share of its maximum health.
+func (a *LightAttunement) OnAttack(attacker *Entity, target *Entity, rng *rand.Rand) []Tick {
+	if attacker.CurrentHP <= 0 || rng.Float64() > ProcChance {
+		return nil
+	}
+	amount := heal(attacker, attacker.MaxHP/20)
+	if amount == 0 {
+		return nil
+	}
+	return []Tick{newTick("heal", EventHeal{HealerID: attacker.ID, TargetID: attacker.ID, Amount: amount})}
+}
+
+// Light and Dark are each strong against the other.
+func NewLightAttunement() Attunement {
+	return &LightAttunement{BaseAttunement{
+		id:            string(attunement.Light),
+		strongAgainst: string(attunement.Dark),
+	}}
+}
+
+type DarkAttunement struct{ BaseAttunement }
+
+// OnAttack opens a bleeding wound.
+func (a *DarkAttunement) OnAttack(attacker *Entity, target *Entity, rng *rand.Rand) []Tick {
+	if target.CurrentHP <= 0 || rng.Float64() > ProcChance {
+		return nil
+	}
+	return []Tick{target.ApplyCondition(attacker, ConditionBleed, 3, 3)}
+}
+
+func NewDarkAttunement() Attunement {
+	return &DarkAttunement{BaseAttunement{
+		id:            string(attunement.Dark),
+		strongAgainst: string(attunement.Light),
 	}}
 }
 
-// ... other attunements can be added similarly
+var attunementRegistry = map[attunement.Element]func() Attunement{
+	attunement.Fire:  NewFireAttunement,
+	attunement.Water: NewWaterAttunement,
+	attunement.Earth: NewEarthAttunement,
+	attunement.Air:   NewAirAttunement,
+	attunement.Light: NewLightAttunement,
+	attunement.Dark:  NewDarkAttunement,
+}
+
+// GetAttunement returns the combat attunement for an attunement ID, matched
+// case-insensitively, or nil for unknown or missing IDs.
+func GetAttunement(id *string) Attunement {
+	if id == nil {
+		return nil
+	}
+	constructor, ok := attunementRegistry[attunement.Element(strings.ToLower(*id))]
+	if !ok {
+		return nil
+	}
+	return constructor()
+}
+
+// ElementalMultiplier is the damage factor for attacker hitting target based
+// on their attunements' strong and weak relations.
+func ElementalMultiplier(attacker *Entity, target *Entity) float64 {
+	attackerAttunement := GetAttunement(attacker.AttunementID)
+	targetAttunement := GetAttunement(target.AttunementID)
+	if attackerAttunement == nil || targetAttunement == nil {
+		return 1.0
+	}
+	switch targetAttunement.ID() {
+	case attackerAttunement.StrongAgainst():
+		return StrongMultiplier
+	case attackerAttunement.WeakAgainst():
+		return WeakMultiplier
+	default:
+		return 1.0
+	}
+}
@@ -0,0 +1,84 @@
+package combat
+
+import (
+	"math/rand"
+	"testing"
+
+	"empoweredpixels/internal/domain/attunement"
+)
+
+func attuned(id string, element string) *Entity {
+	e := newTestEntity(id, 1000)
+	e.AttunementID = &element
+	return e
+}
+
+func TestGetAttunement_AllElements(t *testing.T) {
+	for _, element := range attunement.AllElements {
+		id := string(element)
+		a := GetAttunement(&id)
+		if a == nil {
+			t.Fatalf("missing attunement for %s", element)
+		}
+		if a.ID() != id {
+			t.Fatalf("expected ID %s, got %s", id, a.ID())
+		}
+	}
+
+	upper := "Fire"
+	if GetAttunement(&upper) == nil {
+		t.Fatal("expected IDs to match case-insensitively")
+	}
+	unknown := "wood"
+	if GetAttunement(&unknown) != nil || GetAttunement(nil) != nil {
+		t.Fatal("expected no attunement for unknown or missing IDs")
+	}
+}
+
+func TestElementalMultiplier(t *testing.T) {
+	tests := []struct {
+		attacker, target string
+		want             float64
+	}{
+		{"fire", "air", StrongMultiplier},
+		{"fire", "water", WeakMultiplier},
+		{"water", "fire", StrongMultiplier},
+		{"earth", "water", StrongMultiplier},
+		{"air", "earth", StrongMultiplier},
+		{"light", "dark", StrongMultiplier},
+		{"dark", "light", StrongMultiplier},
+		{"fire", "light", 1.0},
+		{"fire", "fire", 1.0},
+	}
+	for _, tt := range tests {
+		got := ElementalMultiplier(attuned("a", tt.attacker), attuned("b", tt.target))
+		if got != tt.want {
+			t.Errorf("%s vs %s: expected %f, got %f", tt.attacker, tt.target, tt.want, got)
+		}
+	}
+
+	if got := ElementalMultiplier(newTestEntity("a", 100), attuned("b", "fire")); got != 1.0 {
+		t.Errorf("expected no advantage without an attunement, got %f", got)
+	}
+}
+
+func TestDealDamage_AppliesElementalModifiers(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	attacker := attuned("a", "fire")
+	target := attuned("b", "air")
+	dealDamage(attacker, target, "test", 100, rng)
+	if target.CurrentHP != 1000-125 {
+		t.Fatalf("expected 125 damage with advantage, got %d", 1000-target.CurrentHP)
+	}
+
+	attacker = newTestEntity("a", 1000)
+	attacker.Elemental.Power = 10
+	target = newTestEntity("b", 1000)
+	target.Elemental.Defense = 20
+	dealDamage(attacker, target, "test", 100, rng)
+	// 100 * 1.1 power, then 20% attunement defense
+	if target.CurrentHP != 1000-88 {
+		t.Fatalf("expected 88 damage after attunement bonuses, got %d", 1000-target.CurrentHP)
+	}
+}
@@ -0,0 +1,191 @@
+package combat
+
+import "math/rand"
+
+type ConditionType string
+
+const (
+	// Damage over time
+	ConditionBurn  ConditionType = "Burn"
+	ConditionBleed ConditionType = "Bleed"
+	// Crowd control: stunned entities skip their turn, rooted ones cannot move
+	ConditionStun ConditionType = "Stun"
+	ConditionRoot ConditionType = "Root"
+	// Defensive: shields absorb damage, evasion clouds dodge attacks
+	ConditionShield  ConditionType = "Shield"
+	ConditionEvasion ConditionType = "Evasion"
+)
+
+// Condition is a status effect on an entity. Potency is the per-stack damage
+// of damage over time, the remaining absorb of a shield, or the dodge chance
+// in percent of an evasion cloud.
+type Condition struct {
+	Type     ConditionType
+	SourceID string
+	Stacks   int
+	Rounds   int
+	Potency  int
+}
+
+// conditionRule describes how repeated applications of a condition combine.
+// Stacks always take the longer of both durations.
+type conditionRule struct {
+	maxStacks int
+	// damageOverTime conditions deal Potency per stack each round, scaled by
+	// the applier's ConditionPower.
+	damageOverTime bool
+	// additive conditions add the new potency instead of keeping the higher.
+	additive bool
+}
+
+var conditionRules = map[ConditionType]conditionRule{
+	ConditionBurn:    {maxStacks: 5, damageOverTime: true},
+	ConditionBleed:   {maxStacks: 10, damageOverTime: true},
+	ConditionStun:    {maxStacks: 1},
+	ConditionRoot:    {maxStacks: 1},
+	ConditionShield:  {maxStacks: 1, additive: true},
+	ConditionEvasion: {maxStacks: 1},
+}
+
+type EventCondition struct {
+	FighterID string        `json:"fighterId"`
+	SourceID  string        `json:"sourceId"`
+	Condition ConditionType `json:"condition"`
+	Stacks    int           `json:"stacks"`
+	Rounds    int           `json:"rounds"`
+	Damage    int           `json:"damage,omitempty"`
+}
+
+// conditionDamage scales the base damage of a damage-over-time condition by
+// the applier's ConditionPower: every point adds 2%.
+func conditionDamage(base int, source *Entity) int {
+	if source == nil {
+		return base
+	}
+	return base * (100 + 2*source.Stats.ConditionPower) / 100
+}
+
+// ApplyCondition puts a condition on the entity, or stacks it onto an active
+// one of the same type. The latest applier is credited for kills.
+func (e *Entity) ApplyCondition(source *Entity, conditionType ConditionType, rounds int, potency int) Tick {
+	rule := conditionRules[conditionType]
+	if rule.damageOverTime {
+		potency = conditionDamage(potency, source)
+	}
+	sourceID := ""
+	if source != nil {
+		sourceID = source.ID
+	}
+
+	condition := e.Condition(conditionType)
+	if condition == nil {
+		condition = &Condition{Type: conditionType, Stacks: 1, Rounds: rounds, Potency: potency}
+		e.Conditions = append(e.Conditions, condition)
+	} else {
+		if condition.Stacks < rule.maxStacks {
+			condition.Stacks++
+		}
+		if rounds > condition.Rounds {
+			condition.Rounds = rounds
+		}
+		switch {
+		case rule.additive:
+			condition.Potency += potency
+		case potency > condition.Potency:
+			condition.Potency = potency
+		}
+	}
+	condition.SourceID = sourceID
+
+	return newTick("conditionApplied", EventCondition{
+		FighterID: e.ID,
+		SourceID:  sourceID,
+		Condition: conditionType,
+		Stacks:    condition.Stacks,
+		Rounds:    condition.Rounds,
+	})
+}
+
+// Condition returns the active condition of the given type, if any.
+func (e *Entity) Condition(conditionType ConditionType) *Condition {
+	for _, condition := range e.Conditions {
+		if condition.Type == conditionType {
+			return condition
+		}
+	}
+	return nil
+}
+
+func (e *Entity) IsStunned() bool { return e.Condition(ConditionStun) != nil }
+
+func (e *Entity) IsRooted() bool { return e.Condition(ConditionRoot) != nil }
+
+// absorb lets an active shield soak up damage and returns what is left.
+func (e *Entity) absorb(damage int) int {
+	shield := e.Condition(ConditionShield)
+	if shield == nil || shield.Potency <= 0 {
+		return damage
+	}
+	absorbed := damage
+	if absorbed > shield.Potency {
+		absorbed = shield.Potency
+	}
+	shield.Potency -= absorbed
+	return damage - absorbed
+}
+
+// evades rolls the entity's evasion cloud, if it has one.
+func (e *Entity) evades(rng *rand.Rand) bool {
+	cloud := e.Condition(ConditionEvasion)
+	return cloud != nil && rng.Intn(100) < cloud.Potency
+}
+
+// tickConditions deals damage over time. Kills are credited to the applier.
+func (e *Entity) tickConditions() []Tick {
+	var ticks []Tick
+	for _, condition := range e.Conditions {
+		if !conditionRules[condition.Type].damageOverTime || e.CurrentHP <= 0 || e.IsInvulnerable() {
+			continue
+		}
+		damage := condition.Potency * condition.Stacks
+		e.CurrentHP -= damage
+		if e.CurrentHP < 0 {
+			e.CurrentHP = 0
+		}
+		e.gainCharge(0, damage)
+		ticks = append(ticks, newTick("conditionTick", EventCondition{
+			FighterID: e.ID,
+			SourceID:  condition.SourceID,
+			Condition: condition.Type,
+			Stacks:    condition.Stacks,
+			Rounds:    condition.Rounds,
+			Damage:    damage,
+		}))
+		if e.CurrentHP <= 0 {
+			ticks = append(ticks, newTick("died", EventDied{FighterID: e.ID, KillerID: condition.SourceID}))
+		}
+	}
+	return ticks
+}
+
+// expireConditions counts down durations and removes expired conditions and
+// depleted shields.
+func (e *Entity) expireConditions() []Tick {
+	var ticks []Tick
+	active := e.Conditions[:0]
+	for _, condition := range e.Conditions {
+		condition.Rounds--
+		depleted := condition.Type == ConditionShield && condition.Potency <= 0
+		if condition.Rounds > 0 && !depleted {
+			active = append(active, condition)
+			continue
+		}
+		ticks = append(ticks, newTick("conditionExpired", EventCondition{
+			FighterID: e.ID,
+			SourceID:  condition.SourceID,
+			Condition: condition.Type,
+		}))
+	}
+	e.Conditions = active
+	return ticks
+}
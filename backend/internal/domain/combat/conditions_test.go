@@ -0,0 +1,123 @@
+package combat
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func TestApplyCondition_Stacking(t *testing.T) {
+	source := newTestEntity("source", 100)
+	target := newTestEntity("target", 100)
+
+	target.ApplyCondition(source, ConditionBleed, 2, 4)
+	target.ApplyCondition(source, ConditionBleed, 4, 4)
+	bleed := target.Condition(ConditionBleed)
+	if bleed.Stacks != 2 || bleed.Rounds != 4 {
+		t.Fatalf("expected 2 stacks for 4 rounds, got %d for %d", bleed.Stacks, bleed.Rounds)
+	}
+
+	for i := 0; i < 10; i++ {
+		target.ApplyCondition(source, ConditionStun, 1, 0)
+	}
+	if stun := target.Condition(ConditionStun); stun.Stacks != 1 {
+		t.Fatalf("expected stun not to stack, got %d stacks", stun.Stacks)
+	}
+
+	target.ApplyCondition(source, ConditionShield, 3, 10)
+	target.ApplyCondition(source, ConditionShield, 3, 15)
+	if shield := target.Condition(ConditionShield); shield.Potency != 25 {
+		t.Fatalf("expected shields to add up to 25, got %d", shield.Potency)
+	}
+}
+
+func TestConditionTick_ScalesWithConditionPower(t *testing.T) {
+	source := newTestEntity("source", 100)
+	source.Stats.ConditionPower = 25
+	target := newTestEntity("target", 100)
+
+	target.ApplyCondition(source, ConditionBurn, 3, 4)
+	target.ApplyCondition(source, ConditionBurn, 3, 4)
+
+	ticks := target.StartRound()
+	if len(ticks) != 1 || ticks[0].Type != "conditionTick" {
+		t.Fatalf("expected a single conditionTick, got %v", tickTypes(ticks))
+	}
+	var event EventCondition
+	if err := json.Unmarshal(ticks[0].Payload, &event); err != nil {
+		t.Fatalf("decode tick: %v", err)
+	}
+	// 4 base * 150% for 25 condition power, two stacks
+	if event.Damage != 12 || target.CurrentHP != 88 {
+		t.Fatalf("expected 12 damage, got %d (HP %d)", event.Damage, target.CurrentHP)
+	}
+}
+
+func TestConditionTick_KillCreditsApplier(t *testing.T) {
+	source := newTestEntity("bleeder", 100)
+	victim := newTestEntity("victim", 100)
+	victim.CurrentHP = 4
+	victim.ApplyCondition(source, ConditionBleed, 4, 5)
+
+	ticks := victim.StartRound()
+	if got := tickTypes(ticks); len(got) != 2 || got[0] != "conditionTick" || got[1] != "died" {
+		t.Fatalf("unexpected ticks %v", got)
+	}
+	var died EventDied
+	if err := json.Unmarshal(ticks[1].Payload, &died); err != nil {
+		t.Fatalf("decode died: %v", err)
+	}
+	if died.KillerID != "bleeder" {
+		t.Fatalf("expected kill credit for the bleeder, got %q", died.KillerID)
+	}
+}
+
+func TestConditionExpired(t *testing.T) {
+	target := newTestEntity("target", 100)
+	target.ApplyCondition(nil, ConditionRoot, 2, 0)
+	if !target.IsRooted() {
+		t.Fatal("expected target to be rooted")
+	}
+
+	if ticks := target.EndRound(); len(ticks) != 0 {
+		t.Fatalf("expected root to last another round, got %v", tickTypes(ticks))
+	}
+	ticks := target.EndRound()
+	if len(ticks) != 1 || ticks[0].Type != "conditionExpired" {
+		t.Fatalf("expected conditionExpired, got %v", tickTypes(ticks))
+	}
+	if target.IsRooted() {
+		t.Fatal("expected root to be gone")
+	}
+}
+
+func TestShieldAbsorbsDamage(t *testing.T) {
+	attacker := newTestEntity("a", 100)
+	target := newTestEntity("b", 100)
+	target.ApplyCondition(nil, ConditionShield, 5, 20)
+
+	ticks := dealDamage(attacker, target, "test", 30, rand.New(rand.NewSource(1)))
+	var attack EventAttack
+	_ = json.Unmarshal(ticks[0].Payload, &attack)
+	if attack.Absorbed != 20 || attack.Damage != 10 || target.CurrentHP != 90 {
+		t.Fatalf("expected 20 absorbed and 10 taken, got %+v (HP %d)", attack, target.CurrentHP)
+	}
+
+	// A depleted shield expires at the end of the round
+	if ticks := target.EndRound(); len(ticks) != 1 || ticks[0].Type != "conditionExpired" {
+		t.Fatalf("expected the depleted shield to expire, got %v", tickTypes(ticks))
+	}
+}
+
+func TestEvasionCloudDodges(t *testing.T) {
+	attacker := newTestEntity("a", 100)
+	target := newTestEntity("b", 100)
+	target.ApplyCondition(nil, ConditionEvasion, 5, 100)
+
+	ticks := dealDamage(attacker, target, "test", 30, rand.New(rand.NewSource(1)))
+	var attack EventAttack
+	_ = json.Unmarshal(ticks[0].Payload, &attack)
+	if !attack.IsDodged || target.CurrentHP != 100 {
+		t.Fatalf("expected the hit to be dodged, got %+v", attack)
+	}
+}
@@ -0,0 +1,189 @@
+package combat
+
+import (
+	"encoding/json"
+	"math/rand"
+
+	"empoweredpixels/internal/domain/skills"
+)
+
+// BleedChance is the percent chance that a hit applies the Bleed passive.
+const BleedChance = 25
+
+func newTick(tickType string, event any) Tick {
+	payload, _ := json.Marshal(event)
+	return Tick{Type: tickType, Payload: payload}
+}
+
+// Hit resolution tuning. Chances are in percent.
+const (
+	// BaseDodgeChance applies when the attacker's Accuracy matches the
+	// target's Agility; every two points of difference shift it by 1%.
+	BaseDodgeChance = 5
+	MaxDodgeChance  = 50
+	MaxParryChance  = 50
+	MaxCritChance   = 75
+	// BaseCritMultiplier is the critical damage factor, raised by 1% per
+	// point of Ferocity.
+	BaseCritMultiplier = 1.5
+)
+
+// DodgeChance is the chance that target dodges a hit from attacker.
+func DodgeChance(attacker, target *Entity) int {
+	return clampChance(BaseDodgeChance+(target.Stats.Agility-attacker.Stats.Accuracy)/2, MaxDodgeChance)
+}
+
+// ParryChance is the chance that the entity parries an incoming hit.
+func (e *Entity) ParryChance() int {
+	return clampChance(e.Stats.ParryChance, MaxParryChance)
+}
+
+// CriticalChance combines the weapon's crit chance with Precision: every two
+// points add 1%.
+func (e *Entity) CriticalChance() int {
+	return clampChance(e.Stats.CritChance+e.Stats.Precision/2, MaxCritChance)
+}
+
+// CriticalMultiplier is the damage factor of the entity's critical hits.
+func (e *Entity) CriticalMultiplier() float64 {
+	return BaseCritMultiplier + float64(e.Stats.Ferocity)/100
+}
+
+// ArmorMitigation is the share of damage that gets through the entity's
+// armor: 100 armor halves incoming damage, 300 quarters it.
+func (e *Entity) ArmorMitigation() float64 {
+	return 100 / float64(100+e.EffectiveArmor())
+}
+
+func clampChance(chance, max int) int {
+	if chance < 0 {
+		return 0
+	}
+	if chance > max {
+		return max
+	}
+	return chance
+}
+
+// dealDamage resolves one hit from attacker on target: evasion, dodge, parry,
+// outgoing buffs, elemental advantage, critical hits, armor, attunement
+// defense, blocks, invulnerability and shields, followed by the attacker's
+// on-hit passives and the target's Reflect.
+func dealDamage(attacker, target *Entity, skillID string, damage int, rng *rand.Rand) []Tick {
+	attack := EventAttack{
+		AttackerID: attacker.ID,
+		TargetID:   target.ID,
+		SkillID:    skillID,
+		Combo:      attacker.Combo,
+		Momentum:   attacker.Momentum,
+	}
+	if target.evades(rng) || rng.Intn(100) < DodgeChance(attacker, target) {
+		attack.IsDodged = true
+		return []Tick{newTick("attack", attack)}
+	}
+	if rng.Intn(100) < target.ParryChance() {
+		attack.IsParried = true
+		return []Tick{newTick("attack", attack)}
+	}
+
+	multiplier := attacker.DamageMultiplier() * (1 + attacker.Elemental.Power/100) * ElementalMultiplier(attacker, target)
+	if rng.Intn(100) < attacker.CriticalChance() {
+		attack.IsCritical = true
+		multiplier *= attacker.CriticalMultiplier()
+	}
+	damage = int(float64(damage) * multiplier * target.ArmorMitigation())
+	// Attunement defense mitigates what gets through the armor
+	damage = int(float64(damage) * (1 - target.Elemental.Defense/100))
+	if damage < 1 {
+		damage = 1
+	}
+
+	attack.IsBlocked = target.IsInvulnerable() || target.consumeBlock()
+	if attack.IsBlocked {
+		damage = 0
+	} else {
+		remaining := target.absorb(damage)
+		attack.Absorbed = damage - remaining
+		damage = remaining
+	}
+
+	if damage > 0 {
+		target.CurrentHP -= damage
+		// Target loses momentum when hit
+		target.Momentum -= 0.5
+		if target.Momentum < 0 {
+			target.Momentum = 0
+		}
+		// Target combo breaks when hit
+		target.Combo = 0
+
+		if target.CurrentHP < 0 {
+			target.CurrentHP = 0
+		}
+		attacker.gainCharge(damage, 0)
+		target.gainCharge(0, damage)
+	}
+	attack.Damage = damage
+
+	ticks := []Tick{newTick("attack", attack)}
+	if target.CurrentHP <= 0 {
+		ticks = append(ticks, newTick("died", EventDied{FighterID: target.ID, KillerID: attacker.ID}))
+	}
+	if damage > 0 {
+		ticks = append(ticks, onHit(attacker, target, damage, rng)...)
+	}
+	return ticks
+}
+
+func onHit(attacker, target *Entity, damage int, rng *rand.Rand) []Tick {
+	var ticks []Tick
+
+	if pct := attacker.Loadout.Passives[skills.SkillLifeSteal]; pct > 0 {
+		if amount := heal(attacker, damage*pct/100); amount > 0 {
+			ticks = append(ticks, newTick("heal", EventHeal{HealerID: attacker.ID, TargetID: attacker.ID, Amount: amount}))
+		}
+	}
+
+	if value := attacker.Loadout.Passives[skills.SkillBleed]; value > 0 && target.CurrentHP > 0 && rng.Intn(100) < BleedChance {
+		rounds := 4
+		if def, ok := skills.GetSkillByID(skills.SkillBleed); ok {
+			rounds = def.Duration
+		}
+		ticks = append(ticks, target.ApplyCondition(attacker, ConditionBleed, rounds, value))
+	}
+
+	if pct := target.Loadout.Passives[skills.SkillReflect]; pct > 0 && attacker.CurrentHP > 0 && !attacker.IsInvulnerable() {
+		if reflected := damage * pct / 100; reflected > 0 {
+			attacker.CurrentHP -= reflected
+			if attacker.CurrentHP < 0 {
+				attacker.CurrentHP = 0
+			}
+			attacker.gainCharge(0, reflected)
+			ticks = append(ticks, newTick("reflect", EventDamage{FighterID: attacker.ID, SourceID: target.ID, Damage: reflected}))
+			if attacker.CurrentHP <= 0 {
+				ticks = append(ticks, newTick("died", EventDied{FighterID: attacker.ID, KillerID: target.ID}))
+			}
+		}
+	}
+
+	return ticks
+}
+
+// heal restores up to amount HP without exceeding MaxHP and returns the
+// amount actually restored.
+func heal(e *Entity, amount int) int {
+	if missing := e.MaxHP - e.CurrentHP; amount > missing {
+		amount = missing
+	}
+	if amount < 0 {
+		return 0
+	}
+	e.CurrentHP += amount
+	return amount
+}
+
+// HealingMultiplier is the factor applied to the entity's heals: every point
+// of HealingPower adds 1%.
+func (e *Entity) HealingMultiplier() float64 {
+	return 1 + float64(e.Stats.HealingPower)/100
+}
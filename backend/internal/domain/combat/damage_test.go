@@ -0,0 +1,160 @@
+package combat
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func attackEvent(t *testing.T, ticks []Tick) EventAttack {
+	t.Helper()
+	for _, tick := range ticks {
+		if tick.Type != "attack" {
+			continue
+		}
+		var attack EventAttack
+		if err := json.Unmarshal(tick.Payload, &attack); err != nil {
+			t.Fatalf("decode attack: %v", err)
+		}
+		return attack
+	}
+	t.Fatalf("expected an attack tick, got %v", tickTypes(ticks))
+	return EventAttack{}
+}
+
+func TestDodgeChance_AccuracyAgainstAgility(t *testing.T) {
+	attacker := newTestEntity("attacker", 100)
+	target := newTestEntity("target", 100)
+
+	attacker.Stats.Accuracy = 0
+	target.Stats.Agility = 20
+	if got := DodgeChance(attacker, target); got != 15 {
+		t.Fatalf("expected 15%% dodge chance, got %d", got)
+	}
+	target.Stats.Agility = 500
+	if got := DodgeChance(attacker, target); got != MaxDodgeChance {
+		t.Fatalf("expected dodge chance capped at %d, got %d", MaxDodgeChance, got)
+	}
+	attacker.Stats.Accuracy = 1000
+	if got := DodgeChance(attacker, target); got != 0 {
+		t.Fatalf("expected accurate attackers never to be dodged, got %d", got)
+	}
+}
+
+func TestDealDamage_Dodge(t *testing.T) {
+	attacker := newTestEntity("attacker", 100)
+	attacker.Stats.Accuracy = 0
+	target := newTestEntity("target", 10000)
+	target.Stats.Agility = 1000
+
+	rng := rand.New(rand.NewSource(1))
+	dodged := 0
+	for i := 0; i < 100; i++ {
+		if attackEvent(t, dealDamage(attacker, target, "test", 10, rng)).IsDodged {
+			dodged++
+		}
+	}
+	if dodged < 30 || dodged > 70 {
+		t.Fatalf("expected roughly half of the hits dodged, got %d", dodged)
+	}
+	if target.CurrentHP != 10000-10*(100-dodged) {
+		t.Fatalf("expected dodged hits to deal no damage, HP %d after %d dodges", target.CurrentHP, dodged)
+	}
+}
+
+func TestDealDamage_Parry(t *testing.T) {
+	attacker := newTestEntity("attacker", 100)
+	target := newTestEntity("target", 10000)
+	target.Stats.ParryChance = 100
+	if got := target.ParryChance(); got != MaxParryChance {
+		t.Fatalf("expected parry chance capped at %d, got %d", MaxParryChance, got)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	parried := 0
+	for i := 0; i < 100; i++ {
+		attack := attackEvent(t, dealDamage(attacker, target, "test", 10, rng))
+		if attack.IsParried {
+			if attack.Damage != 0 {
+				t.Fatalf("expected parried hits to deal no damage, got %d", attack.Damage)
+			}
+			parried++
+		}
+	}
+	if parried < 30 || parried > 70 {
+		t.Fatalf("expected roughly half of the hits parried, got %d", parried)
+	}
+}
+
+func TestDealDamage_CriticalScalesWithFerocity(t *testing.T) {
+	attacker := newTestEntity("attacker", 100)
+	attacker.Stats.Precision = 1000
+	attacker.Stats.Ferocity = 50
+	if got := attacker.CriticalChance(); got != MaxCritChance {
+		t.Fatalf("expected crit chance capped at %d, got %d", MaxCritChance, got)
+	}
+
+	target := newTestEntity("target", 10000)
+	rng := rand.New(rand.NewSource(1))
+	crits := 0
+	for i := 0; i < 20; i++ {
+		attack := attackEvent(t, dealDamage(attacker, target, "test", 20, rng))
+		// 20 damage * (1.5 + 0.5)
+		switch {
+		case attack.IsCritical && attack.Damage != 40:
+			t.Fatalf("expected critical hits to deal 40 damage, got %d", attack.Damage)
+		case !attack.IsCritical && attack.Damage != 20:
+			t.Fatalf("expected regular hits to deal 20 damage, got %d", attack.Damage)
+		case attack.IsCritical:
+			crits++
+		}
+	}
+	if crits == 0 {
+		t.Fatal("expected critical hits")
+	}
+}
+
+func TestDealDamage_ArmorMitigation(t *testing.T) {
+	attacker := newTestEntity("attacker", 100)
+	target := newTestEntity("target", 100)
+	target.Stats.Armor = 100
+
+	attack := attackEvent(t, dealDamage(attacker, target, "test", 30, rand.New(rand.NewSource(1))))
+	if attack.Damage != 15 {
+		t.Fatalf("expected 100 armor to halve 30 damage, got %d", attack.Damage)
+	}
+}
+
+func TestMend_ScalesWithHealingPower(t *testing.T) {
+	healer := newTestEntity("healer", 100)
+	healer.Stats.HealingPower = 10
+	ally := newTestEntity("ally", 100)
+	ally.CurrentHP = 40
+
+	mend := NewMend()
+	if !mend.ShouldCast(ally) || mend.ShouldCast(healer) {
+		t.Fatal("expected Mend to target only allies below half health")
+	}
+	ticks, err := mend.Execute(healer, ally, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("mend failed: %v", err)
+	}
+	var event EventHeal
+	if err := json.Unmarshal(ticks[0].Payload, &event); err != nil {
+		t.Fatalf("decode heal: %v", err)
+	}
+	if ticks[0].Type != "heal" || event.Amount != 25 || ally.CurrentHP != 65 {
+		t.Fatalf("expected a 25 HP heal, got %+v (HP %d)", event, ally.CurrentHP)
+	}
+}
+
+func TestTick_FighterIDs(t *testing.T) {
+	attack := newTick("attack", EventAttack{AttackerID: "a", TargetID: "b"})
+	if ids := attack.FighterIDs(); len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Fatalf("expected attacker and target, got %v", ids)
+	}
+	died := newTick("died", EventDied{FighterID: "b", KillerID: "b"})
+	if ids := died.FighterIDs(); len(ids) != 1 || ids[0] != "b" {
+		t.Fatalf("expected a single fighter, got %v", ids)
+	}
+}
@@ -0,0 +1,60 @@
+package combat
+
+// Base values of the stats derived from a fighter's attributes.
+const (
+	BaseHealth = 100
+	// HealthPerVitality is the max HP every point of Vitality adds.
+	HealthPerVitality = 12
+	// BaseMoveDistance is how far an entity moves in a round; every eight
+	// points of Speed add a tile.
+	BaseMoveDistance = 3.0
+)
+
+// MaxHealth is the HP an entity with the stats enters a battle with.
+func MaxHealth(stats Stats) int {
+	return BaseHealth + stats.Vitality*HealthPerVitality
+}
+
+// MoveDistance is how far an entity with the stats moves in a round before
+// speed effects.
+func MoveDistance(stats Stats) float64 {
+	return BaseMoveDistance + float64(stats.Speed)/8
+}
+
+// DerivedStats are the combat values stats turn into, for showing a fighter
+// what its attributes do.
+type DerivedStats struct {
+	MaxHP          int
+	MoveDistance   float64
+	Initiative     int
+	VisionRange    float64
+	DodgeChance    int
+	ParryChance    int
+	CritChance     int
+	CritMultiplier float64
+	// DamageTaken is the share of damage that gets through armor.
+	DamageTaken float64
+	// ConditionDamage is the factor applied to damage-over-time conditions.
+	ConditionDamage   float64
+	HealingMultiplier float64
+}
+
+// Derive computes the derived stats of an entity with the stats outside of
+// a battle: without effects, attunement bonuses or initiative rolls, and
+// dodging an attacker without Accuracy.
+func Derive(stats Stats) DerivedStats {
+	e := &Entity{Stats: stats}
+	return DerivedStats{
+		MaxHP:             MaxHealth(stats),
+		MoveDistance:      MoveDistance(stats),
+		Initiative:        stats.Speed + stats.Agility,
+		VisionRange:       e.VisionRange(),
+		DodgeChance:       DodgeChance(&Entity{}, e),
+		ParryChance:       e.ParryChance(),
+		CritChance:        e.CriticalChance(),
+		CritMultiplier:    e.CriticalMultiplier(),
+		DamageTaken:       e.ArmorMitigation(),
+		ConditionDamage:   float64(conditionDamage(100, e)) / 100,
+		HealingMultiplier: e.HealingMultiplier(),
+	}
+}
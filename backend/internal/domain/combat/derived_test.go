@@ -0,0 +1,20 @@
+package combat
+
+import "testing"
+
+func TestDerive(t *testing.T) {
+	derived := Derive(Stats{Vitality: 10, Speed: 16, Agility: 4, Precision: 10, Armor: 100, ParryChance: 80})
+
+	if derived.MaxHP != 220 {
+		t.Errorf("expected 220 HP, got %d", derived.MaxHP)
+	}
+	if derived.MoveDistance != 5 {
+		t.Errorf("expected a move distance of 5, got %v", derived.MoveDistance)
+	}
+	if derived.Initiative != 20 || derived.DodgeChance != 7 || derived.CritChance != 5 {
+		t.Errorf("unexpected initiative, dodge or crit: %+v", derived)
+	}
+	if derived.ParryChance != MaxParryChance || derived.DamageTaken != 0.5 {
+		t.Errorf("unexpected parry or mitigation: %+v", derived)
+	}
+}
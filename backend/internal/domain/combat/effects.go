@@ -0,0 +1,132 @@
+package combat
+
+import "empoweredpixels/internal/domain/skills"
+
+// BaseManaRegen is the mana every entity regenerates per round.
+const BaseManaRegen = 5
+
+// Effect is a temporary buff or debuff on an entity, applied by a skill. One
+// round of combat counts as one second of skill duration. Damage over time
+// and crowd control are conditions, see ApplyCondition.
+type Effect struct {
+	ID           string // skill that applied the effect
+	SourceID     string // entity that applied the effect
+	Rounds       int    // rounds remaining, including the current one
+	DamagePct    int
+	ArmorPct     int
+	SpeedPct     int
+	Invulnerable bool
+	BlockCharges int
+}
+
+// AddEffect applies an effect. Re-applying the same skill refreshes the
+// existing effect instead of stacking it.
+func (e *Entity) AddEffect(effect *Effect) {
+	for i, existing := range e.Effects {
+		if existing.ID == effect.ID {
+			e.Effects[i] = effect
+			return
+		}
+	}
+	e.Effects = append(e.Effects, effect)
+}
+
+// HasEffect reports whether an effect applied by the given skill is active.
+func (e *Entity) HasEffect(id string) bool {
+	for _, effect := range e.Effects {
+		if effect.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// DamageMultiplier is the factor applied to all outgoing damage.
+func (e *Entity) DamageMultiplier() float64 {
+	pct := 0
+	for _, effect := range e.Effects {
+		pct += effect.DamagePct
+	}
+	return 1.0 + float64(pct)/100.0
+}
+
+// EffectiveArmor is the entity's armor after buffs and debuffs.
+func (e *Entity) EffectiveArmor() int {
+	pct := 0
+	for _, effect := range e.Effects {
+		pct += effect.ArmorPct
+	}
+	armor := e.Stats.Armor * (100 + pct) / 100
+	if armor < 0 {
+		return 0
+	}
+	return armor
+}
+
+// SpeedMultiplier is the factor applied to attack speed and movement.
+func (e *Entity) SpeedMultiplier() float64 {
+	pct := 0
+	for _, effect := range e.Effects {
+		pct += effect.SpeedPct
+	}
+	return 1.0 + float64(pct)/100.0
+}
+
+func (e *Entity) IsInvulnerable() bool {
+	for _, effect := range e.Effects {
+		if effect.Invulnerable {
+			return true
+		}
+	}
+	return false
+}
+
+// consumeBlock uses up one block charge, if the entity has any left.
+func (e *Entity) consumeBlock() bool {
+	for _, effect := range e.Effects {
+		if effect.BlockCharges > 0 {
+			effect.BlockCharges--
+			return true
+		}
+	}
+	return false
+}
+
+// gainCharge converts damage dealt and taken into ultimate charge.
+func (e *Entity) gainCharge(dealt, taken int) {
+	e.chargeDealt += dealt
+	e.chargeTaken += taken
+	e.UltimateCharge = skills.AddUltimateCharge(e.UltimateCharge, e.chargeDealt, e.chargeTaken)
+	e.chargeDealt %= 50
+	e.chargeTaken %= 25
+}
+
+// StartRound regenerates mana, counts down cooldowns and resolves damage over
+// time.
+func (e *Entity) StartRound() []Tick {
+	e.Mana += BaseManaRegen + e.Loadout.Passives[skills.SkillManaRegen]
+	if e.Mana > e.MaxMana {
+		e.Mana = e.MaxMana
+	}
+	for id, remaining := range e.Cooldowns {
+		if remaining > 0 {
+			e.Cooldowns[id] = remaining - 1
+		}
+	}
+
+	return e.tickConditions()
+}
+
+// EndRound counts down effect and condition durations and drops the expired
+// ones.
+func (e *Entity) EndRound() []Tick {
+	active := e.Effects[:0]
+	for _, effect := range e.Effects {
+		effect.Rounds--
+		if effect.Rounds > 0 {
+			active = append(active, effect)
+		}
+	}
+	e.Effects = active
+	return e.expireConditions()
+}
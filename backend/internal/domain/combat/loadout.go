@@ -0,0 +1,360 @@
+package combat
+
+import (
+	"math"
+	"math/rand"
+
+	"empoweredpixels/internal/domain/skills"
+)
+
+// UltimateUnlockLevel is the fighter level at which ultimates become usable.
+const UltimateUnlockLevel = 50
+
+// Mana pool of every entity at the start of a battle
+const (
+	StartingMana   = 50
+	DefaultMaxMana = 100
+)
+
+// ActiveSkill is a loadout skill or ultimate. Casting it replaces the
+// entity's basic attack for the turn.
+type ActiveSkill interface {
+	Skill
+	ManaCost() int
+	Cooldown() int
+	// ShouldCast reports whether casting against target is worthwhile now.
+	ShouldCast(caster *Entity, target *Entity) bool
+}
+
+// AreaSkill is implemented by skills that hit several enemies at once.
+type AreaSkill interface {
+	ExecuteArea(attacker *Entity, targets []*Entity, rng *rand.Rand) ([]Tick, error)
+}
+
+// SkillLoadout is the combat form of a fighter's skill tree: the actives it
+// casts in loadout order, its ultimate and the effect values of its passives.
+type SkillLoadout struct {
+	Actives  []ActiveSkill
+	Ultimate ActiveSkill
+	Passives map[string]int
+}
+
+// CompileLoadout turns a fighter's allocated ranks and loadout into
+// executable skills. Skill reach and base damage come from the weapon type.
+// The ultimate matches the branch with the most allocated points.
+func CompileLoadout(fs *skills.FighterSkills, level int, weaponType string) SkillLoadout {
+	loadout := SkillLoadout{Passives: make(map[string]int)}
+	if fs == nil {
+		return loadout
+	}
+	weapon := weaponSkill(weaponType)
+
+	for id, rank := range fs.AllocatedPoints {
+		if def, ok := skills.GetSkillByID(id); ok && def.Type == skills.Passive {
+			loadout.Passives[id] = skills.CalculateSkillEffect(def, rank)
+		}
+	}
+
+	for _, id := range fs.Loadout {
+		if len(loadout.Actives) == skills.MaxActiveSkills {
+			break
+		}
+		def, ok := skills.GetSkillByID(id)
+		rank := fs.AllocatedPoints[id]
+		if !ok || def.Type != skills.Active || rank == 0 {
+			continue
+		}
+		if skill := newActiveSkill(def, rank, weapon); skill != nil {
+			loadout.Actives = append(loadout.Actives, skill)
+		}
+	}
+
+	if level >= UltimateUnlockLevel {
+		progress := skills.GetSkillProgress(fs.AllocatedPoints)
+		best := -1
+		for i, branch := range progress {
+			if branch.PointsAllocated > 0 && (best < 0 || branch.PointsAllocated > progress[best].PointsAllocated) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			if def, ok := skills.GetUltimateByBranch(progress[best].Branch); ok {
+				loadout.Ultimate = newActiveSkill(def, 1, weapon)
+			}
+		}
+	}
+
+	return loadout
+}
+
+func newActiveSkill(def *skills.Skill, rank int, weapon *BaseDamageSkill) ActiveSkill {
+	base := activeSkill{def: def, rank: rank, weapon: weapon}
+	switch def.ID {
+	case skills.SkillPowerStrike:
+		return &powerStrike{base}
+	case skills.SkillWhirlwind:
+		return &whirlwind{base}
+	case skills.SkillBerserk:
+		return &berserk{base}
+	case skills.SkillExecute:
+		return &execute{base}
+	case skills.SkillBlock:
+		return &block{base}
+	case skills.SkillHeal:
+		return &healSkill{base}
+	case skills.SkillShieldWall:
+		return &shieldWall{base}
+	case skills.SkillImmortal:
+		return &immortal{base}
+	case skills.SkillHaste:
+		return &haste{base}
+	case skills.SkillTeleport:
+		return &teleport{base}
+	case skills.SkillStun:
+		return &stunStrike{base}
+	case skills.UltimateMeteorStrike:
+		return &meteorStrike{base}
+	case skills.UltimateDivineProtection:
+		return &divineProtection{base}
+	case skills.UltimateTimeWarp:
+		return &timeWarp{base}
+	default:
+		return nil
+	}
+}
+
+type activeSkill struct {
+	def    *skills.Skill
+	rank   int
+	weapon *BaseDamageSkill
+}
+
+func (s *activeSkill) ID() string     { return s.def.ID }
+func (s *activeSkill) Name() string   { return s.def.Name }
+func (s *activeSkill) ManaCost() int  { return s.def.ManaCost }
+func (s *activeSkill) Cooldown() int  { return s.def.Cooldown }
+func (s *activeSkill) Range() float64 { return s.weapon.Range() }
+
+func (s *activeSkill) effect() int {
+	return skills.CalculateSkillEffect(s.def, s.rank)
+}
+
+// inReach reports whether target is close enough that a self buff pays off.
+func (s *activeSkill) inReach(caster, target *Entity) bool {
+	return caster.DistanceTo(target) <= s.weapon.Range()*2
+}
+
+// buff applies a self effect and returns its "buff" tick.
+func (s *activeSkill) buff(caster *Entity, effect *Effect) []Tick {
+	effect.ID = s.def.ID
+	effect.SourceID = caster.ID
+	caster.AddEffect(effect)
+	return []Tick{newTick("buff", EventEffect{FighterID: caster.ID, SourceID: caster.ID, EffectID: s.def.ID, Rounds: effect.Rounds})}
+}
+
+// Power Strike: a weapon hit with bonus damage.
+type powerStrike struct{ activeSkill }
+
+func (s *powerStrike) ShouldCast(caster, target *Entity) bool { return true }
+
+func (s *powerStrike) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	damage := s.weapon.roll(attacker, rng) * (100 + s.effect()) / 100
+	return dealDamage(attacker, target, s.def.ID, damage, rng), nil
+}
+
+// Whirlwind: hits every enemy in weapon reach for a share of weapon damage.
+type whirlwind struct{ activeSkill }
+
+func (s *whirlwind) ShouldCast(caster, target *Entity) bool { return true }
+
+func (s *whirlwind) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	return s.ExecuteArea(attacker, []*Entity{target}, rng)
+}
+
+func (s *whirlwind) ExecuteArea(attacker *Entity, targets []*Entity, rng *rand.Rand) ([]Tick, error) {
+	var ticks []Tick
+	for _, target := range targets {
+		if target.CurrentHP <= 0 || attacker.DistanceTo(target) > s.Range() {
+			continue
+		}
+		damage := s.weapon.roll(attacker, rng) * s.effect() / 100
+		ticks = append(ticks, dealDamage(attacker, target, s.def.ID, damage, rng)...)
+	}
+	return ticks, nil
+}
+
+// Berserk: trades armor for damage.
+type berserk struct{ activeSkill }
+
+func (s *berserk) Range() float64 { return math.MaxFloat64 }
+
+func (s *berserk) ShouldCast(caster, target *Entity) bool {
+	return !caster.HasEffect(s.def.ID) && s.inReach(caster, target)
+}
+
+func (s *berserk) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	// Armor penalty shrinks from 15% to 5% with rank
+	armorPenalty := 15 - 5*(s.rank-1)
+	return s.buff(attacker, &Effect{Rounds: s.def.Duration, DamagePct: s.effect(), ArmorPct: -armorPenalty}), nil
+}
+
+// Execute: kills an enemy below the health threshold outright.
+type execute struct{ activeSkill }
+
+func (s *execute) ShouldCast(caster, target *Entity) bool {
+	return !target.IsInvulnerable() && target.CurrentHP*100 <= target.MaxHP*s.effect()
+}
+
+func (s *execute) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	attacker.gainCharge(target.CurrentHP, 0)
+	target.CurrentHP = 0
+	return []Tick{
+		newTick("execute", EventExecute{AttackerID: attacker.ID, TargetID: target.ID, SkillID: s.def.ID}),
+		newTick("died", EventDied{FighterID: target.ID, KillerID: attacker.ID}),
+	}, nil
+}
+
+// Block: negates the next incoming attacks, one per rank.
+type block struct{ activeSkill }
+
+func (s *block) Range() float64 { return math.MaxFloat64 }
+
+func (s *block) ShouldCast(caster, target *Entity) bool {
+	return !caster.HasEffect(s.def.ID) && s.inReach(caster, target)
+}
+
+func (s *block) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	return s.buff(attacker, &Effect{Rounds: s.def.Duration, BlockCharges: s.rank}), nil
+}
+
+// Heal: restores a share of maximum health once below half health.
+type healSkill struct{ activeSkill }
+
+func (s *healSkill) Range() float64 { return math.MaxFloat64 }
+
+func (s *healSkill) ShouldCast(caster, target *Entity) bool {
+	return caster.CurrentHP*2 < caster.MaxHP
+}
+
+func (s *healSkill) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	amount := heal(attacker, int(float64(attacker.MaxHP*s.effect()/100)*attacker.HealingMultiplier()))
+	return []Tick{newTick("heal", EventHeal{HealerID: attacker.ID, TargetID: attacker.ID, Amount: amount})}, nil
+}
+
+// Shield Wall: raises armor, lasting longer with rank.
+type shieldWall struct{ activeSkill }
+
+func (s *shieldWall) Range() float64 { return math.MaxFloat64 }
+
+func (s *shieldWall) ShouldCast(caster, target *Entity) bool {
+	return !caster.HasEffect(s.def.ID) && s.inReach(caster, target)
+}
+
+func (s *shieldWall) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	return s.buff(attacker, &Effect{Rounds: s.def.Duration + 2*(s.rank-1), ArmorPct: s.effect()}), nil
+}
+
+// Immortal: invulnerability as a last resort.
+type immortal struct{ activeSkill }
+
+func (s *immortal) Range() float64 { return math.MaxFloat64 }
+
+func (s *immortal) ShouldCast(caster, target *Entity) bool {
+	return caster.CurrentHP*100 < caster.MaxHP*30
+}
+
+func (s *immortal) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	return s.buff(attacker, &Effect{Rounds: s.def.Duration + (s.rank - 1), Invulnerable: true}), nil
+}
+
+// Haste: raises attack and movement speed.
+type haste struct{ activeSkill }
+
+func (s *haste) Range() float64 { return math.MaxFloat64 }
+
+func (s *haste) ShouldCast(caster, target *Entity) bool { return !caster.HasEffect(s.def.ID) }
+
+func (s *haste) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	return s.buff(attacker, &Effect{Rounds: s.def.Duration, SpeedPct: s.effect()}), nil
+}
+
+// Teleport: closes the distance to an out-of-reach target.
+type teleport struct{ activeSkill }
+
+func (s *teleport) Range() float64 { return float64(s.effect()) + s.weapon.Range() }
+
+func (s *teleport) ShouldCast(caster, target *Entity) bool {
+	return !caster.IsRooted() && caster.DistanceTo(target) > s.weapon.Range()
+}
+
+func (s *teleport) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	fromX, fromY := attacker.X, attacker.Y
+	// Land halfway inside weapon reach, on the caster's side of the target
+	angle := math.Atan2(attacker.Y-target.Y, attacker.X-target.X)
+	attacker.X = target.X + math.Cos(angle)*s.weapon.Range()/2
+	attacker.Y = target.Y + math.Sin(angle)*s.weapon.Range()/2
+	return []Tick{newTick("teleport", EventMove{FighterID: attacker.ID, FromX: fromX, FromY: fromY, ToX: attacker.X, ToY: attacker.Y})}, nil
+}
+
+// Stun Strike: a weapon hit that stuns the target. The effect value is in
+// milliseconds and rounds up to whole rounds.
+type stunStrike struct{ activeSkill }
+
+func (s *stunStrike) ShouldCast(caster, target *Entity) bool { return !target.IsStunned() }
+
+func (s *stunStrike) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	ticks := dealDamage(attacker, target, s.def.ID, s.weapon.roll(attacker, rng), rng)
+	if target.CurrentHP <= 0 || target.IsInvulnerable() {
+		return ticks, nil
+	}
+	rounds := (s.effect() + 999) / 1000
+	return append(ticks, target.ApplyCondition(attacker, ConditionStun, rounds, 0)), nil
+}
+
+// Meteor Strike: fixed damage to every enemy on the map.
+type meteorStrike struct{ activeSkill }
+
+func (s *meteorStrike) Range() float64 { return math.MaxFloat64 }
+
+func (s *meteorStrike) ShouldCast(caster, target *Entity) bool { return true }
+
+func (s *meteorStrike) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	return s.ExecuteArea(attacker, []*Entity{target}, rng)
+}
+
+func (s *meteorStrike) ExecuteArea(attacker *Entity, targets []*Entity, rng *rand.Rand) ([]Tick, error) {
+	var ticks []Tick
+	for _, target := range targets {
+		if target.CurrentHP > 0 {
+			ticks = append(ticks, dealDamage(attacker, target, s.def.ID, s.effect(), rng)...)
+		}
+	}
+	return ticks, nil
+}
+
+// Divine Protection: full heal followed by invulnerability.
+type divineProtection struct{ activeSkill }
+
+func (s *divineProtection) Range() float64 { return math.MaxFloat64 }
+
+func (s *divineProtection) ShouldCast(caster, target *Entity) bool { return true }
+
+func (s *divineProtection) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	amount := heal(attacker, attacker.MaxHP*s.effect()/100)
+	ticks := []Tick{newTick("heal", EventHeal{HealerID: attacker.ID, TargetID: attacker.ID, Amount: amount})}
+	return append(ticks, s.buff(attacker, &Effect{Rounds: s.def.Duration, Invulnerable: true})...), nil
+}
+
+// Time Warp: resets all cooldowns and raises speed.
+type timeWarp struct{ activeSkill }
+
+func (s *timeWarp) Range() float64 { return math.MaxFloat64 }
+
+func (s *timeWarp) ShouldCast(caster, target *Entity) bool { return true }
+
+func (s *timeWarp) Execute(attacker, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	for id := range attacker.Cooldowns {
+		attacker.Cooldowns[id] = 0
+	}
+	return s.buff(attacker, &Effect{Rounds: s.def.Duration, SpeedPct: s.effect()}), nil
+}
@@ -0,0 +1,179 @@
+package combat
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"empoweredpixels/internal/domain/skills"
+)
+
+func newTestEntity(id string, hp int) *Entity {
+	return &Entity{
+		ID:        id,
+		MaxHP:     hp,
+		CurrentHP: hp,
+		Mana:      DefaultMaxMana,
+		MaxMana:   DefaultMaxMana,
+		Cooldowns: make(map[string]int),
+		Stats:     Stats{AttackSpeed: 1.0, Accuracy: 2 * BaseDodgeChance},
+		Loadout:   SkillLoadout{Passives: make(map[string]int)},
+	}
+}
+
+func tickTypes(ticks []Tick) []string {
+	types := make([]string, len(ticks))
+	for i, t := range ticks {
+		types[i] = t.Type
+	}
+	return types
+}
+
+func TestCompileLoadout(t *testing.T) {
+	fs := &skills.FighterSkills{
+		AllocatedPoints: map[string]int{
+			skills.SkillPowerStrike: 2,
+			skills.SkillWhirlwind:   1,
+			skills.SkillBleed:       3,
+			skills.SkillHeal:        1,
+		},
+		// Berserk is not allocated and Heal exceeds the slot limit
+		Loadout: []string{skills.SkillBerserk, skills.SkillWhirlwind, skills.SkillPowerStrike, skills.SkillHeal},
+	}
+
+	loadout := CompileLoadout(fs, 10, "Sword")
+	if len(loadout.Actives) != skills.MaxActiveSkills {
+		t.Fatalf("expected %d actives, got %d", skills.MaxActiveSkills, len(loadout.Actives))
+	}
+	if loadout.Actives[0].ID() != skills.SkillWhirlwind || loadout.Actives[1].ID() != skills.SkillPowerStrike {
+		t.Fatalf("unexpected loadout order: %s, %s", loadout.Actives[0].ID(), loadout.Actives[1].ID())
+	}
+	if loadout.Passives[skills.SkillBleed] != 7 {
+		t.Fatalf("expected rank 3 bleed to deal 7 per round, got %d", loadout.Passives[skills.SkillBleed])
+	}
+	if loadout.Ultimate != nil {
+		t.Fatal("expected no ultimate below the unlock level")
+	}
+
+	loadout = CompileLoadout(fs, UltimateUnlockLevel, "Sword")
+	if loadout.Ultimate == nil || loadout.Ultimate.ID() != skills.UltimateMeteorStrike {
+		t.Fatalf("expected the offense ultimate, got %v", loadout.Ultimate)
+	}
+}
+
+func TestCompileLoadout_NoSkills(t *testing.T) {
+	loadout := CompileLoadout(nil, 60, Unarmed)
+	if len(loadout.Actives) != 0 || loadout.Ultimate != nil || len(loadout.Passives) != 0 {
+		t.Fatalf("expected an empty loadout, got %+v", loadout)
+	}
+}
+
+func TestExecuteThreshold(t *testing.T) {
+	def, _ := skills.GetSkillByID(skills.SkillExecute)
+	skill := newActiveSkill(def, 1, weaponSkill("Sword"))
+
+	attacker := newTestEntity("a", 100)
+	target := newTestEntity("b", 100)
+	target.CurrentHP = 20
+	if skill.ShouldCast(attacker, target) {
+		t.Fatal("execute should not fire above the threshold")
+	}
+
+	target.CurrentHP = 15
+	if !skill.ShouldCast(attacker, target) {
+		t.Fatal("execute should fire at the threshold")
+	}
+	ticks, _ := skill.Execute(attacker, target, rand.New(rand.NewSource(1)))
+	if target.CurrentHP != 0 {
+		t.Fatalf("expected target to die, has %d HP", target.CurrentHP)
+	}
+	if got := tickTypes(ticks); len(got) != 2 || got[0] != "execute" || got[1] != "died" {
+		t.Fatalf("unexpected ticks %v", got)
+	}
+}
+
+func TestWhirlwindHitsEnemiesInReach(t *testing.T) {
+	def, _ := skills.GetSkillByID(skills.SkillWhirlwind)
+	skill := newActiveSkill(def, 1, weaponSkill("Sword"))
+	area, ok := skill.(AreaSkill)
+	if !ok {
+		t.Fatal("whirlwind should be an area skill")
+	}
+
+	attacker := newTestEntity("a", 100)
+	near1 := newTestEntity("b", 100)
+	near1.X = 1
+	near2 := newTestEntity("c", 100)
+	near2.Y = 2
+	far := newTestEntity("d", 100)
+	far.X = 20
+
+	if _, err := area.ExecuteArea(attacker, []*Entity{near1, near2, far}, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatalf("whirlwind: %v", err)
+	}
+	if near1.CurrentHP == 100 || near2.CurrentHP == 100 {
+		t.Fatal("expected both nearby enemies to be hit")
+	}
+	if far.CurrentHP != 100 {
+		t.Fatal("expected the distant enemy to be untouched")
+	}
+}
+
+func TestBerserkTradesArmorForDamage(t *testing.T) {
+	def, _ := skills.GetSkillByID(skills.SkillBerserk)
+	skill := newActiveSkill(def, 1, weaponSkill("Axe"))
+
+	caster := newTestEntity("a", 100)
+	caster.Stats.Armor = 100
+	ticks, _ := skill.Execute(caster, caster, rand.New(rand.NewSource(1)))
+	if ticks[0].Type != "buff" {
+		t.Fatalf("expected a buff tick, got %s", ticks[0].Type)
+	}
+	if caster.DamageMultiplier() != 1.2 {
+		t.Fatalf("expected +20%% damage, got %f", caster.DamageMultiplier())
+	}
+	if caster.EffectiveArmor() != 85 {
+		t.Fatalf("expected -15%% armor, got %d", caster.EffectiveArmor())
+	}
+
+	for i := 0; i < def.Duration; i++ {
+		caster.EndRound()
+	}
+	if caster.HasEffect(skills.SkillBerserk) {
+		t.Fatal("expected berserk to expire after its duration")
+	}
+}
+
+func TestBlockNegatesHits(t *testing.T) {
+	attacker := newTestEntity("a", 100)
+	target := newTestEntity("b", 100)
+	target.AddEffect(&Effect{ID: skills.SkillBlock, Rounds: 5, BlockCharges: 1})
+
+	ticks := dealDamage(attacker, target, "test", 30, rand.New(rand.NewSource(1)))
+	var attack EventAttack
+	_ = json.Unmarshal(ticks[0].Payload, &attack)
+	if !attack.IsBlocked || target.CurrentHP != 100 {
+		t.Fatalf("expected the first hit to be blocked, target has %d HP", target.CurrentHP)
+	}
+
+	dealDamage(attacker, target, "test", 30, rand.New(rand.NewSource(1)))
+	if target.CurrentHP != 70 {
+		t.Fatalf("expected the second hit to land, target has %d HP", target.CurrentHP)
+	}
+}
+
+func TestUltimateChargeFromDamage(t *testing.T) {
+	attacker := newTestEntity("a", 1000)
+	target := newTestEntity("b", 1000)
+
+	for i := 0; i < 10; i++ {
+		dealDamage(attacker, target, "test", 10, rand.New(rand.NewSource(1)))
+	}
+	// 100 damage dealt is 2%, 100 damage taken is 4%
+	if attacker.UltimateCharge != 2 {
+		t.Fatalf("expected attacker charge 2, got %d", attacker.UltimateCharge)
+	}
+	if target.UltimateCharge != 4 {
+		t.Fatalf("expected target charge 4, got %d", target.UltimateCharge)
+	}
+}
@@ -2,6 +2,7 @@ package combat
 
 import (
 	"encoding/json"
+	"math"
 )
 
 type Entity struct {
@@ -17,6 +18,58 @@ type Entity struct {
 	Stats        Stats
 	Combo        int
 	Momentum     float64
+	IsBot        bool
+	// Elemental holds the owner's attunement level bonuses
+	Elemental ElementalBonus
+	// WeaponType selects the entity's skills, see GetSkillsByWeapon.
+	WeaponType string
+	// AttackCharge accumulates AttackSpeed each round; every full point is
+	// one attack.
+	AttackCharge float64
+	// Skill state, see CompileLoadout
+	Loadout        SkillLoadout
+	Mana           int
+	MaxMana        int
+	Cooldowns      map[string]int
+	UltimateCharge int
+	Effects        []*Effect
+	Conditions     []*Condition
+	// Damage not yet converted into ultimate charge
+	chargeDealt int
+	chargeTaken int
+}
+
+// Faction identifies the side an entity fights for: its team, or the entity
+// itself in a free-for-all.
+func (e *Entity) Faction() string {
+	if e.TeamID != nil {
+		return "team:" + *e.TeamID
+	}
+	return "fighter:" + e.ID
+}
+
+// DistanceTo returns the distance between both entities on the map.
+func (e *Entity) DistanceTo(other *Entity) float64 {
+	return math.Hypot(e.X-other.X, e.Y-other.Y)
+}
+
+// BaseVision is how far every entity can see; each point of Vision adds
+// half a tile.
+const BaseVision = 10.0
+
+// VisionRange is the distance up to which the entity spots other entities.
+func (e *Entity) VisionRange() float64 {
+	return BaseVision + float64(e.Stats.Vision)/2
+}
+
+// CanSee reports whether other is within the entity's vision range.
+func (e *Entity) CanSee(other *Entity) bool {
+	return e.DistanceTo(other) <= e.VisionRange()
+}
+
+// IsAllyOf reports whether both entities fight on the same side.
+func (e *Entity) IsAllyOf(other *Entity) bool {
+	return e.Faction() == other.Faction()
 }
 
 type Stats struct {
@@ -32,12 +85,21 @@ type Stats struct {
 	HealingPower   int
 	Speed          int
 	Vision         int
+	// Weapon stats after rarity and enhancement multipliers
+	WeaponDamage int
+	CritChance   int
+	AttackSpeed  float64
 }
 
 type MatchResult struct {
 	MatchID    string
+	Seed       int64
 	RoundTicks []RoundTick
 	Scores     []FighterScore
+	// WinnerIDs lists every fighter on the winning side, WinnerTeamID is set
+	// when that side is a team. Both are empty when the battle is a draw.
+	WinnerIDs    []string
+	WinnerTeamID *string
 }
 
 type RoundTick struct {
@@ -50,15 +112,53 @@ type Tick struct {
 	Payload json.RawMessage `json:"payload"`
 }
 
+// FighterIDs returns every fighter the tick's event refers to, in whatever
+// role.
+func (t Tick) FighterIDs() []string {
+	var refs struct {
+		FighterID  string `json:"fighterId"`
+		AttackerID string `json:"attackerId"`
+		TargetID   string `json:"targetId"`
+		KillerID   string `json:"killerId"`
+		CasterID   string `json:"casterId"`
+		HealerID   string `json:"healerId"`
+		SourceID   string `json:"sourceId"`
+	}
+	if err := json.Unmarshal(t.Payload, &refs); err != nil {
+		return nil
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range []string{refs.FighterID, refs.AttackerID, refs.TargetID, refs.KillerID, refs.CasterID, refs.HealerID, refs.SourceID} {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 type FighterScore struct {
 	FighterID string
+	IsBot     bool
 	Kills     int
 	Deaths    int
 	Assists   int
+	// Totals over the match; healing counts towards the healer
+	DamageDealt int
+	DamageTaken int
+	Healed      int
+	// UltimateCharge is the charge the fighter carries into its next match.
+	UltimateCharge int
 }
 
 type EventSpawn struct {
 	FighterID string  `json:"fighterId"`
+	Name      string  `json:"name,omitempty"`
+	IsBot     bool    `json:"isBot,omitempty"`
+	TeamID    *string `json:"teamId,omitempty"`
+	Weapon    string  `json:"weapon,omitempty"`
 	X         float64 `json:"x"`
 	Y         float64 `json:"y"`
 	HP        int     `json:"hp"`
@@ -79,6 +179,9 @@ type EventAttack struct {
 	Damage     int     `json:"damage"`
 	IsCritical bool    `json:"isCritical"`
 	IsParried  bool    `json:"isParried"`
+	IsBlocked  bool    `json:"isBlocked,omitempty"`
+	IsDodged   bool    `json:"isDodged,omitempty"`
+	Absorbed   int     `json:"absorbed,omitempty"`
 	Combo      int     `json:"combo"`
 	Momentum   float64 `json:"momentum"`
 }
@@ -93,3 +196,29 @@ type EventDied struct {
 	FighterID string `json:"fighterId"`
 	KillerID  string `json:"killerId"`
 }
+
+type EventSkillCast struct {
+	CasterID string `json:"casterId"`
+	TargetID string `json:"targetId"`
+	SkillID  string `json:"skillId"`
+	Mana     int    `json:"mana"`
+}
+
+type EventEffect struct {
+	FighterID string `json:"fighterId"`
+	SourceID  string `json:"sourceId"`
+	EffectID  string `json:"effectId"`
+	Rounds    int    `json:"rounds"`
+}
+
+type EventDamage struct {
+	FighterID string `json:"fighterId"`
+	SourceID  string `json:"sourceId"`
+	Damage    int    `json:"damage"`
+}
+
+type EventExecute struct {
+	AttackerID string `json:"attackerId"`
+	TargetID   string `json:"targetId"`
+	SkillID    string `json:"skillId"`
+}
@@ -1,7 +1,6 @@
 package combat
 
 import (
-	"encoding/json"
 	"math"
 	"math/rand"
 )
@@ -10,7 +9,9 @@ type Skill interface {
 	ID() string
 	Name() string
 	Range() float64
-	Execute(attacker *Entity, target *Entity) ([]Tick, error)
+	// Execute resolves the skill against target. All randomness must be drawn
+	// from rng so that a battle can be replayed from its seed.
+	Execute(attacker *Entity, target *Entity, rng *rand.Rand) ([]Tick, error)
 }
 
 type BaseDamageSkill struct {
@@ -25,8 +26,8 @@ func (s *BaseDamageSkill) ID() string      { return s.id }
 func (s *BaseDamageSkill) Name() string    { return s.name }
 func (s *BaseDamageSkill) Range() float64 { return s.rng }
 
-func (s *BaseDamageSkill) Execute(attacker *Entity, target *Entity) ([]Tick, error) {
-	damage := rand.Intn(s.maxDamage-s.minDamage+1) + s.minDamage
+func (s *BaseDamageSkill) Execute(attacker *Entity, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	damage := s.roll(attacker, rng)
 
 	// Apply Combo & Momentum Multipliers
 	// Each combo point adds 5% damage
@@ -44,47 +45,13 @@ func (s *BaseDamageSkill) Execute(attacker *Entity, target *Entity) ([]Tick, err
 		attacker.Momentum = 5.0
 	}
 
-	// Apply armor reduction (simple formula for now)
-	damage = damage - (target.Stats.Armor / 10)
-	if damage < 1 {
-		damage = 1
-	}
-
-	target.CurrentHP -= damage
-	// Target loses momentum when hit
-	target.Momentum -= 0.5
-	if target.Momentum < 0 {
-		target.Momentum = 0
-	}
-	// Target combo breaks when hit
-	target.Combo = 0
-
-	if target.CurrentHP < 0 {
-		target.CurrentHP = 0
-	}
-
-	attackEvent := EventAttack{
-		AttackerID: attacker.ID,
-		TargetID:   target.ID,
-		SkillID:    s.id,
-		Damage:     damage,
-		Combo:      attacker.Combo,
-		Momentum:   attacker.Momentum,
-	}
-	
-	payload, _ := json.Marshal(attackEvent)
-	ticks := []Tick{{Type: "attack", Payload: payload}}
-
-	if target.CurrentHP <= 0 {
-		diedEvent := EventDied{
-			FighterID: target.ID,
-			KillerID:  attacker.ID,
-		}
-		diedPayload, _ := json.Marshal(diedEvent)
-		ticks = append(ticks, Tick{Type: "died", Payload: diedPayload})
-	}
+	return dealDamage(attacker, target, s.id, damage, rng), nil
+}
 
-	return ticks, nil
+// roll returns the raw damage of one hit, before any multipliers. A quarter
+// of the equipped weapon's damage is added to every hit.
+func (s *BaseDamageSkill) roll(attacker *Entity, rng *rand.Rand) int {
+	return rng.Intn(s.maxDamage-s.minDamage+1) + s.minDamage + attacker.Stats.WeaponDamage/4
 }
 
 func NewBowShot() Skill {
@@ -127,12 +94,62 @@ func NewGreatswordBlow() Skill {
 	}
 }
 
+func NewSwordSlash() Skill {
+	return &BaseDamageSkill{
+		id:        "SwordSlash",
+		name:      "Slash",
+		minDamage: 6,
+		maxDamage: 9,
+		rng:       3.0,
+	}
+}
+
+func NewStaffBolt() Skill {
+	return &BaseDamageSkill{
+		id:        "StaffBolt",
+		name:      "Bolt",
+		minDamage: 4,
+		maxDamage: 7,
+		rng:       12.0,
+	}
+}
+
+func NewAxeCleave() Skill {
+	return &BaseDamageSkill{
+		id:        "AxeCleave",
+		name:      "Cleave",
+		minDamage: 7,
+		maxDamage: 11,
+		rng:       2.5,
+	}
+}
+
+func NewUnarmedStrike() Skill {
+	return &BaseDamageSkill{
+		id:        "UnarmedStrike",
+		name:      "Strike",
+		minDamage: 2,
+		maxDamage: 4,
+		rng:       1.5,
+	}
+}
+
+// GetSkillsByWeapon returns the skills available to a weapon type, as named
+// by weapons.WeaponType.String() or Unarmed.
 func GetSkillsByWeapon(weaponID string) []Skill {
 	switch weaponID {
+	case "Sword":
+		return []Skill{NewSwordSlash()}
 	case "Bow":
 		return []Skill{NewBowShot()}
+	case "Staff":
+		return []Skill{NewStaffBolt()}
 	case "Dagger":
 		return []Skill{NewDaggerSlice()}
+	case "Axe":
+		return []Skill{NewAxeCleave()}
+	case Unarmed:
+		return []Skill{NewUnarmedStrike()}
 	case "Glaive":
 		return []Skill{NewGlaiveSwing()}
 	case "Greatsword":
@@ -141,3 +158,36 @@ func GetSkillsByWeapon(weaponID string) []Skill {
 		return []Skill{NewGreatswordBlow()}
 	}
 }
+
+// weaponSkill returns the basic attack of a weapon type, which active skills
+// use for their reach and base damage.
+func weaponSkill(weaponType string) *BaseDamageSkill {
+	if skill, ok := GetSkillsByWeapon(weaponType)[0].(*BaseDamageSkill); ok {
+		return skill
+	}
+	return NewUnarmedStrike().(*BaseDamageSkill)
+}
+
+// MendCooldown is the number of rounds between two casts of Mend.
+const MendCooldown = 3
+
+// Mend is the basic heal of every entity with HealingPower. It targets a
+// wounded ally or the caster itself.
+type Mend struct{}
+
+func NewMend() *Mend { return &Mend{} }
+
+func (s *Mend) ID() string     { return "Mend" }
+func (s *Mend) Name() string   { return "Mend" }
+func (s *Mend) Range() float64 { return 6.0 }
+
+// ShouldCast reports whether target is hurt badly enough to be worth a heal.
+func (s *Mend) ShouldCast(target *Entity) bool {
+	return target.CurrentHP > 0 && target.CurrentHP*2 < target.MaxHP
+}
+
+// Execute restores 5 HP plus two per point of the caster's HealingPower.
+func (s *Mend) Execute(attacker *Entity, target *Entity, rng *rand.Rand) ([]Tick, error) {
+	amount := heal(target, 5+2*attacker.Stats.HealingPower)
+	return []Tick{newTick("heal", EventHeal{HealerID: attacker.ID, TargetID: target.ID, Amount: amount})}, nil
+}
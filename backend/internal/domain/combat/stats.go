@@ -0,0 +1,69 @@
+package combat
+
+import (
+	"empoweredpixels/internal/domain/inventory"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/weapons"
+)
+
+// Unarmed is the weapon type of fighters without an equipped weapon.
+const Unarmed = "Unarmed"
+
+// Gear is what a fighter carries into a battle on top of its attributes. It
+// is stored with the battle input, so a replay fights with the same gear even
+// after the fighter re-equips.
+type Gear struct {
+	Weapon      *weapons.Weapon       `json:"weapon,omitempty"`
+	Enhancement int                   `json:"enhancement,omitempty"`
+	Equipment   []inventory.Equipment `json:"equipment,omitempty"`
+}
+
+// CompileStats merges a fighter's base attributes with its gear into the
+// stats used by the simulator, adding the stats of every equipped item, and returns the weapon type its skills are
+// chosen from. Fighters without gear fall back to the weapon definition
+// referenced by WeaponID, which is how generated bots are armed.
+func CompileStats(f roster.Fighter, gear Gear) (Stats, string) {
+	stats := Stats{
+		Power:          f.Power,
+		ConditionPower: f.ConditionPower,
+		Precision:      f.Precision,
+		Ferocity:       f.Ferocity,
+		Accuracy:       f.Accuracy,
+		Agility:        f.Agility,
+		Armor:          f.Armor,
+		Vitality:       f.Vitality,
+		ParryChance:    f.ParryChance,
+		HealingPower:   f.HealingPower,
+		Speed:          f.Speed,
+		Vision:         f.Vision,
+		AttackSpeed:    1.0,
+	}
+
+	for _, item := range gear.Equipment {
+		bonus := inventory.CalculateEquipmentStats(item)
+		stats.Power += bonus.Power
+		stats.Precision += bonus.Precision
+		stats.Accuracy += bonus.Accuracy
+		stats.Agility += bonus.Agility
+		stats.Armor += bonus.Armor
+		stats.Vitality += bonus.Vitality
+	}
+
+	weapon := gear.Weapon
+	if weapon == nil && f.WeaponID != nil {
+		if def, ok := weapons.GetWeaponByID(*f.WeaponID); ok {
+			weapon = def
+		}
+	}
+	if weapon == nil {
+		return stats, Unarmed
+	}
+
+	weaponStats := weapons.CalculateStats(weapon, gear.Enhancement)
+	stats.WeaponDamage = weaponStats.Damage
+	stats.CritChance = weaponStats.CritChance
+	if weaponStats.AttackSpeed > 0 {
+		stats.AttackSpeed = weaponStats.AttackSpeed
+	}
+	return stats, weapon.Type.String()
+}
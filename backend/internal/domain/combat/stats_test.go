@@ -0,0 +1,81 @@
+package combat
+
+import (
+	"testing"
+
+	"empoweredpixels/internal/domain/inventory"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/weapons"
+)
+
+func TestCompileStats_Unarmed(t *testing.T) {
+	f := roster.Fighter{Power: 10, Armor: 4, Vitality: 6}
+
+	stats, weaponType := CompileStats(f, Gear{})
+	if weaponType != Unarmed {
+		t.Fatalf("expected %s, got %s", Unarmed, weaponType)
+	}
+	if stats.Power != 10 || stats.Armor != 4 || stats.Vitality != 6 {
+		t.Fatalf("base stats changed: %+v", stats)
+	}
+	if stats.WeaponDamage != 0 || stats.CritChance != 0 || stats.AttackSpeed != 1.0 {
+		t.Fatalf("unexpected weapon stats: %+v", stats)
+	}
+}
+
+func TestCompileStats_EquippedWeapon(t *testing.T) {
+	weapon := &weapons.Weapon{
+		Type:        weapons.Dagger,
+		Rarity:      weapons.Rare,
+		BaseDamage:  20,
+		AttackSpeed: 1.5,
+		CritChance:  10,
+	}
+
+	stats, weaponType := CompileStats(roster.Fighter{}, Gear{Weapon: weapon, Enhancement: 5})
+	if weaponType != "Dagger" {
+		t.Fatalf("expected Dagger, got %s", weaponType)
+	}
+	// 20 * 1.6 (rare) * 1.5 (+5)
+	if stats.WeaponDamage != 48 {
+		t.Fatalf("expected weapon damage 48, got %d", stats.WeaponDamage)
+	}
+	if stats.CritChance != 20 {
+		t.Fatalf("expected crit chance 20, got %d", stats.CritChance)
+	}
+	if stats.AttackSpeed != 1.5 {
+		t.Fatalf("expected attack speed 1.5, got %f", stats.AttackSpeed)
+	}
+}
+
+func TestCompileStats_WeaponIDFallback(t *testing.T) {
+	def := weapons.WeaponDatabase[0]
+	f := roster.Fighter{WeaponID: &def.ID}
+
+	stats, weaponType := CompileStats(f, Gear{})
+	if weaponType != def.Type.String() {
+		t.Fatalf("expected %s, got %s", def.Type, weaponType)
+	}
+	if stats.WeaponDamage != weapons.CalculateStats(&def, 0).Damage {
+		t.Fatalf("unexpected weapon damage %d", stats.WeaponDamage)
+	}
+}
+
+func TestCompileStats_Equipment(t *testing.T) {
+	f := roster.Fighter{Power: 10, Armor: 2, Vitality: 6}
+	gear := Gear{Equipment: []inventory.Equipment{
+		{ItemID: inventory.BasicVestID, Rarity: inventory.ItemRarityCommon},
+		{ItemID: inventory.BasicSwordID, Rarity: inventory.ItemRarityRare, Enhancement: 5},
+		{ItemID: "unknown"},
+	}}
+
+	stats, _ := CompileStats(f, gear)
+	// The vest grants its stats as defined, the rare sword +5 scaled by
+	// 1.6 * 1.5 and the unknown item nothing
+	if stats.Armor != 6 || stats.Vitality != 9 {
+		t.Fatalf("expected the vest on armor and vitality, got %+v", stats)
+	}
+	if stats.Power != 19 || stats.Accuracy != 4 {
+		t.Fatalf("expected the scaled sword on power and accuracy, got %+v", stats)
+	}
+}
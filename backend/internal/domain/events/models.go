@@ -32,3 +32,23 @@ type EventStatus struct {
 	Multiplier     float64        `json:"multiplier"`
 	Type           string         `json:"type,omitempty"`
 }
+
+// WindowAt returns the weekly occurrence of the event that starts last at or
+// before t, in t's location, and whether t falls inside it. The end hour is
+// inclusive, so an event from Friday 0 to Saturday 23 lasts 48 hours.
+func (e WeekendEvent) WindowAt(t time.Time) (start time.Time, end time.Time, ok bool) {
+	days := (e.EndDay - e.StartDay + 7) % 7
+	length := time.Duration(days)*24*time.Hour + time.Duration(e.EndHour+1-e.StartHour)*time.Hour
+	if length <= 0 {
+		length += 7 * 24 * time.Hour
+	}
+
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	sinceStartDay := (int(t.Weekday()) - e.StartDay + 7) % 7
+	start = midnight.AddDate(0, 0, -sinceStartDay).Add(time.Duration(e.StartHour) * time.Hour)
+	if start.After(t) {
+		start = start.AddDate(0, 0, -7)
+	}
+	end = start.Add(length)
+	return start, end, t.Before(end)
+}
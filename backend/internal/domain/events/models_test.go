@@ -0,0 +1,38 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowAt(t *testing.T) {
+	// Friday 00:00 through Saturday 23:59
+	weekend := WeekendEvent{StartDay: 5, EndDay: 6, StartHour: 0, EndHour: 23}
+	// Sunday only
+	sunday := WeekendEvent{StartDay: 0, EndDay: 0, StartHour: 0, EndHour: 23}
+
+	friday := time.Date(2026, 2, 6, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name  string
+		event WeekendEvent
+		at    time.Time
+		start time.Time
+		ok    bool
+	}{
+		{"opens on friday", weekend, friday, friday, true},
+		{"still open saturday night", weekend, friday.Add(47 * time.Hour), friday, true},
+		{"closed on sunday", weekend, friday.Add(48 * time.Hour), friday, false},
+		{"closed on thursday", weekend, friday.Add(-time.Hour), friday.AddDate(0, 0, -7), false},
+		{"sunday event on sunday", sunday, friday.Add(54 * time.Hour), friday.Add(48 * time.Hour), true},
+	}
+
+	for _, tc := range cases {
+		start, end, ok := tc.event.WindowAt(tc.at)
+		if ok != tc.ok || !start.Equal(tc.start) {
+			t.Errorf("%s: got start %v ok %v, want start %v ok %v", tc.name, start, ok, tc.start, tc.ok)
+		}
+		if !end.After(start) {
+			t.Errorf("%s: window ends at %v before it starts", tc.name, end)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package inventory
+
+// Equipment items handed out by the starter pack
+const (
+	BasicSwordID = "9C46EB15-4D04-4F90-B8A1-D4BD0A5A82B1"
+	BasicVestID  = "E98E3A82-C2B1-4A2D-A8C6-29BAE0D6A5A6"
+)
+
+// EquipmentStats are the attributes an equipped item adds to its fighter.
+type EquipmentStats struct {
+	Power     int
+	Precision int
+	Accuracy  int
+	Agility   int
+	Armor     int
+	Vitality  int
+}
+
+// EquipmentDefinition is the static definition of an equipment item.
+type EquipmentDefinition struct {
+	ItemID string
+	Name   string
+	Stats  EquipmentStats
+}
+
+// EquipmentDatabase contains all static equipment definitions
+var EquipmentDatabase = []EquipmentDefinition{
+	{ItemID: BasicSwordID, Name: "Basic Sword", Stats: EquipmentStats{Power: 4, Accuracy: 2}},
+	{ItemID: BasicVestID, Name: "Basic Vest", Stats: EquipmentStats{Armor: 4, Vitality: 3}},
+	{ItemID: "sword_01", Name: "Short Sword", Stats: EquipmentStats{Power: 3, Precision: 2}},
+	{ItemID: "iron_sword", Name: "Iron Sword", Stats: EquipmentStats{Power: 5, Accuracy: 1}},
+	{ItemID: "armor_01", Name: "Padded Armor", Stats: EquipmentStats{Armor: 3, Vitality: 2}},
+	{ItemID: "leather_armor", Name: "Leather Armor", Stats: EquipmentStats{Armor: 2, Agility: 3}},
+}
+
+// GetEquipmentByID returns the definition of an equipment item.
+func GetEquipmentByID(itemID string) (*EquipmentDefinition, bool) {
+	for i := range EquipmentDatabase {
+		if EquipmentDatabase[i].ItemID == itemID {
+			return &EquipmentDatabase[i], true
+		}
+	}
+	return nil, false
+}
+
+// RarityMultiplier returns the stat scaling factor of an item rarity. It
+// follows the weapon multipliers of the same tiers.
+func RarityMultiplier(rarity int) float64 {
+	switch rarity {
+	case ItemRarityRare:
+		return 1.6
+	case ItemRarityFabled:
+		return 2.0
+	case ItemRarityMythic:
+		return 2.5
+	case ItemRarityLegendary:
+		return 3.5
+	default:
+		return 1.0
+	}
+}
+
+// CalculateEquipmentStats returns the stats an equipped item grants: its
+// definition scaled by rarity and by 10% per enhancement level, the way
+// weapon damage scales. Items without a definition grant nothing.
+func CalculateEquipmentStats(item Equipment) EquipmentStats {
+	def, ok := GetEquipmentByID(item.ItemID)
+	if !ok {
+		return EquipmentStats{}
+	}
+	multiplier := RarityMultiplier(item.Rarity) * (1.0 + float64(item.Enhancement)*0.1)
+	scale := func(v int) int {
+		return int(float64(v) * multiplier)
+	}
+	return EquipmentStats{
+		Power:     scale(def.Stats.Power),
+		Precision: scale(def.Stats.Precision),
+		Accuracy:  scale(def.Stats.Accuracy),
+		Agility:   scale(def.Stats.Agility),
+		Armor:     scale(def.Stats.Armor),
+		Vitality:  scale(def.Stats.Vitality),
+	}
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import "time"
+
+// What started a job run
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Run is one run of a background job.
+type Run struct {
+	ID      int64
+	Job     string
+	Trigger string
+	// Instance is the process that ran the job, host and pid.
+	Instance string
+	Started  time.Time
+	Duration time.Duration
+	// Error is the error the run ended with, empty when it succeeded.
+	Error string
+}
+
+// Failed reports whether the run ended with an error.
+func (r Run) Failed() bool {
+	return r.Error != ""
+}
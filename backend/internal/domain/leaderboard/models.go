@@ -32,6 +32,7 @@ const (
 	CategoryCombat      = "combat"
 	CategoryAchievements = "achievements"
 	CategoryStreak      = "streak"
+	CategoryLeague      = "league"
 )
 
 // Trend types
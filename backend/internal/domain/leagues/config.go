@@ -0,0 +1,180 @@
+package leagues
+
+import (
+	"errors"
+	"time"
+
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/tournaments"
+)
+
+var ErrInvalidConfig = errors.New("invalid league configuration")
+
+// DefaultMaxFightersPerUser is how many fighters a user may enter into a
+// league that doesn't say otherwise.
+const DefaultMaxFightersPerUser = 1
+
+// Config is how a league runs and who may enter it. Zero caps are no caps.
+type Config struct {
+	// Schedule is the cron expression of the league's runs, DefaultSchedule
+	// when empty.
+	Schedule string
+	// Format is the format of the tournament every run plays.
+	Format      tournaments.Format
+	MinFighters int
+	MaxFighters int
+	MinLevel    int
+	MaxLevel    int
+	// MaxPowerlevel caps the power of subscribed fighters, like the match
+	// option of the same name.
+	MaxPowerlevel int
+	// EntryFee is the gold a subscription costs. It is not refunded.
+	EntryFee int
+	// MaxFightersPerUser is how many fighters one user may enter,
+	// DefaultMaxFightersPerUser when 0.
+	MaxFightersPerUser int
+	// TeamSize is how many fighters of one user fight side by side in the
+	// league's matches. At 0 or 1 every fighter fights on its own.
+	TeamSize int
+	// PlacementPoints are the standings points of the first placements of a
+	// match, DefaultPlacementPoints when empty. KillPoints are added for
+	// every kill.
+	PlacementPoints []int
+	KillPoints      int
+	// BotCount and BotPowerlevel fill the match of a league that runs without
+	// tournaments.
+	BotCount      *int
+	BotPowerlevel *int
+	// Deactivations are the windows the league neither runs nor takes
+	// subscriptions in.
+	Deactivations []Window
+}
+
+// Window is a period of time, From included and To excluded.
+type Window struct {
+	From time.Time
+	To   time.Time
+}
+
+// Contains reports whether t falls in the window.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.From) && t.Before(w.To)
+}
+
+// DefaultConfig is the configuration of a league created without one.
+func DefaultConfig() Config {
+	return Config{
+		Schedule:           DefaultSchedule,
+		Format:             tournaments.FormatSingleElimination,
+		MaxFightersPerUser: DefaultMaxFightersPerUser,
+		PlacementPoints:    DefaultPlacementPoints,
+		KillPoints:         DefaultKillPoints,
+	}
+}
+
+// Normalized returns the config with defaults for its empty fields.
+func (c Config) Normalized() Config {
+	if c.Schedule == "" {
+		c.Schedule = DefaultSchedule
+	}
+	if c.Format == "" {
+		c.Format = tournaments.FormatSingleElimination
+	}
+	if c.MaxFightersPerUser <= 0 {
+		c.MaxFightersPerUser = DefaultMaxFightersPerUser
+	}
+	if len(c.PlacementPoints) == 0 {
+		c.PlacementPoints = DefaultPlacementPoints
+	}
+	return c
+}
+
+// Validate reports the first problem of a normalized config.
+func (c Config) Validate() error {
+	if _, err := ParseSchedule(c.Schedule); err != nil {
+		return err
+	}
+	switch {
+	case !c.Format.Valid():
+		return ErrInvalidConfig
+	case c.MinFighters < 0, c.MaxFighters < 0, c.MinLevel < 0, c.MaxLevel < 0,
+		c.MaxPowerlevel < 0, c.EntryFee < 0, c.MaxFightersPerUser < 0, c.TeamSize < 0, c.KillPoints < 0:
+		return ErrInvalidConfig
+	case c.TeamSize > c.MaxFightersPerUser:
+		// A team is made of the fighters of one user
+		return ErrInvalidConfig
+	case c.MaxFighters > 0 && c.MaxFighters < c.MinFighters:
+		return ErrInvalidConfig
+	case c.MaxLevel > 0 && c.MaxLevel < c.MinLevel:
+		return ErrInvalidConfig
+	}
+	for _, points := range c.PlacementPoints {
+		if points < 0 {
+			return ErrInvalidConfig
+		}
+	}
+	for _, w := range c.Deactivations {
+		if !w.From.Before(w.To) {
+			return ErrInvalidConfig
+		}
+	}
+	return nil
+}
+
+// Teams groups fighters into the teams of a league match: the fighters of
+// every user make teams of up to TeamSize in the order given. Teams come in
+// the order of their first fighter.
+func (c Config) Teams(fighters []roster.Fighter) [][]string {
+	size := c.TeamSize
+	if size < 1 {
+		size = 1
+	}
+	var teams [][]string
+	filling := make(map[int64]int)
+	for _, f := range fighters {
+		i, ok := filling[f.UserID]
+		if !ok || len(teams[i]) >= size {
+			teams = append(teams, nil)
+			i = len(teams) - 1
+			filling[f.UserID] = i
+		}
+		teams[i] = append(teams[i], f.ID)
+	}
+	return teams
+}
+
+// Deactivated reports whether t falls in one of the deactivation windows.
+func (c Config) Deactivated(t time.Time) bool {
+	for _, w := range c.Deactivations {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the league runs and takes subscriptions at t.
+func (l League) Active(t time.Time) bool {
+	return !l.IsDeactivated && !l.Config.Deactivated(t)
+}
+
+// Eligibility reasons a fighter can't enter a league
+var (
+	ErrLevelTooLow  = errors.New("fighter level is below the league minimum")
+	ErrLevelTooHigh = errors.New("fighter level is above the league maximum")
+	ErrPowerTooHigh = errors.New("fighter power is above the league cap")
+)
+
+// Eligible reports why a fighter of the given level and power can't enter
+// the league, or nil when they can.
+func (c Config) Eligible(level int, power int) error {
+	switch {
+	case c.MinLevel > 0 && level < c.MinLevel:
+		return ErrLevelTooLow
+	case c.MaxLevel > 0 && level > c.MaxLevel:
+		return ErrLevelTooHigh
+	case c.MaxPowerlevel > 0 && power > c.MaxPowerlevel:
+		return ErrPowerTooHigh
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package leagues
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/roster"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("expected the default config to be valid, got %v", err)
+	}
+
+	now := time.Now()
+	invalid := []Config{
+		{Schedule: "never"},
+		{Format: "knockout"},
+		{MinFighters: 8, MaxFighters: 4},
+		{MinLevel: 10, MaxLevel: 5},
+		{EntryFee: -1},
+		{KillPoints: -1},
+		{MaxFightersPerUser: 1, TeamSize: 2},
+		{PlacementPoints: []int{5, -1}},
+		{Deactivations: []Window{{From: now, To: now}}},
+	}
+	for _, c := range invalid {
+		if c.Normalized().Validate() == nil {
+			t.Fatalf("expected %+v to be invalid", c)
+		}
+	}
+}
+
+func TestConfig_Teams(t *testing.T) {
+	fighters := []roster.Fighter{
+		{ID: "a1", UserID: 1}, {ID: "b1", UserID: 2}, {ID: "a2", UserID: 1},
+		{ID: "a3", UserID: 1}, {ID: "b2", UserID: 2},
+	}
+
+	got, _ := json.Marshal(Config{TeamSize: 2}.Teams(fighters))
+	if string(got) != `[["a1","a2"],["b1","b2"],["a3"]]` {
+		t.Fatalf("unexpected teams %s", got)
+	}
+	got, _ = json.Marshal(Config{}.Teams(fighters[:2]))
+	if string(got) != `[["a1"],["b1"]]` {
+		t.Fatalf("expected every fighter on its own, got %s", got)
+	}
+}
+
+func TestConfig_Eligible(t *testing.T) {
+	c := Config{MinLevel: 5, MaxLevel: 10, MaxPowerlevel: 100}
+	if err := c.Eligible(7, 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Eligible(4, 50); err != ErrLevelTooLow {
+		t.Fatalf("expected ErrLevelTooLow, got %v", err)
+	}
+	if err := c.Eligible(11, 50); err != ErrLevelTooHigh {
+		t.Fatalf("expected ErrLevelTooHigh, got %v", err)
+	}
+	if err := c.Eligible(7, 101); err != ErrPowerTooHigh {
+		t.Fatalf("expected ErrPowerTooHigh, got %v", err)
+	}
+}
+
+func TestLeague_Active(t *testing.T) {
+	from := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+	league := League{Config: Config{Deactivations: []Window{{From: from, To: from.Add(48 * time.Hour)}}}}
+
+	if !league.Active(from.Add(-time.Minute)) || league.Active(from) || !league.Active(from.Add(48*time.Hour)) {
+		t.Fatal("expected the league inactive within its window only")
+	}
+	league.IsDeactivated = true
+	if league.Active(from.Add(-time.Minute)) {
+		t.Fatal("expected a deactivated league inactive")
+	}
+}
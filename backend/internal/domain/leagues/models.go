@@ -5,8 +5,11 @@ import "time"
 type League struct {
 	ID            int
 	Name          string
-	Options       []byte
+	Config        Config
 	IsDeactivated bool
+	// LastRun is when the league's schedule last came due, nil before the
+	// league was first scheduled.
+	LastRun *time.Time
 }
 
 type LeagueSubscription struct {
@@ -0,0 +1,171 @@
+package leagues
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid league schedule")
+
+// DefaultSchedule runs a league every four hours, on the hour.
+const DefaultSchedule = "0 */4 * * *"
+
+// scheduleDescriptors are the shorthands a schedule may use instead of the
+// five fields.
+var scheduleDescriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// Schedule is a parsed cron expression: minute, hour, day of month, month and
+// day of week, evaluated in UTC. Fields take *, values, ranges (1-5), steps
+// (*/15, 1-30/2) and comma separated lists; Sunday is 0 or 7.
+type Schedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	// anyDay and anyWeekday record unrestricted day fields: when both are
+	// restricted a time matches either of them.
+	anyDay     bool
+	anyWeekday bool
+}
+
+type scheduleField struct {
+	min, max int
+}
+
+var scheduleFields = [5]scheduleField{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week
+}
+
+// ParseSchedule parses a cron expression.
+func ParseSchedule(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expanded, ok := scheduleDescriptors[expr]; ok {
+		expr = expanded
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != len(scheduleFields) {
+		return Schedule{}, ErrInvalidSchedule
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		set, err := parseScheduleField(field, scheduleFields[i])
+		if err != nil {
+			return Schedule{}, err
+		}
+		bits[i] = set
+	}
+	// Sunday is both 0 and 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return Schedule{
+		minutes:    bits[0],
+		hours:      bits[1],
+		days:       bits[2],
+		months:     bits[3],
+		weekdays:   bits[4],
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}, nil
+}
+
+func parseScheduleField(field string, bounds scheduleField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, ErrInvalidSchedule
+			}
+			step = n
+			part = part[:i]
+		}
+
+		low, high := bounds.min, bounds.max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			ends := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(ends[0]); err != nil {
+				return 0, ErrInvalidSchedule
+			}
+			if high, err = strconv.Atoi(ends[1]); err != nil {
+				return 0, ErrInvalidSchedule
+			}
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, ErrInvalidSchedule
+			}
+			low = value
+			if step == 1 {
+				high = value
+			}
+		}
+		if low < bounds.min || high > bounds.max || low > high {
+			return 0, ErrInvalidSchedule
+		}
+
+		for v := low; v <= high; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// maxScheduleSearch bounds the search for the next run of a schedule that
+// never matches, such as the 31st of February.
+const maxScheduleSearch = 5 * 366 * 24 * time.Hour
+
+// Next returns the first time the schedule matches after after, or the zero
+// time if it never does.
+func (s Schedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxScheduleSearch)
+
+	for t.Before(limit) {
+		switch {
+		case s.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hours&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s Schedule) matchesDay(t time.Time) bool {
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
@@ -0,0 +1,51 @@
+package leagues
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseSchedule(expr); err != ErrInvalidSchedule {
+			t.Fatalf("expected %q to be invalid, got %v", expr, err)
+		}
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	from := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC) // a Saturday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{DefaultSchedule, time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"*/20 9 * * *", time.Date(2026, 3, 14, 9, 40, 0, 0, time.UTC)},
+		{"0 18 * * 1-5", time.Date(2026, 3, 16, 18, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// A day of month or a day of week: the Monday comes first
+		{"0 0 20 * 1", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.expr)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", c.expr, err)
+		}
+		if got := schedule.Next(from); !got.Equal(c.want) {
+			t.Fatalf("%q: expected %s, got %s", c.expr, c.want, got)
+		}
+	}
+}
+
+func TestSchedule_NextNever(t *testing.T) {
+	schedule, err := ParseSchedule("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Fatalf("expected no run on February 31st, got %s", next)
+	}
+}
@@ -0,0 +1,164 @@
+package leagues
+
+import (
+	"sort"
+	"time"
+
+	"empoweredpixels/internal/domain/combat"
+)
+
+// DefaultPlacementPoints are the points of the first placements of a league
+// match; later placements score none.
+var DefaultPlacementPoints = []int{10, 6, 4, 2, 1}
+
+// DefaultKillPoints are the points of a kill in a league created with the
+// default config.
+const DefaultKillPoints = 1
+
+// Result is what one fighter scored in a league match.
+type Result struct {
+	FighterID   string
+	Placement   int
+	Won         bool
+	Kills       int
+	Deaths      int
+	DamageDealt int
+	Points      int
+}
+
+// Results scores a finished league match. Winners share the first place and
+// the other fighters follow by kills, then damage dealt. Bots score nothing.
+func Results(config Config, winnerIDs []string, scores []combat.FighterScore) []Result {
+	won := make(map[string]bool, len(winnerIDs))
+	for _, id := range winnerIDs {
+		won[id] = true
+	}
+
+	var winners, others []Result
+	for _, score := range scores {
+		if score.IsBot {
+			continue
+		}
+		r := Result{
+			FighterID:   score.FighterID,
+			Won:         won[score.FighterID],
+			Kills:       score.Kills,
+			Deaths:      score.Deaths,
+			DamageDealt: score.DamageDealt,
+		}
+		if r.Won {
+			winners = append(winners, r)
+		} else {
+			others = append(others, r)
+		}
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		a, b := others[i], others[j]
+		if a.Kills != b.Kills {
+			return a.Kills > b.Kills
+		}
+		if a.DamageDealt != b.DamageDealt {
+			return a.DamageDealt > b.DamageDealt
+		}
+		return a.FighterID < b.FighterID
+	})
+
+	for i := range winners {
+		winners[i].Placement = 1
+	}
+	for i := range others {
+		others[i].Placement = len(winners) + i + 1
+	}
+
+	results := append(winners, others...)
+	for i := range results {
+		r := &results[i]
+		if r.Placement <= len(config.PlacementPoints) {
+			r.Points = config.PlacementPoints[r.Placement-1]
+		}
+		r.Points += r.Kills * config.KillPoints
+	}
+	return results
+}
+
+// MatchResult is a result as recorded for a match of a league.
+type MatchResult struct {
+	LeagueID int
+	MatchID  string
+	UserID   int64
+	Result   Result
+}
+
+// Standing is a fighter's line in the table of a league season. Seasons are
+// the ranked seasons; 0 holds what was played while no season ran.
+type Standing struct {
+	LeagueID    int
+	Season      int
+	FighterID   string
+	UserID      int64
+	Points      int
+	Matches     int
+	Wins        int
+	Kills       int
+	Deaths      int
+	DamageDealt int
+	Updated     time.Time
+	// FighterName and Username are read along for display.
+	FighterName string
+	Username    string
+	// Position is the place in the table, set by Rank.
+	Position int
+}
+
+// NewStanding is the line one match result adds to a fighter's standing.
+func NewStanding(leagueID int, season int, userID int64, r Result, at time.Time) Standing {
+	s := Standing{
+		LeagueID:    leagueID,
+		Season:      season,
+		FighterID:   r.FighterID,
+		UserID:      userID,
+		Points:      r.Points,
+		Matches:     1,
+		Kills:       r.Kills,
+		Deaths:      r.Deaths,
+		DamageDealt: r.DamageDealt,
+		Updated:     at,
+	}
+	if r.Won {
+		s.Wins = 1
+	}
+	return s
+}
+
+// Ahead reports whether s ranks ahead of other. Ties on points are broken by
+// wins, then the kill difference, then damage dealt, then fewer matches.
+func (s Standing) Ahead(other Standing) bool {
+	switch {
+	case s.Points != other.Points:
+		return s.Points > other.Points
+	case s.Wins != other.Wins:
+		return s.Wins > other.Wins
+	case s.Kills-s.Deaths != other.Kills-other.Deaths:
+		return s.Kills-s.Deaths > other.Kills-other.Deaths
+	case s.DamageDealt != other.DamageDealt:
+		return s.DamageDealt > other.DamageDealt
+	case s.Matches != other.Matches:
+		return s.Matches < other.Matches
+	}
+	return s.FighterID < other.FighterID
+}
+
+// Rank orders a table and numbers its positions from 1.
+func Rank(table []Standing) {
+	sort.SliceStable(table, func(i, j int) bool { return table[i].Ahead(table[j]) })
+	for i := range table {
+		table[i].Position = i + 1
+	}
+}
+
+// UserPoints are the league points a user's fighters scored in a season,
+// over every league.
+type UserPoints struct {
+	UserID int64
+	Points int
+}
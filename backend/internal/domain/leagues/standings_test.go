@@ -0,0 +1,62 @@
+package leagues
+
+import (
+	"testing"
+
+	"empoweredpixels/internal/domain/combat"
+)
+
+func TestResults(t *testing.T) {
+	config := Config{PlacementPoints: []int{10, 6, 4}, KillPoints: 2}
+	scores := []combat.FighterScore{
+		{FighterID: "a", Kills: 1, DamageDealt: 300},
+		{FighterID: "b", Kills: 3, DamageDealt: 100},
+		{FighterID: "bot", IsBot: true, Kills: 5},
+		{FighterID: "c", Kills: 1, DamageDealt: 500},
+		{FighterID: "d"},
+		{FighterID: "e"},
+	}
+
+	results := Results(config, []string{"a"}, scores)
+	if len(results) != 5 {
+		t.Fatalf("expected the bot left out, got %+v", results)
+	}
+	want := []struct {
+		id        string
+		placement int
+		points    int
+	}{
+		{"a", 1, 12},
+		{"b", 2, 12},
+		{"c", 3, 6},
+		{"d", 4, 0},
+		{"e", 5, 0},
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.FighterID != w.id || r.Placement != w.placement || r.Points != w.points {
+			t.Fatalf("expected %s placed %d with %d points, got %+v", w.id, w.placement, w.points, r)
+		}
+	}
+	if !results[0].Won || results[1].Won {
+		t.Fatalf("expected only the winner to be marked, got %+v", results)
+	}
+}
+
+func TestRank(t *testing.T) {
+	table := []Standing{
+		{FighterID: "fewer-wins", Points: 20, Wins: 1, Matches: 3},
+		{FighterID: "more-matches", Points: 20, Wins: 2, Kills: 4, Deaths: 1, Matches: 4},
+		{FighterID: "top", Points: 30},
+		{FighterID: "fewer-matches", Points: 20, Wins: 2, Kills: 4, Deaths: 1, Matches: 3},
+		{FighterID: "worse-diff", Points: 20, Wins: 2, Kills: 4, Deaths: 3, Matches: 2},
+	}
+
+	Rank(table)
+	order := []string{"top", "fewer-matches", "more-matches", "worse-diff", "fewer-wins"}
+	for i, id := range order {
+		if table[i].FighterID != id || table[i].Position != i+1 {
+			t.Fatalf("expected %s at %d, got %+v", id, i+1, table)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package matches
 
-import "time"
+import (
+	"time"
+
+	"empoweredpixels/internal/domain/combat"
+)
 
 const (
 	MatchStatusLobby    = "lobby"
@@ -18,6 +22,16 @@ type Match struct {
 	CancelledAt   *time.Time
 	Status        string
 	Options       []byte
+	// RevealedAt is when the outcome of a completed match becomes public. A
+	// live match settles with it at the end of its stream and is revealed
+	// early when the stream ends first. Nil means public since completion.
+	RevealedAt *time.Time
+}
+
+// Withheld reports whether the outcome of the match is kept from everyone
+// at now, such as while it is streamed live.
+func (m Match) Withheld(now time.Time) bool {
+	return m.RevealedAt != nil && now.Before(*m.RevealedAt)
 }
 
 type MatchTeam struct {
@@ -34,15 +48,91 @@ type MatchRegistration struct {
 }
 
 type MatchResult struct {
-	ID         string
-	MatchID    string
-	RoundTicks []byte
+	ID          string
+	MatchID     string
+	RoundTicks  []byte
+	Seed        int64
+	BattleInput []byte
+	// WinnerTeamID is set when a team match ended with a surviving team.
+	WinnerTeamID *string
 }
 
 type MatchScoreFighter struct {
 	MatchID      string
 	FighterID    string
+	IsBot        bool
 	TotalKills   int
 	TotalDeaths  int
 	TotalAssists int
+	// Damage and healing over the match
+	TotalDamageDealt int
+	TotalDamageTaken int
+	TotalHealed      int
+}
+
+// CombatLogEntry is a single tick of a battle, see combat.Tick.
+type CombatLogEntry struct {
+	MatchID   string
+	Round     int
+	Tick      int
+	EventType string
+	Payload   []byte
+}
+
+// CombatLogFilter narrows down the combat log of a match. Empty fields match
+// every entry.
+type CombatLogFilter struct {
+	FighterID  string
+	EventTypes []string
+	FromRound  *int
+	ToRound    *int
+	Limit      int
+	Offset     int
+}
+
+// BattleSummary is the outcome of a battle without its ticks.
+type BattleSummary struct {
+	MatchID string `json:"matchId"`
+	// WinnerID is the last fighter standing of a free-for-all
+	WinnerID     *string               `json:"winnerId"`
+	WinnerTeamID *string               `json:"winnerTeamId,omitempty"`
+	WinnerIDs    []string              `json:"winnerIds"`
+	TotalRounds  int                   `json:"totalRounds"`
+	TotalDamage  int                   `json:"totalDamage"`
+	TotalHealed  int                   `json:"totalHealed"`
+	Scores       []combat.FighterScore `json:"scores"`
+}
+
+// Match run queue states
+const (
+	RunStatusPending = "pending"
+	RunStatusRunning = "running"
+	RunStatusDone    = "done"
+	RunStatusFailed  = "failed"
+)
+
+// MatchRun is a queued execution of a match. A worker leases a pending run
+// until LeaseUntil; a running run whose lease expired belongs to a worker
+// that died and is picked up again.
+type MatchRun struct {
+	ID          string
+	MatchID     string
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	LastError   *string
+	AvailableAt time.Time
+	EnqueuedAt  time.Time
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	LeaseUntil  *time.Time
+}
+
+// MatchRunStats describes the current state of the run queue.
+type MatchRunStats struct {
+	Pending int
+	Running int
+	Failed  int
+	// OldestPending is when the longest waiting pending run was enqueued
+	OldestPending *time.Time
 }
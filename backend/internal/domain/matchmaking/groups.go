@@ -0,0 +1,196 @@
+package matchmaking
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Group sizes of matchmade matches. Full groups are formed right away;
+// groups of at least MinGroupSize once their oldest ticket waited FillTimeout.
+const (
+	MinGroupSize = 2
+	MaxGroupSize = 4
+	FillTimeout  = 20 * time.Second
+)
+
+// Search windows. A ticket accepts opponents whose rating is within its
+// rating window and whose power is within its power window, in percent of
+// the stronger fighter's power. Both windows widen the longer the ticket
+// waits.
+const (
+	BaseRatingWindow    = 100.0
+	RatingWindowPerSec  = 10.0
+	MaxRatingWindow     = 800.0
+	BasePowerWindow     = 10.0
+	PowerWindowPerSec   = 0.5
+	MaxPowerWindow      = 60.0
+	MinPowerGap         = 5
+	DefaultWaitEstimate = 30 * time.Second
+)
+
+// Window is how far a ticket searches for opponents.
+type Window struct {
+	Rating float64
+	// Power is in percent.
+	Power float64
+}
+
+// SearchWindow returns the window of a ticket that waited for waited.
+func SearchWindow(waited time.Duration) Window {
+	seconds := waited.Seconds()
+	return Window{
+		Rating: math.Min(BaseRatingWindow+RatingWindowPerSec*seconds, MaxRatingWindow),
+		Power:  math.Min(BasePowerWindow+PowerWindowPerSec*seconds, MaxPowerWindow),
+	}
+}
+
+// Compatible reports whether both tickets accept each other at now.
+func Compatible(a, b Ticket, now time.Time) bool {
+	wa, wb := SearchWindow(a.Waited(now)), SearchWindow(b.Waited(now))
+	if math.Abs(a.Rating-b.Rating) > math.Min(wa.Rating, wb.Rating) {
+		return false
+	}
+	return powerGap(a, b) <= allowedPowerGap(a, b, math.Min(wa.Power, wb.Power))
+}
+
+func powerGap(a, b Ticket) int {
+	gap := a.Power - b.Power
+	if gap < 0 {
+		return -gap
+	}
+	return gap
+}
+
+func allowedPowerGap(a, b Ticket, percent float64) int {
+	stronger := a.Power
+	if b.Power > stronger {
+		stronger = b.Power
+	}
+	allowed := int(float64(stronger) * percent / 100)
+	if allowed < MinPowerGap {
+		return MinPowerGap
+	}
+	return allowed
+}
+
+// FormGroups groups waiting tickets into matches, oldest tickets first. Each
+// group is built around its oldest ticket with the closest rated tickets
+// every member of the group accepts. Tickets left over keep waiting.
+func FormGroups(tickets []Ticket, now time.Time) [][]Ticket {
+	queue := append([]Ticket(nil), tickets...)
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].QueuedAt.Before(queue[j].QueuedAt)
+	})
+
+	grouped := make(map[string]bool, len(queue))
+	var groups [][]Ticket
+	for _, anchor := range queue {
+		if grouped[anchor.FighterID] {
+			continue
+		}
+
+		candidates := make([]Ticket, 0, len(queue))
+		for _, other := range queue {
+			if other.FighterID == anchor.FighterID || grouped[other.FighterID] || other.UserID == anchor.UserID {
+				continue
+			}
+			if Compatible(anchor, other, now) {
+				candidates = append(candidates, other)
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return math.Abs(candidates[i].Rating-anchor.Rating) < math.Abs(candidates[j].Rating-anchor.Rating)
+		})
+
+		group := []Ticket{anchor}
+		for _, candidate := range candidates {
+			if len(group) == MaxGroupSize {
+				break
+			}
+			if acceptsAll(candidate, group, now) {
+				group = append(group, candidate)
+			}
+		}
+
+		if len(group) < MaxGroupSize && (len(group) < MinGroupSize || anchor.Waited(now) < FillTimeout) {
+			continue
+		}
+		for _, ticket := range group {
+			grouped[ticket.FighterID] = true
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func acceptsAll(candidate Ticket, group []Ticket, now time.Time) bool {
+	for _, member := range group {
+		if member.UserID == candidate.UserID || !Compatible(candidate, member, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// EstimateWait estimates how much longer ticket waits for a match given the
+// other waiting tickets: until the search windows of the ticket and its
+// closest opponent widen far enough to accept each other, and the older of
+// both waited FillTimeout. Without opponents in the queue the ticket waits
+// for a new one, which takes recentWait, the average wait of recently
+// matched tickets.
+func EstimateWait(ticket Ticket, waiting []Ticket, now time.Time, recentWait time.Duration) time.Duration {
+	waited := ticket.Waited(now)
+	best := time.Duration(-1)
+	for _, other := range waiting {
+		if other.FighterID == ticket.FighterID || other.UserID == ticket.UserID {
+			continue
+		}
+		// Times are counted from when ticket queued
+		otherWaited := other.Waited(now)
+		total := timeToReach(ticket, other)
+		if t := timeToReach(other, ticket) - otherWaited + waited; t > total {
+			total = t
+		}
+		older := waited
+		if otherWaited > older {
+			older = otherWaited
+		}
+		if t := FillTimeout - older + waited; t > total {
+			total = t
+		}
+		if best < 0 || total < best {
+			best = total
+		}
+	}
+
+	if best < 0 {
+		if recentWait <= 0 {
+			return DefaultWaitEstimate
+		}
+		return recentWait
+	}
+	if remaining := best - waited; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// timeToReach returns how long ticket has to wait in total, counted from
+// queuing, before its windows include other. Tickets out of reach of the
+// widest windows return the longest wait a window takes to widen fully.
+func timeToReach(ticket, other Ticket) time.Duration {
+	ratingGap := math.Abs(ticket.Rating - other.Rating)
+	ratingSeconds := math.Max(0, (ratingGap-BaseRatingWindow)/RatingWindowPerSec)
+
+	powerSeconds := 0.0
+	if gap := powerGap(ticket, other); gap > MinPowerGap {
+		stronger := math.Max(float64(ticket.Power), float64(other.Power))
+		percent := float64(gap) * 100 / stronger
+		powerSeconds = math.Max(0, (percent-BasePowerWindow)/PowerWindowPerSec)
+	}
+
+	maxSeconds := math.Max((MaxRatingWindow-BaseRatingWindow)/RatingWindowPerSec, (MaxPowerWindow-BasePowerWindow)/PowerWindowPerSec)
+	seconds := math.Min(math.Max(ratingSeconds, powerSeconds), maxSeconds)
+	return time.Duration(seconds * float64(time.Second))
+}
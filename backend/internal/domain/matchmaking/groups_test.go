@@ -0,0 +1,102 @@
+package matchmaking
+
+import (
+	"testing"
+	"time"
+)
+
+func ticket(fighterID string, userID int64, rating float64, power int, queuedAt time.Time) Ticket {
+	return Ticket{FighterID: fighterID, UserID: userID, Rating: rating, Power: power, Status: TicketStatusWaiting, QueuedAt: queuedAt}
+}
+
+func TestSearchWindowWidens(t *testing.T) {
+	if w := SearchWindow(0); w.Rating != BaseRatingWindow || w.Power != BasePowerWindow {
+		t.Fatalf("unexpected initial window %+v", w)
+	}
+	if w := SearchWindow(10 * time.Second); w.Rating != 200 || w.Power != 15 {
+		t.Fatalf("unexpected window after 10s %+v", w)
+	}
+	if w := SearchWindow(time.Hour); w.Rating != MaxRatingWindow || w.Power != MaxPowerWindow {
+		t.Fatalf("expected the windows to stop widening, got %+v", w)
+	}
+}
+
+func TestFormGroups(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// A full group of close ratings forms right away
+	full := []Ticket{
+		ticket("a", 1, 1500, 100, now),
+		ticket("b", 2, 1520, 100, now),
+		ticket("c", 3, 1480, 105, now),
+		ticket("d", 4, 1550, 95, now),
+		ticket("far", 5, 2100, 100, now),
+	}
+	groups := FormGroups(full, now)
+	if len(groups) != 1 || len(groups[0]) != MaxGroupSize {
+		t.Fatalf("expected one full group, got %v", groups)
+	}
+	for _, member := range groups[0] {
+		if member.FighterID == "far" {
+			t.Fatal("expected the far rated ticket to keep waiting")
+		}
+	}
+
+	// A pair waits for more fighters until the fill timeout
+	pair := []Ticket{ticket("a", 1, 1500, 100, now), ticket("b", 2, 1550, 100, now)}
+	if groups := FormGroups(pair, now); len(groups) != 0 {
+		t.Fatalf("expected the pair to wait, got %v", groups)
+	}
+	if groups := FormGroups(pair, now.Add(FillTimeout)); len(groups) != 1 || len(groups[0]) != 2 {
+		t.Fatalf("expected the pair to be matched after the fill timeout, got %v", groups)
+	}
+
+	// Fighters of one user never meet, and windows widen over time
+	apart := []Ticket{
+		ticket("a", 1, 1500, 100, now),
+		ticket("a2", 1, 1500, 100, now),
+		ticket("b", 2, 1900, 100, now),
+	}
+	if groups := FormGroups(apart, now.Add(FillTimeout)); len(groups) != 0 {
+		t.Fatalf("expected no group within the initial windows, got %v", groups)
+	}
+	if groups := FormGroups(apart, now.Add(30*time.Second)); len(groups) != 1 || len(groups[0]) != 2 {
+		t.Fatalf("expected the widened windows to match a pair, got %v", groups)
+	}
+}
+
+func TestFormGroups_PowerWindow(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tickets := []Ticket{ticket("a", 1, 1500, 100, now), ticket("b", 2, 1500, 200, now)}
+
+	if groups := FormGroups(tickets, now.Add(FillTimeout)); len(groups) != 0 {
+		t.Fatalf("expected fighters of twice the power to wait, got %v", groups)
+	}
+	if groups := FormGroups(tickets, now.Add(80*time.Second)); len(groups) != 1 {
+		t.Fatalf("expected the power window to widen, got %v", groups)
+	}
+}
+
+func TestEstimateWait(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	alone := ticket("a", 1, 1500, 100, now)
+
+	if eta := EstimateWait(alone, []Ticket{alone}, now, 0); eta != DefaultWaitEstimate {
+		t.Fatalf("expected the default estimate without opponents, got %v", eta)
+	}
+	if eta := EstimateWait(alone, nil, now, time.Minute); eta != time.Minute {
+		t.Fatalf("expected the recent average without opponents, got %v", eta)
+	}
+
+	// 300 rating apart: the windows reach each other after 20s
+	other := ticket("b", 2, 1800, 100, now)
+	if eta := EstimateWait(alone, []Ticket{alone, other}, now.Add(5*time.Second), 0); eta != 15*time.Second {
+		t.Fatalf("expected 15s, got %v", eta)
+	}
+
+	// Close opponents match once the fill timeout passed
+	near := ticket("c", 3, 1510, 100, now.Add(-10*time.Second))
+	if eta := EstimateWait(alone, []Ticket{alone, near}, now, 0); eta != FillTimeout-10*time.Second {
+		t.Fatalf("expected the older ticket's fill timeout, got %v", eta)
+	}
+}
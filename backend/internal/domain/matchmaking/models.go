@@ -0,0 +1,30 @@
+package matchmaking
+
+import "time"
+
+const (
+	TicketStatusWaiting   = "waiting"
+	TicketStatusMatched   = "matched"
+	TicketStatusCancelled = "cancelled"
+)
+
+// Ticket is a fighter waiting in the matchmaking queue. Rating and Power are
+// taken when the fighter queues.
+type Ticket struct {
+	FighterID string
+	UserID    int64
+	Rating    float64
+	Power     int
+	Status    string
+	QueuedAt  time.Time
+	MatchID   *string
+	MatchedAt *time.Time
+}
+
+// Waited returns how long the ticket has been waiting at now.
+func (t Ticket) Waited(now time.Time) time.Duration {
+	if now.Before(t.QueuedAt) {
+		return 0
+	}
+	return now.Sub(t.QueuedAt)
+}
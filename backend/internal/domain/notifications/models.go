@@ -0,0 +1,33 @@
+package notifications
+
+import "time"
+
+// Notification types
+const (
+	TypeReward       = "reward"
+	TypeAchievement  = "achievement"
+	TypeLeagueMatch  = "leagueMatch"
+	TypeDailyReward  = "dailyReward"
+	TypeWeekendEvent = "weekendEvent"
+	TypeLevelUp      = "levelUp"
+)
+
+// Notification is an entry of a user's inbox. Data carries the JSON encoded
+// details of the event the notification is about. Notifications with a Key
+// are stored at most once per user, which makes repeated sends harmless.
+type Notification struct {
+	ID      string
+	UserID  int64
+	Type    string
+	Title   string
+	Message string
+	Data    []byte
+	Key     *string
+	ReadAt  *time.Time
+	Created time.Time
+}
+
+// Read reports whether the user has seen the notification.
+func (n Notification) Read() bool {
+	return n.ReadAt != nil
+}
@@ -0,0 +1,165 @@
+// Package rating rates fighters with Glicko-2. Every settled match is one
+// rating period for the fighters who took part in it.
+package rating
+
+import (
+	"math"
+	"time"
+)
+
+// Rating of a new fighter.
+const (
+	DefaultRating     = 1500.0
+	DefaultDeviation  = 350.0
+	DefaultVolatility = 0.06
+)
+
+const (
+	// scale converts between the Glicko and the Glicko-2 scale.
+	scale = 173.7178
+	// tau constrains how fast the volatility changes.
+	tau = 0.5
+	// epsilon is the convergence tolerance of the volatility iteration.
+	epsilon = 0.000001
+)
+
+// Rating is a fighter's skill estimate: Rating is the estimate itself,
+// Deviation how uncertain it is and Volatility how erratic the fighter's
+// results are.
+type Rating struct {
+	FighterID  string
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+	Matches    int
+	Wins       int
+	Losses     int
+	Updated    time.Time
+}
+
+// New returns the rating of a fighter without rated matches.
+func New(fighterID string) Rating {
+	return Rating{
+		FighterID:  fighterID,
+		Rating:     DefaultRating,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+	}
+}
+
+// Conservative is the rating the fighter is at least as good as with 95%
+// certainty. It ranks fighters without penalising well-known ones.
+func (r Rating) Conservative() float64 {
+	return r.Rating - 2*r.Deviation
+}
+
+// Outcome is a result against one opponent: 1 for a win, 0 for a loss and
+// 0.5 for a draw.
+type Outcome struct {
+	Opponent Rating
+	Score    float64
+}
+
+// Win, Loss and Draw are the scores of an outcome.
+const (
+	Win  = 1.0
+	Loss = 0.0
+	Draw = 0.5
+)
+
+// Expected returns the score r is expected to get against opponent.
+func (r Rating) Expected(opponent Rating) float64 {
+	mu, muj, phij := (r.Rating-DefaultRating)/scale, (opponent.Rating-DefaultRating)/scale, opponent.Deviation/scale
+	return expected(mu, muj, phij)
+}
+
+// Update returns the rating after one rating period with the outcomes. The
+// match counters are left to the caller.
+func (r Rating) Update(outcomes []Outcome) Rating {
+	if len(outcomes) == 0 {
+		return r
+	}
+
+	mu := (r.Rating - DefaultRating) / scale
+	phi := r.Deviation / scale
+
+	var variance, improvement float64
+	for _, outcome := range outcomes {
+		muj := (outcome.Opponent.Rating - DefaultRating) / scale
+		phij := outcome.Opponent.Deviation / scale
+		g := gPhi(phij)
+		e := expected(mu, muj, phij)
+		variance += g * g * e * (1 - e)
+		improvement += g * (outcome.Score - e)
+	}
+	v := 1 / variance
+	delta := v * improvement
+
+	sigma := volatility(phi, r.Volatility, v, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*improvement
+
+	updated := r
+	updated.Rating = scale*newMu + DefaultRating
+	updated.Deviation = math.Min(scale*newPhi, DefaultDeviation)
+	updated.Volatility = sigma
+	return updated
+}
+
+// Idle returns the rating after periods rating periods without matches. Only
+// the deviation grows, as in step 6 of Glickman's description of Glicko-2,
+// up to that of a new fighter.
+func (r Rating) Idle(periods int) Rating {
+	if periods <= 0 {
+		return r
+	}
+	phi := r.Deviation / scale
+	idle := r
+	idle.Deviation = math.Min(scale*math.Sqrt(phi*phi+float64(periods)*r.Volatility*r.Volatility), DefaultDeviation)
+	return idle
+}
+
+func gPhi(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muj, phij float64) float64 {
+	return 1 / (1 + math.Exp(-gPhi(phij)*(mu-muj)))
+}
+
+// volatility solves for the new volatility with the Illinois algorithm, as
+// in step 5 of Glickman's description of Glicko-2.
+func volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
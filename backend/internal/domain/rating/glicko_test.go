@@ -0,0 +1,60 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+// The example from Glickman's "Example of the Glicko-2 system".
+func TestUpdate_GlickmanExample(t *testing.T) {
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	updated := player.Update([]Outcome{
+		{Opponent: Rating{Rating: 1400, Deviation: 30}, Score: Win},
+		{Opponent: Rating{Rating: 1550, Deviation: 100}, Score: Loss},
+		{Opponent: Rating{Rating: 1700, Deviation: 300}, Score: Loss},
+	})
+
+	if math.Abs(updated.Rating-1464.06) > 0.01 {
+		t.Errorf("expected a rating of 1464.06, got %.2f", updated.Rating)
+	}
+	if math.Abs(updated.Deviation-151.52) > 0.01 {
+		t.Errorf("expected a deviation of 151.52, got %.2f", updated.Deviation)
+	}
+	if math.Abs(updated.Volatility-0.05999) > 0.00001 {
+		t.Errorf("expected a volatility of 0.05999, got %.5f", updated.Volatility)
+	}
+}
+
+func TestUpdate_NewFighters(t *testing.T) {
+	winner, loser := New("a"), New("b")
+	newWinner := winner.Update([]Outcome{{Opponent: loser, Score: Win}})
+	newLoser := loser.Update([]Outcome{{Opponent: winner, Score: Loss}})
+
+	if newWinner.Rating <= DefaultRating || newLoser.Rating >= DefaultRating {
+		t.Fatalf("expected the winner to gain and the loser to lose, got %.1f and %.1f", newWinner.Rating, newLoser.Rating)
+	}
+	if newWinner.Deviation >= DefaultDeviation {
+		t.Fatalf("expected a rated match to lower the deviation, got %.1f", newWinner.Deviation)
+	}
+	if math.Abs(winner.Expected(loser)-0.5) > 1e-9 {
+		t.Fatalf("expected even odds between new fighters, got %v", winner.Expected(loser))
+	}
+}
+
+func TestIdle(t *testing.T) {
+	settled := Rating{Rating: 1800, Deviation: 50, Volatility: 0.06}
+
+	idle := settled.Idle(30)
+	if idle.Rating != settled.Rating {
+		t.Fatalf("expected the rating to stay, got %.1f", idle.Rating)
+	}
+	if math.Abs(idle.Deviation-75.89) > 0.01 {
+		t.Fatalf("expected a deviation of 75.89, got %.2f", idle.Deviation)
+	}
+	if settled.Idle(0) != settled {
+		t.Fatal("expected no idle periods to change nothing")
+	}
+	if capped := settled.Idle(100000); capped.Deviation != DefaultDeviation {
+		t.Fatalf("expected the deviation to stop at a new fighter's, got %.1f", capped.Deviation)
+	}
+}
@@ -0,0 +1,165 @@
+package roster
+
+import "time"
+
+// AttributePointsPerLevel is the number of attribute points a fighter earns
+// with every level, its first included.
+const AttributePointsPerLevel = 3
+
+// AttributePoints returns the attribute points a fighter of level has to
+// spend in total.
+func AttributePoints(level int) int {
+	if level < 1 {
+		level = 1
+	}
+	if level > MaxLevel {
+		level = MaxLevel
+	}
+	return AttributePointsPerLevel * level
+}
+
+// AttributeCap returns the most points a fighter of level can spend on a
+// single attribute, so fighters can't sink their whole budget into one stat.
+func AttributeCap(level int) int {
+	if level < 1 {
+		level = 1
+	}
+	return 5 + 2*level
+}
+
+// Respec pricing in gold. Every respec costs RespecBaseCost more than the
+// previous one, up to RespecMaxCost.
+const (
+	RespecBaseCost = 250
+	RespecMaxCost  = 2500
+)
+
+// RespecCost returns the gold a fighter pays for its next respec after
+// respecs earlier ones.
+func RespecCost(respecs int) int {
+	cost := RespecBaseCost * (respecs + 1)
+	if cost > RespecMaxCost {
+		return RespecMaxCost
+	}
+	return cost
+}
+
+// Attributes are attribute points, one field per fighter stat.
+type Attributes struct {
+	Power          int
+	ConditionPower int
+	Precision      int
+	Ferocity       int
+	Accuracy       int
+	Agility        int
+	Armor          int
+	Vitality       int
+	ParryChance    int
+	HealingPower   int
+	Speed          int
+	Vision         int
+}
+
+func (a Attributes) values() []int {
+	return []int{
+		a.Power, a.ConditionPower, a.Precision, a.Ferocity, a.Accuracy, a.Agility,
+		a.Armor, a.Vitality, a.ParryChance, a.HealingPower, a.Speed, a.Vision,
+	}
+}
+
+// Total returns the sum of all points.
+func (a Attributes) Total() int {
+	total := 0
+	for _, value := range a.values() {
+		total += value
+	}
+	return total
+}
+
+// Max returns the points of the attribute with the most points.
+func (a Attributes) Max() int {
+	max := 0
+	for i, value := range a.values() {
+		if i == 0 || value > max {
+			max = value
+		}
+	}
+	return max
+}
+
+// Min returns the points of the attribute with the fewest points.
+func (a Attributes) Min() int {
+	min := 0
+	for i, value := range a.values() {
+		if i == 0 || value < min {
+			min = value
+		}
+	}
+	return min
+}
+
+// Plus returns the sum of both point sets.
+func (a Attributes) Plus(b Attributes) Attributes {
+	return Attributes{
+		Power:          a.Power + b.Power,
+		ConditionPower: a.ConditionPower + b.ConditionPower,
+		Precision:      a.Precision + b.Precision,
+		Ferocity:       a.Ferocity + b.Ferocity,
+		Accuracy:       a.Accuracy + b.Accuracy,
+		Agility:        a.Agility + b.Agility,
+		Armor:          a.Armor + b.Armor,
+		Vitality:       a.Vitality + b.Vitality,
+		ParryChance:    a.ParryChance + b.ParryChance,
+		HealingPower:   a.HealingPower + b.HealingPower,
+		Speed:          a.Speed + b.Speed,
+		Vision:         a.Vision + b.Vision,
+	}
+}
+
+// Negated returns the point set with every sign flipped.
+func (a Attributes) Negated() Attributes {
+	return Attributes{}.minus(a)
+}
+
+func (a Attributes) minus(b Attributes) Attributes {
+	return Attributes{
+		Power:          a.Power - b.Power,
+		ConditionPower: a.ConditionPower - b.ConditionPower,
+		Precision:      a.Precision - b.Precision,
+		Ferocity:       a.Ferocity - b.Ferocity,
+		Accuracy:       a.Accuracy - b.Accuracy,
+		Agility:        a.Agility - b.Agility,
+		Armor:          a.Armor - b.Armor,
+		Vitality:       a.Vitality - b.Vitality,
+		ParryChance:    a.ParryChance - b.ParryChance,
+		HealingPower:   a.HealingPower - b.HealingPower,
+		Speed:          a.Speed - b.Speed,
+		Vision:         a.Vision - b.Vision,
+	}
+}
+
+// Allocate adds the points to the fighter's stats. Negative points take
+// them back off.
+func (f *Fighter) Allocate(points Attributes) {
+	f.Power += points.Power
+	f.ConditionPower += points.ConditionPower
+	f.Precision += points.Precision
+	f.Ferocity += points.Ferocity
+	f.Accuracy += points.Accuracy
+	f.Agility += points.Agility
+	f.Armor += points.Armor
+	f.Vitality += points.Vitality
+	f.ParryChance += points.ParryChance
+	f.HealingPower += points.HealingPower
+	f.Speed += points.Speed
+	f.Vision += points.Vision
+}
+
+// AttributeAllocation is what a fighter spent its attribute points on, on
+// top of the stats it was created with and gained by leveling.
+type AttributeAllocation struct {
+	FighterID string
+	Points    Attributes
+	Respecs   int
+	Updated   time.Time
+}
@@ -0,0 +1,32 @@
+package roster
+
+import "testing"
+
+func TestAttributeBudget(t *testing.T) {
+	if AttributePoints(1) != 3 || AttributePoints(10) != 30 || AttributePoints(MaxLevel+5) != AttributePoints(MaxLevel) {
+		t.Fatal("unexpected attribute points per level")
+	}
+	if AttributeCap(1) != 7 || AttributeCap(10) != 25 {
+		t.Fatal("unexpected attribute caps")
+	}
+	if RespecCost(0) != RespecBaseCost || RespecCost(1) != 2*RespecBaseCost || RespecCost(100) != RespecMaxCost {
+		t.Fatal("unexpected respec costs")
+	}
+}
+
+func TestAllocateAndTakeBack(t *testing.T) {
+	points := Attributes{Power: 3, Vitality: 2, Speed: -1}
+	if points.Total() != 4 || points.Max() != 3 || points.Min() != -1 {
+		t.Fatalf("unexpected totals for %+v", points)
+	}
+
+	fighter := Fighter{Power: 10, Vitality: 4, Speed: 6}
+	fighter.Allocate(points)
+	if fighter.Power != 13 || fighter.Vitality != 6 || fighter.Speed != 5 {
+		t.Fatalf("unexpected stats after allocating: %+v", fighter)
+	}
+	fighter.Allocate(points.Negated())
+	if fighter.Power != 10 || fighter.Vitality != 4 || fighter.Speed != 6 {
+		t.Fatalf("expected the points to be taken back, got %+v", fighter)
+	}
+}
@@ -0,0 +1,85 @@
+package roster
+
+import "time"
+
+// MaxLevel is the highest level a fighter can reach. Experience earned at the
+// cap is still counted but no longer advances the fighter.
+const MaxLevel = 50
+
+// ExperienceToNextLevel returns the experience a fighter needs to advance
+// from level to the next one: 100 times the level. It is 0 at the cap.
+func ExperienceToNextLevel(level int) int {
+	if level >= MaxLevel {
+		return 0
+	}
+	return 100 * level
+}
+
+// ExperienceForLevel returns the total experience a fighter needs to reach
+// level.
+func ExperienceForLevel(level int) int {
+	if level < 1 {
+		level = 1
+	}
+	if level > MaxLevel {
+		level = MaxLevel
+	}
+	return 50 * level * (level - 1)
+}
+
+// LevelForExperience returns the level total experience is worth, the
+// experience earned within that level and the experience the level takes.
+func LevelForExperience(experience int) (level int, current int, next int) {
+	if experience < 0 {
+		experience = 0
+	}
+	level = 1
+	for level < MaxLevel && experience >= ExperienceForLevel(level+1) {
+		level++
+	}
+	return level, experience - ExperienceForLevel(level), ExperienceToNextLevel(level)
+}
+
+// StatGains are stat points a fighter gains.
+type StatGains struct {
+	Power    int
+	Vitality int
+	Accuracy int
+	Agility  int
+	Armor    int
+}
+
+// StatGrowthPerLevel is what a fighter gains with every level.
+var StatGrowthPerLevel = StatGains{Power: 2, Vitality: 2, Accuracy: 2, Agility: 2, Armor: 1}
+
+// Times returns the gains of n levels.
+func (g StatGains) Times(n int) StatGains {
+	return StatGains{
+		Power:    g.Power * n,
+		Vitality: g.Vitality * n,
+		Accuracy: g.Accuracy * n,
+		Agility:  g.Agility * n,
+		Armor:    g.Armor * n,
+	}
+}
+
+// Grow adds the gains to the fighter's stats.
+func (f *Fighter) Grow(gains StatGains) {
+	f.Power += gains.Power
+	f.Vitality += gains.Vitality
+	f.Accuracy += gains.Accuracy
+	f.Agility += gains.Agility
+	f.Armor += gains.Armor
+}
+
+// LevelUp records a fighter advancing one or more levels at once.
+type LevelUp struct {
+	ID         int64
+	FighterID  string
+	UserID     int64
+	FromLevel  int
+	ToLevel    int
+	Experience int
+	Gains      StatGains
+	Created    time.Time
+}
@@ -0,0 +1,33 @@
+package roster
+
+import "testing"
+
+func TestLevelForExperience(t *testing.T) {
+	cases := []struct {
+		experience int
+		level      int
+		current    int
+		next       int
+	}{
+		{0, 1, 0, 100},
+		{99, 1, 99, 100},
+		{100, 2, 0, 200},
+		{299, 2, 199, 200},
+		{300, 3, 0, 300},
+		{ExperienceForLevel(MaxLevel) + 5000, MaxLevel, 5000, 0},
+	}
+	for _, c := range cases {
+		level, current, next := LevelForExperience(c.experience)
+		if level != c.level || current != c.current || next != c.next {
+			t.Errorf("experience %d: expected level %d (%d/%d), got %d (%d/%d)", c.experience, c.level, c.current, c.next, level, current, next)
+		}
+	}
+}
+
+func TestStatGrowth(t *testing.T) {
+	fighter := Fighter{Power: 10, Armor: 3}
+	fighter.Grow(StatGrowthPerLevel.Times(3))
+	if fighter.Power != 16 || fighter.Vitality != 6 || fighter.Armor != 6 {
+		t.Fatalf("unexpected stats after 3 levels: %+v", fighter)
+	}
+}
@@ -31,6 +31,8 @@ type Fighter struct {
 	TotalDamageTaken int64
 	Created        time.Time
 	IsDeleted      bool
+	// IsBot marks synthetic match opponents that are never persisted.
+	IsBot bool
 }
 
 type FighterExperience struct {
@@ -0,0 +1,287 @@
+package seasons
+
+import (
+	"sort"
+	"time"
+
+	"empoweredpixels/internal/domain/rating"
+)
+
+// Division is a tier of the ranked ladder.
+type Division string
+
+const (
+	DivisionBronze   Division = "bronze"
+	DivisionSilver   Division = "silver"
+	DivisionGold     Division = "gold"
+	DivisionPlatinum Division = "platinum"
+	DivisionDiamond  Division = "diamond"
+	DivisionMaster   Division = "master"
+	DivisionMythic   Division = "mythic"
+)
+
+// Divisions lists the divisions from the lowest to the highest.
+var Divisions = []Division{
+	DivisionBronze,
+	DivisionSilver,
+	DivisionGold,
+	DivisionPlatinum,
+	DivisionDiamond,
+	DivisionMaster,
+	DivisionMythic,
+}
+
+// divisionFloors are the conservative ratings a fighter needs for each
+// division, in the order of Divisions.
+var divisionFloors = []float64{0, 1100, 1300, 1500, 1700, 1900, 2100}
+
+// DivisionFor returns the division a conservative rating belongs to. New
+// fighters start in Bronze, since their uncertain rating is worth little.
+func DivisionFor(conservative float64) Division {
+	division := DivisionBronze
+	for i, floor := range divisionFloors {
+		if conservative >= floor {
+			division = Divisions[i]
+		}
+	}
+	return division
+}
+
+// Rank is the position of the division in Divisions, or -1 for an unknown
+// one.
+func (d Division) Rank() int {
+	for i, division := range Divisions {
+		if division == d {
+			return i
+		}
+	}
+	return -1
+}
+
+// Next returns the division above d, or d at the top.
+func (d Division) Next() Division {
+	if rank := d.Rank(); rank >= 0 && rank < len(Divisions)-1 {
+		return Divisions[rank+1]
+	}
+	return d
+}
+
+// Previous returns the division below d, or d at the bottom.
+func (d Division) Previous() Division {
+	if rank := d.Rank(); rank > 0 {
+		return Divisions[rank-1]
+	}
+	return d
+}
+
+// RewardPool is the end-of-season reward pool of the division.
+func (d Division) RewardPool() string {
+	return "season_" + string(d)
+}
+
+// SeriesKind tells whether a series is played for a promotion or against a
+// demotion.
+type SeriesKind string
+
+const (
+	SeriesPromotion SeriesKind = "promotion"
+	SeriesDemotion  SeriesKind = "demotion"
+)
+
+// SeriesWinsNeeded is how many wins or losses decide a best of three series.
+const SeriesWinsNeeded = 2
+
+// Series is played whenever a fighter's rating leaves their division. It
+// moves the fighter one division up or down only when it is won or lost.
+type Series struct {
+	Kind   SeriesKind
+	Wins   int
+	Losses int
+}
+
+// DecayGrace is how long a fighter can stay away from ranked matches before
+// their rating decays.
+const DecayGrace = 14 * 24 * time.Hour
+
+// DecayPeriod is one rating period of decay.
+const DecayPeriod = 24 * time.Hour
+
+// Soft reset of every rating when a season rolls over: ratings move
+// SoftResetRetained of the way back towards the default and become at least
+// SoftResetDeviation uncertain, so fighters have to play their way back up.
+const (
+	SoftResetRetained  = 0.5
+	SoftResetDeviation = 150.0
+)
+
+// LadderEntry is a fighter's place on the ranked ladder of the current
+// season.
+type LadderEntry struct {
+	FighterID string
+	UserID    int64
+	Division  Division
+	Series    *Series
+	LastMatch time.Time
+	// Decayed is the time up to which idle periods were decayed.
+	Decayed *time.Time
+	Updated time.Time
+}
+
+// NewLadderEntry places a fighter after their first rated match.
+func NewLadderEntry(fighterID string, userID int64, r rating.Rating, now time.Time) *LadderEntry {
+	return &LadderEntry{
+		FighterID: fighterID,
+		UserID:    userID,
+		Division:  DivisionFor(r.Conservative()),
+		LastMatch: now,
+		Updated:   now,
+	}
+}
+
+// Record moves the entry after a rated match the fighter ended with score
+// and the rating the match left them with. A running series counts the
+// match; otherwise a rating outside the division opens a series.
+func (e *LadderEntry) Record(r rating.Rating, score float64, now time.Time) {
+	e.LastMatch = now
+	e.Decayed = nil
+	e.Updated = now
+
+	if e.Series != nil {
+		switch score {
+		case rating.Win:
+			e.Series.Wins++
+		case rating.Loss:
+			e.Series.Losses++
+		}
+		e.resolveSeries()
+		return
+	}
+
+	target := DivisionFor(r.Conservative())
+	switch {
+	case target.Rank() > e.Division.Rank():
+		e.Series = &Series{Kind: SeriesPromotion}
+	case target.Rank() < e.Division.Rank():
+		e.Series = &Series{Kind: SeriesDemotion}
+	}
+}
+
+func (e *LadderEntry) resolveSeries() {
+	switch {
+	case e.Series.Wins >= SeriesWinsNeeded:
+		if e.Series.Kind == SeriesPromotion {
+			e.Division = e.Division.Next()
+		}
+		e.Series = nil
+	case e.Series.Losses >= SeriesWinsNeeded:
+		if e.Series.Kind == SeriesDemotion {
+			e.Division = e.Division.Previous()
+		}
+		e.Series = nil
+	}
+}
+
+// Decay returns the rating after the idle periods since the grace after the
+// fighter's last match ran out and that weren't decayed yet. A fighter whose
+// decayed rating falls below their division drops one division without a
+// series. It reports false when there was nothing to decay.
+func (e *LadderEntry) Decay(r rating.Rating, now time.Time) (rating.Rating, bool) {
+	from := e.LastMatch.Add(DecayGrace)
+	if e.Decayed != nil && e.Decayed.After(from) {
+		from = *e.Decayed
+	}
+	periods := int(now.Sub(from) / DecayPeriod)
+	if periods <= 0 {
+		return r, false
+	}
+
+	decayed := from.Add(time.Duration(periods) * DecayPeriod)
+	e.Decayed = &decayed
+	e.Updated = now
+
+	r = r.Idle(periods)
+	if DivisionFor(r.Conservative()).Rank() < e.Division.Rank() {
+		e.Division = e.Division.Previous()
+		e.Series = nil
+	}
+	return r, true
+}
+
+// SoftReset returns the rating a fighter starts the next season with.
+func SoftReset(r rating.Rating) rating.Rating {
+	reset := r
+	reset.Rating = rating.DefaultRating + (r.Rating-rating.DefaultRating)*SoftResetRetained
+	if reset.Deviation < SoftResetDeviation {
+		reset.Deviation = SoftResetDeviation
+	}
+	return reset
+}
+
+// Reset places the entry in the next season with its soft reset rating.
+func (e *LadderEntry) Reset(r rating.Rating, now time.Time) {
+	e.Division = DivisionFor(r.Conservative())
+	e.Series = nil
+	e.Decayed = nil
+	e.Updated = now
+}
+
+// Placement is a ladder entry with the rating it is ranked by.
+type Placement struct {
+	Entry  LadderEntry
+	Rating rating.Rating
+}
+
+// Ahead reports whether p ranks above other: by division, then by
+// conservative rating.
+func (p Placement) Ahead(other Placement) bool {
+	if p.Entry.Division != other.Entry.Division {
+		return p.Entry.Division.Rank() > other.Entry.Division.Rank()
+	}
+	return p.Rating.Conservative() > other.Rating.Conservative()
+}
+
+// Standing is a user's final position in a season and the best division
+// one of their fighters finished in. Users without ranked fighters have no
+// division.
+type Standing struct {
+	UserID   int64
+	Position int
+	Division Division
+}
+
+// Standings ranks every user by their best placed fighter. Users without
+// ranked fighters follow everyone else in the order of their id.
+func Standings(userIDs []int64, placements []Placement) []Standing {
+	best := make(map[int64]Placement, len(placements))
+	for _, placement := range placements {
+		current, ok := best[placement.Entry.UserID]
+		if !ok || placement.Ahead(current) {
+			best[placement.Entry.UserID] = placement
+		}
+	}
+
+	ids := append([]int64(nil), userIDs...)
+	sort.SliceStable(ids, func(i, j int) bool {
+		a, aRanked := best[ids[i]]
+		b, bRanked := best[ids[j]]
+		switch {
+		case aRanked && bRanked:
+			if a.Ahead(b) || b.Ahead(a) {
+				return a.Ahead(b)
+			}
+		case aRanked != bRanked:
+			return aRanked
+		}
+		return ids[i] < ids[j]
+	})
+
+	standings := make([]Standing, 0, len(ids))
+	for i, id := range ids {
+		standing := Standing{UserID: id, Position: i + 1}
+		if placement, ok := best[id]; ok {
+			standing.Division = placement.Entry.Division
+		}
+		standings = append(standings, standing)
+	}
+	return standings
+}
@@ -0,0 +1,127 @@
+package seasons
+
+import (
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/rating"
+)
+
+func TestDivisionFor(t *testing.T) {
+	cases := map[float64]Division{
+		800:  DivisionBronze,
+		1100: DivisionSilver,
+		1499: DivisionGold,
+		1750: DivisionDiamond,
+		2600: DivisionMythic,
+	}
+	for conservative, expected := range cases {
+		if got := DivisionFor(conservative); got != expected {
+			t.Errorf("%.0f: expected %s, got %s", conservative, expected, got)
+		}
+	}
+	if DivisionFor(rating.New("a").Conservative()) != DivisionBronze {
+		t.Fatal("expected new fighters to start in Bronze")
+	}
+}
+
+func TestLadderEntry_PromotionSeries(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	gold := rating.Rating{Rating: 1500, Deviation: 60}
+	entry := &LadderEntry{FighterID: "a", Division: DivisionSilver}
+
+	entry.Record(gold, rating.Win, now)
+	if entry.Division != DivisionSilver || entry.Series == nil || entry.Series.Kind != SeriesPromotion {
+		t.Fatalf("expected a promotion series in Silver, got %s %+v", entry.Division, entry.Series)
+	}
+
+	entry.Record(gold, rating.Win, now)
+	entry.Record(gold, rating.Loss, now)
+	if entry.Division != DivisionSilver || entry.Series == nil {
+		t.Fatalf("expected the series to go on after one win, got %s %+v", entry.Division, entry.Series)
+	}
+	entry.Record(gold, rating.Win, now)
+	if entry.Division != DivisionGold || entry.Series != nil {
+		t.Fatalf("expected the won series to promote, got %s %+v", entry.Division, entry.Series)
+	}
+}
+
+func TestLadderEntry_DemotionSeries(t *testing.T) {
+	now := time.Now()
+	silver := rating.Rating{Rating: 1300, Deviation: 60}
+	entry := &LadderEntry{FighterID: "a", Division: DivisionGold}
+
+	entry.Record(silver, rating.Loss, now)
+	if entry.Series == nil || entry.Series.Kind != SeriesDemotion {
+		t.Fatalf("expected a demotion series, got %+v", entry.Series)
+	}
+	entry.Record(silver, rating.Win, now)
+	entry.Record(silver, rating.Win, now)
+	if entry.Division != DivisionGold || entry.Series != nil {
+		t.Fatalf("expected the won series to keep Gold, got %s %+v", entry.Division, entry.Series)
+	}
+
+	entry.Record(silver, rating.Loss, now)
+	entry.Record(silver, rating.Loss, now)
+	entry.Record(silver, rating.Loss, now)
+	if entry.Division != DivisionSilver || entry.Series != nil {
+		t.Fatalf("expected the lost series to demote, got %s %+v", entry.Division, entry.Series)
+	}
+}
+
+func TestLadderEntry_Decay(t *testing.T) {
+	lastMatch := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	entry := &LadderEntry{FighterID: "a", Division: DivisionPlatinum, LastMatch: lastMatch}
+	r := rating.Rating{Rating: 1620, Deviation: 50, Volatility: 0.06}
+
+	if _, decayed := entry.Decay(r, lastMatch.Add(DecayGrace)); decayed {
+		t.Fatal("expected no decay within the grace")
+	}
+
+	now := lastMatch.Add(DecayGrace + 30*DecayPeriod + time.Hour)
+	r, decayed := entry.Decay(r, now)
+	if !decayed || r.Deviation <= 50 {
+		t.Fatalf("expected the deviation to grow, got %.1f", r.Deviation)
+	}
+	if entry.Division != DivisionGold {
+		t.Fatalf("expected the decayed rating to drop a division, got %s", entry.Division)
+	}
+	if _, again := entry.Decay(r, now); again {
+		t.Fatal("expected the decayed periods not to decay twice")
+	}
+}
+
+func TestSoftReset(t *testing.T) {
+	reset := SoftReset(rating.Rating{Rating: 2300, Deviation: 40, Volatility: 0.06, Wins: 10})
+
+	if reset.Rating != 1900 || reset.Deviation != SoftResetDeviation || reset.Wins != 10 {
+		t.Fatalf("unexpected reset rating %+v", reset)
+	}
+}
+
+func TestStandings(t *testing.T) {
+	placements := []Placement{
+		{Entry: LadderEntry{FighterID: "a1", UserID: 1, Division: DivisionGold}, Rating: rating.Rating{Rating: 1500, Deviation: 50}},
+		{Entry: LadderEntry{FighterID: "a2", UserID: 1, Division: DivisionDiamond}, Rating: rating.Rating{Rating: 1800, Deviation: 50}},
+		{Entry: LadderEntry{FighterID: "b", UserID: 2, Division: DivisionDiamond}, Rating: rating.Rating{Rating: 1850, Deviation: 50}},
+		{Entry: LadderEntry{FighterID: "c", UserID: 3, Division: DivisionMythic}, Rating: rating.Rating{Rating: 1700, Deviation: 50}},
+	}
+
+	standings := Standings([]int64{5, 4, 1, 2, 3}, placements)
+
+	expected := []Standing{
+		{UserID: 3, Position: 1, Division: DivisionMythic},
+		{UserID: 2, Position: 2, Division: DivisionDiamond},
+		{UserID: 1, Position: 3, Division: DivisionDiamond},
+		{UserID: 4, Position: 4},
+		{UserID: 5, Position: 5},
+	}
+	if len(standings) != len(expected) {
+		t.Fatalf("expected %d standings, got %d", len(expected), len(standings))
+	}
+	for i := range expected {
+		if standings[i] != expected[i] {
+			t.Errorf("position %d: expected %+v, got %+v", i+1, expected[i], standings[i])
+		}
+	}
+}
@@ -2,11 +2,16 @@ package seasons
 
 import "time"
 
+// SeasonLength is how long a ranked season runs.
+const SeasonLength = 28 * 24 * time.Hour
+
 type Season struct {
 	ID        int64
 	SeasonID  int
 	StartDate time.Time
 	EndDate   time.Time
+	// Completed is set once the season rolled over to the next one.
+	Completed *time.Time
 }
 
 type SeasonSummary struct {
@@ -97,6 +97,8 @@ const (
 	ItemTypeBundle      = "bundle"
 	ItemTypeEquipment   = "equipment"
 	ItemTypeConsumable  = "consumable"
+	ItemTypeRespec      = "respec"
+	ItemTypeLeagueEntry = "league_entry"
 )
 
 // Currency constants
@@ -1,10 +1,32 @@
 package skills
 
+// Skill IDs
+const (
+	SkillPowerStrike         = "skl_power_strike"
+	SkillBleed               = "skl_bleed"
+	SkillWhirlwind           = "skl_whirlwind"
+	SkillBerserk             = "skl_berserk"
+	SkillExecute             = "skl_execute"
+	SkillBlock               = "skl_block"
+	SkillHeal                = "skl_heal"
+	SkillShieldWall          = "skl_shield_wall"
+	SkillReflect             = "skl_reflect"
+	SkillImmortal            = "skl_immortal"
+	SkillHaste               = "skl_haste"
+	SkillManaRegen           = "skl_mana_regen"
+	SkillTeleport            = "skl_teleport"
+	SkillStun                = "skl_stun"
+	SkillLifeSteal           = "skl_life_steal"
+	UltimateMeteorStrike     = "ult_meteor_strike"
+	UltimateDivineProtection = "ult_divine_protection"
+	UltimateTimeWarp         = "ult_time_warp"
+)
+
 // SkillDatabase contains all 15 skill definitions (5 per branch, tiers 1-3 MVP)
 var SkillDatabase = []Skill{
 	// ===== OFFENSE BRANCH =====
 	{
-		ID:          "skl_power_strike",
+		ID:          SkillPowerStrike,
 		Name:        "Power Strike",
 		Branch:      Offense,
 		Tier:        1,
@@ -17,7 +39,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_power_strike_001.png?prompt=power%20strike%20skill%20icon%20with%20glowing%20sword&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_bleed",
+		ID:          SkillBleed,
 		Name:        "Bleed",
 		Branch:      Offense,
 		Tier:        1,
@@ -30,7 +52,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_bleed_001.png?prompt=bleed%20skill%20icon%20with%20blood%20drops&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_whirlwind",
+		ID:          SkillWhirlwind,
 		Name:        "Whirlwind",
 		Branch:      Offense,
 		Tier:        2,
@@ -43,7 +65,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_whirlwind_001.png?prompt=whirlwind%20attack%20skill%20icon%20with%20spinning%20blades&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_berserk",
+		ID:          SkillBerserk,
 		Name:        "Berserk",
 		Branch:      Offense,
 		Tier:        2,
@@ -56,7 +78,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_berserk_001.png?prompt=berserk%20rage%20skill%20icon%20with%20red%20aura&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_execute",
+		ID:          SkillExecute,
 		Name:        "Execute",
 		Branch:      Offense,
 		Tier:        3,
@@ -71,7 +93,7 @@ var SkillDatabase = []Skill{
 
 	// ===== DEFENSE BRANCH =====
 	{
-		ID:          "skl_block",
+		ID:          SkillBlock,
 		Name:        "Block",
 		Branch:      Defense,
 		Tier:        1,
@@ -84,7 +106,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_block_001.png?prompt=shield%20block%20skill%20icon%20with%20blue%20barrier&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_heal",
+		ID:          SkillHeal,
 		Name:        "Heal",
 		Branch:      Defense,
 		Tier:        1,
@@ -97,7 +119,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_heal_001.png?prompt=heal%20skill%20icon%20with%20green%20cross%20and%20light&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_shield_wall",
+		ID:          SkillShieldWall,
 		Name:        "Shield Wall",
 		Branch:      Defense,
 		Tier:        2,
@@ -110,7 +132,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_shield_wall_001.png?prompt=shield%20wall%20skill%20icon%20with%20golden%20barrier&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_reflect",
+		ID:          SkillReflect,
 		Name:        "Reflect",
 		Branch:      Defense,
 		Tier:        2,
@@ -123,7 +145,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_reflect_001.png?prompt=reflect%20skill%20icon%20with%20mirrored%20shield&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_immortal",
+		ID:          SkillImmortal,
 		Name:        "Immortal",
 		Branch:      Defense,
 		Tier:        3,
@@ -138,7 +160,7 @@ var SkillDatabase = []Skill{
 
 	// ===== UTILITY BRANCH =====
 	{
-		ID:          "skl_haste",
+		ID:          SkillHaste,
 		Name:        "Haste",
 		Branch:      Utility,
 		Tier:        1,
@@ -151,7 +173,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_haste_001.png?prompt=haste%20skill%20icon%20with%20wind%20swirls%20and%20speed%20lines&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_mana_regen",
+		ID:          SkillManaRegen,
 		Name:        "Mana Regeneration",
 		Branch:      Utility,
 		Tier:        1,
@@ -164,7 +186,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_mana_regen_001.png?prompt=mana%20regeneration%20skill%20icon%20with%20blue%20energy%20orb&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_teleport",
+		ID:          SkillTeleport,
 		Name:        "Teleport",
 		Branch:      Utility,
 		Tier:        2,
@@ -177,7 +199,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_teleport_001.png?prompt=teleport%20skill%20icon%20with%20purple%20portal%20swirl&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_stun",
+		ID:          SkillStun,
 		Name:        "Stun Strike",
 		Branch:      Utility,
 		Tier:        2,
@@ -190,7 +212,7 @@ var SkillDatabase = []Skill{
 		IconURL:     "https://vibemedia.space/skl_stun_001.png?prompt=stun%20skill%20icon%20with%20stars%20and%20impact%20burst&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "skl_life_steal",
+		ID:          SkillLifeSteal,
 		Name:        "Life Steal Aura",
 		Branch:      Utility,
 		Tier:        3,
@@ -207,7 +229,7 @@ var SkillDatabase = []Skill{
 // UltimateSkills contains the ultimate abilities (unlocked at level 50)
 var UltimateSkills = []Skill{
 	{
-		ID:          "ult_meteor_strike",
+		ID:          UltimateMeteorStrike,
 		Name:        "Meteor Strike",
 		Branch:      Offense,
 		Tier:        5,
@@ -220,7 +242,7 @@ var UltimateSkills = []Skill{
 		IconURL:     "https://vibemedia.space/ult_meteor_001.png?prompt=meteor%20strike%20ultimate%20icon%20with%20falling%20fire%20rock&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "ult_divine_protection",
+		ID:          UltimateDivineProtection,
 		Name:        "Divine Protection",
 		Branch:      Defense,
 		Tier:        5,
@@ -233,7 +255,7 @@ var UltimateSkills = []Skill{
 		IconURL:     "https://vibemedia.space/ult_divine_001.png?prompt=divine%20protection%20ultimate%20icon%20with%20golden%20dome%20shield&style=pixel_game_asset&key=NOGON",
 	},
 	{
-		ID:          "ult_time_warp",
+		ID:          UltimateTimeWarp,
 		Name:        "Time Warp",
 		Branch:      Utility,
 		Tier:        5,
@@ -292,4 +314,4 @@ func GetUltimateByBranch(branch SkillBranch) (*Skill, bool) {
 		}
 	}
 	return nil, false
-}
\ No newline at end of file
+}
@@ -0,0 +1,367 @@
+package tournaments
+
+import "sort"
+
+// Seed ranks the entrants by rating, the best rated one first, and numbers
+// their seeds from 1.
+func Seed(entrants []Entrant) {
+	sort.SliceStable(entrants, func(i, j int) bool {
+		if entrants[i].Rating != entrants[j].Rating {
+			return entrants[i].Rating > entrants[j].Rating
+		}
+		return entrants[i].FighterID < entrants[j].FighterID
+	})
+	for i := range entrants {
+		entrants[i].Seed = i + 1
+	}
+}
+
+// SwissRounds is the number of rounds a Swiss tournament of n entrants
+// plays: enough for a single undefeated fighter to remain.
+func SwissRounds(n int) int {
+	rounds := 1
+	for 1<<rounds < n {
+		rounds++
+	}
+	return rounds
+}
+
+// Lives is how many losses knock a fighter out, 0 when none do.
+func Lives(format Format) int {
+	switch format {
+	case FormatSingleElimination:
+		return 1
+	case FormatDoubleElimination:
+		return 2
+	}
+	return 0
+}
+
+// BracketOrder returns the seeds of a bracket of size slots in slot order.
+// Adjacent slots meet in the first round and the two best seeds can only
+// meet in the final.
+func BracketOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, 2*len(order))
+		for _, seed := range order {
+			next = append(next, seed, 2*len(order)+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// bracketSize is the smallest power of two that holds n entrants.
+func bracketSize(n int) int {
+	size := 1
+	for size < n {
+		size *= 2
+	}
+	return size
+}
+
+// Pair returns the pairings of the round after t.Round, or nil once the
+// tournament is decided. Byes are returned done, every other pairing
+// running and waiting for its match.
+func Pair(t Tournament, entrants []Entrant, played []Pairing) []Pairing {
+	round := t.Round + 1
+	switch t.Format {
+	case FormatSwiss:
+		if t.Round >= t.Rounds {
+			return nil
+		}
+		return pairSwiss(round, entrants, played)
+	case FormatDoubleElimination:
+		return pairDoubleElimination(round, entrants)
+	default:
+		alive := remaining(entrants)
+		if len(alive) < 2 {
+			return nil
+		}
+		return pairBracket(round, BracketWinners, alive, len(entrants))
+	}
+}
+
+func remaining(entrants []Entrant) []Entrant {
+	var alive []Entrant
+	for _, e := range entrants {
+		if e.Eliminated == 0 {
+			alive = append(alive, e)
+		}
+	}
+	return alive
+}
+
+// pairBracket pairs the fighters still in a bracket of the whole field. The
+// first round places everyone by seed and gives the best seeds the byes of a
+// field short of a power of two; later rounds pair neighbours in bracket
+// order.
+func pairBracket(round int, bracket string, fighters []Entrant, field int) []Pairing {
+	size := bracketSize(field)
+	order := BracketOrder(size)
+
+	var pairings []Pairing
+	if round == 1 {
+		bySeed := make(map[int]Entrant, len(fighters))
+		for _, f := range fighters {
+			bySeed[f.Seed] = f
+		}
+		for i := 0; i < size; i += 2 {
+			a, aOK := bySeed[order[i]]
+			b, bOK := bySeed[order[i+1]]
+			switch {
+			case aOK && bOK:
+				pairings = append(pairings, newPairing(round, bracket, len(pairings), a, &b))
+			case aOK:
+				pairings = append(pairings, newPairing(round, bracket, len(pairings), a, nil))
+			case bOK:
+				pairings = append(pairings, newPairing(round, bracket, len(pairings), b, nil))
+			}
+		}
+		return pairings
+	}
+
+	position := make(map[int]int, size)
+	for i, seed := range order {
+		position[seed] = i
+	}
+	sorted := append([]Entrant(nil), fighters...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return position[sorted[i].Seed] < position[sorted[j].Seed]
+	})
+	for i := 0; i+1 < len(sorted); i += 2 {
+		pairings = append(pairings, newPairing(round, bracket, len(pairings), sorted[i], &sorted[i+1]))
+	}
+	return pairings
+}
+
+// pairDoubleElimination plays the winners and the losers bracket side by
+// side until one undefeated and one once beaten fighter are left for the
+// final. Should the undefeated fighter lose it, both have one loss and the
+// final is played again.
+func pairDoubleElimination(round int, entrants []Entrant) []Pairing {
+	var winners, losers []Entrant
+	for _, e := range remaining(entrants) {
+		if e.Losses == 0 {
+			winners = append(winners, e)
+		} else {
+			losers = append(losers, e)
+		}
+	}
+
+	sort.SliceStable(losers, func(i, j int) bool { return losers[i].Seed < losers[j].Seed })
+
+	switch {
+	case len(winners)+len(losers) < 2:
+		return nil
+	case len(winners)+len(losers) == 2 && len(losers) > 0:
+		finalists := append(winners, losers...)
+		return []Pairing{newPairing(round, BracketFinal, 0, finalists[0], &finalists[1])}
+	}
+
+	var pairings []Pairing
+	if len(winners) > 1 {
+		pairings = pairBracket(round, BracketWinners, winners, len(entrants))
+	}
+
+	// The losers bracket pairs the best seed with the worst; with an odd
+	// number the best seed sits the round out.
+	if len(losers)%2 == 1 {
+		losers = losers[1:]
+	}
+	for i := 0; i < len(losers)/2; i++ {
+		opponent := losers[len(losers)-1-i]
+		pairings = append(pairings, newPairing(round, BracketLosers, i, losers[i], &opponent))
+	}
+	return pairings
+}
+
+// pairSwiss pairs fighters with the same score, best ranked first, avoiding
+// rematches where it can. With an odd number the lowest ranked fighter
+// without a bye gets one.
+func pairSwiss(round int, entrants []Entrant, played []Pairing) []Pairing {
+	ranked := append([]Entrant(nil), entrants...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score() != ranked[j].Score() {
+			return ranked[i].Score() > ranked[j].Score()
+		}
+		return ranked[i].Seed < ranked[j].Seed
+	})
+
+	var pairings []Pairing
+	if len(ranked)%2 == 1 {
+		bye := len(ranked) - 1
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if ranked[i].Byes == 0 {
+				bye = i
+				break
+			}
+		}
+		pairings = append(pairings, newPairing(round, BracketSwiss, 0, ranked[bye], nil))
+		ranked = append(ranked[:bye], ranked[bye+1:]...)
+	}
+
+	met := make(map[[2]string]bool)
+	for _, p := range played {
+		if !p.IsBye() {
+			met[[2]string{p.FighterA, *p.FighterB}] = true
+			met[[2]string{*p.FighterB, p.FighterA}] = true
+		}
+	}
+
+	order, ok := pairWithoutRematches(ranked, met)
+	if !ok {
+		// Every pairing has a rematch: pair neighbours
+		order = ranked
+	}
+	for i := 0; i+1 < len(order); i += 2 {
+		pairings = append(pairings, newPairing(round, BracketSwiss, len(pairings), order[i], &order[i+1]))
+	}
+	return pairings
+}
+
+// pairWithoutRematches orders the fighters into pairs of neighbours that
+// haven't met yet. It pairs the best ranked fighter with the next best one
+// they can play and backtracks when that leaves the rest unpairable.
+func pairWithoutRematches(ranked []Entrant, met map[[2]string]bool) ([]Entrant, bool) {
+	if len(ranked) == 0 {
+		return nil, true
+	}
+	a := ranked[0]
+	for i := 1; i < len(ranked); i++ {
+		if met[[2]string{a.FighterID, ranked[i].FighterID}] {
+			continue
+		}
+		rest := make([]Entrant, 0, len(ranked)-2)
+		rest = append(rest, ranked[1:i]...)
+		rest = append(rest, ranked[i+1:]...)
+		if paired, ok := pairWithoutRematches(rest, met); ok {
+			return append([]Entrant{a, ranked[i]}, paired...), true
+		}
+	}
+	return nil, false
+}
+
+func newPairing(round int, bracket string, slot int, a Entrant, b *Entrant) Pairing {
+	p := Pairing{
+		Round:    round,
+		Bracket:  bracket,
+		Slot:     slot,
+		FighterA: a.FighterID,
+		Status:   PairingRunning,
+	}
+	if b == nil {
+		winner := a.FighterID
+		p.WinnerID = &winner
+		p.Status = PairingDone
+		return p
+	}
+	opponent := b.FighterID
+	p.FighterB = &opponent
+	return p
+}
+
+// Decide returns the winner of a pairing from the winners of its match. An
+// elimination match needs a winner, so a draw goes to the better seed; a
+// Swiss match may end without one.
+func Decide(format Format, p Pairing, winners []string, entrants map[string]*Entrant) *string {
+	if p.IsBye() {
+		return &p.FighterA
+	}
+
+	a, b := p.FighterA, *p.FighterB
+	aWon, bWon := false, false
+	for _, id := range winners {
+		aWon = aWon || id == a
+		bWon = bWon || id == b
+	}
+	switch {
+	case aWon && !bWon:
+		return &a
+	case bWon && !aWon:
+		return &b
+	case format == FormatSwiss:
+		return nil
+	case entrants[a].Seed <= entrants[b].Seed:
+		return &a
+	}
+	return &b
+}
+
+// Apply counts the result of a done pairing for its fighters.
+func Apply(format Format, p Pairing, entrants map[string]*Entrant) {
+	a := entrants[p.FighterA]
+	if p.IsBye() {
+		a.Byes++
+		return
+	}
+	b := entrants[*p.FighterB]
+	if p.WinnerID == nil {
+		a.Draws++
+		b.Draws++
+		return
+	}
+
+	winner, loser := a, b
+	if *p.WinnerID == b.FighterID {
+		winner, loser = b, a
+	}
+	winner.Wins++
+	loser.Losses++
+	if lives := Lives(format); lives > 0 && loser.Losses >= lives {
+		loser.Eliminated = p.Round
+	}
+}
+
+// Place sets the final placements of a decided tournament. Elimination
+// tournaments rank fighters by how long they lasted, Swiss ones by score
+// and then by the score of their opponents; seeds break the remaining ties.
+func Place(format Format, entrants []Entrant, played []Pairing) {
+	ranked := make([]*Entrant, 0, len(entrants))
+	for i := range entrants {
+		ranked = append(ranked, &entrants[i])
+	}
+
+	if format == FormatSwiss {
+		scores := make(map[string]float64, len(entrants))
+		for _, e := range entrants {
+			scores[e.FighterID] = e.Score()
+		}
+		buchholz := make(map[string]float64, len(entrants))
+		for _, p := range played {
+			if p.Status == PairingDone && !p.IsBye() {
+				buchholz[p.FighterA] += scores[*p.FighterB]
+				buchholz[*p.FighterB] += scores[p.FighterA]
+			}
+		}
+		sort.SliceStable(ranked, func(i, j int) bool {
+			a, b := ranked[i], ranked[j]
+			if a.Score() != b.Score() {
+				return a.Score() > b.Score()
+			}
+			if buchholz[a.FighterID] != buchholz[b.FighterID] {
+				return buchholz[a.FighterID] > buchholz[b.FighterID]
+			}
+			return a.Seed < b.Seed
+		})
+	} else {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			a, b := ranked[i], ranked[j]
+			if (a.Eliminated == 0) != (b.Eliminated == 0) {
+				return a.Eliminated == 0
+			}
+			if a.Eliminated != b.Eliminated {
+				return a.Eliminated > b.Eliminated
+			}
+			if a.Wins != b.Wins {
+				return a.Wins > b.Wins
+			}
+			return a.Seed < b.Seed
+		})
+	}
+
+	for i, e := range ranked {
+		e.Placement = i + 1
+	}
+}
@@ -0,0 +1,212 @@
+package tournaments
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func newEntrants(n int) []Entrant {
+	entrants := make([]Entrant, 0, n)
+	for i := 0; i < n; i++ {
+		entrants = append(entrants, Entrant{FighterID: fmt.Sprintf("f%02d", i), Rating: float64(1000 + 10*i)})
+	}
+	Seed(entrants)
+	return entrants
+}
+
+// play runs the tournament to its end with results from winner and returns
+// every pairing played.
+func play(t *testing.T, tournament Tournament, entrants []Entrant, winner func(a, b *Entrant) []string) []Pairing {
+	byID := make(map[string]*Entrant, len(entrants))
+	for i := range entrants {
+		byID[entrants[i].FighterID] = &entrants[i]
+	}
+
+	var played []Pairing
+	for {
+		round := Pair(tournament, entrants, played)
+		if round == nil {
+			break
+		}
+		tournament.Round++
+		if tournament.Round > 4*len(entrants) {
+			t.Fatal("expected the tournament to end")
+		}
+		for _, p := range round {
+			if p.Status != PairingDone {
+				p.WinnerID = Decide(tournament.Format, p, winner(byID[p.FighterA], byID[*p.FighterB]), byID)
+				p.Status = PairingDone
+			}
+			Apply(tournament.Format, p, byID)
+			played = append(played, p)
+		}
+	}
+	Place(tournament.Format, entrants, played)
+	return played
+}
+
+func betterSeedWins(a, b *Entrant) []string {
+	if a.Seed < b.Seed {
+		return []string{a.FighterID}
+	}
+	return []string{b.FighterID}
+}
+
+func placements(entrants []Entrant) map[int]int {
+	bySeed := make(map[int]int, len(entrants))
+	for _, e := range entrants {
+		bySeed[e.Placement] = e.Seed
+	}
+	return bySeed
+}
+
+func TestBracketOrder(t *testing.T) {
+	if order := BracketOrder(8); !reflect.DeepEqual(order, []int{1, 8, 4, 5, 2, 7, 3, 6}) {
+		t.Fatalf("unexpected bracket order %v", order)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	entrants := newEntrants(3)
+	if entrants[0].FighterID != "f02" || entrants[0].Seed != 1 || entrants[2].Seed != 3 {
+		t.Fatalf("expected the best rated fighter seeded first, got %+v", entrants)
+	}
+}
+
+func TestPair_SingleEliminationByes(t *testing.T) {
+	entrants := newEntrants(6)
+	round := Pair(Tournament{Format: FormatSingleElimination}, entrants, nil)
+
+	byes, matches := 0, 0
+	for _, p := range round {
+		if p.IsBye() {
+			byes++
+			if seed := seedOf(entrants, p.FighterA); seed > 2 {
+				t.Fatalf("expected the best seeds to get the byes, got seed %d", seed)
+			}
+		} else {
+			matches++
+		}
+	}
+	if byes != 2 || matches != 2 {
+		t.Fatalf("expected 2 byes and 2 matches, got %d and %d", byes, matches)
+	}
+}
+
+func seedOf(entrants []Entrant, fighterID string) int {
+	for _, e := range entrants {
+		if e.FighterID == fighterID {
+			return e.Seed
+		}
+	}
+	return 0
+}
+
+func TestPlay_SingleElimination(t *testing.T) {
+	entrants := newEntrants(6)
+	played := play(t, Tournament{Format: FormatSingleElimination}, entrants, betterSeedWins)
+
+	if len(played) != 7 {
+		t.Fatalf("expected 5 matches and 2 byes, got %d pairings", len(played))
+	}
+	bySeed := placements(entrants)
+	if bySeed[1] != 1 || bySeed[2] != 2 {
+		t.Fatalf("expected the top seeds to finish first and second, got %v", bySeed)
+	}
+}
+
+func TestPlay_DoubleElimination(t *testing.T) {
+	for n := 2; n <= 9; n++ {
+		random := rand.New(rand.NewSource(int64(n)))
+		entrants := newEntrants(n)
+		play(t, Tournament{Format: FormatDoubleElimination}, entrants, func(a, b *Entrant) []string {
+			if random.Intn(2) == 0 {
+				return []string{a.FighterID}
+			}
+			return []string{b.FighterID}
+		})
+
+		for _, e := range entrants {
+			if e.Placement == 1 {
+				if e.Eliminated != 0 || e.Losses > 1 {
+					t.Fatalf("%d entrants: expected a champion with at most one loss, got %+v", n, e)
+				}
+				continue
+			}
+			if e.Losses != 2 || e.Eliminated == 0 {
+				t.Fatalf("%d entrants: expected everyone else out after two losses, got %+v", n, e)
+			}
+		}
+	}
+}
+
+func TestPlay_DoubleEliminationFinalReset(t *testing.T) {
+	entrants := newEntrants(2)
+	// The lower seed loses the first match, then wins the rest
+	first := true
+	played := play(t, Tournament{Format: FormatDoubleElimination}, entrants, func(a, b *Entrant) []string {
+		if first {
+			first = false
+			return betterSeedWins(a, b)
+		}
+		if a.Seed > b.Seed {
+			return []string{a.FighterID}
+		}
+		return []string{b.FighterID}
+	})
+
+	if len(played) != 3 || played[2].Bracket != BracketFinal {
+		t.Fatalf("expected the final to be played again, got %d pairings", len(played))
+	}
+	if bySeed := placements(entrants); bySeed[1] != 2 {
+		t.Fatalf("expected the lower seed to win, got %v", bySeed)
+	}
+}
+
+func TestPlay_Swiss(t *testing.T) {
+	entrants := newEntrants(5)
+	tournament := Tournament{Format: FormatSwiss, Rounds: SwissRounds(len(entrants))}
+	played := play(t, tournament, entrants, betterSeedWins)
+
+	if tournament.Rounds != 3 {
+		t.Fatalf("expected 3 rounds, got %d", tournament.Rounds)
+	}
+	met := make(map[[2]string]bool)
+	for _, p := range played {
+		if p.IsBye() {
+			continue
+		}
+		key := [2]string{p.FighterA, *p.FighterB}
+		if p.FighterA > *p.FighterB {
+			key = [2]string{*p.FighterB, p.FighterA}
+		}
+		if met[key] {
+			t.Fatalf("unexpected rematch %v", key)
+		}
+		met[key] = true
+	}
+	for _, e := range entrants {
+		if e.Byes > 1 {
+			t.Fatalf("expected at most one bye per fighter, got %+v", e)
+		}
+	}
+	if bySeed := placements(entrants); bySeed[1] != 1 {
+		t.Fatalf("expected the top seed to win, got %v", bySeed)
+	}
+}
+
+func TestDecide_Draws(t *testing.T) {
+	entrants := newEntrants(2)
+	byID := map[string]*Entrant{entrants[0].FighterID: &entrants[0], entrants[1].FighterID: &entrants[1]}
+	b := entrants[0].FighterID
+	p := Pairing{FighterA: entrants[1].FighterID, FighterB: &b}
+
+	if winner := Decide(FormatSwiss, p, nil, byID); winner != nil {
+		t.Fatalf("expected a Swiss draw, got %s", *winner)
+	}
+	if winner := Decide(FormatSingleElimination, p, nil, byID); winner == nil || *winner != b {
+		t.Fatal("expected an elimination draw to go to the better seed")
+	}
+}
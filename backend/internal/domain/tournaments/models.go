@@ -0,0 +1,151 @@
+// Package tournaments runs brackets between the fighters subscribed to a
+// league. Every pairing is a one on one match.
+package tournaments
+
+import "time"
+
+// Format is how a tournament pairs its entrants.
+type Format string
+
+const (
+	// FormatSingleElimination knocks a fighter out with their first loss.
+	FormatSingleElimination Format = "single_elimination"
+	// FormatDoubleElimination knocks a fighter out with their second loss.
+	// Fighters with one loss play on in the losers bracket and the winner of
+	// that meets the undefeated fighter in the final.
+	FormatDoubleElimination Format = "double_elimination"
+	// FormatSwiss pairs fighters with the same score for a fixed number of
+	// rounds without knocking anyone out.
+	FormatSwiss Format = "swiss"
+)
+
+// Valid reports whether f is a known format.
+func (f Format) Valid() bool {
+	switch f {
+	case FormatSingleElimination, FormatDoubleElimination, FormatSwiss:
+		return true
+	}
+	return false
+}
+
+// Tournament states
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+)
+
+// Brackets a pairing is played in
+const (
+	BracketWinners = "winners"
+	BracketLosers  = "losers"
+	BracketFinal   = "final"
+	BracketSwiss   = "swiss"
+)
+
+// Pairing states: a running pairing waits for its match, a done one has its
+// result. Byes are done as soon as they are paired.
+const (
+	PairingRunning = "running"
+	PairingDone    = "done"
+)
+
+// MinEntrants is how many fighters a tournament needs.
+const MinEntrants = 2
+
+type Tournament struct {
+	ID       string
+	LeagueID int
+	Format   Format
+	Status   string
+	// Round is the round being played, 0 before the first one.
+	Round int
+	// Rounds is the number of rounds of a Swiss tournament, 0 otherwise.
+	Rounds    int
+	Created   time.Time
+	Completed *time.Time
+}
+
+// Entrant is a fighter taking part in a tournament.
+type Entrant struct {
+	TournamentID string
+	FighterID    string
+	UserID       int64
+	// Seed is the fighter's rank by rating when the tournament started, 1
+	// for the best rated one.
+	Seed   int
+	Rating float64
+	Wins   int
+	Losses int
+	Draws  int
+	Byes   int
+	// Eliminated is the round the fighter was knocked out in, 0 while they
+	// are in.
+	Eliminated int
+	// Placement is the final position, set when the tournament completed.
+	Placement int
+	// Members are the fighters of the entrant's team, the entrant first, as
+	// they were when the tournament started.
+	Members []string
+}
+
+// Team returns the fighters that fight for the entrant. Entrants stored
+// without members fight on their own.
+func (e Entrant) Team() []string {
+	if len(e.Members) == 0 {
+		return []string{e.FighterID}
+	}
+	return e.Members
+}
+
+// Score is a Swiss score: a point for every win or bye and half a point for
+// every draw.
+func (e Entrant) Score() float64 {
+	return float64(e.Wins+e.Byes) + 0.5*float64(e.Draws)
+}
+
+// Pairing is one match of a round. A pairing without FighterB is a bye.
+type Pairing struct {
+	ID           string
+	TournamentID string
+	Round        int
+	Bracket      string
+	Slot         int
+	FighterA     string
+	FighterB     *string
+	MatchID      *string
+	WinnerID     *string
+	Status       string
+}
+
+// IsBye reports whether the pairing is a bye.
+func (p Pairing) IsBye() bool {
+	return p.FighterB == nil
+}
+
+// Opponent returns the other fighter of the pairing, or "" for a bye or a
+// fighter not in it.
+func (p Pairing) Opponent(fighterID string) string {
+	switch {
+	case p.FighterB == nil:
+		return ""
+	case p.FighterA == fighterID:
+		return *p.FighterB
+	case *p.FighterB == fighterID:
+		return p.FighterA
+	}
+	return ""
+}
+
+// RewardPool is the reward pool of a final placement, "" for placements
+// without a reward.
+func RewardPool(placement int) string {
+	switch placement {
+	case 1:
+		return "tournament_first"
+	case 2:
+		return "tournament_second"
+	case 3:
+		return "tournament_third"
+	}
+	return ""
+}
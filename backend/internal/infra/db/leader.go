@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LeaderLock elects one leader among the instances sharing a database. The
+// leader holds a session advisory lock on a connection of its own, so the
+// lead passes on once its process or connection dies.
+type LeaderLock struct {
+	pool *pgxpool.Pool
+	key  int64
+	mu   sync.Mutex
+	conn *pgxpool.Conn
+}
+
+// NewLeaderLock returns the lock of the election called name.
+func NewLeaderLock(pool *pgxpool.Pool, name string) *LeaderLock {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return &LeaderLock{pool: pool, key: int64(h.Sum64())}
+}
+
+// Leader reports whether this instance leads, taking the lock when it is
+// free. A leader whose connection broke loses the lead.
+func (l *LeaderLock) Leader(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.Ping(ctx); err == nil {
+			return true, nil
+		}
+		l.drop()
+	}
+
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	var locked bool
+	if err := conn.QueryRow(ctx, `select pg_try_advisory_lock($1)`, l.key).Scan(&locked); err != nil || !locked {
+		conn.Release()
+		return false, err
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Resign gives up the lead.
+func (l *LeaderLock) Resign(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	if _, err := l.conn.Exec(ctx, `select pg_advisory_unlock($1)`, l.key); err != nil {
+		l.drop()
+		return err
+	}
+	l.conn.Release()
+	l.conn = nil
+	return nil
+}
+
+// drop closes the leader's connection, which releases the lock if the
+// server still holds it.
+func (l *LeaderLock) drop() {
+	_ = l.conn.Conn().Close(context.Background())
+	l.conn.Release()
+	l.conn = nil
+}
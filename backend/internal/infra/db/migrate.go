@@ -8,13 +8,85 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ApplyMigrations applies the migrations of dir the database hasn't applied
+// yet, in file name order, and records them in schema_migrations. Every
+// migration runs in a transaction with its record.
 func ApplyMigrations(ctx context.Context, pool *pgxpool.Pool, dir string) error {
+	files, err := migrationFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	const createTable = `
+		create table if not exists schema_migrations (
+			name text primary key,
+			applied timestamptz not null default now()
+		)`
+	if _, err := pool.Exec(ctx, createTable); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	applied, err := appliedMigrations(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		if applied[name] {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read migration %s: %w", file, err)
+		}
+		sql := strings.TrimSpace(string(data))
+
+		err = pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+			if sql != "" {
+				if _, err := tx.Exec(ctx, sql); err != nil {
+					return err
+				}
+			}
+			_, err := tx.Exec(ctx, `insert into schema_migrations (name) values ($1) on conflict (name) do nothing`, name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// PendingMigrations returns the names of the migrations of dir the database
+// hasn't applied.
+func PendingMigrations(ctx context.Context, pool *pgxpool.Pool, dir string) ([]string, error) {
+	files, err := migrationFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, file := range files {
+		if name := filepath.Base(file); !applied[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+func migrationFiles(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return fmt.Errorf("read migrations dir: %w", err)
+		return nil, fmt.Errorf("read migrations dir: %w", err)
 	}
 
 	var files []string
@@ -29,20 +101,23 @@ func ApplyMigrations(ctx context.Context, pool *pgxpool.Pool, dir string) error
 		files = append(files, filepath.Join(dir, name))
 	}
 	sort.Strings(files)
+	return files, nil
+}
 
-	for _, file := range files {
-		data, err := os.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("read migration %s: %w", file, err)
-		}
-		sql := strings.TrimSpace(string(data))
-		if sql == "" {
-			continue
-		}
-		if _, err := pool.Exec(ctx, sql); err != nil {
-			return fmt.Errorf("apply migration %s: %w", file, err)
-		}
+func appliedMigrations(ctx context.Context, pool *pgxpool.Pool) (map[string]bool, error) {
+	rows, err := pool.Query(ctx, `select name from schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
 	}
+	defer rows.Close()
 
-	return nil
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied[name] = true
+	}
+	return applied, rows.Err()
 }
@@ -0,0 +1,4 @@
+-- Deterministic replays: every match result keeps the seed and the exact
+-- simulator input it was produced from.
+ALTER TABLE match_results ADD COLUMN IF NOT EXISTS seed BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE match_results ADD COLUMN IF NOT EXISTS battle_input JSONB NULL;
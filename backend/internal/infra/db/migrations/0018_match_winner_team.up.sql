@@ -0,0 +1,2 @@
+-- Team matches: remember which team won
+ALTER TABLE match_results ADD COLUMN IF NOT EXISTS winner_team_id UUID NULL REFERENCES match_teams(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+-- Bots are synthetic fighters without a row in fighters, so their score rows
+-- cannot reference it.
+ALTER TABLE match_score_fighters DROP CONSTRAINT IF EXISTS match_score_fighters_fighter_id_fkey;
+ALTER TABLE match_score_fighters ADD COLUMN IF NOT EXISTS is_bot BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,8 @@
+-- Skill tree allocations, active loadout and carried-over ultimate charge
+CREATE TABLE IF NOT EXISTS fighter_skills (
+    fighter_id UUID PRIMARY KEY REFERENCES fighters(id) ON DELETE CASCADE,
+    allocated_points JSONB NOT NULL DEFAULT '{}',
+    loadout JSONB NOT NULL DEFAULT '[]',
+    ultimate_charge INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
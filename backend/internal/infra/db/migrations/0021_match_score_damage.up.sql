@@ -0,0 +1,3 @@
+ALTER TABLE match_score_fighters ADD COLUMN IF NOT EXISTS total_damage_dealt INT NOT NULL DEFAULT 0;
+ALTER TABLE match_score_fighters ADD COLUMN IF NOT EXISTS total_damage_taken INT NOT NULL DEFAULT 0;
+ALTER TABLE match_score_fighters ADD COLUMN IF NOT EXISTS total_healed INT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Every fighter a combat log entry refers to, for per-fighter queries
+ALTER TABLE combat_logs ADD COLUMN IF NOT EXISTS fighter_ids TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_combat_logs_match_round ON combat_logs(match_id, round, tick);
+CREATE INDEX IF NOT EXISTS idx_combat_logs_fighter_ids ON combat_logs USING GIN (fighter_ids);
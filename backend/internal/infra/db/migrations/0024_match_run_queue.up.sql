@@ -0,0 +1,19 @@
+-- Persistent queue of match executions, claimed by workers with SKIP LOCKED
+CREATE TABLE IF NOT EXISTS match_runs (
+    id UUID PRIMARY KEY,
+    match_id UUID NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    last_error TEXT NULL,
+    available_at TIMESTAMPTZ NOT NULL,
+    enqueued_at TIMESTAMPTZ NOT NULL,
+    started_at TIMESTAMPTZ NULL,
+    finished_at TIMESTAMPTZ NULL,
+    lease_until TIMESTAMPTZ NULL
+);
+
+-- A match is queued at most once at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_match_runs_active_match ON match_runs(match_id) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_match_runs_pending ON match_runs(available_at, enqueued_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_match_runs_lease ON match_runs(lease_until) WHERE status = 'running';
@@ -0,0 +1,5 @@
+-- One row per settled match; claiming it makes settlement happen once
+CREATE TABLE IF NOT EXISTS match_settlements (
+    match_id UUID PRIMARY KEY REFERENCES matches(id) ON DELETE CASCADE,
+    settled_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,12 @@
+-- Every level up of a fighter with the stats it gained
+CREATE TABLE IF NOT EXISTS fighter_level_history (
+    id BIGSERIAL PRIMARY KEY,
+    fighter_id UUID NOT NULL REFERENCES fighters(id) ON DELETE CASCADE,
+    from_level INTEGER NOT NULL,
+    to_level INTEGER NOT NULL,
+    experience INTEGER NOT NULL,
+    gains JSONB NOT NULL DEFAULT '{}',
+    created TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fighter_level_history_fighter ON fighter_level_history(fighter_id, created DESC);
@@ -0,0 +1,18 @@
+-- Attribute points a fighter spent, so a respec knows what to take back
+CREATE TABLE IF NOT EXISTS fighter_attribute_allocations (
+    fighter_id UUID PRIMARY KEY REFERENCES fighters(id) ON DELETE CASCADE,
+    power INTEGER NOT NULL DEFAULT 0,
+    condition_power INTEGER NOT NULL DEFAULT 0,
+    precision INTEGER NOT NULL DEFAULT 0,
+    ferocity INTEGER NOT NULL DEFAULT 0,
+    accuracy INTEGER NOT NULL DEFAULT 0,
+    agility INTEGER NOT NULL DEFAULT 0,
+    armor INTEGER NOT NULL DEFAULT 0,
+    vitality INTEGER NOT NULL DEFAULT 0,
+    parry_chance INTEGER NOT NULL DEFAULT 0,
+    healing_power INTEGER NOT NULL DEFAULT 0,
+    speed INTEGER NOT NULL DEFAULT 0,
+    vision INTEGER NOT NULL DEFAULT 0,
+    respecs INTEGER NOT NULL DEFAULT 0,
+    updated TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,29 @@
+-- Glicko-2 rating of every fighter with rated matches
+CREATE TABLE IF NOT EXISTS fighter_ratings (
+    fighter_id UUID PRIMARY KEY REFERENCES fighters(id) ON DELETE CASCADE,
+    rating DOUBLE PRECISION NOT NULL DEFAULT 1500,
+    deviation DOUBLE PRECISION NOT NULL DEFAULT 350,
+    volatility DOUBLE PRECISION NOT NULL DEFAULT 0.06,
+    matches INTEGER NOT NULL DEFAULT 0,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    updated TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fighter_ratings_rating ON fighter_ratings(rating DESC);
+
+-- The latest matchmaking ticket of every fighter
+CREATE TABLE IF NOT EXISTS matchmaking_tickets (
+    fighter_id UUID PRIMARY KEY REFERENCES fighters(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    rating DOUBLE PRECISION NOT NULL,
+    power INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'waiting',
+    queued_at TIMESTAMPTZ NOT NULL,
+    match_id UUID NULL REFERENCES matches(id) ON DELETE SET NULL,
+    matched_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_matchmaking_tickets_waiting ON matchmaking_tickets(queued_at) WHERE status = 'waiting';
+CREATE INDEX IF NOT EXISTS idx_matchmaking_tickets_user ON matchmaking_tickets(user_id, queued_at DESC);
+CREATE INDEX IF NOT EXISTS idx_matchmaking_tickets_matched ON matchmaking_tickets(matched_at) WHERE status = 'matched';
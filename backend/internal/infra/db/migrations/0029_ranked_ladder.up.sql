@@ -0,0 +1,20 @@
+-- Seasons roll over once; completed marks the ones that did
+ALTER TABLE seasons ADD COLUMN IF NOT EXISTS completed TIMESTAMPTZ NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_seasons_season_id ON seasons(season_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_season_summaries_user_season ON season_summaries(user_id, season_id);
+
+-- Place of every ranked fighter on the ladder of the current season
+CREATE TABLE IF NOT EXISTS ladder_entries (
+    fighter_id UUID PRIMARY KEY REFERENCES fighters(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    division TEXT NOT NULL,
+    series TEXT NULL,
+    series_wins INTEGER NOT NULL DEFAULT 0,
+    series_losses INTEGER NOT NULL DEFAULT 0,
+    last_match TIMESTAMPTZ NOT NULL,
+    decayed TIMESTAMPTZ NULL,
+    updated TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ladder_entries_last_match ON ladder_entries(last_match);
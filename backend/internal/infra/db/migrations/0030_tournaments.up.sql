@@ -0,0 +1,47 @@
+-- Tournaments between the fighters subscribed to a league
+CREATE TABLE IF NOT EXISTS tournaments (
+    id UUID PRIMARY KEY,
+    league_id INTEGER NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+    format TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running',
+    round INTEGER NOT NULL DEFAULT 0,
+    rounds INTEGER NOT NULL DEFAULT 0,
+    created TIMESTAMPTZ NOT NULL,
+    completed TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_league ON tournaments(league_id, created DESC);
+-- A league runs one tournament at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tournaments_league_running ON tournaments(league_id) WHERE status = 'running';
+
+CREATE TABLE IF NOT EXISTS tournament_entrants (
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    fighter_id UUID NOT NULL REFERENCES fighters(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    seed INTEGER NOT NULL,
+    rating DOUBLE PRECISION NOT NULL,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    draws INTEGER NOT NULL DEFAULT 0,
+    byes INTEGER NOT NULL DEFAULT 0,
+    eliminated INTEGER NOT NULL DEFAULT 0,
+    placement INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (tournament_id, fighter_id)
+);
+
+CREATE TABLE IF NOT EXISTS tournament_pairings (
+    id UUID PRIMARY KEY,
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL,
+    bracket TEXT NOT NULL,
+    slot INTEGER NOT NULL,
+    fighter_a UUID NOT NULL,
+    fighter_b UUID NULL,
+    match_id UUID NULL REFERENCES matches(id) ON DELETE SET NULL,
+    winner_id UUID NULL,
+    status TEXT NOT NULL,
+    UNIQUE (tournament_id, round, bracket, slot)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tournament_pairings_match ON tournament_pairings(match_id) WHERE match_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tournament_pairings_running ON tournament_pairings(tournament_id) WHERE status = 'running';
@@ -0,0 +1,2 @@
+-- When a league's schedule last came due; runs are claimed by moving it
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS last_run TIMESTAMPTZ NULL;
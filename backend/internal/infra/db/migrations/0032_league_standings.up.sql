@@ -0,0 +1,40 @@
+-- When the result of a league match was added to the standings; matches are
+-- scored once by claiming it
+ALTER TABLE league_matches ADD COLUMN IF NOT EXISTS scored TIMESTAMPTZ NULL;
+
+-- Points of every fighter in a league per ranked season; season 0 holds the
+-- matches played while no season ran
+CREATE TABLE IF NOT EXISTS league_standings (
+    league_id INTEGER NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+    season INTEGER NOT NULL,
+    fighter_id UUID NOT NULL REFERENCES fighters(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    points INTEGER NOT NULL DEFAULT 0,
+    matches INTEGER NOT NULL DEFAULT 0,
+    wins INTEGER NOT NULL DEFAULT 0,
+    kills INTEGER NOT NULL DEFAULT 0,
+    deaths INTEGER NOT NULL DEFAULT 0,
+    damage_dealt INTEGER NOT NULL DEFAULT 0,
+    updated TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (league_id, season, fighter_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_league_standings_season_user ON league_standings(season, user_id);
+
+-- What every fighter scored in a league match, for the highscores over the
+-- latest matches
+CREATE TABLE IF NOT EXISTS league_match_results (
+    league_id INTEGER NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+    match_id UUID NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    fighter_id UUID NOT NULL REFERENCES fighters(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    placement INTEGER NOT NULL,
+    won BOOLEAN NOT NULL DEFAULT FALSE,
+    kills INTEGER NOT NULL DEFAULT 0,
+    deaths INTEGER NOT NULL DEFAULT 0,
+    damage_dealt INTEGER NOT NULL DEFAULT 0,
+    points INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (league_id, match_id, fighter_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_league_matches_scored ON league_matches(league_id, scored DESC);
@@ -0,0 +1,13 @@
+-- History of the background job runs of every instance; each job keeps its
+-- latest runs
+CREATE TABLE IF NOT EXISTS job_runs (
+    id BIGSERIAL PRIMARY KEY,
+    job TEXT NOT NULL,
+    trigger TEXT NOT NULL,
+    instance TEXT NOT NULL,
+    started TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    error TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job ON job_runs(job, id DESC);
@@ -0,0 +1,5 @@
+-- teamSize used to cap the fighters of one user; it now sizes the teams of
+-- league matches and the cap is stored as maxFightersPerUser
+UPDATE leagues
+SET options = (options - 'teamSize') || jsonb_build_object('maxFightersPerUser', options->'teamSize')
+WHERE options ? 'teamSize';
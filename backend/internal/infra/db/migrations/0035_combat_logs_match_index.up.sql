@@ -0,0 +1,3 @@
+-- The match index of combat_logs, created again safely for databases where
+-- 0015 didn't get to it
+CREATE INDEX IF NOT EXISTS idx_combat_logs_match_id ON combat_logs(match_id);
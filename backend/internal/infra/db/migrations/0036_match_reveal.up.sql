@@ -0,0 +1,3 @@
+-- When the outcome of a match becomes public; live matches keep it until
+-- their stream ends
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS revealed_at TIMESTAMPTZ;
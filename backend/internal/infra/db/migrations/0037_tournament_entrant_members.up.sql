@@ -0,0 +1,2 @@
+-- The team every entrant fights with, saved when the tournament starts
+ALTER TABLE tournament_entrants ADD COLUMN IF NOT EXISTS members TEXT[] NOT NULL DEFAULT '{}';
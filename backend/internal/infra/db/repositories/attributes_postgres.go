@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AttributeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAttributeRepository(pool *pgxpool.Pool) *AttributeRepository {
+	return &AttributeRepository{pool: pool}
+}
+
+const attributeColumns = `fighter_id, power, condition_power, precision, ferocity, accuracy, agility, armor, vitality, parry_chance, healing_power, speed, vision, respecs, updated`
+
+func scanAllocation(row pgx.Row) (*roster.AttributeAllocation, error) {
+	var allocation roster.AttributeAllocation
+	points := &allocation.Points
+	err := row.Scan(
+		&allocation.FighterID,
+		&points.Power, &points.ConditionPower, &points.Precision, &points.Ferocity,
+		&points.Accuracy, &points.Agility, &points.Armor, &points.Vitality,
+		&points.ParryChance, &points.HealingPower, &points.Speed, &points.Vision,
+		&allocation.Respecs, &allocation.Updated,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &allocation, nil
+}
+
+func (r *AttributeRepository) Get(ctx context.Context, fighterID string) (*roster.AttributeAllocation, error) {
+	query := `select ` + attributeColumns + ` from fighter_attribute_allocations where fighter_id = $1`
+	return scanAllocation(db.Conn(ctx, r.pool).QueryRow(ctx, query, fighterID))
+}
+
+// Lock returns the fighter's allocation and locks it until the surrounding
+// unit of work ends, creating an empty one first if the fighter has none.
+func (r *AttributeRepository) Lock(ctx context.Context, fighterID string) (*roster.AttributeAllocation, error) {
+	const insert = `
+		insert into fighter_attribute_allocations (fighter_id)
+		values ($1)
+		on conflict (fighter_id) do nothing`
+
+	conn := db.Conn(ctx, r.pool)
+	if _, err := conn.Exec(ctx, insert, fighterID); err != nil {
+		return nil, err
+	}
+	query := `select ` + attributeColumns + ` from fighter_attribute_allocations where fighter_id = $1 for update`
+	return scanAllocation(conn.QueryRow(ctx, query, fighterID))
+}
+
+func (r *AttributeRepository) Save(ctx context.Context, allocation *roster.AttributeAllocation) error {
+	const query = `
+		insert into fighter_attribute_allocations (` + attributeColumns + `)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		on conflict (fighter_id) do update
+		set power = excluded.power, condition_power = excluded.condition_power,
+		    precision = excluded.precision, ferocity = excluded.ferocity,
+		    accuracy = excluded.accuracy, agility = excluded.agility,
+		    armor = excluded.armor, vitality = excluded.vitality,
+		    parry_chance = excluded.parry_chance, healing_power = excluded.healing_power,
+		    speed = excluded.speed, vision = excluded.vision,
+		    respecs = excluded.respecs, updated = excluded.updated`
+
+	points := allocation.Points
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
+		allocation.FighterID,
+		points.Power, points.ConditionPower, points.Precision, points.Ferocity,
+		points.Accuracy, points.Agility, points.Armor, points.Vitality,
+		points.ParryChance, points.HealingPower, points.Speed, points.Vision,
+		allocation.Respecs, allocation.Updated,
+	)
+	return err
+}
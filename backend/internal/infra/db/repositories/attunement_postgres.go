@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"empoweredpixels/internal/domain/attunement"
+	"empoweredpixels/internal/infra/db"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -29,7 +30,7 @@ func (r *AttunementPostgres) GetPlayerAttunements(ctx context.Context, userID in
 		ORDER BY element ASC
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query attunements: %w", err)
 	}
@@ -56,7 +57,7 @@ func (r *AttunementPostgres) GetAttunement(ctx context.Context, userID int, elem
 	`
 
 	var a attunement.Attunement
-	err := r.pool.QueryRow(ctx, query, userID, string(element)).Scan(
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, userID, string(element)).Scan(
 		&a.Element, &a.Level, &a.CurrentXP, &a.TotalXP,
 	)
 	if err != nil {
@@ -71,7 +72,7 @@ func (r *AttunementPostgres) GetAttunement(ctx context.Context, userID int, elem
 
 // AddXP adds XP to an element and handles level-ups
 func (r *AttunementPostgres) AddXP(ctx context.Context, userID int, element attunement.Element, xpAmount int, source string) (levelUp bool, newLevel int, err error) {
-	tx, err := r.pool.Begin(ctx)
+	tx, err := db.Conn(ctx, r.pool).Begin(ctx)
 	if err != nil {
 		return false, 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -162,7 +163,7 @@ func (r *AttunementPostgres) CreateInitialAttunements(ctx context.Context, userI
 	`
 
 	for _, element := range elements {
-		_, err := r.pool.Exec(ctx, query, userID, string(element))
+		_, err := db.Conn(ctx, r.pool).Exec(ctx, query, userID, string(element))
 		if err != nil {
 			return fmt.Errorf("failed to create attunement for %s: %w", element, err)
 		}
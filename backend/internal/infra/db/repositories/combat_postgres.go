@@ -3,8 +3,13 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 
 	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/infra/db"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -17,19 +22,25 @@ func NewCombatRepository(pool *pgxpool.Pool) *CombatRepository {
 	return &CombatRepository{pool: pool}
 }
 
+// SaveLogs replaces the combat log of a match with one row per tick.
 func (r *CombatRepository) SaveLogs(ctx context.Context, matchID string, roundTicks []combat.RoundTick) error {
 	const query = `
-		insert into combat_logs (match_id, round, tick, event_type, payload)
-		values ($1, $2, $3, $4, $5)`
+		insert into combat_logs (match_id, round, tick, event_type, payload, fighter_ids)
+		values ($1, $2, $3, $4, $5, $6)`
 
 	batch := &pgx.Batch{}
+	batch.Queue(`delete from combat_logs where match_id = $1`, matchID)
 	for _, rt := range roundTicks {
 		for i, tick := range rt.Ticks {
-			batch.Queue(query, matchID, rt.Round, i, tick.Type, tick.Payload)
+			fighterIDs := tick.FighterIDs()
+			if fighterIDs == nil {
+				fighterIDs = []string{}
+			}
+			batch.Queue(query, matchID, rt.Round, i, tick.Type, tick.Payload, fighterIDs)
 		}
 	}
 
-	br := r.pool.SendBatch(ctx, batch)
+	br := db.Conn(ctx, r.pool).SendBatch(ctx, batch)
 	defer br.Close()
 
 	for i := 0; i < batch.Len(); i++ {
@@ -41,19 +52,59 @@ func (r *CombatRepository) SaveLogs(ctx context.Context, matchID string, roundTi
 	return nil
 }
 
-type BattleSummary struct {
-	WinnerID    *string               `json:"winnerId"`
-	TotalRounds int                   `json:"totalRounds"`
-	Scores      []combat.FighterScore `json:"scores"`
+// ListLogs returns a page of a match's combat log in battle order, together
+// with the number of entries matching the filter.
+func (r *CombatRepository) ListLogs(ctx context.Context, matchID string, filter matches.CombatLogFilter) ([]matches.CombatLogEntry, int, error) {
+	conditions := []string{"match_id = $1"}
+	args := []any{matchID}
+	addCondition := func(format string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+	if filter.FighterID != "" {
+		addCondition("fighter_ids @> array[$%d]::text[]", filter.FighterID)
+	}
+	if len(filter.EventTypes) > 0 {
+		addCondition("event_type = any($%d)", filter.EventTypes)
+	}
+	if filter.FromRound != nil {
+		addCondition("round >= $%d", *filter.FromRound)
+	}
+	if filter.ToRound != nil {
+		addCondition("round <= $%d", *filter.ToRound)
+	}
+	args = append(args, filter.Limit, filter.Offset)
+
+	query := fmt.Sprintf(`
+		select match_id, round, tick, event_type, payload, count(*) over ()
+		from combat_logs
+		where %s
+		order by round, tick
+		limit $%d offset $%d`, strings.Join(conditions, " and "), len(args)-1, len(args))
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []matches.CombatLogEntry
+	total := 0
+	for rows.Next() {
+		var entry matches.CombatLogEntry
+		if err := rows.Scan(&entry.MatchID, &entry.Round, &entry.Tick, &entry.EventType, &entry.Payload, &total); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
 }
 
-func (r *CombatRepository) SaveSummary(ctx context.Context, matchID string, winnerID *string, totalRounds int, scores []combat.FighterScore) error {
-	summary := BattleSummary{
-		WinnerID:    winnerID,
-		TotalRounds: totalRounds,
-		Scores:      scores,
+func (r *CombatRepository) SaveSummary(ctx context.Context, summary *matches.BattleSummary) error {
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return err
 	}
-	summaryJSON, _ := json.Marshal(summary)
 
 	const query = `
 		insert into battle_details (match_id, winner_id, total_rounds, summary)
@@ -63,6 +114,28 @@ func (r *CombatRepository) SaveSummary(ctx context.Context, matchID string, winn
 			total_rounds = excluded.total_rounds,
 			summary = excluded.summary`
 
-	_, err := r.pool.Exec(ctx, query, matchID, winnerID, totalRounds, summaryJSON)
+	_, err = db.Conn(ctx, r.pool).Exec(ctx, query, summary.MatchID, summary.WinnerID, summary.TotalRounds, summaryJSON)
 	return err
 }
+
+func (r *CombatRepository) GetSummary(ctx context.Context, matchID string) (*matches.BattleSummary, error) {
+	const query = `
+		select summary
+		from battle_details
+		where match_id = $1`
+
+	var summaryJSON []byte
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, matchID).Scan(&summaryJSON)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var summary matches.BattleSummary
+	if err := json.Unmarshal(summaryJSON, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
@@ -16,6 +16,7 @@ type DailyRewardRepository interface {
 	GetUserDailyReward(ctx context.Context, userID int) (*daily.UserDailyReward, error)
 	ClaimReward(ctx context.Context, userID int, streak int) error
 	ResetStreak(ctx context.Context, userID int) error
+	ListClaimable(ctx context.Context) ([]daily.UserDailyReward, error)
 }
 
 // DailyRewardPostgres implements DailyRewardRepository
@@ -119,6 +120,32 @@ func (r *DailyRewardPostgres) ResetStreak(ctx context.Context, userID int) error
 	return nil
 }
 
+// ListClaimable retrieves every user who claimed before but not yet today
+func (r *DailyRewardPostgres) ListClaimable(ctx context.Context) ([]daily.UserDailyReward, error) {
+	query := `
+		SELECT user_id, streak, last_claimed, total_claimed, updated
+		FROM daily_rewards
+		WHERE last_claimed < CURRENT_DATE
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list claimable rewards: %w", err)
+	}
+	defer rows.Close()
+
+	var result []daily.UserDailyReward
+	for rows.Next() {
+		var dr daily.UserDailyReward
+		if err := rows.Scan(&dr.UserID, &dr.Streak, &dr.LastClaimed, &dr.TotalClaimed, &dr.Updated); err != nil {
+			return nil, fmt.Errorf("failed to scan claimable reward: %w", err)
+		}
+		dr.CanClaim = true
+		result = append(result, dr)
+	}
+	return result, rows.Err()
+}
+
 // formatDuration formats duration as HH:MM:SS
 func formatDuration(d time.Duration) string {
 	hours := int(d.Hours())
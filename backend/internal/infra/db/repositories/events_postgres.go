@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"empoweredpixels/internal/domain/events"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -45,6 +46,55 @@ func (r *EventRepository) GetActiveEvents(ctx context.Context) ([]events.ActiveE
 	return result, nil
 }
 
+// ListScheduledEvents returns the enabled weekend event definitions.
+func (r *EventRepository) ListScheduledEvents(ctx context.Context) ([]events.WeekendEvent, error) {
+	query := `
+		SELECT id, name, description, event_type, multiplier, start_day, end_day, start_hour, end_hour, is_active, created_at
+		FROM weekend_events
+		WHERE is_active = true
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []events.WeekendEvent
+	for rows.Next() {
+		var e events.WeekendEvent
+		if err := rows.Scan(&e.ID, &e.Name, &e.Description, &e.EventType, &e.Multiplier,
+			&e.StartDay, &e.EndDay, &e.StartHour, &e.EndHour, &e.IsActive, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// StartEvent activates the event from startedAt until endsAt unless it is
+// already active then. It returns nil when nothing was started.
+func (r *EventRepository) StartEvent(ctx context.Context, event events.WeekendEvent, startedAt time.Time, endsAt time.Time) (*events.ActiveEvent, error) {
+	query := `
+		INSERT INTO active_events (event_id, started_at, ends_at, is_active)
+		SELECT $1::uuid, $2::timestamptz, $3::timestamptz, true
+		WHERE NOT EXISTS (
+			SELECT 1 FROM active_events
+			WHERE event_id = $1::uuid AND is_active = true AND ends_at > $2::timestamptz
+		)
+		RETURNING id, event_id, started_at, ends_at, is_active
+	`
+	var ae events.ActiveEvent
+	err := r.db.QueryRow(ctx, query, event.ID, startedAt, endsAt).Scan(&ae.ID, &ae.EventID, &ae.StartedAt, &ae.EndsAt, &ae.IsActive)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ae.Event = &event
+	return &ae, nil
+}
+
 func (r *EventRepository) GetStatus(ctx context.Context) (*events.EventStatus, error) {
 	active, err := r.GetActiveEvents(ctx)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"empoweredpixels/internal/domain/inventory"
+	"empoweredpixels/internal/infra/db"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -40,7 +41,7 @@ func (r *ItemRepository) CountByUserAndItemID(ctx context.Context, userID int64,
 		where user_id = $1 and item_id = $2`
 
 	var count int
-	err := r.pool.QueryRow(ctx, query, userID, itemID).Scan(&count)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, userID, itemID).Scan(&count)
 	return count, err
 }
 
@@ -54,7 +55,7 @@ func (r *ItemRepository) CreateMany(ctx context.Context, items []inventory.Item)
 		batch.Queue(query, item.ID, item.UserID, item.ItemID, item.Rarity, item.Created)
 	}
 
-	br := r.pool.SendBatch(ctx, batch)
+	br := db.Conn(ctx, r.pool).SendBatch(ctx, batch)
 	defer br.Close()
 	_, err := br.Exec()
 	return err
@@ -69,7 +70,7 @@ func (r *ItemRepository) DeleteByUserAndItemID(ctx context.Context, userID int64
 			limit $3
 		)`
 
-	result, err := r.pool.Exec(ctx, query, userID, itemID, limit)
+	result, err := db.Conn(ctx, r.pool).Exec(ctx, query, userID, itemID, limit)
 	if err != nil {
 		return 0, err
 	}
@@ -83,7 +84,7 @@ func (r *EquipmentRepository) GetByID(ctx context.Context, userID int64, id stri
 		where id = $1 and user_id = $2`
 
 	var equip inventory.Equipment
-	err := r.pool.QueryRow(ctx, query, id, userID).Scan(
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, id, userID).Scan(
 		&equip.ID, &equip.UserID, &equip.FighterID, &equip.ItemID, &equip.Level, &equip.Rarity, &equip.Enhancement, &equip.Created,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -103,7 +104,7 @@ func (r *EquipmentRepository) ListInventory(ctx context.Context, userID int64, l
 		order by level desc, rarity desc
 		limit $2 offset $3`
 
-	rows, err := r.pool.Query(ctx, query, userID, limit, offset)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +129,7 @@ func (r *EquipmentRepository) ListInventoryAll(ctx context.Context, userID int64
 		from equipment
 		where user_id = $1 and fighter_id is null`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +155,7 @@ func (r *EquipmentRepository) ListByFighter(ctx context.Context, userID int64, f
 		where user_id = $1 and fighter_id = $2
 		order by item_id`
 
-	rows, err := r.pool.Query(ctx, query, userID, fighterID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, userID, fighterID)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +180,7 @@ func (r *EquipmentRepository) UpdateEnhancement(ctx context.Context, equipmentID
 		set enhancement = $1
 		where id = $2`
 
-	_, err := r.pool.Exec(ctx, query, enhancement, equipmentID)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, enhancement, equipmentID)
 	return err
 }
 
@@ -189,7 +190,7 @@ func (r *EquipmentRepository) UpdateFighter(ctx context.Context, equipmentID str
 		set fighter_id = $1
 		where id = $2`
 
-	_, err := r.pool.Exec(ctx, query, fighterID, equipmentID)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, fighterID, equipmentID)
 	return err
 }
 
@@ -198,7 +199,7 @@ func (r *EquipmentRepository) Delete(ctx context.Context, equipmentID string) er
 		delete from equipment
 		where id = $1`
 
-	_, err := r.pool.Exec(ctx, query, equipmentID)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, equipmentID)
 	return err
 }
 
@@ -207,7 +208,7 @@ func (r *EquipmentRepository) Create(ctx context.Context, equipment *inventory.E
 		insert into equipment (id, user_id, fighter_id, item_id, level, rarity, enhancement, created)
 		values ($1, $2, $3, $4, $5, $6, $7, $8)`
 
-	_, err := r.pool.Exec(ctx, query,
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
 		equipment.ID,
 		equipment.UserID,
 		equipment.FighterID,
@@ -227,7 +228,7 @@ func (r *EquipmentOptionRepository) GetByEquipmentID(ctx context.Context, equipm
 		where equipment_id = $1`
 
 	var option inventory.EquipmentOption
-	err := r.pool.QueryRow(ctx, query, equipmentID).Scan(&option.EquipmentID, &option.IsFavorite)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, equipmentID).Scan(&option.EquipmentID, &option.IsFavorite)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -244,6 +245,6 @@ func (r *EquipmentOptionRepository) Upsert(ctx context.Context, option *inventor
 		on conflict (equipment_id)
 		do update set is_favorite = excluded.is_favorite`
 
-	_, err := r.pool.Exec(ctx, query, option.EquipmentID, option.IsFavorite)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, option.EquipmentID, option.IsFavorite)
 	return err
 }
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"empoweredpixels/internal/domain/jobs"
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// JobRunHistory is how many runs of every job are kept.
+const JobRunHistory = 100
+
+type JobRunRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewJobRunRepository(pool *pgxpool.Pool) *JobRunRepository {
+	return &JobRunRepository{pool: pool}
+}
+
+const jobRunColumns = `id, job, trigger, instance, started, duration_ms, coalesce(error, '')`
+
+func scanJobRuns(rows pgx.Rows) ([]jobs.Run, error) {
+	defer rows.Close()
+
+	var result []jobs.Run
+	for rows.Next() {
+		var run jobs.Run
+		var durationMS int64
+		if err := rows.Scan(&run.ID, &run.Job, &run.Trigger, &run.Instance, &run.Started, &durationMS, &run.Error); err != nil {
+			return nil, err
+		}
+		run.Duration = time.Duration(durationMS) * time.Millisecond
+		result = append(result, run)
+	}
+	return result, rows.Err()
+}
+
+// Create records a run and drops the runs of its job beyond the history.
+func (r *JobRunRepository) Create(ctx context.Context, run *jobs.Run) error {
+	const insert = `
+		insert into job_runs (job, trigger, instance, started, duration_ms, error)
+		values ($1, $2, $3, $4, $5, nullif($6, ''))
+		returning id`
+	const prune = `
+		delete from job_runs
+		where job = $1 and id <= (
+			select id from job_runs where job = $1 order by id desc offset $2 limit 1
+		)`
+
+	conn := db.Conn(ctx, r.pool)
+	err := conn.QueryRow(ctx, insert,
+		run.Job, run.Trigger, run.Instance, run.Started, run.Duration.Milliseconds(), run.Error,
+	).Scan(&run.ID)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Exec(ctx, prune, run.Job, JobRunHistory)
+	return err
+}
+
+// ListByJob returns the latest runs of a job, latest first.
+func (r *JobRunRepository) ListByJob(ctx context.Context, job string, limit int) ([]jobs.Run, error) {
+	query := `select ` + jobRunColumns + ` from job_runs where job = $1 order by id desc limit $2`
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, job, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanJobRuns(rows)
+}
+
+// Latest returns the latest run of every job, whichever instance ran it.
+func (r *JobRunRepository) Latest(ctx context.Context) ([]jobs.Run, error) {
+	query := `select distinct on (job) ` + jobRunColumns + ` from job_runs order by job, id desc`
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return scanJobRuns(rows)
+}
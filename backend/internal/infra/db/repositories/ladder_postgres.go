@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"empoweredpixels/internal/domain/seasons"
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LadderRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLadderRepository(pool *pgxpool.Pool) *LadderRepository {
+	return &LadderRepository{pool: pool}
+}
+
+const placementColumns = `
+	l.fighter_id, l.user_id, l.division, l.series, l.series_wins, l.series_losses, l.last_match, l.decayed, l.updated,
+	r.fighter_id, r.rating, r.deviation, r.volatility, r.matches, r.wins, r.losses, r.updated`
+
+const placementTables = `ladder_entries l join fighter_ratings r on r.fighter_id = l.fighter_id`
+
+func scanPlacement(row pgx.Row) (*seasons.Placement, error) {
+	var p seasons.Placement
+	var series *string
+	var wins, losses int
+	err := row.Scan(
+		&p.Entry.FighterID, &p.Entry.UserID, &p.Entry.Division, &series, &wins, &losses,
+		&p.Entry.LastMatch, &p.Entry.Decayed, &p.Entry.Updated,
+		&p.Rating.FighterID, &p.Rating.Rating, &p.Rating.Deviation, &p.Rating.Volatility,
+		&p.Rating.Matches, &p.Rating.Wins, &p.Rating.Losses, &p.Rating.Updated,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if series != nil {
+		p.Entry.Series = &seasons.Series{Kind: seasons.SeriesKind(*series), Wins: wins, Losses: losses}
+	}
+	return &p, nil
+}
+
+func (r *LadderRepository) listPlacements(ctx context.Context, query string, args ...any) ([]seasons.Placement, error) {
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []seasons.Placement
+	for rows.Next() {
+		placement, err := scanPlacement(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *placement)
+	}
+	return result, rows.Err()
+}
+
+func (r *LadderRepository) Get(ctx context.Context, fighterID string) (*seasons.Placement, error) {
+	query := `select ` + placementColumns + ` from ` + placementTables + ` where l.fighter_id = $1`
+	return scanPlacement(db.Conn(ctx, r.pool).QueryRow(ctx, query, fighterID))
+}
+
+// ListTop returns a page of the ladder, best placed fighters first.
+func (r *LadderRepository) ListTop(ctx context.Context, limit int, offset int) ([]seasons.Placement, error) {
+	query := `select ` + placementColumns + ` from ` + placementTables + `
+		order by array_position($1::text[], l.division) desc, r.rating - 2 * r.deviation desc, l.fighter_id
+		limit $2 offset $3`
+
+	divisions := make([]string, 0, len(seasons.Divisions))
+	for _, division := range seasons.Divisions {
+		divisions = append(divisions, string(division))
+	}
+	return r.listPlacements(ctx, query, divisions, limit, offset)
+}
+
+// ListAll returns every placement, locked until the surrounding unit of work
+// ends.
+func (r *LadderRepository) ListAll(ctx context.Context) ([]seasons.Placement, error) {
+	query := `select ` + placementColumns + ` from ` + placementTables + ` order by l.fighter_id for update`
+	return r.listPlacements(ctx, query)
+}
+
+// ListIdle returns the placements of fighters without a match since before,
+// locked until the surrounding unit of work ends.
+func (r *LadderRepository) ListIdle(ctx context.Context, before time.Time) ([]seasons.Placement, error) {
+	query := `select ` + placementColumns + ` from ` + placementTables + `
+		where l.last_match < $1
+		order by l.fighter_id
+		for update`
+	return r.listPlacements(ctx, query, before)
+}
+
+func (r *LadderRepository) Save(ctx context.Context, entry *seasons.LadderEntry) error {
+	const query = `
+		insert into ladder_entries (fighter_id, user_id, division, series, series_wins, series_losses, last_match, decayed, updated)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		on conflict (fighter_id) do update
+		set user_id = excluded.user_id, division = excluded.division, series = excluded.series,
+		    series_wins = excluded.series_wins, series_losses = excluded.series_losses,
+		    last_match = excluded.last_match, decayed = excluded.decayed, updated = excluded.updated`
+
+	var series *string
+	var wins, losses int
+	if entry.Series != nil {
+		kind := string(entry.Series.Kind)
+		series, wins, losses = &kind, entry.Series.Wins, entry.Series.Losses
+	}
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
+		entry.FighterID, entry.UserID, string(entry.Division), series, wins, losses,
+		entry.LastMatch, entry.Decayed, entry.Updated,
+	)
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"empoweredpixels/internal/domain/leaderboard"
+	"empoweredpixels/internal/infra/db"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,6 +16,7 @@ type LeaderboardRepository interface {
 	GetByCategory(ctx context.Context, category string, limit int, offset int) ([]leaderboard.Entry, error)
 	GetUserRank(ctx context.Context, category string, userID int) (*leaderboard.Entry, error)
 	UpsertEntry(ctx context.Context, entry *leaderboard.Entry) error
+	DeleteCategoryExcept(ctx context.Context, category string, userIDs []int) error
 	GetTotalCount(ctx context.Context, category string) (int, error)
 	GetNearbyRanks(ctx context.Context, category string, userID int, rangeSize int) ([]leaderboard.Entry, error)
 }
@@ -23,7 +25,7 @@ type LeaderboardRepository interface {
 type AchievementRepository interface {
 	ListAchievements(ctx context.Context) ([]leaderboard.Achievement, error)
 	GetPlayerAchievements(ctx context.Context, userID int) ([]leaderboard.PlayerAchievement, error)
-	UpdateAchievementProgress(ctx context.Context, userID int, achievementKey string, progress int) error
+	UpdateAchievementProgress(ctx context.Context, userID int, achievementKey string, progress int) (*leaderboard.Achievement, error)
 	ClaimAchievementReward(ctx context.Context, userID int, achievementID string) error
 }
 
@@ -107,7 +109,16 @@ func (r *LeaderboardPostgres) UpsertEntry(ctx context.Context, entry *leaderboar
 		DO UPDATE SET rank = EXCLUDED.rank, score = EXCLUDED.score, previous_rank = leaderboard_entries.rank, updated_at = NOW()
 	`
 
-	_, err := r.db.Exec(ctx, query, entry.Category, entry.UserID, entry.Rank, entry.Score, entry.PreviousRank)
+	_, err := db.Conn(ctx, r.db).Exec(ctx, query, entry.Category, entry.UserID, entry.Rank, entry.Score, entry.PreviousRank)
+	return err
+}
+
+// DeleteCategoryExcept removes the entries of a category of every user but
+// userIDs
+func (r *LeaderboardPostgres) DeleteCategoryExcept(ctx context.Context, category string, userIDs []int) error {
+	query := `DELETE FROM leaderboard_entries WHERE category = $1 AND NOT (user_id = ANY($2))`
+
+	_, err := db.Conn(ctx, r.db).Exec(ctx, query, category, userIDs)
 	return err
 }
 
@@ -220,22 +231,44 @@ func (r *AchievementPostgres) GetPlayerAchievements(ctx context.Context, userID
 	return achievements, rows.Err()
 }
 
-// UpdateAchievementProgress updates a player's achievement progress
-func (r *AchievementPostgres) UpdateAchievementProgress(ctx context.Context, userID int, achievementKey string, progress int) error {
+// UpdateAchievementProgress updates a player's achievement progress and
+// returns the achievement when this update completed it
+func (r *AchievementPostgres) UpdateAchievementProgress(ctx context.Context, userID int, achievementKey string, progress int) (*leaderboard.Achievement, error) {
 	query := `
-		INSERT INTO player_achievements (user_id, achievement_id, progress, completed, completed_at)
-		SELECT $1, a.id, $3, $3 >= a.requirement_value, CASE WHEN $3 >= a.requirement_value THEN NOW() ELSE NULL END
-		FROM achievements a
-		WHERE a.key = $2
-		ON CONFLICT (user_id, achievement_id)
-		DO UPDATE SET
-			progress = EXCLUDED.progress,
-			completed = EXCLUDED.completed,
-			completed_at = COALESCE(player_achievements.completed_at, EXCLUDED.completed_at)
+		WITH previous AS (
+			SELECT pa.completed
+			FROM player_achievements pa
+			JOIN achievements a ON a.id = pa.achievement_id
+			WHERE pa.user_id = $1 AND a.key = $2
+		), upserted AS (
+			INSERT INTO player_achievements (user_id, achievement_id, progress, completed, completed_at)
+			SELECT $1, a.id, $3, $3 >= a.requirement_value, CASE WHEN $3 >= a.requirement_value THEN NOW() ELSE NULL END
+			FROM achievements a
+			WHERE a.key = $2
+			ON CONFLICT (user_id, achievement_id)
+			DO UPDATE SET
+				progress = EXCLUDED.progress,
+				completed = EXCLUDED.completed,
+				completed_at = COALESCE(player_achievements.completed_at, EXCLUDED.completed_at)
+			RETURNING achievement_id, completed
+		)
+		SELECT a.id, a.key, a.name, a.description, a.icon, a.category, a.requirement_type, a.requirement_value, a.reward_gold, COALESCE(a.reward_title, ''), a.hidden, a.created_at
+		FROM upserted u
+		JOIN achievements a ON a.id = u.achievement_id
+		WHERE u.completed AND NOT COALESCE((SELECT completed FROM previous), false)
 	`
 
-	_, err := r.db.Exec(ctx, query, userID, achievementKey, progress)
-	return err
+	var a leaderboard.Achievement
+	err := r.db.QueryRow(ctx, query, userID, achievementKey, progress).Scan(
+		&a.ID, &a.Key, &a.Name, &a.Description, &a.Icon, &a.Category, &a.RequirementType, &a.RequirementValue, &a.RewardGold, &a.RewardTitle, &a.Hidden, &a.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
 }
 
 // ClaimAchievementReward marks an achievement reward as claimed
@@ -2,10 +2,13 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/tournaments"
+	"empoweredpixels/internal/infra/db"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -34,14 +37,108 @@ func NewLeagueMatchRepository(pool *pgxpool.Pool) *LeagueMatchRepository {
 	return &LeagueMatchRepository{pool: pool}
 }
 
-func (r *LeagueRepository) List(ctx context.Context) ([]leagues.League, error) {
-	const query = `
-		select id, name, options, is_deactivated
-		from leagues
-		where is_deactivated = false
-		order by id`
+// leagueOptions is how a league config is stored in the options column.
+type leagueOptions struct {
+	Schedule           string         `json:"schedule,omitempty"`
+	Format             string         `json:"format,omitempty"`
+	MinFighters        int            `json:"minFighters,omitempty"`
+	MaxFighters        int            `json:"maxFighters,omitempty"`
+	MinLevel           int            `json:"minLevel,omitempty"`
+	MaxLevel           int            `json:"maxLevel,omitempty"`
+	MaxPowerlevel      int            `json:"maxPowerlevel,omitempty"`
+	EntryFee           int            `json:"entryFee,omitempty"`
+	MaxFightersPerUser int            `json:"maxFightersPerUser,omitempty"`
+	TeamSize           int            `json:"teamSize,omitempty"`
+	PlacementPoints    []int          `json:"placementPoints,omitempty"`
+	KillPoints         int            `json:"killPoints,omitempty"`
+	BotCount           *int           `json:"botCount,omitempty"`
+	BotPowerlevel      *int           `json:"botPowerlevel,omitempty"`
+	Deactivations      []leagueWindow `json:"deactivations,omitempty"`
+}
+
+type leagueWindow struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+func encodeLeagueConfig(config leagues.Config) ([]byte, error) {
+	options := leagueOptions{
+		Schedule:           config.Schedule,
+		Format:             string(config.Format),
+		MinFighters:        config.MinFighters,
+		MaxFighters:        config.MaxFighters,
+		MinLevel:           config.MinLevel,
+		MaxLevel:           config.MaxLevel,
+		MaxPowerlevel:      config.MaxPowerlevel,
+		EntryFee:           config.EntryFee,
+		MaxFightersPerUser: config.MaxFightersPerUser,
+		TeamSize:           config.TeamSize,
+		PlacementPoints:    config.PlacementPoints,
+		KillPoints:         config.KillPoints,
+		BotCount:           config.BotCount,
+		BotPowerlevel:      config.BotPowerlevel,
+	}
+	for _, w := range config.Deactivations {
+		options.Deactivations = append(options.Deactivations, leagueWindow{From: w.From, To: w.To})
+	}
+	return json.Marshal(options)
+}
+
+// decodeLeagueConfig reads the options of a league, filling in the defaults
+// for what older leagues don't set.
+func decodeLeagueConfig(data []byte) (leagues.Config, error) {
+	var options leagueOptions
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &options); err != nil {
+			return leagues.Config{}, err
+		}
+	}
+	config := leagues.Config{
+		Schedule:           options.Schedule,
+		Format:             tournaments.Format(options.Format),
+		MinFighters:        options.MinFighters,
+		MaxFighters:        options.MaxFighters,
+		MinLevel:           options.MinLevel,
+		MaxLevel:           options.MaxLevel,
+		MaxPowerlevel:      options.MaxPowerlevel,
+		EntryFee:           options.EntryFee,
+		MaxFightersPerUser: options.MaxFightersPerUser,
+		TeamSize:           options.TeamSize,
+		PlacementPoints:    options.PlacementPoints,
+		KillPoints:         options.KillPoints,
+		BotCount:           options.BotCount,
+		BotPowerlevel:      options.BotPowerlevel,
+	}
+	for _, w := range options.Deactivations {
+		config.Deactivations = append(config.Deactivations, leagues.Window{From: w.From, To: w.To})
+	}
+	return config.Normalized(), nil
+}
 
-	rows, err := r.pool.Query(ctx, query)
+const leagueColumns = `id, name, options, is_deactivated, last_run`
+
+func scanLeague(row pgx.Row) (*leagues.League, error) {
+	var league leagues.League
+	var name *string
+	var options []byte
+	err := row.Scan(&league.ID, &name, &options, &league.IsDeactivated, &league.LastRun)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		league.Name = *name
+	}
+	if league.Config, err = decodeLeagueConfig(options); err != nil {
+		return nil, err
+	}
+	return &league, nil
+}
+
+func (r *LeagueRepository) listLeagues(ctx context.Context, query string, args ...any) ([]leagues.League, error) {
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -49,30 +146,83 @@ func (r *LeagueRepository) List(ctx context.Context) ([]leagues.League, error) {
 
 	var result []leagues.League
 	for rows.Next() {
-		var league leagues.League
-		if err := rows.Scan(&league.ID, &league.Name, &league.Options, &league.IsDeactivated); err != nil {
+		league, err := scanLeague(rows)
+		if err != nil {
 			return nil, err
 		}
-		result = append(result, league)
+		result = append(result, *league)
 	}
 	return result, rows.Err()
 }
 
+// List returns the leagues that aren't deactivated.
+func (r *LeagueRepository) List(ctx context.Context) ([]leagues.League, error) {
+	query := `select ` + leagueColumns + ` from leagues where is_deactivated = false order by id`
+	return r.listLeagues(ctx, query)
+}
+
+// ListAll returns every league, deactivated ones included.
+func (r *LeagueRepository) ListAll(ctx context.Context) ([]leagues.League, error) {
+	query := `select ` + leagueColumns + ` from leagues order by id`
+	return r.listLeagues(ctx, query)
+}
+
 func (r *LeagueRepository) GetByID(ctx context.Context, id int) (*leagues.League, error) {
+	query := `select ` + leagueColumns + ` from leagues where id = $1`
+	return scanLeague(db.Conn(ctx, r.pool).QueryRow(ctx, query, id))
+}
+
+// Lock returns the league locked until the surrounding unit of work ends, so
+// that subscriptions to it are checked against its caps one at a time.
+func (r *LeagueRepository) Lock(ctx context.Context, id int) (*leagues.League, error) {
+	query := `select ` + leagueColumns + ` from leagues where id = $1 for update`
+	return scanLeague(db.Conn(ctx, r.pool).QueryRow(ctx, query, id))
+}
+
+func (r *LeagueRepository) Create(ctx context.Context, league *leagues.League) error {
 	const query = `
-		select id, name, options, is_deactivated
-		from leagues
-		where id = $1`
+		insert into leagues (name, options, is_deactivated)
+		values ($1, $2, $3)
+		returning id`
 
-	var league leagues.League
-	err := r.pool.QueryRow(ctx, query, id).Scan(&league.ID, &league.Name, &league.Options, &league.IsDeactivated)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
+	options, err := encodeLeagueConfig(league.Config)
+	if err != nil {
+		return err
 	}
+	return db.Conn(ctx, r.pool).QueryRow(ctx, query, league.Name, options, league.IsDeactivated).Scan(&league.ID)
+}
+
+func (r *LeagueRepository) Update(ctx context.Context, league *leagues.League) error {
+	const query = `update leagues set name = $2, options = $3, is_deactivated = $4 where id = $1`
+
+	options, err := encodeLeagueConfig(league.Config)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &league, nil
+	_, err = db.Conn(ctx, r.pool).Exec(ctx, query, league.ID, league.Name, options, league.IsDeactivated)
+	return err
+}
+
+// Delete deletes a league with its subscriptions and history. It reports
+// whether the league existed.
+func (r *LeagueRepository) Delete(ctx context.Context, id int) (bool, error) {
+	result, err := db.Conn(ctx, r.pool).Exec(ctx, `delete from leagues where id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// ClaimRun moves the league's last run from previous to at and reports
+// whether this call did, so only one instance runs a league that came due.
+func (r *LeagueRepository) ClaimRun(ctx context.Context, id int, previous *time.Time, at time.Time) (bool, error) {
+	const query = `update leagues set last_run = $3 where id = $1 and last_run is not distinct from $2`
+
+	result, err := db.Conn(ctx, r.pool).Exec(ctx, query, id, previous, at)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
 }
 
 func (r *LeagueSubscriptionRepository) ListByLeague(ctx context.Context, leagueID int) ([]leagues.LeagueSubscription, error) {
@@ -81,7 +231,7 @@ func (r *LeagueSubscriptionRepository) ListByLeague(ctx context.Context, leagueI
 		from league_subscriptions
 		where league_id = $1`
 
-	rows, err := r.pool.Query(ctx, query, leagueID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, leagueID)
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +255,7 @@ func (r *LeagueSubscriptionRepository) ListByLeagueAndUser(ctx context.Context,
 		join fighters f on f.id = ls.fighter_id
 		where ls.league_id = $1 and f.user_id = $2`
 
-	rows, err := r.pool.Query(ctx, query, leagueID, userID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, leagueID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +277,7 @@ func (r *LeagueMatchRepository) Create(ctx context.Context, leagueID int, matchI
 		insert into league_matches (league_id, match_id, started)
 		values ($1, $2, null)
 		on conflict (league_id, match_id) do nothing`
-	_, err := r.pool.Exec(ctx, query, leagueID, matchID)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, leagueID, matchID)
 	return err
 }
 
@@ -137,13 +287,26 @@ func (r *LeagueMatchRepository) UpdateStarted(ctx context.Context, leagueID int,
 	return err
 }
 
+// ClaimScoring marks the league match scored. It reports whether the caller
+// claimed it, so that a result is added to the standings once.
+func (r *LeagueMatchRepository) ClaimScoring(ctx context.Context, leagueID int, matchID string, at time.Time) (bool, error) {
+	const query = `
+		update league_matches set scored = $3
+		where league_id = $1 and match_id = $2 and scored is null`
+	result, err := db.Conn(ctx, r.pool).Exec(ctx, query, leagueID, matchID, at)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
 func (r *LeagueSubscriptionRepository) Create(ctx context.Context, subscription *leagues.LeagueSubscription) error {
 	const query = `
 		insert into league_subscriptions (league_id, fighter_id, created)
 		values ($1, $2, $3)
 		on conflict (league_id, fighter_id) do nothing`
 
-	_, err := r.pool.Exec(ctx, query, subscription.LeagueID, subscription.FighterID, subscription.Created)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, subscription.LeagueID, subscription.FighterID, subscription.Created)
 	return err
 }
 
@@ -152,7 +315,7 @@ func (r *LeagueSubscriptionRepository) Delete(ctx context.Context, subscription
 		delete from league_subscriptions
 		where league_id = $1 and fighter_id = $2`
 
-	result, err := r.pool.Exec(ctx, query, subscription.LeagueID, subscription.FighterID)
+	result, err := db.Conn(ctx, r.pool).Exec(ctx, query, subscription.LeagueID, subscription.FighterID)
 	if err != nil {
 		return err
 	}
@@ -186,3 +349,20 @@ func (r *LeagueMatchRepository) ListByLeague(ctx context.Context, leagueID int,
 	}
 	return result, rows.Err()
 }
+
+func (r *LeagueMatchRepository) GetByMatch(ctx context.Context, matchID string) (*leagues.LeagueMatch, error) {
+	const query = `
+		select league_id, match_id, started
+		from league_matches
+		where match_id = $1`
+
+	var match leagues.LeagueMatch
+	err := r.pool.QueryRow(ctx, query, matchID).Scan(&match.LeagueID, &match.MatchID, &match.Started)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
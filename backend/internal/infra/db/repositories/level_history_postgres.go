@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LevelHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLevelHistoryRepository(pool *pgxpool.Pool) *LevelHistoryRepository {
+	return &LevelHistoryRepository{pool: pool}
+}
+
+// statGainsJSON is the stored form of roster.StatGains.
+type statGainsJSON struct {
+	Power    int `json:"power"`
+	Vitality int `json:"vitality"`
+	Accuracy int `json:"accuracy"`
+	Agility  int `json:"agility"`
+	Armor    int `json:"armor"`
+}
+
+func (r *LevelHistoryRepository) Record(ctx context.Context, levelUp *roster.LevelUp) error {
+	const query = `
+		insert into fighter_level_history (fighter_id, from_level, to_level, experience, gains, created)
+		values ($1, $2, $3, $4, $5, $6)
+		returning id`
+
+	gains, err := json.Marshal(statGainsJSON(levelUp.Gains))
+	if err != nil {
+		return err
+	}
+	return db.Conn(ctx, r.pool).QueryRow(ctx, query,
+		levelUp.FighterID, levelUp.FromLevel, levelUp.ToLevel, levelUp.Experience, gains, levelUp.Created,
+	).Scan(&levelUp.ID)
+}
+
+func (r *LevelHistoryRepository) ListByFighter(ctx context.Context, fighterID string, limit int) ([]roster.LevelUp, error) {
+	const query = `
+		select h.id, h.fighter_id, f.user_id, h.from_level, h.to_level, h.experience, h.gains, h.created
+		from fighter_level_history h
+		join fighters f on f.id = h.fighter_id
+		where h.fighter_id = $1
+		order by h.created desc, h.id desc
+		limit $2`
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, fighterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []roster.LevelUp
+	for rows.Next() {
+		var levelUp roster.LevelUp
+		var gains []byte
+		if err := rows.Scan(&levelUp.ID, &levelUp.FighterID, &levelUp.UserID, &levelUp.FromLevel, &levelUp.ToLevel, &levelUp.Experience, &gains, &levelUp.Created); err != nil {
+			return nil, err
+		}
+		var stored statGainsJSON
+		_ = json.Unmarshal(gains, &stored)
+		levelUp.Gains = roster.StatGains(stored)
+		result = append(result, levelUp)
+	}
+	return result, rows.Err()
+}
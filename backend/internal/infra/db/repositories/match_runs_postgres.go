@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"empoweredpixels/internal/domain/matches"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MatchRunRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMatchRunRepository(pool *pgxpool.Pool) *MatchRunRepository {
+	return &MatchRunRepository{pool: pool}
+}
+
+const matchRunColumns = `id, match_id, status, attempts, max_attempts, last_error, available_at, enqueued_at, started_at, finished_at, lease_until`
+
+func scanMatchRun(row pgx.Row) (*matches.MatchRun, error) {
+	var run matches.MatchRun
+	err := row.Scan(
+		&run.ID, &run.MatchID, &run.Status, &run.Attempts, &run.MaxAttempts, &run.LastError,
+		&run.AvailableAt, &run.EnqueuedAt, &run.StartedAt, &run.FinishedAt, &run.LeaseUntil,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Enqueue adds the run. It reports false when the match is already queued
+// or running.
+func (r *MatchRunRepository) Enqueue(ctx context.Context, run *matches.MatchRun) (bool, error) {
+	const query = `
+		insert into match_runs (id, match_id, status, attempts, max_attempts, available_at, enqueued_at)
+		values ($1, $2, 'pending', 0, $3, $4, $5)
+		on conflict (match_id) where status in ('pending', 'running') do nothing`
+
+	tag, err := r.pool.Exec(ctx, query, run.ID, run.MatchID, run.MaxAttempts, run.AvailableAt, run.EnqueuedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Claim leases the oldest available pending run until leaseUntil. Runs
+// locked by concurrent claims are skipped. It returns nil when there is no
+// work.
+func (r *MatchRunRepository) Claim(ctx context.Context, now time.Time, leaseUntil time.Time) (*matches.MatchRun, error) {
+	const query = `
+		update match_runs
+		set status = 'running', attempts = attempts + 1, started_at = $1, lease_until = $2
+		where id = (
+			select id from match_runs
+			where status = 'pending' and available_at <= $1
+			order by available_at, enqueued_at
+			limit 1
+			for update skip locked
+		)
+		returning ` + matchRunColumns
+
+	return scanMatchRun(r.pool.QueryRow(ctx, query, now, leaseUntil))
+}
+
+func (r *MatchRunRepository) Complete(ctx context.Context, id string, finishedAt time.Time) error {
+	const query = `
+		update match_runs
+		set status = 'done', finished_at = $2, lease_until = null
+		where id = $1`
+	_, err := r.pool.Exec(ctx, query, id, finishedAt)
+	return err
+}
+
+// Retry puts a running run back into the queue, available again at
+// availableAt.
+func (r *MatchRunRepository) Retry(ctx context.Context, id string, availableAt time.Time, lastError string) error {
+	const query = `
+		update match_runs
+		set status = 'pending', available_at = $2, last_error = $3, lease_until = null
+		where id = $1 and status = 'running'`
+	_, err := r.pool.Exec(ctx, query, id, availableAt, lastError)
+	return err
+}
+
+func (r *MatchRunRepository) Fail(ctx context.Context, id string, finishedAt time.Time, lastError string) error {
+	const query = `
+		update match_runs
+		set status = 'failed', finished_at = $2, last_error = $3, lease_until = null
+		where id = $1 and status = 'running'`
+	_, err := r.pool.Exec(ctx, query, id, finishedAt, lastError)
+	return err
+}
+
+// ReleaseExpired takes back every running run whose lease ran out before
+// now: runs with attempts left become pending again, the others fail. It
+// returns the released runs in their new state.
+func (r *MatchRunRepository) ReleaseExpired(ctx context.Context, now time.Time) ([]matches.MatchRun, error) {
+	const query = `
+		update match_runs
+		set status = case when attempts < max_attempts then 'pending' else 'failed' end,
+			finished_at = case when attempts < max_attempts then null else $1 end,
+			available_at = $1,
+			last_error = 'worker lease expired',
+			lease_until = null
+		where id in (
+			select id from match_runs
+			where status = 'running' and lease_until < $1
+			for update skip locked
+		)
+		returning ` + matchRunColumns
+
+	rows, err := r.pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []matches.MatchRun
+	for rows.Next() {
+		run, err := scanMatchRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *run)
+	}
+	return result, rows.Err()
+}
+
+// ListOrphanedMatches returns the matches that have been running since
+// before startedBefore without a queued run, such as runs of a process that
+// died before the queue existed.
+func (r *MatchRunRepository) ListOrphanedMatches(ctx context.Context, startedBefore time.Time) ([]string, error) {
+	const query = `
+		select m.id
+		from matches m
+		where m.status = 'running' and m.started < $1
+			and not exists (
+				select 1 from match_runs q
+				where q.match_id = m.id and q.status in ('pending', 'running')
+			)`
+
+	rows, err := r.pool.Query(ctx, query, startedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		result = append(result, id)
+	}
+	return result, rows.Err()
+}
+
+func (r *MatchRunRepository) Stats(ctx context.Context) (matches.MatchRunStats, error) {
+	const query = `
+		select
+			count(*) filter (where status = 'pending'),
+			count(*) filter (where status = 'running'),
+			count(*) filter (where status = 'failed'),
+			min(enqueued_at) filter (where status = 'pending')
+		from match_runs`
+
+	var stats matches.MatchRunStats
+	err := r.pool.QueryRow(ctx, query).Scan(&stats.Pending, &stats.Running, &stats.Failed, &stats.OldestPending)
+	return stats, err
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MatchSettlementRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMatchSettlementRepository(pool *pgxpool.Pool) *MatchSettlementRepository {
+	return &MatchSettlementRepository{pool: pool}
+}
+
+// Claim marks the match as settled. It reports false when the match already
+// was. A concurrent claim of the same match waits until the first one's
+// transaction ends.
+func (r *MatchSettlementRepository) Claim(ctx context.Context, matchID string, settledAt time.Time) (bool, error) {
+	const query = `
+		insert into match_settlements (match_id, settled_at)
+		values ($1, $2)
+		on conflict (match_id) do nothing`
+
+	tag, err := db.Conn(ctx, r.pool).Exec(ctx, query, matchID, settledAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -3,8 +3,10 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 
 	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/infra/db"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -52,28 +54,28 @@ func NewMatchScoreRepository(pool *pgxpool.Pool) *MatchScoreRepository {
 
 func (r *MatchRepository) Create(ctx context.Context, match *matches.Match) error {
 	const query = `
-		insert into matches (id, creator_user_id, created, started, completed_at, cancelled_at, status, options)
-		values ($1, $2, $3, $4, $5, $6, $7, $8)`
+		insert into matches (id, creator_user_id, created, started, completed_at, cancelled_at, status, options, revealed_at)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 	status := match.Status
 	if status == "" {
 		status = matches.MatchStatusLobby
 	}
-	_, err := r.pool.Exec(ctx, query,
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
 		match.ID, match.CreatorUserID, match.Created, match.Started,
-		match.CompletedAt, match.CancelledAt, status, match.Options)
+		match.CompletedAt, match.CancelledAt, status, match.Options, match.RevealedAt)
 	return err
 }
 
 func (r *MatchRepository) GetByID(ctx context.Context, id string) (*matches.Match, error) {
 	const query = `
-		select id, creator_user_id, created, started, completed_at, cancelled_at, status, options
+		select id, creator_user_id, created, started, completed_at, cancelled_at, status, options, revealed_at
 		from matches
 		where id = $1`
 
 	var match matches.Match
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(
 		&match.ID, &match.CreatorUserID, &match.Created, &match.Started,
-		&match.CompletedAt, &match.CancelledAt, &match.Status, &match.Options,
+		&match.CompletedAt, &match.CancelledAt, &match.Status, &match.Options, &match.RevealedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
@@ -90,13 +92,13 @@ func (r *MatchRepository) ListOpen(ctx context.Context, limit int, offset int) (
 
 func (r *MatchRepository) ListByStatus(ctx context.Context, status string, limit int, offset int) ([]matches.Match, error) {
 	const query = `
-		select id, creator_user_id, created, started, completed_at, cancelled_at, status, options
+		select id, creator_user_id, created, started, completed_at, cancelled_at, status, options, revealed_at
 		from matches
 		where status = $1
 		order by created desc
 		limit $2 offset $3`
 
-	rows, err := r.pool.Query(ctx, query, status, limit, offset)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, status, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +108,7 @@ func (r *MatchRepository) ListByStatus(ctx context.Context, status string, limit
 	for rows.Next() {
 		var match matches.Match
 		if err := rows.Scan(&match.ID, &match.CreatorUserID, &match.Created, &match.Started,
-			&match.CompletedAt, &match.CancelledAt, &match.Status, &match.Options); err != nil {
+			&match.CompletedAt, &match.CancelledAt, &match.Status, &match.Options, &match.RevealedAt); err != nil {
 			return nil, err
 		}
 		result = append(result, match)
@@ -117,7 +119,7 @@ func (r *MatchRepository) ListByStatus(ctx context.Context, status string, limit
 
 func (r *MatchRepository) GetCurrentMatch(ctx context.Context, userID int64) (*matches.Match, error) {
 	const query = `
-		select m.id, m.creator_user_id, m.created, m.started, m.completed_at, m.cancelled_at, m.status, m.options
+		select m.id, m.creator_user_id, m.created, m.started, m.completed_at, m.cancelled_at, m.status, m.options, m.revealed_at
 		from matches m
 		join match_registrations mr on mr.match_id = m.id
 		join fighters f on f.id = mr.fighter_id
@@ -126,9 +128,9 @@ func (r *MatchRepository) GetCurrentMatch(ctx context.Context, userID int64) (*m
 		limit 1`
 
 	var match matches.Match
-	err := r.pool.QueryRow(ctx, query, userID).Scan(
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, userID).Scan(
 		&match.ID, &match.CreatorUserID, &match.Created, &match.Started,
-		&match.CompletedAt, &match.CancelledAt, &match.Status, &match.Options,
+		&match.CompletedAt, &match.CancelledAt, &match.Status, &match.Options, &match.RevealedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
@@ -142,21 +144,46 @@ func (r *MatchRepository) GetCurrentMatch(ctx context.Context, userID int64) (*m
 func (r *MatchRepository) Update(ctx context.Context, match *matches.Match) error {
 	const query = `
 		update matches
-		set started = $2, completed_at = $3, cancelled_at = $4, status = $5
+		set started = $2, completed_at = $3, cancelled_at = $4, status = $5, revealed_at = $6
 		where id = $1`
-	_, err := r.pool.Exec(ctx, query,
-		match.ID, match.Started, match.CompletedAt, match.CancelledAt, match.Status)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
+		match.ID, match.Started, match.CompletedAt, match.CancelledAt, match.Status, match.RevealedAt)
 	return err
 }
 
+// Reveal makes the outcome of a match public from at on, unless it already
+// is by then.
+func (r *MatchRepository) Reveal(ctx context.Context, id string, at time.Time) error {
+	const query = `
+		update matches
+		set revealed_at = $2
+		where id = $1 and revealed_at > $2`
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, id, at)
+	return err
+}
+
+// MarkRunning moves a lobby to running. It reports false when the match was
+// not in the lobby, such as when another execution started it first.
+func (r *MatchRepository) MarkRunning(ctx context.Context, id string, started time.Time) (bool, error) {
+	const query = `
+		update matches
+		set status = 'running', started = $2
+		where id = $1 and status = 'lobby'`
+	tag, err := db.Conn(ctx, r.pool).Exec(ctx, query, id, started)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
 func (r *MatchRepository) ListStaleLobbies(ctx context.Context, olderThanMinutes int) ([]matches.Match, error) {
 	const query = `
-		select id, creator_user_id, created, started, completed_at, cancelled_at, status, options
+		select id, creator_user_id, created, started, completed_at, cancelled_at, status, options, revealed_at
 		from matches
 		where status = 'lobby' and created < now() - interval '1 minute' * $1
 		order by created asc`
 
-	rows, err := r.pool.Query(ctx, query, olderThanMinutes)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, olderThanMinutes)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +193,7 @@ func (r *MatchRepository) ListStaleLobbies(ctx context.Context, olderThanMinutes
 	for rows.Next() {
 		var match matches.Match
 		if err := rows.Scan(&match.ID, &match.CreatorUserID, &match.Created, &match.Started,
-			&match.CompletedAt, &match.CancelledAt, &match.Status, &match.Options); err != nil {
+			&match.CompletedAt, &match.CancelledAt, &match.Status, &match.Options, &match.RevealedAt); err != nil {
 			return nil, err
 		}
 		result = append(result, match)
@@ -185,7 +212,7 @@ func (r *MatchRepository) CountRecentActiveUsers(ctx context.Context, minutes in
 		   or m.started > now() - interval '1 minute' * $1`
 
 	var count int
-	err := r.pool.QueryRow(ctx, query, minutes).Scan(&count)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, minutes).Scan(&count)
 	return count, err
 }
 
@@ -194,7 +221,7 @@ func (r *MatchTeamRepository) Create(ctx context.Context, team *matches.MatchTea
 		insert into match_teams (id, match_id, password)
 		values ($1, $2, $3)`
 
-	_, err := r.pool.Exec(ctx, query, team.ID, team.MatchID, team.Password)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, team.ID, team.MatchID, team.Password)
 	return err
 }
 
@@ -204,7 +231,7 @@ func (r *MatchTeamRepository) ListByMatch(ctx context.Context, matchID string) (
 		from match_teams
 		where match_id = $1`
 
-	rows, err := r.pool.Query(ctx, query, matchID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, matchID)
 	if err != nil {
 		return nil, err
 	}
@@ -228,7 +255,7 @@ func (r *MatchTeamRepository) GetByID(ctx context.Context, id string) (*matches.
 		where id = $1`
 
 	var team matches.MatchTeam
-	err := r.pool.QueryRow(ctx, query, id).Scan(&team.ID, &team.MatchID, &team.Password)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(&team.ID, &team.MatchID, &team.Password)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -245,7 +272,7 @@ func (r *MatchRegistrationRepository) Upsert(ctx context.Context, registration *
 		on conflict (match_id, fighter_id)
 		do update set team_id = excluded.team_id, date = excluded.date`
 
-	_, err := r.pool.Exec(ctx, query,
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
 		registration.MatchID,
 		registration.FighterID,
 		registration.TeamID,
@@ -259,7 +286,7 @@ func (r *MatchRegistrationRepository) Delete(ctx context.Context, matchID string
 		delete from match_registrations
 		where match_id = $1 and fighter_id = $2`
 
-	_, err := r.pool.Exec(ctx, query, matchID, fighterID)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, matchID, fighterID)
 	return err
 }
 
@@ -270,7 +297,7 @@ func (r *MatchRegistrationRepository) GetByMatchAndFighter(ctx context.Context,
 		where match_id = $1 and fighter_id = $2`
 
 	var registration matches.MatchRegistration
-	err := r.pool.QueryRow(ctx, query, matchID, fighterID).Scan(
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, matchID, fighterID).Scan(
 		&registration.MatchID, &registration.FighterID, &registration.TeamID, &registration.Date,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -290,7 +317,7 @@ func (r *MatchRegistrationRepository) CountByMatchAndUser(ctx context.Context, m
 		where mr.match_id = $1 and f.user_id = $2`
 
 	var count int
-	err := r.pool.QueryRow(ctx, query, matchID, userID).Scan(&count)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, matchID, userID).Scan(&count)
 	return count, err
 }
 
@@ -301,7 +328,7 @@ func (r *MatchRegistrationRepository) ListByMatch(ctx context.Context, matchID s
 		where match_id = $1
 		order by date asc`
 
-	rows, err := r.pool.Query(ctx, query, matchID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, matchID)
 	if err != nil {
 		return nil, err
 	}
@@ -320,12 +347,12 @@ func (r *MatchRegistrationRepository) ListByMatch(ctx context.Context, matchID s
 
 func (r *MatchResultRepository) GetByMatch(ctx context.Context, matchID string) (*matches.MatchResult, error) {
 	const query = `
-		select id, match_id, round_ticks
+		select id, match_id, round_ticks, seed, battle_input, winner_team_id
 		from match_results
 		where match_id = $1`
 
 	var result matches.MatchResult
-	err := r.pool.QueryRow(ctx, query, matchID).Scan(&result.ID, &result.MatchID, &result.RoundTicks)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, matchID).Scan(&result.ID, &result.MatchID, &result.RoundTicks, &result.Seed, &result.BattleInput, &result.WinnerTeamID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -337,22 +364,26 @@ func (r *MatchResultRepository) GetByMatch(ctx context.Context, matchID string)
 
 func (r *MatchResultRepository) Upsert(ctx context.Context, result *matches.MatchResult) error {
 	const query = `
-		insert into match_results (id, match_id, round_ticks)
-		values ($1, $2, $3)
+		insert into match_results (id, match_id, round_ticks, seed, battle_input, winner_team_id)
+		values ($1, $2, $3, $4, $5, $6)
 		on conflict (match_id)
-		do update set round_ticks = excluded.round_ticks`
+		do update set round_ticks = excluded.round_ticks,
+					  seed = excluded.seed,
+					  battle_input = excluded.battle_input,
+					  winner_team_id = excluded.winner_team_id`
 
-	_, err := r.pool.Exec(ctx, query, result.ID, result.MatchID, result.RoundTicks)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, result.ID, result.MatchID, result.RoundTicks, result.Seed, result.BattleInput, result.WinnerTeamID)
 	return err
 }
 
 func (r *MatchScoreRepository) ListByMatch(ctx context.Context, matchID string) ([]matches.MatchScoreFighter, error) {
 	const query = `
-		select match_id, fighter_id, total_kills, total_deaths, total_assists
+		select match_id, fighter_id, is_bot, total_kills, total_deaths, total_assists,
+		       total_damage_dealt, total_damage_taken, total_healed
 		from match_score_fighters
 		where match_id = $1`
 
-	rows, err := r.pool.Query(ctx, query, matchID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, matchID)
 	if err != nil {
 		return nil, err
 	}
@@ -361,7 +392,8 @@ func (r *MatchScoreRepository) ListByMatch(ctx context.Context, matchID string)
 	var scores []matches.MatchScoreFighter
 	for rows.Next() {
 		var score matches.MatchScoreFighter
-		if err := rows.Scan(&score.MatchID, &score.FighterID, &score.TotalKills, &score.TotalDeaths, &score.TotalAssists); err != nil {
+		if err := rows.Scan(&score.MatchID, &score.FighterID, &score.IsBot, &score.TotalKills, &score.TotalDeaths, &score.TotalAssists,
+			&score.TotalDamageDealt, &score.TotalDamageTaken, &score.TotalHealed); err != nil {
 			return nil, err
 		}
 		scores = append(scores, score)
@@ -371,19 +403,28 @@ func (r *MatchScoreRepository) ListByMatch(ctx context.Context, matchID string)
 
 func (r *MatchScoreRepository) Upsert(ctx context.Context, scores []matches.MatchScoreFighter) error {
 	const query = `
-		insert into match_score_fighters (match_id, fighter_id, total_kills, total_deaths, total_assists)
-		values ($1, $2, $3, $4, $5)
+		insert into match_score_fighters (match_id, fighter_id, is_bot, total_kills, total_deaths, total_assists,
+		                                  total_damage_dealt, total_damage_taken, total_healed)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		on conflict (match_id, fighter_id)
 		do update set total_kills = excluded.total_kills,
 					  total_deaths = excluded.total_deaths,
-					  total_assists = excluded.total_assists`
+					  total_assists = excluded.total_assists,
+					  total_damage_dealt = excluded.total_damage_dealt,
+					  total_damage_taken = excluded.total_damage_taken,
+					  total_healed = excluded.total_healed`
 
 	batch := &pgx.Batch{}
 	for _, score := range scores {
-		batch.Queue(query, score.MatchID, score.FighterID, score.TotalKills, score.TotalDeaths, score.TotalAssists)
+		batch.Queue(query, score.MatchID, score.FighterID, score.IsBot, score.TotalKills, score.TotalDeaths, score.TotalAssists,
+			score.TotalDamageDealt, score.TotalDamageTaken, score.TotalHealed)
 	}
-	br := r.pool.SendBatch(ctx, batch)
+	br := db.Conn(ctx, r.pool).SendBatch(ctx, batch)
 	defer br.Close()
-	_, err := br.Exec()
-	return err
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := br.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
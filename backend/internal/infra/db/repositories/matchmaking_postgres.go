@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"empoweredpixels/internal/domain/matchmaking"
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MatchmakingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMatchmakingRepository(pool *pgxpool.Pool) *MatchmakingRepository {
+	return &MatchmakingRepository{pool: pool}
+}
+
+const ticketColumns = `fighter_id, user_id, rating, power, status, queued_at, match_id, matched_at`
+
+func scanTicket(row pgx.Row) (*matchmaking.Ticket, error) {
+	var ticket matchmaking.Ticket
+	err := row.Scan(
+		&ticket.FighterID, &ticket.UserID, &ticket.Rating, &ticket.Power,
+		&ticket.Status, &ticket.QueuedAt, &ticket.MatchID, &ticket.MatchedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (r *MatchmakingRepository) listTickets(ctx context.Context, query string, args ...any) ([]matchmaking.Ticket, error) {
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []matchmaking.Ticket
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *ticket)
+	}
+	return result, rows.Err()
+}
+
+// Enqueue replaces the fighter's previous ticket. It reports false when the
+// fighter is already waiting.
+func (r *MatchmakingRepository) Enqueue(ctx context.Context, ticket *matchmaking.Ticket) (bool, error) {
+	const query = `
+		insert into matchmaking_tickets (fighter_id, user_id, rating, power, status, queued_at)
+		values ($1, $2, $3, $4, 'waiting', $5)
+		on conflict (fighter_id) do update
+		set user_id = excluded.user_id, rating = excluded.rating, power = excluded.power,
+		    status = 'waiting', queued_at = excluded.queued_at, match_id = null, matched_at = null
+		where matchmaking_tickets.status <> 'waiting'`
+
+	tag, err := db.Conn(ctx, r.pool).Exec(ctx, query, ticket.FighterID, ticket.UserID, ticket.Rating, ticket.Power, ticket.QueuedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetByUser returns the user's most recent ticket.
+func (r *MatchmakingRepository) GetByUser(ctx context.Context, userID int64) (*matchmaking.Ticket, error) {
+	query := `select ` + ticketColumns + ` from matchmaking_tickets where user_id = $1 order by queued_at desc limit 1`
+	return scanTicket(db.Conn(ctx, r.pool).QueryRow(ctx, query, userID))
+}
+
+// Cancel takes the user's waiting tickets out of the queue. It reports
+// whether the user was waiting.
+func (r *MatchmakingRepository) Cancel(ctx context.Context, userID int64) (bool, error) {
+	const query = `
+		update matchmaking_tickets
+		set status = 'cancelled'
+		where user_id = $1 and status = 'waiting'`
+
+	tag, err := db.Conn(ctx, r.pool).Exec(ctx, query, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListWaiting returns the waiting tickets, oldest first, locked until the
+// surrounding unit of work ends. Tickets locked by a concurrent pass are
+// skipped.
+func (r *MatchmakingRepository) ListWaiting(ctx context.Context) ([]matchmaking.Ticket, error) {
+	query := `select ` + ticketColumns + ` from matchmaking_tickets where status = 'waiting' order by queued_at for update skip locked`
+	return r.listTickets(ctx, query)
+}
+
+func (r *MatchmakingRepository) MarkMatched(ctx context.Context, fighterIDs []string, matchID string, matchedAt time.Time) error {
+	const query = `
+		update matchmaking_tickets
+		set status = 'matched', match_id = $2, matched_at = $3
+		where fighter_id = any($1) and status = 'waiting'`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, fighterIDs, matchID, matchedAt)
+	return err
+}
+
+// AverageWait returns the average time tickets matched since since waited.
+func (r *MatchmakingRepository) AverageWait(ctx context.Context, since time.Time) (time.Duration, error) {
+	const query = `
+		select coalesce(avg(extract(epoch from matched_at - queued_at)), 0)
+		from matchmaking_tickets
+		where status = 'matched' and matched_at >= $1`
+
+	var seconds float64
+	if err := db.Conn(ctx, r.pool).QueryRow(ctx, query, since).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
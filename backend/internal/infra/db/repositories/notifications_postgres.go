@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"empoweredpixels/internal/domain/notifications"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationRepository(pool *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{pool: pool}
+}
+
+// Create stores the notification. It reports false when the user already
+// has a notification with the same key.
+func (r *NotificationRepository) Create(ctx context.Context, notification *notifications.Notification) (bool, error) {
+	const query = `
+		insert into notifications (id, user_id, type, title, message, data, key, read_at, created)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		on conflict (user_id, key) where key is not null do nothing`
+
+	tag, err := r.pool.Exec(ctx, query,
+		notification.ID, notification.UserID, notification.Type, notification.Title, notification.Message,
+		jsonData(notification.Data), notification.Key, notification.ReadAt, notification.Created,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// CreateForAllUsers stores a copy of the notification for every user and
+// returns the copies created. Users that already have a notification with
+// the same key are skipped.
+func (r *NotificationRepository) CreateForAllUsers(ctx context.Context, notification notifications.Notification) ([]notifications.Notification, error) {
+	const query = `
+		insert into notifications (id, user_id, type, title, message, data, key, created)
+		select gen_random_uuid(), u.id, $1, $2, $3, $4, $5, $6
+		from users u
+		where u.banned is null
+		on conflict (user_id, key) where key is not null do nothing
+		returning id, user_id`
+
+	rows, err := r.pool.Query(ctx, query,
+		notification.Type, notification.Title, notification.Message,
+		jsonData(notification.Data), notification.Key, notification.Created,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []notifications.Notification
+	for rows.Next() {
+		created := notification
+		if err := rows.Scan(&created.ID, &created.UserID); err != nil {
+			return nil, err
+		}
+		result = append(result, created)
+	}
+	return result, rows.Err()
+}
+
+// List returns a page of the user's notifications, newest first, and the
+// total number of notifications matching.
+func (r *NotificationRepository) List(ctx context.Context, userID int64, unreadOnly bool, limit int, offset int) ([]notifications.Notification, int, error) {
+	const query = `
+		select id, user_id, type, title, message, data, key, read_at, created, count(*) over()
+		from notifications
+		where user_id = $1 and (not $2 or read_at is null)
+		order by created desc, id
+		limit $3 offset $4`
+
+	rows, err := r.pool.Query(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var result []notifications.Notification
+	total := 0
+	for rows.Next() {
+		var notification notifications.Notification
+		if err := rows.Scan(
+			&notification.ID, &notification.UserID, &notification.Type, &notification.Title, &notification.Message,
+			&notification.Data, &notification.Key, &notification.ReadAt, &notification.Created, &total,
+		); err != nil {
+			return nil, 0, err
+		}
+		result = append(result, notification)
+	}
+	return result, total, rows.Err()
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID int64) (int, error) {
+	const query = `select count(*) from notifications where user_id = $1 and read_at is null`
+
+	var count int
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications as read. It reports false
+// when the user has no such notification.
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID int64, id string, readAt time.Time) (bool, error) {
+	const query = `
+		update notifications
+		set read_at = coalesce(read_at, $3)
+		where id = $1 and user_id = $2`
+
+	tag, err := r.pool.Exec(ctx, query, id, userID, readAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and
+// returns how many there were.
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int64, readAt time.Time) (int, error) {
+	const query = `
+		update notifications
+		set read_at = $2
+		where user_id = $1 and read_at is null`
+
+	tag, err := r.pool.Exec(ctx, query, userID, readAt)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func jsonData(data []byte) []byte {
+	if len(data) == 0 {
+		return []byte("{}")
+	}
+	return data
+}
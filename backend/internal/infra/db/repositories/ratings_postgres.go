@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RatingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRatingRepository(pool *pgxpool.Pool) *RatingRepository {
+	return &RatingRepository{pool: pool}
+}
+
+const ratingColumns = `fighter_id, rating, deviation, volatility, matches, wins, losses, updated`
+
+func scanRating(row pgx.Row) (*rating.Rating, error) {
+	var r rating.Rating
+	err := row.Scan(&r.FighterID, &r.Rating, &r.Deviation, &r.Volatility, &r.Matches, &r.Wins, &r.Losses, &r.Updated)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *RatingRepository) Get(ctx context.Context, fighterID string) (*rating.Rating, error) {
+	query := `select ` + ratingColumns + ` from fighter_ratings where fighter_id = $1`
+	return scanRating(db.Conn(ctx, r.pool).QueryRow(ctx, query, fighterID))
+}
+
+// ListByFighters returns the ratings of the fighters that have one, locked
+// until the surrounding unit of work ends.
+func (r *RatingRepository) ListByFighters(ctx context.Context, fighterIDs []string) ([]rating.Rating, error) {
+	query := `select ` + ratingColumns + ` from fighter_ratings where fighter_id = any($1) order by fighter_id for update`
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, fighterIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []rating.Rating
+	for rows.Next() {
+		stored, err := scanRating(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *stored)
+	}
+	return result, rows.Err()
+}
+
+func (r *RatingRepository) Save(ctx context.Context, stored *rating.Rating) error {
+	const query = `
+		insert into fighter_ratings (` + ratingColumns + `)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		on conflict (fighter_id) do update
+		set rating = excluded.rating, deviation = excluded.deviation, volatility = excluded.volatility,
+		    matches = excluded.matches, wins = excluded.wins, losses = excluded.losses, updated = excluded.updated`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
+		stored.FighterID, stored.Rating, stored.Deviation, stored.Volatility,
+		stored.Matches, stored.Wins, stored.Losses, stored.Updated,
+	)
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"empoweredpixels/internal/domain/rewards"
+	"empoweredpixels/internal/infra/db"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,7 +25,7 @@ func (r *RewardRepository) Create(ctx context.Context, reward *rewards.Reward) e
 		insert into rewards (id, user_id, reward_pool_id, claimed, created)
 		values ($1, $2, $3, $4, $5)`
 
-	_, err := r.pool.Exec(ctx, query, reward.ID, reward.UserID, reward.RewardPoolID, reward.Claimed, reward.Created)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, reward.ID, reward.UserID, reward.RewardPoolID, reward.Claimed, reward.Created)
 	return err
 }
 
@@ -34,7 +35,7 @@ func (r *RewardRepository) ListUnclaimed(ctx context.Context, userID int64) ([]r
 		from rewards
 		where user_id = $1 and claimed is null`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +59,7 @@ func (r *RewardRepository) GetUnclaimed(ctx context.Context, userID int64, rewar
 		where id = $1 and reward_pool_id = $2 and user_id = $3 and claimed is null`
 
 	var reward rewards.Reward
-	err := r.pool.QueryRow(ctx, query, rewardID, poolID, userID).Scan(
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, rewardID, poolID, userID).Scan(
 		&reward.ID, &reward.UserID, &reward.RewardPoolID, &reward.Claimed, &reward.Created,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -80,6 +81,6 @@ func (r *RewardRepository) MarkClaimed(ctx context.Context, rewardID string, cla
 		set claimed = $1
 		where id = $2`
 
-	_, err := r.pool.Exec(ctx, query, claimedAt, rewardID)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, claimedAt, rewardID)
 	return err
 }
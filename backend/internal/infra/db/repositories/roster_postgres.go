@@ -5,10 +5,13 @@ import (
 	"errors"
 
 	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/infra/db"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var ErrFighterNotFound = errors.New("fighter not found")
+
 type FighterRepository struct {
 	pool *pgxpool.Pool
 }
@@ -40,7 +43,7 @@ func (r *FighterRepository) ListByUser(ctx context.Context, userID int64) ([]ros
 		where user_id = $1 and is_deleted = false
 		order by created`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +77,7 @@ func (r *FighterRepository) GetByUserAndID(ctx context.Context, userID int64, id
 		where user_id = $1 and id = $2 and is_deleted = false`
 
 	var fighter roster.Fighter
-	err := r.pool.QueryRow(ctx, query, userID, id).Scan(
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, userID, id).Scan(
 		&fighter.ID, &fighter.UserID, &fighter.Name, &fighter.Level, &fighter.XP, &fighter.XPToNextLevel,
 		&fighter.Power, &fighter.ConditionPower, &fighter.Precision, &fighter.Ferocity,
 		&fighter.Accuracy, &fighter.Agility, &fighter.Armor, &fighter.Vitality,
@@ -100,7 +103,7 @@ func (r *FighterRepository) ListByMatch(ctx context.Context, matchID string) ([]
 		join match_registrations mr on mr.fighter_id = f.id
 		where mr.match_id = $1 and f.is_deleted = false`
 
-	rows, err := r.pool.Query(ctx, query, matchID)
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, matchID)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +137,7 @@ func (r *FighterRepository) GetByID(ctx context.Context, id string) (*roster.Fig
 		where id = $1`
 
 	var fighter roster.Fighter
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(
 		&fighter.ID, &fighter.UserID, &fighter.Name, &fighter.Level, &fighter.XP, &fighter.XPToNextLevel,
 		&fighter.Power, &fighter.ConditionPower, &fighter.Precision, &fighter.Ferocity,
 		&fighter.Accuracy, &fighter.Agility, &fighter.Armor, &fighter.Vitality,
@@ -156,7 +159,7 @@ func (r *FighterRepository) GetByID(ctx context.Context, id string) (*roster.Fig
 func (r *FighterRepository) NameExists(ctx context.Context, name string) (bool, error) {
 	const query = `select 1 from fighters where name = $1`
 	var exists int
-	err := r.pool.QueryRow(ctx, query, name).Scan(&exists)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, name).Scan(&exists)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return false, nil
 	}
@@ -166,7 +169,7 @@ func (r *FighterRepository) NameExists(ctx context.Context, name string) (bool,
 func (r *FighterRepository) UserHasFighter(ctx context.Context, userID int64) (bool, error) {
 	const query = `select 1 from fighters where user_id = $1 and is_deleted = false limit 1`
 	var exists int
-	err := r.pool.QueryRow(ctx, query, userID).Scan(&exists)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, userID).Scan(&exists)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return false, nil
 	}
@@ -178,7 +181,7 @@ func (r *FighterRepository) Create(ctx context.Context, fighter *roster.Fighter)
 		insert into fighters (id, user_id, name, level, xp, xp_to_next_level, power, condition_power, precision, ferocity, accuracy, agility, armor, vitality, parry_chance, healing_power, speed, vision, weapon_id, attunement_id, matches_won, matches_lost, total_matches, total_damage_dealt, total_damage_taken, created, is_deleted)
 		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27)`
 
-	_, err := r.pool.Exec(ctx, query,
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
 		fighter.ID,
 		fighter.UserID,
 		fighter.Name,
@@ -216,7 +219,7 @@ func (r *FighterRepository) SoftDelete(ctx context.Context, userID int64, id str
 		set is_deleted = true
 		where id = $1 and user_id = $2`
 
-	_, err := r.pool.Exec(ctx, query, id, userID)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, id, userID)
 	return err
 }
 
@@ -225,18 +228,34 @@ func (r *FighterRepository) Update(ctx context.Context, fighter *roster.Fighter)
 		update fighters
 		set level = $1, power = $2, condition_power = $3, precision = $4, ferocity = $5,
 		    accuracy = $6, agility = $7, armor = $8, vitality = $9, parry_chance = $10,
-		    healing_power = $11, speed = $12, vision = $13
-		where id = $14`
+		    healing_power = $11, speed = $12, vision = $13, xp = $14, xp_to_next_level = $15
+		where id = $16`
 
-	_, err := r.pool.Exec(ctx, query,
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
 		fighter.Level, fighter.Power, fighter.ConditionPower, fighter.Precision, fighter.Ferocity,
 		fighter.Accuracy, fighter.Agility, fighter.Armor, fighter.Vitality, fighter.ParryChance,
-		fighter.HealingPower, fighter.Speed, fighter.Vision,
+		fighter.HealingPower, fighter.Speed, fighter.Vision, fighter.XP, fighter.XPToNextLevel,
 		fighter.ID,
 	)
 	return err
 }
 
+// RecordMatch adds a finished match and its damage totals to the fighter's
+// lifetime counters.
+func (r *FighterRepository) RecordMatch(ctx context.Context, fighterID string, won bool, damageDealt, damageTaken int64) error {
+	const query = `
+		update fighters
+		set total_matches = total_matches + 1,
+		    matches_won = matches_won + case when $2 then 1 else 0 end,
+		    matches_lost = matches_lost + case when $2 then 0 else 1 end,
+		    total_damage_dealt = total_damage_dealt + $3,
+		    total_damage_taken = total_damage_taken + $4
+		where id = $1`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, fighterID, won, damageDealt, damageTaken)
+	return err
+}
+
 func (r *ExperienceRepository) GetByFighterID(ctx context.Context, fighterID string) (*roster.FighterExperience, error) {
 	const query = `
 		select id, fighter_id, experience
@@ -244,7 +263,7 @@ func (r *ExperienceRepository) GetByFighterID(ctx context.Context, fighterID str
 		where fighter_id = $1`
 
 	var exp roster.FighterExperience
-	err := r.pool.QueryRow(ctx, query, fighterID).Scan(&exp.ID, &exp.FighterID, &exp.Experience)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, fighterID).Scan(&exp.ID, &exp.FighterID, &exp.Experience)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -261,10 +280,25 @@ func (r *ExperienceRepository) Upsert(ctx context.Context, experience *roster.Fi
 		on conflict (fighter_id)
 		do update set experience = excluded.experience`
 
-	_, err := r.pool.Exec(ctx, query, experience.FighterID, experience.Experience)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, experience.FighterID, experience.Experience)
 	return err
 }
 
+// Add increments the fighter's experience by amount in a single statement
+// and returns the new total.
+func (r *ExperienceRepository) Add(ctx context.Context, fighterID string, amount int) (int, error) {
+	const query = `
+		insert into fighter_experiences (fighter_id, experience)
+		values ($1, $2)
+		on conflict (fighter_id)
+		do update set experience = fighter_experiences.experience + excluded.experience
+		returning experience`
+
+	var total int
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, fighterID, amount).Scan(&total)
+	return total, err
+}
+
 func (r *ConfigurationRepository) GetByFighterID(ctx context.Context, fighterID string) (*roster.FighterConfiguration, error) {
 	const query = `
 		select fighter_id, attunement_id
@@ -272,7 +306,7 @@ func (r *ConfigurationRepository) GetByFighterID(ctx context.Context, fighterID
 		where fighter_id = $1`
 
 	var config roster.FighterConfiguration
-	err := r.pool.QueryRow(ctx, query, fighterID).Scan(&config.FighterID, &config.AttunementID)
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, fighterID).Scan(&config.FighterID, &config.AttunementID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -289,6 +323,16 @@ func (r *ConfigurationRepository) Upsert(ctx context.Context, configuration *ros
 		on conflict (fighter_id)
 		do update set attunement_id = excluded.attunement_id`
 
-	_, err := r.pool.Exec(ctx, query, configuration.FighterID, configuration.AttunementID)
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, configuration.FighterID, configuration.AttunementID)
 	return err
 }
+
+func (r *FighterRepository) GetFighterLevel(ctx context.Context, fighterID string) (int, error) {
+	const query = `select level from fighters where id = $1 and is_deleted = false`
+	var level int
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query, fighterID).Scan(&level)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrFighterNotFound
+	}
+	return level, err
+}
@@ -2,11 +2,75 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"empoweredpixels/internal/domain/seasons"
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+type SeasonRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSeasonRepository(pool *pgxpool.Pool) *SeasonRepository {
+	return &SeasonRepository{pool: pool}
+}
+
+// Current returns the latest season that didn't roll over yet.
+func (r *SeasonRepository) Current(ctx context.Context) (*seasons.Season, error) {
+	const query = `
+		select id, season_id, start_date, end_date, completed
+		from seasons
+		where completed is null
+		order by season_id desc
+		limit 1`
+
+	var season seasons.Season
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query).Scan(&season.ID, &season.SeasonID, &season.StartDate, &season.EndDate, &season.Completed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// LastSeasonID returns the number of the latest season, 0 before the first.
+func (r *SeasonRepository) LastSeasonID(ctx context.Context) (int, error) {
+	const query = `select coalesce(max(season_id), 0) from seasons`
+
+	var seasonID int
+	err := db.Conn(ctx, r.pool).QueryRow(ctx, query).Scan(&seasonID)
+	return seasonID, err
+}
+
+func (r *SeasonRepository) Create(ctx context.Context, season *seasons.Season) error {
+	const query = `
+		insert into seasons (season_id, start_date, end_date)
+		values ($1, $2, $3)
+		returning id`
+
+	return db.Conn(ctx, r.pool).QueryRow(ctx, query, season.SeasonID, season.StartDate, season.EndDate).Scan(&season.ID)
+}
+
+// Complete marks the season as rolled over. It reports false when it already
+// was. A concurrent completion of the same season waits until the first
+// one's transaction ends.
+func (r *SeasonRepository) Complete(ctx context.Context, id int64, completed time.Time) (bool, error) {
+	const query = `update seasons set completed = $1 where id = $2 and completed is null`
+
+	tag, err := db.Conn(ctx, r.pool).Exec(ctx, query, completed, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
 type SeasonSummaryRepository struct {
 	pool *pgxpool.Pool
 }
@@ -39,3 +103,14 @@ func (r *SeasonSummaryRepository) ListByUser(ctx context.Context, userID int64,
 	}
 	return result, rows.Err()
 }
+
+// Create records the summary unless the user already has one for the season.
+func (r *SeasonSummaryRepository) Create(ctx context.Context, summary *seasons.SeasonSummary) error {
+	const query = `
+		insert into season_summaries (user_id, season_id, position)
+		values ($1, $2, $3)
+		on conflict (user_id, season_id) do nothing`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, summary.UserID, summary.SeasonID, summary.Position)
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"empoweredpixels/internal/domain/shop"
+	"empoweredpixels/internal/infra/db"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -198,7 +199,7 @@ func (r *PlayerGoldPostgres) GetPlayerGold(ctx context.Context, userID int) (*sh
 	`
 
 	var pg shop.PlayerGold
-	err := r.db.QueryRow(ctx, query, userID).Scan(
+	err := db.Conn(ctx, r.db).QueryRow(ctx, query, userID).Scan(
 		&pg.UserID, &pg.Balance, &pg.LifetimeEarned, &pg.LifetimeSpent, &pg.Updated,
 	)
 	if err != nil {
@@ -226,7 +227,7 @@ func (r *PlayerGoldPostgres) AddGold(ctx context.Context, userID int, amount int
 			updated = NOW()
 	`
 
-	_, err := r.db.Exec(ctx, query, userID, amount)
+	_, err := db.Conn(ctx, r.db).Exec(ctx, query, userID, amount)
 	if err != nil {
 		return fmt.Errorf("failed to add gold: %w", err)
 	}
@@ -244,7 +245,7 @@ func (r *PlayerGoldPostgres) SpendGold(ctx context.Context, userID int, amount i
 		WHERE user_id = $1 AND balance >= $2
 	`
 
-	result, err := r.db.Exec(ctx, query, userID, amount)
+	result, err := db.Conn(ctx, r.db).Exec(ctx, query, userID, amount)
 	if err != nil {
 		return fmt.Errorf("failed to spend gold: %w", err)
 	}
@@ -320,7 +321,7 @@ func (r *TransactionPostgres) CreateTransaction(ctx context.Context, tx *shop.Tr
 	metadata, _ := json.Marshal(tx.Metadata)
 
 	var id int
-	err := r.db.QueryRow(ctx, query,
+	err := db.Conn(ctx, r.db).QueryRow(ctx, query,
 		tx.UserID, tx.ShopItemID, tx.ItemType, tx.ItemName,
 		tx.PriceAmount, tx.PriceCurrency, tx.GoldChange, tx.Status, metadata,
 	).Scan(&id)
@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StandingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStandingRepository(pool *pgxpool.Pool) *StandingRepository {
+	return &StandingRepository{pool: pool}
+}
+
+// Add adds the standing to the fighter's line in the table of its league
+// season, creating the line on the fighter's first match.
+func (r *StandingRepository) Add(ctx context.Context, s *leagues.Standing) error {
+	const query = `
+		insert into league_standings (league_id, season, fighter_id, user_id, points, matches, wins, kills, deaths, damage_dealt, updated)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		on conflict (league_id, season, fighter_id) do update set
+			points = league_standings.points + excluded.points,
+			matches = league_standings.matches + excluded.matches,
+			wins = league_standings.wins + excluded.wins,
+			kills = league_standings.kills + excluded.kills,
+			deaths = league_standings.deaths + excluded.deaths,
+			damage_dealt = league_standings.damage_dealt + excluded.damage_dealt,
+			updated = excluded.updated`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
+		s.LeagueID, s.Season, s.FighterID, s.UserID, s.Points, s.Matches, s.Wins, s.Kills, s.Deaths, s.DamageDealt, s.Updated,
+	)
+	return err
+}
+
+// AddResult records what a fighter scored in a league match.
+func (r *StandingRepository) AddResult(ctx context.Context, result *leagues.MatchResult) error {
+	const query = `
+		insert into league_match_results (league_id, match_id, fighter_id, user_id, placement, won, kills, deaths, damage_dealt, points)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		on conflict (league_id, match_id, fighter_id) do nothing`
+
+	res := result.Result
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
+		result.LeagueID, result.MatchID, res.FighterID, result.UserID, res.Placement, res.Won, res.Kills, res.Deaths, res.DamageDealt, res.Points,
+	)
+	return err
+}
+
+// ListByLeague returns the table of a league season with the names of the
+// fighters and their users, unordered.
+func (r *StandingRepository) ListByLeague(ctx context.Context, leagueID int, season int) ([]leagues.Standing, error) {
+	const query = `
+		select s.league_id, s.season, s.fighter_id, s.user_id, s.points, s.matches, s.wins, s.kills, s.deaths, s.damage_dealt, s.updated,
+			coalesce(f.name, ''), coalesce(u.username, '')
+		from league_standings s
+		left join fighters f on f.id = s.fighter_id
+		left join users u on u.id = s.user_id
+		where s.league_id = $1 and s.season = $2`
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, leagueID, season)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []leagues.Standing
+	for rows.Next() {
+		var s leagues.Standing
+		if err := rows.Scan(
+			&s.LeagueID, &s.Season, &s.FighterID, &s.UserID, &s.Points, &s.Matches, &s.Wins, &s.Kills, &s.Deaths, &s.DamageDealt, &s.Updated,
+			&s.FighterName, &s.Username,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+// Highscores sums what every fighter scored over the latest scored matches
+// of a league, unordered.
+func (r *StandingRepository) Highscores(ctx context.Context, leagueID int, lastMatches int) ([]leagues.Standing, error) {
+	const query = `
+		with recent as (
+			select match_id
+			from league_matches
+			where league_id = $1 and scored is not null
+			order by scored desc
+			limit $2
+		)
+		select r.league_id, r.fighter_id, r.user_id, sum(r.points), count(*), count(*) filter (where r.won),
+			sum(r.kills), sum(r.deaths), sum(r.damage_dealt), coalesce(f.name, ''), coalesce(u.username, '')
+		from league_match_results r
+		join recent m on m.match_id = r.match_id
+		left join fighters f on f.id = r.fighter_id
+		left join users u on u.id = r.user_id
+		where r.league_id = $1
+		group by r.league_id, r.fighter_id, r.user_id, f.name, u.username`
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, leagueID, lastMatches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []leagues.Standing
+	for rows.Next() {
+		var s leagues.Standing
+		if err := rows.Scan(
+			&s.LeagueID, &s.FighterID, &s.UserID, &s.Points, &s.Matches, &s.Wins,
+			&s.Kills, &s.Deaths, &s.DamageDealt, &s.FighterName, &s.Username,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+// ListSeasons returns the seasons the league has standings for, latest
+// first.
+func (r *StandingRepository) ListSeasons(ctx context.Context, leagueID int) ([]int, error) {
+	const query = `
+		select distinct season
+		from league_standings
+		where league_id = $1
+		order by season desc`
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []int
+	for rows.Next() {
+		var season int
+		if err := rows.Scan(&season); err != nil {
+			return nil, err
+		}
+		result = append(result, season)
+	}
+	return result, rows.Err()
+}
+
+// PointsByUser returns the points every user scored over all leagues in a
+// season, most first.
+func (r *StandingRepository) PointsByUser(ctx context.Context, season int) ([]leagues.UserPoints, error) {
+	const query = `
+		select user_id, sum(points)
+		from league_standings
+		where season = $1
+		group by user_id
+		having sum(points) > 0
+		order by sum(points) desc, user_id`
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, season)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []leagues.UserPoints
+	for rows.Next() {
+		var p leagues.UserPoints
+		if err := rows.Scan(&p.UserID, &p.Points); err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
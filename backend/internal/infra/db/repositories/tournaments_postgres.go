@@ -0,0 +1,258 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"empoweredpixels/internal/domain/tournaments"
+	"empoweredpixels/internal/infra/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TournamentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTournamentRepository(pool *pgxpool.Pool) *TournamentRepository {
+	return &TournamentRepository{pool: pool}
+}
+
+const tournamentColumns = `id, league_id, format, status, round, rounds, created, completed`
+
+func scanTournament(row pgx.Row) (*tournaments.Tournament, error) {
+	var t tournaments.Tournament
+	err := row.Scan(&t.ID, &t.LeagueID, &t.Format, &t.Status, &t.Round, &t.Rounds, &t.Created, &t.Completed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TournamentRepository) listTournaments(ctx context.Context, query string, args ...any) ([]tournaments.Tournament, error) {
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []tournaments.Tournament
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *t)
+	}
+	return result, rows.Err()
+}
+
+func (r *TournamentRepository) Create(ctx context.Context, t *tournaments.Tournament) error {
+	const query = `
+		insert into tournaments (` + tournamentColumns + `)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
+		t.ID, t.LeagueID, string(t.Format), t.Status, t.Round, t.Rounds, t.Created, t.Completed,
+	)
+	return err
+}
+
+func (r *TournamentRepository) Get(ctx context.Context, id string) (*tournaments.Tournament, error) {
+	query := `select ` + tournamentColumns + ` from tournaments where id = $1`
+	return scanTournament(db.Conn(ctx, r.pool).QueryRow(ctx, query, id))
+}
+
+// Lock returns the tournament locked until the surrounding unit of work
+// ends, so that results of its matches are counted one at a time.
+func (r *TournamentRepository) Lock(ctx context.Context, id string) (*tournaments.Tournament, error) {
+	query := `select ` + tournamentColumns + ` from tournaments where id = $1 for update`
+	return scanTournament(db.Conn(ctx, r.pool).QueryRow(ctx, query, id))
+}
+
+func (r *TournamentRepository) Update(ctx context.Context, t *tournaments.Tournament) error {
+	const query = `update tournaments set status = $2, round = $3, completed = $4 where id = $1`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, t.ID, t.Status, t.Round, t.Completed)
+	return err
+}
+
+func (r *TournamentRepository) GetRunningByLeague(ctx context.Context, leagueID int) (*tournaments.Tournament, error) {
+	query := `select ` + tournamentColumns + ` from tournaments where league_id = $1 and status = 'running'`
+	return scanTournament(db.Conn(ctx, r.pool).QueryRow(ctx, query, leagueID))
+}
+
+func (r *TournamentRepository) ListRunning(ctx context.Context) ([]tournaments.Tournament, error) {
+	query := `select ` + tournamentColumns + ` from tournaments where status = 'running' order by created`
+	return r.listTournaments(ctx, query)
+}
+
+// ListByLeague returns the tournaments of a league, the latest first.
+func (r *TournamentRepository) ListByLeague(ctx context.Context, leagueID int, limit int, offset int) ([]tournaments.Tournament, error) {
+	query := `select ` + tournamentColumns + ` from tournaments where league_id = $1 order by created desc limit $2 offset $3`
+	return r.listTournaments(ctx, query, leagueID, limit, offset)
+}
+
+const entrantColumns = `tournament_id, fighter_id, user_id, seed, rating, wins, losses, draws, byes, eliminated, placement, members`
+
+func scanEntrant(row pgx.Row) (*tournaments.Entrant, error) {
+	var e tournaments.Entrant
+	err := row.Scan(
+		&e.TournamentID, &e.FighterID, &e.UserID, &e.Seed, &e.Rating,
+		&e.Wins, &e.Losses, &e.Draws, &e.Byes, &e.Eliminated, &e.Placement, &e.Members,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *TournamentRepository) CreateEntrants(ctx context.Context, entrants []tournaments.Entrant) error {
+	const query = `
+		insert into tournament_entrants (` + entrantColumns + `)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	batch := &pgx.Batch{}
+	for _, e := range entrants {
+		batch.Queue(query, e.TournamentID, e.FighterID, e.UserID, e.Seed, e.Rating,
+			e.Wins, e.Losses, e.Draws, e.Byes, e.Eliminated, e.Placement, e.Team())
+	}
+
+	br := db.Conn(ctx, r.pool).SendBatch(ctx, batch)
+	defer br.Close()
+	for range entrants {
+		if _, err := br.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListEntrants returns the entrants of a tournament by seed.
+func (r *TournamentRepository) ListEntrants(ctx context.Context, tournamentID string) ([]tournaments.Entrant, error) {
+	query := `select ` + entrantColumns + ` from tournament_entrants where tournament_id = $1 order by seed`
+
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []tournaments.Entrant
+	for rows.Next() {
+		e, err := scanEntrant(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *e)
+	}
+	return result, rows.Err()
+}
+
+func (r *TournamentRepository) UpdateEntrant(ctx context.Context, e *tournaments.Entrant) error {
+	const query = `
+		update tournament_entrants
+		set wins = $3, losses = $4, draws = $5, byes = $6, eliminated = $7, placement = $8
+		where tournament_id = $1 and fighter_id = $2`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
+		e.TournamentID, e.FighterID, e.Wins, e.Losses, e.Draws, e.Byes, e.Eliminated, e.Placement,
+	)
+	return err
+}
+
+// LastChampion returns the winner of the league's latest completed
+// tournament.
+func (r *TournamentRepository) LastChampion(ctx context.Context, leagueID int) (*tournaments.Tournament, *tournaments.Entrant, error) {
+	query := `select ` + tournamentColumns + ` from tournaments
+		where league_id = $1 and status = 'completed'
+		order by completed desc
+		limit 1`
+
+	t, err := scanTournament(db.Conn(ctx, r.pool).QueryRow(ctx, query, leagueID))
+	if err != nil || t == nil {
+		return nil, nil, err
+	}
+
+	query = `select ` + entrantColumns + ` from tournament_entrants where tournament_id = $1 and placement = 1`
+	champion, err := scanEntrant(db.Conn(ctx, r.pool).QueryRow(ctx, query, t.ID))
+	if err != nil || champion == nil {
+		return nil, nil, err
+	}
+	return t, champion, nil
+}
+
+const pairingColumns = `id, tournament_id, round, bracket, slot, fighter_a, fighter_b, match_id, winner_id, status`
+
+func scanPairing(row pgx.Row) (*tournaments.Pairing, error) {
+	var p tournaments.Pairing
+	err := row.Scan(&p.ID, &p.TournamentID, &p.Round, &p.Bracket, &p.Slot, &p.FighterA, &p.FighterB, &p.MatchID, &p.WinnerID, &p.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *TournamentRepository) listPairings(ctx context.Context, query string, args ...any) ([]tournaments.Pairing, error) {
+	rows, err := db.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []tournaments.Pairing
+	for rows.Next() {
+		p, err := scanPairing(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *p)
+	}
+	return result, rows.Err()
+}
+
+func (r *TournamentRepository) CreatePairing(ctx context.Context, p *tournaments.Pairing) error {
+	const query = `
+		insert into tournament_pairings (` + pairingColumns + `)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query,
+		p.ID, p.TournamentID, p.Round, p.Bracket, p.Slot, p.FighterA, p.FighterB, p.MatchID, p.WinnerID, p.Status,
+	)
+	return err
+}
+
+func (r *TournamentRepository) UpdatePairing(ctx context.Context, p *tournaments.Pairing) error {
+	const query = `update tournament_pairings set match_id = $2, winner_id = $3, status = $4 where id = $1`
+
+	_, err := db.Conn(ctx, r.pool).Exec(ctx, query, p.ID, p.MatchID, p.WinnerID, p.Status)
+	return err
+}
+
+// ListPairings returns the pairings of a tournament in bracket order.
+func (r *TournamentRepository) ListPairings(ctx context.Context, tournamentID string) ([]tournaments.Pairing, error) {
+	query := `select ` + pairingColumns + ` from tournament_pairings where tournament_id = $1 order by round, bracket desc, slot`
+	return r.listPairings(ctx, query, tournamentID)
+}
+
+func (r *TournamentRepository) GetPairingByMatch(ctx context.Context, matchID string) (*tournaments.Pairing, error) {
+	query := `select ` + pairingColumns + ` from tournament_pairings where match_id = $1`
+	return scanPairing(db.Conn(ctx, r.pool).QueryRow(ctx, query, matchID))
+}
+
+// ListRunningPairings returns the pairings of every tournament that still
+// wait for their match.
+func (r *TournamentRepository) ListRunningPairings(ctx context.Context) ([]tournaments.Pairing, error) {
+	query := `select ` + pairingColumns + ` from tournament_pairings where status = 'running' order by tournament_id, round, slot`
+	return r.listPairings(ctx, query)
+}
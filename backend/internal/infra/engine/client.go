@@ -5,18 +5,37 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/roster"
+	matchesusecase "empoweredpixels/internal/usecase/matches"
+)
+
+var (
+	ErrNotConfigured   = errors.New("engine url not configured")
+	ErrInvalidResponse = errors.New("invalid engine response")
 )
 
+// Client runs matches on a remote combat engine. It implements
+// matches.CombatEngine.
 type Client struct {
-	baseURL string
-	http    *http.Client
+	baseURL      string
+	http         *http.Client
+	retries      int
+	retryBackoff time.Duration
 }
 
+// Config configures the client. Timeout bounds every attempt; a failed
+// attempt is retried up to Retries times, waiting RetryBackoff, doubled after
+// every retry, in between.
 type Config struct {
-	BaseURL string
-	Timeout time.Duration
+	BaseURL      string
+	Timeout      time.Duration
+	Retries      int
+	RetryBackoff time.Duration
 }
 
 type MatchRequest struct {
@@ -25,21 +44,58 @@ type MatchRequest struct {
 	Fighters []FighterInput  `json:"fighters"`
 }
 
+// FighterInput is a participant with everything the engine needs to fight
+// with it. Gear, skills and attunement bonuses are part of the options.
 type FighterInput struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	UserID         int64   `json:"userId"`
+	IsBot          bool    `json:"isBot"`
+	TeamID         *string `json:"teamId"`
+	Level          int     `json:"level"`
+	Power          int     `json:"power"`
+	ConditionPower int     `json:"conditionPower"`
+	Precision      int     `json:"precision"`
+	Ferocity       int     `json:"ferocity"`
+	Accuracy       int     `json:"accuracy"`
+	Agility        int     `json:"agility"`
+	Armor          int     `json:"armor"`
+	Vitality       int     `json:"vitality"`
+	ParryChance    int     `json:"parryChance"`
+	HealingPower   int     `json:"healingPower"`
+	Speed          int     `json:"speed"`
+	Vision         int     `json:"vision"`
+	WeaponID       *string `json:"weaponId"`
+	AttunementID   *string `json:"attunementId"`
 }
 
 type MatchResult struct {
-	RoundTicks json.RawMessage `json:"roundTicks"`
-	Scores     []ScoreResult   `json:"scores"`
+	Seed         int64           `json:"seed"`
+	RoundTicks   json.RawMessage `json:"roundTicks"`
+	Scores       []ScoreResult   `json:"scores"`
+	WinnerIDs    []string        `json:"winnerIds"`
+	WinnerTeamID *string         `json:"winnerTeamId"`
 }
 
 type ScoreResult struct {
-	FighterID    string `json:"fighterId"`
-	TotalKills   int    `json:"totalKills"`
-	TotalDeaths  int    `json:"totalDeaths"`
-	TotalAssists int    `json:"totalAssists"`
+	FighterID      string `json:"fighterId"`
+	IsBot          bool   `json:"isBot"`
+	TotalKills     int    `json:"totalKills"`
+	TotalDeaths    int    `json:"totalDeaths"`
+	TotalAssists   int    `json:"totalAssists"`
+	DamageDealt    int    `json:"damageDealt"`
+	DamageTaken    int    `json:"damageTaken"`
+	Healed         int    `json:"healed"`
+	UltimateCharge int    `json:"ultimateCharge"`
+}
+
+// statusError is a non-2xx engine response.
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("engine error: status %d", e.status)
 }
 
 func NewClient(cfg Config) *Client {
@@ -47,15 +103,100 @@ func NewClient(cfg Config) *Client {
 	if timeout == 0 {
 		timeout = 10 * time.Second
 	}
+	backoff := cfg.RetryBackoff
+	if backoff == 0 {
+		backoff = 200 * time.Millisecond
+	}
+	retries := cfg.Retries
+	if retries < 0 {
+		retries = 0
+	}
 	return &Client{
-		baseURL: cfg.BaseURL,
-		http:    &http.Client{Timeout: timeout},
+		baseURL:      cfg.BaseURL,
+		http:         &http.Client{Timeout: timeout},
+		retries:      retries,
+		retryBackoff: backoff,
 	}
 }
 
+// Run resolves a battle on the remote engine.
+func (c *Client) Run(ctx context.Context, matchID string, fighters []roster.Fighter, options matchesusecase.BattleOptions) (*combat.MatchResult, error) {
+	encodedOptions, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	req := MatchRequest{
+		MatchID:  matchID,
+		Options:  encodedOptions,
+		Fighters: make([]FighterInput, 0, len(fighters)),
+	}
+	for _, f := range fighters {
+		input := FighterInput{
+			ID:             f.ID,
+			Name:           f.Name,
+			UserID:         f.UserID,
+			IsBot:          f.IsBot,
+			Level:          f.Level,
+			Power:          f.Power,
+			ConditionPower: f.ConditionPower,
+			Precision:      f.Precision,
+			Ferocity:       f.Ferocity,
+			Accuracy:       f.Accuracy,
+			Agility:        f.Agility,
+			Armor:          f.Armor,
+			Vitality:       f.Vitality,
+			ParryChance:    f.ParryChance,
+			HealingPower:   f.HealingPower,
+			Speed:          f.Speed,
+			Vision:         f.Vision,
+			WeaponID:       f.WeaponID,
+			AttunementID:   f.AttunementID,
+		}
+		if teamID, ok := options.Teams[f.ID]; ok {
+			input.TeamID = &teamID
+		}
+		req.Fighters = append(req.Fighters, input)
+	}
+
+	remote, err := c.RunMatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &combat.MatchResult{
+		MatchID:      matchID,
+		Seed:         remote.Seed,
+		WinnerIDs:    remote.WinnerIDs,
+		WinnerTeamID: remote.WinnerTeamID,
+	}
+	if result.Seed == 0 {
+		result.Seed = options.Seed
+	}
+	if err := json.Unmarshal(remote.RoundTicks, &result.RoundTicks); err != nil {
+		return nil, ErrInvalidResponse
+	}
+	for _, score := range remote.Scores {
+		result.Scores = append(result.Scores, combat.FighterScore{
+			FighterID:      score.FighterID,
+			IsBot:          score.IsBot,
+			Kills:          score.TotalKills,
+			Deaths:         score.TotalDeaths,
+			Assists:        score.TotalAssists,
+			DamageDealt:    score.DamageDealt,
+			DamageTaken:    score.DamageTaken,
+			Healed:         score.Healed,
+			UltimateCharge: score.UltimateCharge,
+		})
+	}
+	return result, nil
+}
+
+// RunMatch posts the request to the engine, retrying timeouts, network
+// errors and server errors.
 func (c *Client) RunMatch(ctx context.Context, req MatchRequest) (*MatchResult, error) {
 	if c.baseURL == "" {
-		return nil, errors.New("engine url not configured")
+		return nil, ErrNotConfigured
 	}
 
 	body, err := json.Marshal(req)
@@ -63,6 +204,23 @@ func (c *Client) RunMatch(ctx context.Context, req MatchRequest) (*MatchResult,
 		return nil, err
 	}
 
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := c.post(ctx, body)
+		if err == nil || attempt >= c.retries || !retryable(err) {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) post(ctx context.Context, body []byte) (*MatchResult, error) {
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/match/run", bytes.NewReader(body))
 	if err != nil {
 		return nil, err
@@ -76,12 +234,25 @@ func (c *Client) RunMatch(ctx context.Context, req MatchRequest) (*MatchResult,
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, errors.New("engine error")
+		return nil, &statusError{status: resp.StatusCode}
 	}
 
 	var result MatchResult
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+		return nil, ErrInvalidResponse
 	}
 	return &result, nil
 }
+
+// retryable reports whether another attempt may succeed: everything but
+// client errors and invalid responses.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrInvalidResponse) {
+		return false
+	}
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.status >= 500 || status.status == http.StatusTooManyRequests
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/roster"
+	matchesusecase "empoweredpixels/internal/usecase/matches"
+)
+
+// stubEngine is an in-process engine answering /match/run. The first
+// failures calls answer with status; after that it decides the battle from
+// the request alone: the fighter with the most power wins. The last decoded
+// request is stored in received.
+func stubEngine(t *testing.T, failures int32, status int, received *MatchRequest) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if r.Method != http.MethodPost || r.URL.Path != "/match/run" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected a JSON request, got %q", r.Header.Get("Content-Type"))
+		}
+		if n <= failures {
+			w.WriteHeader(status)
+			return
+		}
+
+		var req MatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if received != nil {
+			*received = req
+		}
+		var options matchesusecase.BattleOptions
+		if err := json.Unmarshal(req.Options, &options); err != nil {
+			t.Errorf("decode options: %v", err)
+		}
+
+		winner := req.Fighters[0]
+		for _, f := range req.Fighters {
+			if f.Power > winner.Power {
+				winner = f
+			}
+		}
+		ticks, _ := json.Marshal([]combat.RoundTick{{Round: 0, Ticks: []combat.Tick{}}, {Round: 1, Ticks: []combat.Tick{}}})
+		result := MatchResult{
+			Seed:       options.Seed,
+			RoundTicks: ticks,
+			WinnerIDs:  []string{winner.ID},
+		}
+		for _, f := range req.Fighters {
+			score := ScoreResult{FighterID: f.ID, IsBot: f.IsBot, DamageDealt: f.Power}
+			if f.ID == winner.ID {
+				score.TotalKills = len(req.Fighters) - 1
+			} else {
+				score.TotalDeaths = 1
+			}
+			result.Scores = append(result.Scores, score)
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func contractFighters() []roster.Fighter {
+	weapon := "sword_1"
+	return []roster.Fighter{
+		{ID: "a", Name: "Alpha", UserID: 1, Level: 3, Power: 30, Accuracy: 12, Agility: 8, Armor: 5, Vitality: 9, Speed: 7, Vision: 4, WeaponID: &weapon},
+		{ID: "b", Name: "Bravo", UserID: 2, Level: 2, Power: 20, ParryChance: 10, HealingPower: 6},
+		{ID: "bot", Name: "Bot", Power: 10, IsBot: true},
+	}
+}
+
+func TestClient_Contract(t *testing.T) {
+	var received MatchRequest
+	server, _ := stubEngine(t, 0, 0, &received)
+
+	client := NewClient(Config{BaseURL: server.URL})
+	options := matchesusecase.BattleOptions{MaxRounds: 50, MapSize: 20, Seed: 42, Teams: map[string]string{"a": "red"}}
+	result, err := client.Run(context.Background(), "match-1", contractFighters(), options)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	// Request: every participant with its full stats
+	if received.MatchID != "match-1" || len(received.Fighters) != 3 {
+		t.Fatalf("unexpected request %+v", received)
+	}
+	alpha := received.Fighters[0]
+	if alpha.Power != 30 || alpha.Accuracy != 12 || alpha.Agility != 8 || alpha.Armor != 5 ||
+		alpha.Vitality != 9 || alpha.Speed != 7 || alpha.Vision != 4 || alpha.Level != 3 || alpha.UserID != 1 {
+		t.Fatalf("expected full stats, got %+v", alpha)
+	}
+	if alpha.WeaponID == nil || *alpha.WeaponID != "sword_1" || alpha.TeamID == nil || *alpha.TeamID != "red" {
+		t.Fatalf("expected weapon and team, got %+v", alpha)
+	}
+	if bravo := received.Fighters[1]; bravo.ParryChance != 10 || bravo.HealingPower != 6 || bravo.TeamID != nil {
+		t.Fatalf("unexpected second fighter %+v", bravo)
+	}
+	if !received.Fighters[2].IsBot {
+		t.Fatal("expected bots to be marked")
+	}
+
+	// Response: mapped onto the domain result
+	if result.MatchID != "match-1" || result.Seed != 42 || len(result.RoundTicks) != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(result.WinnerIDs) != 1 || result.WinnerIDs[0] != "a" {
+		t.Fatalf("expected a to win, got %v", result.WinnerIDs)
+	}
+	if len(result.Scores) != 3 || result.Scores[0].Kills != 2 || result.Scores[0].DamageDealt != 30 || !result.Scores[2].IsBot {
+		t.Fatalf("unexpected scores %+v", result.Scores)
+	}
+}
+
+func TestClient_RetriesServerErrors(t *testing.T) {
+	server, calls := stubEngine(t, 2, http.StatusServiceUnavailable, nil)
+	client := NewClient(Config{BaseURL: server.URL, Retries: 2, RetryBackoff: time.Millisecond})
+
+	if _, err := client.Run(context.Background(), "match-1", contractFighters(), matchesusecase.BattleOptions{}); err != nil {
+		t.Fatalf("expected the third attempt to succeed: %v", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls := stubEngine(t, 5, http.StatusBadRequest, nil)
+	client := NewClient(Config{BaseURL: server.URL, Retries: 3, RetryBackoff: time.Millisecond})
+
+	if _, err := client.Run(context.Background(), "match-1", contractFighters(), matchesusecase.BattleOptions{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if *calls != 1 {
+		t.Fatalf("expected a single attempt, got %d", *calls)
+	}
+}
+
+func TestClient_TimesOut(t *testing.T) {
+	release := make(chan struct{})
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(Config{BaseURL: server.URL, Timeout: 20 * time.Millisecond, Retries: 1, RetryBackoff: time.Millisecond})
+	if _, err := client.Run(context.Background(), "match-1", contractFighters(), matchesusecase.BattleOptions{}); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("expected the timed out attempt to be retried once, got %d attempts", calls)
+	}
+}
+
+func TestClient_NotConfigured(t *testing.T) {
+	client := NewClient(Config{})
+	if _, err := client.Run(context.Background(), "match-1", nil, matchesusecase.BattleOptions{}); err != ErrNotConfigured {
+		t.Fatalf("expected ErrNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	leaderboardusecase "empoweredpixels/internal/usecase/leaderboard"
+)
+
+// LeaderboardJob recalculates the leaderboard categories every interval.
+type LeaderboardJob struct {
+	service  *leaderboardusecase.Service
+	interval time.Duration
+}
+
+func NewLeaderboardJob(service *leaderboardusecase.Service, interval time.Duration) *LeaderboardJob {
+	return &LeaderboardJob{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (j *LeaderboardJob) Name() string {
+	return "leaderboard"
+}
+
+func (j *LeaderboardJob) Interval() time.Duration {
+	return j.interval
+}
+
+func (j *LeaderboardJob) Run(ctx context.Context) error {
+	return j.service.RecalculateAll(ctx)
+}
@@ -2,26 +2,52 @@ package jobs
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"log"
 	"time"
 
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/tournaments"
 	"empoweredpixels/internal/infra/db/repositories"
 	matchesusecase "empoweredpixels/internal/usecase/matches"
+	tournamentsusecase "empoweredpixels/internal/usecase/tournaments"
 )
 
 var (
-	ErrLeagueNotFound   = errors.New("league not found")
-	ErrNoSubscriptions  = errors.New("league has no subscriptions")
+	ErrLeagueNotFound    = errors.New("league not found")
+	ErrNoSubscriptions   = errors.New("league has no subscriptions")
+	ErrLeagueInactive    = errors.New("league is not active")
+	ErrNotEnoughFighters = errors.New("league has too few subscriptions")
 )
 
+// LeagueNotifier tells users about the league matches their fighters played.
+type LeagueNotifier interface {
+	LeagueMatchFinished(ctx context.Context, userID int64, leagueID int, leagueName string, matchID string, won bool) error
+}
+
+// LeagueSchedule claims the leagues whose schedule came due.
+type LeagueSchedule interface {
+	DueLeagues(ctx context.Context) ([]leagues.League, error)
+}
+
+// TournamentStarter starts the tournaments leagues run instead of a single
+// match.
+type TournamentStarter interface {
+	Start(ctx context.Context, leagueID int, format tournaments.Format) (*tournaments.Tournament, error)
+}
+
 type LeagueJob struct {
 	matchService    *matchesusecase.Service
 	leagueRepo      *repositories.LeagueRepository
 	subRepo         *repositories.LeagueSubscriptionRepository
 	leagueMatchRepo *repositories.LeagueMatchRepository
 	fighterRepo     *repositories.FighterRepository
+	notifier        LeagueNotifier
+	tournaments     TournamentStarter
+	schedule        LeagueSchedule
 	interval        time.Duration
+	now             func() time.Time
 }
 
 func NewLeagueJob(
@@ -39,44 +65,63 @@ func NewLeagueJob(
 		leagueMatchRepo: leagueMatchRepo,
 		fighterRepo:     fighterRepo,
 		interval:        interval,
+		now:             time.Now,
 	}
 }
 
-func (j *LeagueJob) Start() {
-	if j.interval <= 0 {
-		return
-	}
+// SetNotifier installs the notifier told about every finished league match.
+func (j *LeagueJob) SetNotifier(notifier LeagueNotifier) {
+	j.notifier = notifier
+}
 
-	go func() {
-		ticker := time.NewTicker(j.interval)
-		defer ticker.Stop()
+// SetTournaments makes every league run a tournament between its
+// subscribers instead of one match with all of them.
+func (j *LeagueJob) SetTournaments(tournaments TournamentStarter) {
+	j.tournaments = tournaments
+}
 
-		for {
-			select {
-			case <-ticker.C:
-				j.runAllActiveLeagues()
-			}
-		}
-	}()
+// SetSchedule makes every tick run the leagues whose own schedule came due
+// instead of every league.
+func (j *LeagueJob) SetSchedule(schedule LeagueSchedule) {
+	j.schedule = schedule
 }
 
-func (j *LeagueJob) runAllActiveLeagues() {
-	ctx := context.Background()
-	leagues, err := j.leagueRepo.List(ctx)
-	if err != nil {
-		return
-	}
+func (j *LeagueJob) Name() string {
+	return "league"
+}
 
-	for _, league := range leagues {
-		_ = j.RunLeague(ctx, league.ID)
+func (j *LeagueJob) Interval() time.Duration {
+	return j.interval
+}
+
+// Run runs the leagues that came due. Failed leagues are logged and don't
+// keep the others from running; the leagues claimed before the schedule
+// failed still run.
+func (j *LeagueJob) Run(ctx context.Context) error {
+	var due []leagues.League
+	var err error
+	if j.schedule != nil {
+		due, err = j.schedule.DueLeagues(ctx)
+	} else {
+		due, err = j.leagueRepo.List(ctx)
+	}
+
+	for _, league := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := j.RunLeague(ctx, league.ID); err != nil {
+			log.Printf("league %d run error: %v", league.ID, err)
+		}
 	}
+	return err
 }
 
 func (j *LeagueJob) RunMatch(ctx context.Context, matchID string) error {
 	if j.matchService == nil {
 		return nil
 	}
-	return j.matchService.ExecuteMatch(ctx, matchID)
+	return j.matchService.StartMatch(ctx, matchID)
 }
 
 func (j *LeagueJob) RunLeague(ctx context.Context, leagueID int) error {
@@ -88,6 +133,9 @@ func (j *LeagueJob) RunLeague(ctx context.Context, leagueID int) error {
 	if err != nil || league == nil {
 		return ErrLeagueNotFound
 	}
+	if !league.Active(j.now()) {
+		return ErrLeagueInactive
+	}
 
 	subs, err := j.subRepo.ListByLeague(ctx, leagueID)
 	if err != nil {
@@ -96,53 +144,100 @@ func (j *LeagueJob) RunLeague(ctx context.Context, leagueID int) error {
 	if len(subs) == 0 {
 		return ErrNoSubscriptions
 	}
-
-	firstFighter, err := j.fighterRepo.GetByID(ctx, subs[0].FighterID)
-	if err != nil || firstFighter == nil {
-		return err
+	if len(subs) < league.Config.MinFighters {
+		return ErrNotEnoughFighters
 	}
-	creatorUserID := firstFighter.UserID
 
-	options := j.matchService.DefaultOptions()
-	if len(league.Options) > 0 {
-		var leagueOpts struct {
-			BotCount      *int `json:"botCount"`
-			BotPowerlevel *int `json:"botPowerlevel"`
-		}
-		_ = json.Unmarshal(league.Options, &leagueOpts)
-		if leagueOpts.BotCount != nil {
-			options.BotCount = leagueOpts.BotCount
+	if j.tournaments != nil {
+		_, err := j.tournaments.Start(ctx, leagueID, league.Config.Format)
+		if errors.Is(err, tournamentsusecase.ErrTournamentRunning) {
+			// The last run's tournament is still being played
+			return nil
 		}
-		if leagueOpts.BotPowerlevel != nil {
-			options.BotPowerlevel = leagueOpts.BotPowerlevel
-		}
-	}
-
-	match, err := j.matchService.CreateMatch(ctx, creatorUserID, options)
-	if err != nil {
 		return err
 	}
 
+	var fighters []roster.Fighter
 	for _, sub := range subs {
 		fighter, err := j.fighterRepo.GetByID(ctx, sub.FighterID)
-		if err != nil || fighter == nil {
+		if err != nil || fighter == nil || fighter.IsDeleted {
 			continue
 		}
-		_ = j.matchService.Join(ctx, fighter.UserID, match.ID, sub.FighterID)
+		fighters = append(fighters, *fighter)
 	}
 
-	if err := j.leagueMatchRepo.Create(ctx, leagueID, match.ID); err != nil {
+	options := j.matchService.DefaultOptions()
+	if league.Config.BotCount != nil {
+		options.BotCount = league.Config.BotCount
+	}
+	if league.Config.BotPowerlevel != nil {
+		options.BotPowerlevel = league.Config.BotPowerlevel
+	}
+
+	match, err := j.matchService.CreateTeamMatchFor(ctx, options, league.Config.Teams(fighters))
+	if err != nil {
 		return err
 	}
 
-	if err := j.matchService.ExecuteMatch(ctx, match.ID); err != nil {
+	if err := j.leagueMatchRepo.Create(ctx, leagueID, match.ID); err != nil {
 		return err
 	}
 
-	updated, _ := j.matchService.GetMatch(ctx, match.ID)
-	if updated != nil && updated.Started != nil {
-		_ = j.leagueMatchRepo.UpdateStarted(ctx, leagueID, match.ID, updated.Started)
+	return j.matchService.StartMatch(ctx, match.ID)
+}
+
+// MatchFinished records the start of a finished league match and tells its
+// players how it went. Matches that are not league matches are ignored.
+func (j *LeagueJob) MatchFinished(ctx context.Context, matchID string) {
+	if j.leagueRepo == nil || j.leagueMatchRepo == nil || j.fighterRepo == nil || j.matchService == nil {
+		return
+	}
+
+	leagueMatch, err := j.leagueMatchRepo.GetByMatch(ctx, matchID)
+	if err != nil || leagueMatch == nil {
+		return
+	}
+
+	match, _ := j.matchService.GetMatch(ctx, matchID)
+	if match != nil && match.Started != nil {
+		_ = j.leagueMatchRepo.UpdateStarted(ctx, leagueMatch.LeagueID, matchID, match.Started)
+	}
+
+	league, err := j.leagueRepo.GetByID(ctx, leagueMatch.LeagueID)
+	if err != nil || league == nil {
+		return
 	}
 
-	return nil
+	// Fighters of every user that joined
+	fighters, err := j.fighterRepo.ListByMatch(ctx, matchID)
+	if err != nil {
+		return
+	}
+	joined := make(map[int64][]string)
+	for _, f := range fighters {
+		joined[f.UserID] = append(joined[f.UserID], f.ID)
+	}
+
+	j.notifyFinished(ctx, league.ID, league.Name, matchID, joined)
+}
+
+func (j *LeagueJob) notifyFinished(ctx context.Context, leagueID int, leagueName string, matchID string, joined map[int64][]string) {
+	if j.notifier == nil {
+		return
+	}
+
+	winners := make(map[string]bool)
+	if summary, err := j.matchService.BattleSummary(ctx, matchID); err == nil {
+		for _, id := range summary.WinnerIDs {
+			winners[id] = true
+		}
+	}
+
+	for userID, fighterIDs := range joined {
+		won := false
+		for _, id := range fighterIDs {
+			won = won || winners[id]
+		}
+		_ = j.notifier.LeagueMatchFinished(ctx, userID, leagueID, leagueName, matchID, won)
+	}
 }
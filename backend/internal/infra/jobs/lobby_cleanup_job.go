@@ -12,7 +12,6 @@ type LobbyCleanupJob struct {
 	matchService     *matchesusecase.Service
 	olderThanMinutes int
 	interval         time.Duration
-	stop             chan struct{}
 }
 
 func NewLobbyCleanupJob(matchService *matchesusecase.Service, olderThanMinutes int, interval time.Duration) *LobbyCleanupJob {
@@ -20,38 +19,24 @@ func NewLobbyCleanupJob(matchService *matchesusecase.Service, olderThanMinutes i
 		matchService:     matchService,
 		olderThanMinutes: olderThanMinutes,
 		interval:         interval,
-		stop:             make(chan struct{}),
 	}
 }
 
-func (j *LobbyCleanupJob) Start() {
-	go func() {
-		ticker := time.NewTicker(j.interval)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				j.Run()
-			case <-j.stop:
-				return
-			}
-		}
-	}()
+func (j *LobbyCleanupJob) Name() string {
+	return "lobby-cleanup"
 }
 
-func (j *LobbyCleanupJob) Stop() {
-	close(j.stop)
+func (j *LobbyCleanupJob) Interval() time.Duration {
+	return j.interval
 }
 
-func (j *LobbyCleanupJob) Run() {
-	ctx := context.Background()
+func (j *LobbyCleanupJob) Run(ctx context.Context) error {
 	cancelled, err := j.matchService.CleanupStaleLobbies(ctx, j.olderThanMinutes)
 	if err != nil {
-		log.Printf("lobby cleanup error: %v", err)
-		return
+		return err
 	}
 	if cancelled > 0 {
 		log.Printf("lobby cleanup: cancelled %d stale lobbies", cancelled)
 	}
+	return nil
 }
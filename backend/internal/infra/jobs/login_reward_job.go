@@ -1,14 +1,43 @@
 package jobs
 
-import "context"
+import (
+	"context"
+	"time"
 
+	dailyusecase "empoweredpixels/internal/usecase/daily"
+)
+
+// LoginRewardJob reminds returning players that their daily reward is ready.
 type LoginRewardJob struct {
+	dailyService *dailyusecase.Service
+	interval     time.Duration
+}
+
+func NewLoginRewardJob(dailyService *dailyusecase.Service, interval time.Duration) *LoginRewardJob {
+	return &LoginRewardJob{
+		dailyService: dailyService,
+		interval:     interval,
+	}
+}
+
+func (j *LoginRewardJob) Name() string {
+	return "login-reward"
+}
+
+func (j *LoginRewardJob) Interval() time.Duration {
+	return j.interval
 }
 
-func NewLoginRewardJob() *LoginRewardJob {
-	return &LoginRewardJob{}
+func (j *LoginRewardJob) Run(ctx context.Context) error {
+	return j.CreateLoginRewards(ctx)
 }
 
+// CreateLoginRewards notifies every player whose daily reward became
+// claimable. Players are notified once per day however often it runs.
 func (j *LoginRewardJob) CreateLoginRewards(ctx context.Context) error {
-	return nil
+	if j.dailyService == nil {
+		return nil
+	}
+	_, err := j.dailyService.NotifyClaimable(ctx)
+	return err
 }
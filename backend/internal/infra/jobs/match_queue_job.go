@@ -0,0 +1,132 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	matchesusecase "empoweredpixels/internal/usecase/matches"
+)
+
+// MatchQueueJob executes queued matches with a fixed number of workers. It
+// sweeps for matches left behind by dead workers when it starts and then
+// every sweepInterval.
+type MatchQueueJob struct {
+	matchService  *matchesusecase.Service
+	workers       int
+	pollInterval  time.Duration
+	sweepInterval time.Duration
+	stop          chan struct{}
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+func NewMatchQueueJob(matchService *matchesusecase.Service, workers int, pollInterval time.Duration, sweepInterval time.Duration) *MatchQueueJob {
+	if workers < 1 {
+		workers = 1
+	}
+	return &MatchQueueJob{
+		matchService:  matchService,
+		workers:       workers,
+		pollInterval:  pollInterval,
+		sweepInterval: sweepInterval,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start runs the workers and the sweep until Shutdown. The matches they
+// execute are cancelled with ctx or by a shutdown running out of time.
+func (j *MatchQueueJob) Start(ctx context.Context) {
+	ctx, j.cancel = context.WithCancel(ctx)
+	j.Sweep(ctx)
+
+	for i := 0; i < j.workers; i++ {
+		j.wg.Add(1)
+		go j.work(ctx)
+	}
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		ticker := time.NewTicker(j.sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				j.Sweep(ctx)
+			case <-j.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown lets the workers finish the matches they are running until ctx is
+// done, then cancels the matches left. It returns once every worker returned,
+// with the error of ctx when matches were cut short.
+func (j *MatchQueueJob) Shutdown(ctx context.Context) error {
+	if j.cancel == nil {
+		return nil
+	}
+	close(j.stop)
+
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		j.cancel()
+		return nil
+	case <-ctx.Done():
+		j.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work processes runs until the queue is empty, then polls for more.
+func (j *MatchQueueJob) work(ctx context.Context) {
+	defer j.wg.Done()
+
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		processed, err := j.matchService.ProcessNext(ctx)
+		if err != nil {
+			log.Printf("match queue error: %v", err)
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-time.After(j.pollInterval):
+		case <-j.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *MatchQueueJob) Sweep(ctx context.Context) {
+	recovered, err := j.matchService.RecoverStuckMatches(ctx)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("match queue sweep error: %v", err)
+	}
+	if recovered > 0 {
+		log.Printf("match queue sweep: recovered %d matches", recovered)
+	}
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	matchmakingusecase "empoweredpixels/internal/usecase/matchmaking"
+)
+
+// MatchmakingJob forms matches from the matchmaking queue every interval.
+type MatchmakingJob struct {
+	service  *matchmakingusecase.Service
+	interval time.Duration
+}
+
+func NewMatchmakingJob(service *matchmakingusecase.Service, interval time.Duration) *MatchmakingJob {
+	return &MatchmakingJob{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (j *MatchmakingJob) Name() string {
+	return "matchmaking"
+}
+
+func (j *MatchmakingJob) Interval() time.Duration {
+	return j.interval
+}
+
+func (j *MatchmakingJob) Run(ctx context.Context) error {
+	formed, err := j.service.Tick(ctx)
+	if err != nil {
+		return err
+	}
+	if formed > 0 {
+		log.Printf("matchmaking: formed %d matches", formed)
+	}
+	return nil
+}
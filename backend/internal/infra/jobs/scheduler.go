@@ -0,0 +1,363 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	jobsdomain "empoweredpixels/internal/domain/jobs"
+)
+
+var (
+	ErrUnknownJob       = errors.New("unknown job")
+	ErrJobRunning       = errors.New("job is already running")
+	ErrSchedulerStopped = errors.New("scheduler is not running")
+)
+
+// DefaultElectionInterval is how often instances try to take the lead.
+const DefaultElectionInterval = 15 * time.Second
+
+// Job is a background job the scheduler runs every interval. Jobs stop early
+// when their context is cancelled.
+type Job interface {
+	Name() string
+	Interval() time.Duration
+	Run(ctx context.Context) error
+}
+
+// Initializer is a job with setup its leader runs once before the first run,
+// as soon as it leads rather than an interval later. A failed Init is tried
+// again every interval, and the job doesn't run until it succeeds.
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// Elector elects the one instance that runs the scheduled jobs.
+type Elector interface {
+	// Leader reports whether this instance leads, taking the lead when it is
+	// free.
+	Leader(ctx context.Context) (bool, error)
+	Resign(ctx context.Context) error
+}
+
+// RunRepository keeps the history of the runs of every instance.
+type RunRepository interface {
+	Create(ctx context.Context, run *jobsdomain.Run) error
+	ListByJob(ctx context.Context, job string, limit int) ([]jobsdomain.Run, error)
+	Latest(ctx context.Context) ([]jobsdomain.Run, error)
+}
+
+// JobStatus is a registered job with its latest run.
+type JobStatus struct {
+	Name     string
+	Interval time.Duration
+	// Running reports whether this instance runs the job right now.
+	Running bool
+	LastRun *jobsdomain.Run
+}
+
+// Scheduler runs the registered jobs on the elected instance, every job in a
+// goroutine of its own that never overlaps its runs. Stop cancels the
+// running jobs and waits for them.
+type Scheduler struct {
+	elector          Elector
+	runs             RunRepository
+	instance         string
+	electionInterval time.Duration
+	now              func() time.Time
+
+	entries []*scheduledJob
+	byName  map[string]*scheduledJob
+
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	leading atomic.Bool
+}
+
+type scheduledJob struct {
+	job     Job
+	mu      sync.Mutex
+	running bool
+	last    *jobsdomain.Run
+}
+
+// NewScheduler returns a scheduler electing its leader with elector and
+// recording runs with runs. Without an elector the instance always leads;
+// without a repository only the runs of this instance are known.
+func NewScheduler(elector Elector, runs RunRepository) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		elector:          elector,
+		runs:             runs,
+		instance:         fmt.Sprintf("%s:%d", host, os.Getpid()),
+		electionInterval: DefaultElectionInterval,
+		now:              time.Now,
+		byName:           make(map[string]*scheduledJob),
+	}
+}
+
+// Register adds a job. Jobs without an interval only run when triggered.
+// Register panics on a name taken by another job.
+func (s *Scheduler) Register(job Job) {
+	if _, ok := s.byName[job.Name()]; ok {
+		panic("jobs: job registered twice: " + job.Name())
+	}
+	entry := &scheduledJob{job: job}
+	s.entries = append(s.entries, entry)
+	s.byName[job.Name()] = entry
+}
+
+// Instance names this process in the run history.
+func (s *Scheduler) Instance() string {
+	return s.instance
+}
+
+// Leader reports whether this instance runs the scheduled jobs.
+func (s *Scheduler) Leader() bool {
+	return s.leading.Load()
+}
+
+// Start runs the election and the jobs until ctx is cancelled or Stop is
+// called.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx != nil {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	s.elect(s.ctx)
+	s.wg.Add(1)
+	go s.campaign(s.ctx)
+
+	for _, entry := range s.entries {
+		if entry.job.Interval() <= 0 {
+			continue
+		}
+		s.wg.Add(1)
+		go s.loop(s.ctx, entry)
+	}
+}
+
+// Stop cancels the running jobs, waits for them to return and gives up the
+// lead.
+func (s *Scheduler) Stop() {
+	// Cancelling under the lock keeps Trigger from adding runs to the wait
+	s.mu.Lock()
+	cancel := s.cancel
+	if cancel != nil {
+		cancel()
+	}
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	s.wg.Wait()
+	if s.elector != nil {
+		ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+		defer done()
+		if err := s.elector.Resign(ctx); err != nil {
+			log.Printf("job scheduler resign error: %v", err)
+		}
+	}
+	s.leading.Store(false)
+}
+
+// campaign keeps trying to take the lead, and checks the leader still has
+// it, every election interval.
+func (s *Scheduler) campaign(ctx context.Context) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.electionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.elect(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Scheduler) elect(ctx context.Context) {
+	if s.elector == nil {
+		s.leading.Store(true)
+		return
+	}
+	leading, err := s.elector.Leader(ctx)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("job scheduler election error: %v", err)
+	}
+	if s.leading.Swap(leading) != leading {
+		if leading {
+			log.Printf("job scheduler: %s leads", s.instance)
+		} else {
+			log.Printf("job scheduler: %s lost the lead", s.instance)
+		}
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, entry *scheduledJob) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(entry.job.Interval())
+	defer ticker.Stop()
+
+	initialized := s.init(ctx, entry)
+	for {
+		select {
+		case <-ticker.C:
+			if !s.leading.Load() {
+				continue
+			}
+			if !initialized {
+				if initialized = s.init(ctx, entry); !initialized {
+					continue
+				}
+			}
+			_, _ = s.run(ctx, entry, jobsdomain.TriggerSchedule)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// init runs the Init of a job on the leader and reports whether the job is
+// ready for its runs.
+func (s *Scheduler) init(ctx context.Context, entry *scheduledJob) bool {
+	initializer, ok := entry.job.(Initializer)
+	if !ok {
+		return true
+	}
+	if !s.leading.Load() {
+		return false
+	}
+	if err := initializer.Init(ctx); err != nil {
+		if ctx.Err() == nil {
+			log.Printf("job %s init error: %v", entry.job.Name(), err)
+		}
+		return false
+	}
+	return true
+}
+
+// Trigger runs a job on this instance, leader or not, and returns its run.
+// The run is cancelled by Stop rather than by ctx.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*jobsdomain.Run, error) {
+	entry, ok := s.byName[name]
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+
+	s.mu.Lock()
+	runCtx := s.ctx
+	if runCtx == nil || runCtx.Err() != nil {
+		s.mu.Unlock()
+		return nil, ErrSchedulerStopped
+	}
+	s.wg.Add(1)
+	s.mu.Unlock()
+	defer s.wg.Done()
+
+	return s.run(runCtx, entry, jobsdomain.TriggerManual)
+}
+
+// run runs a job unless it is running already and records the run.
+func (s *Scheduler) run(ctx context.Context, entry *scheduledJob, trigger string) (*jobsdomain.Run, error) {
+	entry.mu.Lock()
+	if entry.running {
+		entry.mu.Unlock()
+		return nil, ErrJobRunning
+	}
+	entry.running = true
+	entry.mu.Unlock()
+
+	started := s.now()
+	err := entry.job.Run(ctx)
+	run := &jobsdomain.Run{
+		Job:      entry.job.Name(),
+		Trigger:  trigger,
+		Instance: s.instance,
+		Started:  started,
+		Duration: s.now().Sub(started),
+	}
+	if err != nil {
+		run.Error = err.Error()
+		log.Printf("job %s error: %v", run.Job, err)
+	}
+
+	if s.runs != nil {
+		// The run is recorded even when shutdown cancelled it
+		recordCtx, done := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := s.runs.Create(recordCtx, run); err != nil {
+			log.Printf("job %s history error: %v", run.Job, err)
+		}
+		done()
+	}
+
+	entry.mu.Lock()
+	entry.running = false
+	entry.last = run
+	entry.mu.Unlock()
+	return run, nil
+}
+
+// Jobs returns the registered jobs in registration order with their latest
+// runs on any instance.
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobStatus, error) {
+	latest := make(map[string]jobsdomain.Run)
+	if s.runs != nil {
+		runs, err := s.runs.Latest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs {
+			latest[run.Job] = run
+		}
+	}
+
+	result := make([]JobStatus, 0, len(s.entries))
+	for _, entry := range s.entries {
+		status := JobStatus{Name: entry.job.Name(), Interval: entry.job.Interval()}
+		entry.mu.Lock()
+		status.Running = entry.running
+		if entry.last != nil {
+			last := *entry.last
+			status.LastRun = &last
+		}
+		entry.mu.Unlock()
+		if run, ok := latest[status.Name]; ok && (status.LastRun == nil || run.Started.After(status.LastRun.Started)) {
+			status.LastRun = &run
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// Runs returns the latest runs of a job, latest first. Without a run
+// repository that is the last run of this instance.
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]jobsdomain.Run, error) {
+	entry, ok := s.byName[name]
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	if s.runs != nil {
+		return s.runs.ListByJob(ctx, name, limit)
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.last == nil {
+		return nil, nil
+	}
+	return []jobsdomain.Run{*entry.last}, nil
+}
@@ -0,0 +1,208 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jobsdomain "empoweredpixels/internal/domain/jobs"
+)
+
+type fakeJob struct {
+	name     string
+	interval time.Duration
+	runs     atomic.Int32
+	err      error
+	// block makes runs wait for their context to end
+	block   bool
+	started chan struct{}
+}
+
+func (j *fakeJob) Name() string            { return j.name }
+func (j *fakeJob) Interval() time.Duration { return j.interval }
+
+func (j *fakeJob) Run(ctx context.Context) error {
+	j.runs.Add(1)
+	if j.block {
+		j.started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return j.err
+}
+
+type initJob struct {
+	fakeJob
+	inits atomic.Int32
+}
+
+func (j *initJob) Init(ctx context.Context) error {
+	j.inits.Add(1)
+	return nil
+}
+
+type fakeElector struct {
+	leader atomic.Bool
+}
+
+func (e *fakeElector) Leader(ctx context.Context) (bool, error) { return e.leader.Load(), nil }
+func (e *fakeElector) Resign(ctx context.Context) error         { return nil }
+
+type memoryRuns struct {
+	mu   sync.Mutex
+	runs []jobsdomain.Run
+}
+
+func (m *memoryRuns) Create(ctx context.Context, run *jobsdomain.Run) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run.ID = int64(len(m.runs) + 1)
+	m.runs = append(m.runs, *run)
+	return nil
+}
+
+func (m *memoryRuns) ListByJob(ctx context.Context, job string, limit int) ([]jobsdomain.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []jobsdomain.Run
+	for i := len(m.runs) - 1; i >= 0 && len(result) < limit; i-- {
+		if m.runs[i].Job == job {
+			result = append(result, m.runs[i])
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryRuns) Latest(ctx context.Context) ([]jobsdomain.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	latest := map[string]jobsdomain.Run{}
+	for _, run := range m.runs {
+		latest[run.Job] = run
+	}
+	var result []jobsdomain.Run
+	for _, run := range latest {
+		result = append(result, run)
+	}
+	return result, nil
+}
+
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestScheduler_RunsOnLeaderOnly(t *testing.T) {
+	elector := &fakeElector{}
+	job := &fakeJob{name: "tick", interval: 5 * time.Millisecond}
+	s := NewScheduler(elector, &memoryRuns{})
+	s.electionInterval = 5 * time.Millisecond
+	s.Register(job)
+
+	s.Start(context.Background())
+	defer s.Stop()
+
+	time.Sleep(30 * time.Millisecond)
+	if job.runs.Load() != 0 || s.Leader() {
+		t.Fatalf("expected a follower to run nothing, got %d runs", job.runs.Load())
+	}
+
+	elector.leader.Store(true)
+	waitFor(t, "the leader to run the job", func() bool { return job.runs.Load() > 0 })
+}
+
+func TestScheduler_InitsOnLeaderOnly(t *testing.T) {
+	elector := &fakeElector{}
+	job := &initJob{fakeJob: fakeJob{name: "init", interval: time.Hour}}
+	s := NewScheduler(elector, nil)
+	s.electionInterval = 5 * time.Millisecond
+	s.Register(job)
+
+	s.Start(context.Background())
+	time.Sleep(30 * time.Millisecond)
+	if job.inits.Load() != 0 {
+		t.Fatalf("expected a follower not to init the job")
+	}
+	s.Stop()
+
+	elector.leader.Store(true)
+	s = NewScheduler(elector, nil)
+	s.Register(job)
+	s.Start(context.Background())
+	defer s.Stop()
+
+	// The leader inits the job right away, not an interval later
+	waitFor(t, "the leader to init the job", func() bool { return job.inits.Load() == 1 })
+	if job.runs.Load() != 0 {
+		t.Fatalf("expected Init not to run the job")
+	}
+}
+
+func TestScheduler_TriggerRecordsRuns(t *testing.T) {
+	ctx := context.Background()
+	runs := &memoryRuns{}
+	failing := &fakeJob{name: "failing", err: errors.New("boom")}
+	s := NewScheduler(nil, runs)
+	s.Register(failing)
+
+	if _, err := s.Trigger(ctx, "failing"); err != ErrSchedulerStopped {
+		t.Fatalf("expected ErrSchedulerStopped before Start, got %v", err)
+	}
+	s.Start(ctx)
+	defer s.Stop()
+
+	run, err := s.Trigger(ctx, "failing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if run.Error != "boom" || run.Trigger != jobsdomain.TriggerManual || run.Instance != s.Instance() {
+		t.Fatalf("expected a failed manual run of this instance, got %+v", run)
+	}
+	if _, err := s.Trigger(ctx, "missing"); err != ErrUnknownJob {
+		t.Fatalf("expected ErrUnknownJob, got %v", err)
+	}
+
+	statuses, err := s.Jobs(ctx)
+	if err != nil || len(statuses) != 1 || statuses[0].LastRun == nil || statuses[0].LastRun.ID != 1 {
+		t.Fatalf("expected the recorded run as the last run, got %+v and %v", statuses, err)
+	}
+	history, _ := s.Runs(ctx, "failing", 10)
+	if len(history) != 1 {
+		t.Fatalf("expected one run in the history, got %+v", history)
+	}
+}
+
+func TestScheduler_StopCancelsRunningJobs(t *testing.T) {
+	job := &fakeJob{name: "slow", block: true, started: make(chan struct{}, 1)}
+	s := NewScheduler(nil, nil)
+	s.Register(job)
+	s.Start(context.Background())
+
+	result := make(chan *jobsdomain.Run, 1)
+	go func() {
+		run, _ := s.Trigger(context.Background(), "slow")
+		result <- run
+	}()
+	<-job.started
+
+	if _, err := s.Trigger(context.Background(), "slow"); err != ErrJobRunning {
+		t.Fatalf("expected ErrJobRunning, got %v", err)
+	}
+
+	s.Stop()
+	if statuses, _ := s.Jobs(context.Background()); statuses[0].Running {
+		t.Fatalf("expected Stop to wait for the running job")
+	}
+	if run := <-result; run == nil || run.Error != context.Canceled.Error() {
+		t.Fatalf("expected the run cancelled, got %+v", run)
+	}
+}
@@ -1,14 +1,63 @@
 package jobs
 
-import "context"
+import (
+	"context"
+	"log"
+	"time"
 
+	seasonsusecase "empoweredpixels/internal/usecase/seasons"
+)
+
+// SeasonInitiatorJob keeps a ranked season running: it starts the first one,
+// rolls seasons over once they end and decays idle fighters every interval.
 type SeasonInitiatorJob struct {
+	service  *seasonsusecase.Service
+	interval time.Duration
 }
 
-func NewSeasonInitiatorJob() *SeasonInitiatorJob {
-	return &SeasonInitiatorJob{}
+func NewSeasonInitiatorJob(service *seasonsusecase.Service, interval time.Duration) *SeasonInitiatorJob {
+	return &SeasonInitiatorJob{
+		service:  service,
+		interval: interval,
+	}
 }
 
+// Init starts a season unless one is running. The scheduler runs it on the
+// leader before the first run.
 func (j *SeasonInitiatorJob) Init(ctx context.Context) error {
+	season, err := j.service.StartSeason(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("season %d runs until %s", season.SeasonID, season.EndDate.Format(time.RFC3339))
 	return nil
 }
+
+func (j *SeasonInitiatorJob) Name() string {
+	return "season"
+}
+
+func (j *SeasonInitiatorJob) Interval() time.Duration {
+	return j.interval
+}
+
+// Run rolls the season over and decays idle fighters. Decay runs even when
+// the rollover failed.
+func (j *SeasonInitiatorJob) Run(ctx context.Context) error {
+	next, rolloverErr := j.service.Rollover(ctx)
+	if rolloverErr != nil {
+		log.Printf("season rollover error: %v", rolloverErr)
+	}
+	if next != nil {
+		log.Printf("season rollover: season %d started", next.SeasonID)
+	}
+
+	decayed, err := j.service.Decay(ctx)
+	if err != nil {
+		return err
+	}
+	if decayed > 0 {
+		log.Printf("ladder decay: decayed %d fighters", decayed)
+	}
+	return rolloverErr
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	tournamentsusecase "empoweredpixels/internal/usecase/tournaments"
+)
+
+// TournamentJob settles tournament pairings whose results were missed: it
+// counts finished matches the hook didn't, scores cancelled ones and starts
+// matches left in their lobby.
+type TournamentJob struct {
+	service  *tournamentsusecase.Service
+	interval time.Duration
+}
+
+func NewTournamentJob(service *tournamentsusecase.Service, interval time.Duration) *TournamentJob {
+	return &TournamentJob{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (j *TournamentJob) Name() string {
+	return "tournament-sweep"
+}
+
+func (j *TournamentJob) Interval() time.Duration {
+	return j.interval
+}
+
+func (j *TournamentJob) Run(ctx context.Context) error {
+	settled, err := j.service.Sweep(ctx)
+	if settled > 0 {
+		log.Printf("tournament sweep: settled %d pairings", settled)
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	eventsusecase "empoweredpixels/internal/usecase/events"
+)
+
+// WeekendEventJob starts weekend events when their weekly window opens.
+type WeekendEventJob struct {
+	eventService *eventsusecase.Service
+	interval     time.Duration
+}
+
+func NewWeekendEventJob(eventService *eventsusecase.Service, interval time.Duration) *WeekendEventJob {
+	return &WeekendEventJob{
+		eventService: eventService,
+		interval:     interval,
+	}
+}
+
+func (j *WeekendEventJob) Name() string {
+	return "weekend-event"
+}
+
+func (j *WeekendEventJob) Interval() time.Duration {
+	return j.interval
+}
+
+func (j *WeekendEventJob) Run(ctx context.Context) error {
+	started, err := j.eventService.StartDueEvents(ctx)
+	if err != nil {
+		return err
+	}
+	for _, event := range started {
+		if event.Event != nil {
+			log.Printf("weekend event started: %s", event.Event.Name)
+		}
+	}
+	return nil
+}
@@ -13,6 +13,12 @@ import (
 type Service struct {
 	repo     repositories.DailyRewardRepository
 	goldRepo repositories.PlayerGoldRepository
+	notifier Notifier
+}
+
+// Notifier tells users their daily reward is ready
+type Notifier interface {
+	DailyRewardClaimable(ctx context.Context, userID int64, reward daily.Reward) error
 }
 
 // NewService creates a new daily reward service
@@ -26,6 +32,11 @@ func NewService(
 	}
 }
 
+// SetNotifier installs the notifier used by NotifyClaimable
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
 // GetStatus returns user's daily reward status
 func (s *Service) GetStatus(ctx context.Context, userID int) (*daily.UserDailyReward, error) {
 	return s.repo.GetUserDailyReward(ctx, userID)
@@ -101,3 +112,29 @@ func (s *Service) Claim(ctx context.Context, userID int) (*daily.ClaimResult, er
 		NextReward:  nextReward,
 	}, nil
 }
+
+// NotifyClaimable tells every returning user whose reward can be claimed
+// today which reward is waiting and returns the number of such users
+func (s *Service) NotifyClaimable(ctx context.Context) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+
+	claimable, err := s.repo.ListClaimable(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for _, status := range claimable {
+		nextDay := status.Streak + 1
+		if daily.HasStreakBroken(status.LastClaimed) {
+			nextDay = 1
+		}
+		if err := s.notifier.DailyRewardClaimable(ctx, int64(status.UserID), daily.GetRewardForDay(nextDay)); err != nil {
+			return notified, err
+		}
+		notified++
+	}
+	return notified, nil
+}
@@ -4,14 +4,27 @@ import (
 	"context"
 	"empoweredpixels/internal/domain/events"
 	"empoweredpixels/internal/infra/db/repositories"
+	"time"
 )
 
+// Notifier tells players about events that start
+type Notifier interface {
+	WeekendEventStarted(ctx context.Context, event events.ActiveEvent) error
+}
+
 type Service struct {
-	repo *repositories.EventRepository
+	repo     *repositories.EventRepository
+	notifier Notifier
+	now      func() time.Time
 }
 
 func NewService(repo *repositories.EventRepository) *Service {
-	return &Service{repo: repo}
+	return &Service{repo: repo, now: time.Now}
+}
+
+// SetNotifier installs the notifier told about events started by StartDueEvents
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
 }
 
 func (s *Service) GetCurrentEvents(ctx context.Context) ([]events.ActiveEvent, error) {
@@ -21,3 +34,33 @@ func (s *Service) GetCurrentEvents(ctx context.Context) ([]events.ActiveEvent, e
 func (s *Service) GetStatus(ctx context.Context) (*events.EventStatus, error) {
 	return s.repo.GetStatus(ctx)
 }
+
+// StartDueEvents activates every weekend event whose weekly window (in UTC)
+// is open and that is not active yet, and returns the events it started.
+func (s *Service) StartDueEvents(ctx context.Context) ([]events.ActiveEvent, error) {
+	scheduled, err := s.repo.ListScheduledEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	var started []events.ActiveEvent
+	for _, event := range scheduled {
+		start, end, ok := event.WindowAt(now)
+		if !ok {
+			continue
+		}
+		active, err := s.repo.StartEvent(ctx, event, start, end)
+		if err != nil {
+			return started, err
+		}
+		if active == nil {
+			continue
+		}
+		started = append(started, *active)
+		if s.notifier != nil {
+			_ = s.notifier.WeekendEventStarted(ctx, *active)
+		}
+	}
+	return started, nil
+}
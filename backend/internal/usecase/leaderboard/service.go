@@ -6,6 +6,7 @@ import (
 	"sort"
 
 	"empoweredpixels/internal/domain/leaderboard"
+	"empoweredpixels/internal/domain/leagues"
 	"empoweredpixels/internal/infra/db/repositories"
 )
 
@@ -16,6 +17,25 @@ type Service struct {
 	userRepo   *repositories.UserRepository
 	fighterRepo *repositories.FighterRepository
 	goldRepo   repositories.PlayerGoldRepository
+	notifier   Notifier
+	leaguePoints LeaguePoints
+	uow        UnitOfWork
+}
+
+// Notifier tells players about the achievements they complete
+type Notifier interface {
+	AchievementCompleted(ctx context.Context, userID int64, achievement leaderboard.Achievement) error
+}
+
+// LeaguePoints tells the league points users scored in the current season
+type LeaguePoints interface {
+	UserPoints(ctx context.Context) ([]leagues.UserPoints, error)
+}
+
+// UnitOfWork runs fn in a transaction. Repositories called with the context
+// fn receives take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 // NewService creates a new leaderboard service
@@ -35,6 +55,22 @@ func NewService(
 	}
 }
 
+// SetNotifier installs the notifier told about completed achievements
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// SetLeaguePoints ranks users by their league points in the league category
+func (s *Service) SetLeaguePoints(leaguePoints LeaguePoints) {
+	s.leaguePoints = leaguePoints
+}
+
+// SetUnitOfWork makes a recalculated category replace the previous one at
+// once
+func (s *Service) SetUnitOfWork(uow UnitOfWork) {
+	s.uow = uow
+}
+
 // GetLeaderboard retrieves a leaderboard category
 func (s *Service) GetLeaderboard(ctx context.Context, category string, userID int, limit int, offset int) (*leaderboard.ListResponse, error) {
 	entries, err := s.repo.GetByCategory(ctx, category, limit, offset)
@@ -90,9 +126,54 @@ func (s *Service) RecalculateAll(ctx context.Context) error {
 	if err := s.recalculateCombat(ctx); err != nil {
 		return fmt.Errorf("combat leaderboard failed: %w", err)
 	}
+	if s.leaguePoints != nil {
+		if err := s.recalculateLeague(ctx); err != nil {
+			return fmt.Errorf("league leaderboard failed: %w", err)
+		}
+	}
 	return nil
 }
 
+// recalculateLeague ranks users by the league standings points of their
+// fighters in the current season, replacing the entries of users who no
+// longer score
+func (s *Service) recalculateLeague(ctx context.Context) error {
+	points, err := s.leaguePoints.UserPoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	userIDs := make([]int, 0, len(points))
+	for _, p := range points {
+		userIDs = append(userIDs, int(p.UserID))
+	}
+
+	return s.inTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteCategoryExcept(ctx, leaderboard.CategoryLeague, userIDs); err != nil {
+			return err
+		}
+		for rank, p := range points {
+			entry := &leaderboard.Entry{
+				Category: leaderboard.CategoryLeague,
+				UserID:   int(p.UserID),
+				Rank:     rank + 1,
+				Score:    int64(p.Points),
+			}
+			if err := s.repo.UpsertEntry(ctx, entry); err != nil {
+				return fmt.Errorf("user %d: %w", p.UserID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
+	}
+	return s.uow.Do(ctx, fn)
+}
+
 // recalculatePower calculates power rankings based on total fighter power
 func (s *Service) recalculatePower(ctx context.Context) error {
 	// Get all users with their total fighter power
@@ -235,7 +316,14 @@ func (s *Service) GetPlayerAchievements(ctx context.Context, userID int) ([]lead
 
 // UpdateAchievementProgress updates a player's achievement progress
 func (s *Service) UpdateAchievementProgress(ctx context.Context, userID int, achievementKey string, progress int) error {
-	return s.achieveRepo.UpdateAchievementProgress(ctx, userID, achievementKey, progress)
+	completed, err := s.achieveRepo.UpdateAchievementProgress(ctx, userID, achievementKey, progress)
+	if err != nil {
+		return err
+	}
+	if completed != nil && s.notifier != nil {
+		_ = s.notifier.AchievementCompleted(ctx, int64(userID), *completed)
+	}
+	return nil
 }
 
 // ClaimAchievementReward claims an achievement reward
@@ -0,0 +1,73 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"empoweredpixels/internal/domain/leaderboard"
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/infra/db/repositories"
+)
+
+type memoryLeaderboardRepo struct {
+	repositories.LeaderboardRepository
+	entries   map[int]leaderboard.Entry
+	upsertErr error
+}
+
+func (m *memoryLeaderboardRepo) UpsertEntry(ctx context.Context, entry *leaderboard.Entry) error {
+	if m.upsertErr != nil {
+		return m.upsertErr
+	}
+	m.entries[entry.UserID] = *entry
+	return nil
+}
+
+func (m *memoryLeaderboardRepo) DeleteCategoryExcept(ctx context.Context, category string, userIDs []int) error {
+	keep := make(map[int]bool, len(userIDs))
+	for _, id := range userIDs {
+		keep[id] = true
+	}
+	for id, entry := range m.entries {
+		if entry.Category == category && !keep[id] {
+			delete(m.entries, id)
+		}
+	}
+	return nil
+}
+
+type fixedLeaguePoints []leagues.UserPoints
+
+func (p fixedLeaguePoints) UserPoints(ctx context.Context) ([]leagues.UserPoints, error) {
+	return p, nil
+}
+
+func TestRecalculateLeague_ReplacesCategory(t *testing.T) {
+	repo := &memoryLeaderboardRepo{entries: map[int]leaderboard.Entry{
+		1: {Category: leaderboard.CategoryLeague, UserID: 1, Rank: 1, Score: 40},
+		2: {Category: leaderboard.CategoryLeague, UserID: 2, Rank: 2, Score: 30},
+	}}
+	s := NewService(repo, nil, nil, nil, nil)
+	s.SetLeaguePoints(fixedLeaguePoints{{UserID: 3, Points: 12}, {UserID: 1, Points: 5}})
+
+	if err := s.recalculateLeague(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.entries) != 2 {
+		t.Fatalf("expected the stale entry removed, got %+v", repo.entries)
+	}
+	if repo.entries[3].Rank != 1 || repo.entries[1].Rank != 2 {
+		t.Fatalf("unexpected ranks %+v", repo.entries)
+	}
+}
+
+func TestRecalculateLeague_ReturnsWriteErrors(t *testing.T) {
+	repo := &memoryLeaderboardRepo{entries: map[int]leaderboard.Entry{}, upsertErr: errors.New("db down")}
+	s := NewService(repo, nil, nil, nil, nil)
+	s.SetLeaguePoints(fixedLeaguePoints{{UserID: 1, Points: 5}})
+
+	if err := s.recalculateLeague(context.Background()); !errors.Is(err, repo.upsertErr) {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+}
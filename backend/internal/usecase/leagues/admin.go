@@ -0,0 +1,71 @@
+package leagues
+
+import (
+	"context"
+	"strings"
+
+	"empoweredpixels/internal/domain/leagues"
+)
+
+// ListAll returns every league, deactivated ones included.
+func (s *Service) ListAll(ctx context.Context) ([]leagues.League, error) {
+	return s.leagues.ListAll(ctx)
+}
+
+// Create creates a league. Config fields left empty take their defaults.
+func (s *Service) Create(ctx context.Context, name string, config leagues.Config, deactivated bool) (*leagues.League, error) {
+	league := &leagues.League{
+		Name:          strings.TrimSpace(name),
+		Config:        config.Normalized(),
+		IsDeactivated: deactivated,
+	}
+	if err := validateLeague(*league); err != nil {
+		return nil, err
+	}
+	if err := s.leagues.Create(ctx, league); err != nil {
+		return nil, err
+	}
+	return league, nil
+}
+
+// Update replaces the name, config and deactivation of a league. Existing
+// subscriptions stay, even of fighters the new caps would turn away.
+func (s *Service) Update(ctx context.Context, id int, name string, config leagues.Config, deactivated bool) (*leagues.League, error) {
+	league, err := s.leagues.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if league == nil {
+		return nil, ErrInvalidLeague
+	}
+
+	league.Name = strings.TrimSpace(name)
+	league.Config = config.Normalized()
+	league.IsDeactivated = deactivated
+	if err := validateLeague(*league); err != nil {
+		return nil, err
+	}
+	if err := s.leagues.Update(ctx, league); err != nil {
+		return nil, err
+	}
+	return league, nil
+}
+
+// Delete deletes a league with its subscriptions and history.
+func (s *Service) Delete(ctx context.Context, id int) error {
+	deleted, err := s.leagues.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrInvalidLeague
+	}
+	return nil
+}
+
+func validateLeague(league leagues.League) error {
+	if league.Name == "" {
+		return leagues.ErrInvalidConfig
+	}
+	return league.Config.Validate()
+}
@@ -2,14 +2,23 @@ package leagues
 
 import (
 	"context"
+	"time"
 
 	"empoweredpixels/internal/domain/leagues"
 	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/shop"
+	"empoweredpixels/internal/domain/tournaments"
 )
 
 type LeagueRepository interface {
 	List(ctx context.Context) ([]leagues.League, error)
+	ListAll(ctx context.Context) ([]leagues.League, error)
 	GetByID(ctx context.Context, id int) (*leagues.League, error)
+	Lock(ctx context.Context, id int) (*leagues.League, error)
+	Create(ctx context.Context, league *leagues.League) error
+	Update(ctx context.Context, league *leagues.League) error
+	Delete(ctx context.Context, id int) (bool, error)
+	ClaimRun(ctx context.Context, id int, previous *time.Time, at time.Time) (bool, error)
 }
 
 type SubscriptionRepository interface {
@@ -26,3 +35,26 @@ type LeagueMatchRepository interface {
 type FighterRepository interface {
 	GetByUserAndID(ctx context.Context, userID int64, id string) (*roster.Fighter, error)
 }
+
+// GoldRepository is the player gold balance entry fees are paid from.
+type GoldRepository interface {
+	GetPlayerGold(ctx context.Context, userID int) (*shop.PlayerGold, error)
+	SpendGold(ctx context.Context, userID int, amount int) error
+}
+
+// TransactionRepository is the shop ledger entry fees are recorded in.
+type TransactionRepository interface {
+	CreateTransaction(ctx context.Context, tx *shop.Transaction) (int, error)
+}
+
+// UnitOfWork runs fn in a transaction. Repositories called with the context
+// fn receives take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ChampionRepository finds the winner of the latest completed tournament of a
+// league.
+type ChampionRepository interface {
+	LastChampion(ctx context.Context, leagueID int) (*tournaments.Tournament, *tournaments.Entrant, error)
+}
@@ -0,0 +1,43 @@
+package leagues
+
+import (
+	"context"
+
+	"empoweredpixels/internal/domain/leagues"
+)
+
+// DueLeagues claims the runs of the active leagues whose schedule came due
+// and returns those leagues. A league seen for the first time starts its
+// schedule now instead of running. Runs that come due while a league is
+// deactivated are skipped.
+func (s *Service) DueLeagues(ctx context.Context) ([]leagues.League, error) {
+	all, err := s.leagues.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var due []leagues.League
+	for _, league := range all {
+		schedule, err := leagues.ParseSchedule(league.Config.Schedule)
+		if err != nil {
+			continue
+		}
+		if league.LastRun != nil {
+			next := schedule.Next(*league.LastRun)
+			if next.IsZero() || now.Before(next) {
+				continue
+			}
+		}
+
+		claimed, err := s.leagues.ClaimRun(ctx, league.ID, league.LastRun, now)
+		if err != nil {
+			return due, err
+		}
+		if claimed && league.LastRun != nil && league.Active(now) {
+			league.LastRun = &now
+			due = append(due, league)
+		}
+	}
+	return due, nil
+}
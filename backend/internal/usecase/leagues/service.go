@@ -6,12 +6,18 @@ import (
 	"time"
 
 	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/shop"
+	"empoweredpixels/internal/domain/tournaments"
 )
 
 var (
 	ErrInvalidLeague       = errors.New("invalid league")
 	ErrInvalidFighter      = errors.New("invalid fighter")
 	ErrInvalidSubscription = errors.New("invalid league subscription")
+	ErrLeagueInactive      = errors.New("league is not active")
+	ErrLeagueFull          = errors.New("league is full")
+	ErrFighterLimit        = errors.New("fighter limit per user of the league reached")
+	ErrInsufficientGold    = errors.New("insufficient gold")
 )
 
 type Service struct {
@@ -19,6 +25,10 @@ type Service struct {
 	subscriptions SubscriptionRepository
 	matches       LeagueMatchRepository
 	fighters      FighterRepository
+	champions     ChampionRepository
+	gold          GoldRepository
+	transactions  TransactionRepository
+	uow           UnitOfWork
 	now           func() time.Time
 }
 
@@ -42,6 +52,29 @@ func NewService(
 	}
 }
 
+// SetEntryFees installs the gold balance and ledger entry fees are charged
+// to, and the unit of work subscriptions run in.
+func (s *Service) SetEntryFees(gold GoldRepository, transactions TransactionRepository, uow UnitOfWork) {
+	s.gold = gold
+	s.transactions = transactions
+	s.uow = uow
+}
+
+// SetChampions installs the repository LastWinner reads tournament
+// champions from.
+func (s *Service) SetChampions(champions ChampionRepository) {
+	s.champions = champions
+}
+
+// LastWinner returns the latest completed tournament of the league and its
+// champion, or nils when the league has none.
+func (s *Service) LastWinner(ctx context.Context, leagueID int) (*tournaments.Tournament, *tournaments.Entrant, error) {
+	if s.champions == nil {
+		return nil, nil, nil
+	}
+	return s.champions.LastChampion(ctx, leagueID)
+}
+
 func (s *Service) List(ctx context.Context) ([]leagues.League, error) {
 	return s.leagues.List(ctx)
 }
@@ -50,30 +83,100 @@ func (s *Service) Get(ctx context.Context, id int) (*leagues.League, error) {
 	return s.leagues.GetByID(ctx, id)
 }
 
+// Subscribe enters the user's fighter into the league. The fighter has to
+// meet the league's level and power caps, the league must have room for them
+// and the user's team, and the entry fee is charged. Subscribing a fighter
+// again changes nothing.
 func (s *Service) Subscribe(ctx context.Context, userID int64, leagueID int, fighterID string) error {
-	league, err := s.leagues.GetByID(ctx, leagueID)
-	if err != nil {
-		return err
+	return s.inTransaction(ctx, func(ctx context.Context) error {
+		league, err := s.leagues.Lock(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		if league == nil {
+			return ErrInvalidLeague
+		}
+		if !league.Active(s.now()) {
+			return ErrLeagueInactive
+		}
+
+		fighter, err := s.fighters.GetByUserAndID(ctx, userID, fighterID)
+		if err != nil {
+			return err
+		}
+		if fighter == nil || fighter.IsDeleted {
+			return ErrInvalidFighter
+		}
+		if err := league.Config.Eligible(fighter.Level, fighter.Power); err != nil {
+			return err
+		}
+
+		subs, err := s.subscriptions.ListByLeague(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			if sub.FighterID == fighterID {
+				return nil
+			}
+		}
+		if limit := league.Config.MaxFighters; limit > 0 && len(subs) >= limit {
+			return ErrLeagueFull
+		}
+		entered, err := s.subscriptions.ListByLeagueAndUser(ctx, leagueID, userID)
+		if err != nil {
+			return err
+		}
+		if len(entered) >= league.Config.MaxFightersPerUser {
+			return ErrFighterLimit
+		}
+
+		if err := s.chargeEntryFee(ctx, userID, *league); err != nil {
+			return err
+		}
+
+		subscription := &leagues.LeagueSubscription{
+			LeagueID:  leagueID,
+			FighterID: fighterID,
+			Created:   s.now(),
+		}
+		return s.subscriptions.Create(ctx, subscription)
+	})
+}
+
+func (s *Service) chargeEntryFee(ctx context.Context, userID int64, league leagues.League) error {
+	fee := league.Config.EntryFee
+	if fee <= 0 {
+		return nil
 	}
-	if league == nil {
-		return ErrInvalidLeague
+	if s.gold == nil {
+		return ErrInsufficientGold
 	}
 
-	fighter, err := s.fighters.GetByUserAndID(ctx, userID, fighterID)
+	balance, err := s.gold.GetPlayerGold(ctx, int(userID))
 	if err != nil {
 		return err
 	}
-	if fighter == nil {
-		return ErrInvalidFighter
+	if balance == nil || balance.Balance < fee {
+		return ErrInsufficientGold
 	}
-
-	subscription := &leagues.LeagueSubscription{
-		LeagueID:  leagueID,
-		FighterID: fighterID,
-		Created:   s.now(),
+	if err := s.gold.SpendGold(ctx, int(userID), fee); err != nil {
+		return err
 	}
-
-	return s.subscriptions.Create(ctx, subscription)
+	if s.transactions == nil {
+		return nil
+	}
+	_, err = s.transactions.CreateTransaction(ctx, &shop.Transaction{
+		UserID:        int(userID),
+		ItemType:      shop.ItemTypeLeagueEntry,
+		ItemName:      "League entry: " + league.Name,
+		PriceAmount:   fee,
+		PriceCurrency: shop.CurrencyGold,
+		GoldChange:    -fee,
+		Status:        "completed",
+		Metadata:      map[string]interface{}{"league_id": league.ID},
+	})
+	return err
 }
 
 func (s *Service) Unsubscribe(ctx context.Context, userID int64, leagueID int, fighterID string) error {
@@ -123,3 +226,10 @@ func (s *Service) Matches(ctx context.Context, leagueID int, page int, pageSize
 	offset := (page - 1) * pageSize
 	return s.matches.ListByLeague(ctx, leagueID, pageSize, offset)
 }
+
+func (s *Service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
+	}
+	return s.uow.Do(ctx, fn)
+}
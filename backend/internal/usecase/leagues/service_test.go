@@ -0,0 +1,215 @@
+package leagues
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/shop"
+)
+
+type mockLeagueRepo struct {
+	LeagueRepository
+	leagues map[int]*leagues.League
+}
+
+func (m *mockLeagueRepo) List(ctx context.Context) ([]leagues.League, error) {
+	var result []leagues.League
+	for id := 1; id <= len(m.leagues); id++ {
+		if league, ok := m.leagues[id]; ok && !league.IsDeactivated {
+			result = append(result, *league)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockLeagueRepo) Lock(ctx context.Context, id int) (*leagues.League, error) {
+	league, ok := m.leagues[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *league
+	return &copied, nil
+}
+
+func (m *mockLeagueRepo) ClaimRun(ctx context.Context, id int, previous *time.Time, at time.Time) (bool, error) {
+	league := m.leagues[id]
+	if (league.LastRun == nil) != (previous == nil) || (previous != nil && !league.LastRun.Equal(*previous)) {
+		return false, nil
+	}
+	league.LastRun = &at
+	return true, nil
+}
+
+type mockSubscriptionRepo struct {
+	SubscriptionRepository
+	subs []leagues.LeagueSubscription
+	// owners maps fighters to their users
+	owners map[string]int64
+}
+
+func (m *mockSubscriptionRepo) ListByLeague(ctx context.Context, leagueID int) ([]leagues.LeagueSubscription, error) {
+	var result []leagues.LeagueSubscription
+	for _, sub := range m.subs {
+		if sub.LeagueID == leagueID {
+			result = append(result, sub)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockSubscriptionRepo) ListByLeagueAndUser(ctx context.Context, leagueID int, userID int64) ([]leagues.LeagueSubscription, error) {
+	var result []leagues.LeagueSubscription
+	for _, sub := range m.subs {
+		if sub.LeagueID == leagueID && m.owners[sub.FighterID] == userID {
+			result = append(result, sub)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockSubscriptionRepo) Create(ctx context.Context, subscription *leagues.LeagueSubscription) error {
+	m.subs = append(m.subs, *subscription)
+	return nil
+}
+
+type mockFighterRepo struct {
+	fighters map[string]roster.Fighter
+}
+
+func (m *mockFighterRepo) GetByUserAndID(ctx context.Context, userID int64, id string) (*roster.Fighter, error) {
+	fighter, ok := m.fighters[id]
+	if !ok || fighter.UserID != userID {
+		return nil, nil
+	}
+	return &fighter, nil
+}
+
+type mockGold struct {
+	balances map[int]int
+	ledger   []shop.Transaction
+}
+
+func (m *mockGold) GetPlayerGold(ctx context.Context, userID int) (*shop.PlayerGold, error) {
+	return &shop.PlayerGold{UserID: userID, Balance: m.balances[userID]}, nil
+}
+
+func (m *mockGold) SpendGold(ctx context.Context, userID int, amount int) error {
+	m.balances[userID] -= amount
+	return nil
+}
+
+func (m *mockGold) CreateTransaction(ctx context.Context, tx *shop.Transaction) (int, error) {
+	m.ledger = append(m.ledger, *tx)
+	return len(m.ledger), nil
+}
+
+type fixture struct {
+	service *Service
+	leagues *mockLeagueRepo
+	subs    *mockSubscriptionRepo
+	gold    *mockGold
+}
+
+func newFixture(now time.Time, config leagues.Config) fixture {
+	f := fixture{
+		leagues: &mockLeagueRepo{leagues: map[int]*leagues.League{
+			1: {ID: 1, Name: "Arena", Config: config.Normalized()},
+		}},
+		subs: &mockSubscriptionRepo{owners: map[string]int64{"a1": 1, "a2": 1, "b1": 2, "c1": 3}},
+		gold: &mockGold{balances: map[int]int{1: 100, 2: 100, 3: 10}},
+	}
+	fighters := &mockFighterRepo{fighters: map[string]roster.Fighter{
+		"a1": {ID: "a1", UserID: 1, Level: 5, Power: 50},
+		"a2": {ID: "a2", UserID: 1, Level: 5, Power: 50},
+		"b1": {ID: "b1", UserID: 2, Level: 20, Power: 500},
+		"c1": {ID: "c1", UserID: 3, Level: 5, Power: 50},
+	}}
+	f.service = NewService(f.leagues, f.subs, nil, fighters, func() time.Time { return now })
+	f.service.SetEntryFees(f.gold, f.gold, nil)
+	return f
+}
+
+func TestSubscribe_Caps(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(time.Now(), leagues.Config{MaxLevel: 10, MaxPowerlevel: 100, MaxFighters: 2})
+
+	if err := f.service.Subscribe(ctx, 2, 1, "b1"); err != leagues.ErrLevelTooHigh {
+		t.Fatalf("expected ErrLevelTooHigh, got %v", err)
+	}
+	if err := f.service.Subscribe(ctx, 1, 1, "a1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The default team size of one keeps the user's second fighter out
+	if err := f.service.Subscribe(ctx, 1, 1, "a2"); err != ErrFighterLimit {
+		t.Fatalf("expected ErrFighterLimit, got %v", err)
+	}
+	if err := f.service.Subscribe(ctx, 3, 1, "c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f.leagues.leagues[1].Config.MaxFightersPerUser = 2
+	if err := f.service.Subscribe(ctx, 1, 1, "a2"); err != ErrLeagueFull {
+		t.Fatalf("expected ErrLeagueFull, got %v", err)
+	}
+}
+
+func TestSubscribe_EntryFee(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(time.Now(), leagues.Config{EntryFee: 40})
+
+	if err := f.service.Subscribe(ctx, 1, 1, "a1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Subscribing again is free
+	if err := f.service.Subscribe(ctx, 1, 1, "a1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.gold.balances[1] != 60 || len(f.gold.ledger) != 1 || f.gold.ledger[0].ItemType != shop.ItemTypeLeagueEntry {
+		t.Fatalf("expected one fee of 40 charged and recorded, got %d and %+v", f.gold.balances[1], f.gold.ledger)
+	}
+	if err := f.service.Subscribe(ctx, 3, 1, "c1"); err != ErrInsufficientGold {
+		t.Fatalf("expected ErrInsufficientGold, got %v", err)
+	}
+	if len(f.subs.subs) != 1 {
+		t.Fatalf("expected a single subscription, got %+v", f.subs.subs)
+	}
+}
+
+func TestSubscribe_Inactive(t *testing.T) {
+	now := time.Date(2026, 12, 25, 12, 0, 0, 0, time.UTC)
+	f := newFixture(now, leagues.Config{Deactivations: []leagues.Window{{From: now.Add(-time.Hour), To: now.Add(time.Hour)}}})
+
+	if err := f.service.Subscribe(context.Background(), 1, 1, "a1"); !errors.Is(err, ErrLeagueInactive) {
+		t.Fatalf("expected ErrLeagueInactive, got %v", err)
+	}
+}
+
+func TestDueLeagues(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	f := newFixture(now, leagues.Config{Schedule: "@hourly"})
+
+	// The first call starts the schedule without running the league
+	due, err := f.service.DueLeagues(ctx)
+	if err != nil || len(due) != 0 {
+		t.Fatalf("expected nothing due yet, got %v and %v", due, err)
+	}
+
+	now = now.Add(20 * time.Minute)
+	f.service.now = func() time.Time { return now }
+	if due, _ = f.service.DueLeagues(ctx); len(due) != 0 {
+		t.Fatalf("expected nothing due before 10:00, got %v", due)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if due, _ = f.service.DueLeagues(ctx); len(due) != 1 {
+		t.Fatalf("expected the league due at 10:00, got %v", due)
+	}
+	if due, _ = f.service.DueLeagues(ctx); len(due) != 0 {
+		t.Fatalf("expected the run claimed once, got %v", due)
+	}
+}
@@ -0,0 +1,61 @@
+package matches
+
+import (
+	"context"
+	"testing"
+
+	"empoweredpixels/internal/domain/attunement"
+	"empoweredpixels/internal/domain/roster"
+	attunementusecase "empoweredpixels/internal/usecase/attunement"
+)
+
+type xpAward struct {
+	userID  int
+	element attunement.Element
+	source  string
+}
+
+type fakeAttunementService struct {
+	awards []xpAward
+}
+
+func (f *fakeAttunementService) GetAllElementsBonus(ctx context.Context, userID int) (*attunementusecase.AggregatedBonuses, error) {
+	return &attunementusecase.AggregatedBonuses{}, nil
+}
+
+func (f *fakeAttunementService) AwardXP(ctx context.Context, userID int, element attunement.Element, source string) (bool, int, int, error) {
+	f.awards = append(f.awards, xpAward{userID, element, source})
+	return false, 1, 0, nil
+}
+
+func TestService_AwardAttunementXP(t *testing.T) {
+	fire, water, upperFire := "fire", "water", "Fire"
+	fighters := []roster.Fighter{
+		{ID: "a", UserID: 1, AttunementID: &fire},
+		{ID: "b", UserID: 1, AttunementID: &upperFire},
+		{ID: "c", UserID: 2, AttunementID: &water},
+		{ID: "d", UserID: 3},
+	}
+	service := &fakeAttunementService{}
+	s := &Service{attunements: service}
+
+	s.awardAttunementXP(context.Background(), fighters, []string{"b"})
+
+	if len(service.awards) != 2 {
+		t.Fatalf("expected one award per user and element, got %+v", service.awards)
+	}
+	for _, award := range service.awards {
+		switch award.userID {
+		case 1:
+			if award.element != attunement.Fire || award.source != "match_win" {
+				t.Errorf("expected a fire win for user 1, got %+v", award)
+			}
+		case 2:
+			if award.element != attunement.Water || award.source != "element_use" {
+				t.Errorf("expected water use for user 2, got %+v", award)
+			}
+		default:
+			t.Errorf("unexpected award %+v", award)
+		}
+	}
+}
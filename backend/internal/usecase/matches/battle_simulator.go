@@ -5,25 +5,37 @@ import (
 	"math"
 	"math/rand"
 	"sort"
-	"time"
 
 	"empoweredpixels/internal/domain/combat"
 	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/skills"
 )
 
 type BattleSimulator struct {
-	rng *rand.Rand
+	rng     *rand.Rand
+	mapSize float64
 }
 
 func NewBattleSimulator() *BattleSimulator {
-	return &BattleSimulator{
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
-	}
+	return &BattleSimulator{}
 }
 
+// BattleOptions configures a single simulation. Seed is the only source of
+// randomness for the battle: running the same fighters with the same options
+// always produces the same RoundTicks.
 type BattleOptions struct {
-	MaxRounds int
-	MapSize   float64
+	MaxRounds int     `json:"maxRounds"`
+	MapSize   float64 `json:"mapSize"`
+	Seed      int64   `json:"seed"`
+	// Teams maps fighter IDs to team IDs. Fighters without an entry fight
+	// for themselves.
+	Teams map[string]string `json:"teams,omitempty"`
+	// Gear maps fighter IDs to the weapon and equipment they fight with.
+	Gear map[string]combat.Gear `json:"gear,omitempty"`
+	// Skills maps fighter IDs to their skill tree allocations and loadout.
+	Skills map[string]*skills.FighterSkills `json:"skills,omitempty"`
+	// Attunements maps fighter IDs to their owner's attunement level bonuses.
+	Attunements map[string]combat.ElementalBonus `json:"attunements,omitempty"`
 }
 
 func (s *BattleSimulator) Run(matchID string, fighters []roster.Fighter, options BattleOptions) (*combat.MatchResult, error) {
@@ -33,12 +45,11 @@ func (s *BattleSimulator) Run(matchID string, fighters []roster.Fighter, options
 	if options.MapSize == 0 {
 		options.MapSize = 30.0
 	}
+	s.rng = rand.New(rand.NewSource(options.Seed))
+	s.mapSize = options.MapSize
 
-	entities := s.initializeEntities(fighters, options.MapSize)
-	scores := make(map[string]*combat.FighterScore)
-	for _, e := range entities {
-		scores[e.ID] = &combat.FighterScore{FighterID: e.ID}
-	}
+	entities := s.initializeEntities(fighters, options)
+	board := newScoreboard(entities)
 
 	var roundTicks []combat.RoundTick
 
@@ -51,21 +62,44 @@ func (s *BattleSimulator) Run(matchID string, fighters []roster.Fighter, options
 		var ticks []combat.Tick
 		alive := s.getAlive(entities)
 
-		if len(alive) <= 1 {
-			// Battle ends if 1 or 0 fighters left
+		if s.countFactions(alive) <= 1 {
+			// Battle ends once a single team (or fighter) is left standing
 			break
 		}
 
+		// Mana, cooldowns and damage over time
+		for _, e := range alive {
+			roundStart := e.StartRound()
+			ticks = append(ticks, roundStart...)
+			board.record(round, roundStart)
+		}
+
 		// Turn order based on Speed + Agility with some variance
 		s.sortByInitiative(alive)
 
 		for _, attacker := range alive {
-			if attacker.CurrentHP <= 0 {
+			if attacker.CurrentHP <= 0 || attacker.IsStunned() {
+				continue
+			}
+
+			if healTicks := s.mend(attacker, alive); len(healTicks) > 0 {
+				ticks = append(ticks, healTicks...)
+				board.record(round, healTicks)
 				continue
 			}
 
 			target := s.findNearestTarget(attacker, alive)
 			if target == nil {
+				// Nobody in sight: head for the middle of the map
+				if !attacker.IsRooted() {
+					ticks = append(ticks, s.moveTowards(attacker, s.mapSize/2, s.mapSize/2)...)
+				}
+				continue
+			}
+
+			if castTicks, ok := s.castSkill(attacker, target, alive); ok {
+				ticks = append(ticks, castTicks...)
+				board.record(round, castTicks)
 				continue
 			}
 
@@ -73,23 +107,27 @@ func (s *BattleSimulator) Run(matchID string, fighters []roster.Fighter, options
 			skill := s.selectSkill(attacker)
 
 			if dist <= skill.Range() {
-				// Execute combat
-				eventTicks, err := skill.Execute(attacker, target)
-				if err != nil {
-					continue
-				}
-				ticks = append(ticks, eventTicks...)
-
-				// Handle scoring and death
-				for _, t := range eventTicks {
-					if t.Type == "died" {
-						scores[attacker.ID].Kills++
-						scores[target.ID].Deaths++
+				// Execute combat, once per full point of attack charge
+				for attacks := s.chargeAttacks(attacker); attacks > 0 && target.CurrentHP > 0 && attacker.CurrentHP > 0; attacks-- {
+					eventTicks, err := skill.Execute(attacker, target, s.rng)
+					if err != nil {
+						break
 					}
+					if attunement := combat.GetAttunement(attacker.AttunementID); attunement != nil {
+						eventTicks = append(eventTicks, attunement.OnAttack(attacker, target, s.rng)...)
+					}
+					ticks = append(ticks, eventTicks...)
+					board.record(round, eventTicks)
 				}
-			} else {
+			} else if !attacker.IsRooted() {
 				// Movement phase
-				ticks = append(ticks, s.moveTowards(attacker, target)...)
+				ticks = append(ticks, s.moveTowards(attacker, target.X, target.Y)...)
+			}
+		}
+
+		for _, e := range alive {
+			if e.CurrentHP > 0 {
+				ticks = append(ticks, e.EndRound()...)
 			}
 		}
 
@@ -98,41 +136,57 @@ func (s *BattleSimulator) Run(matchID string, fighters []roster.Fighter, options
 		}
 	}
 
+	winnerIDs, winnerTeamID := s.determineWinners(entities)
+	for _, e := range entities {
+		board.scores[e.ID].UltimateCharge = e.UltimateCharge
+	}
+
 	return &combat.MatchResult{
-		MatchID:    matchID,
-		RoundTicks: roundTicks,
-		Scores:     s.finalizeScores(scores),
+		MatchID:      matchID,
+		Seed:         options.Seed,
+		RoundTicks:   roundTicks,
+		Scores:       board.finalize(),
+		WinnerIDs:    winnerIDs,
+		WinnerTeamID: winnerTeamID,
 	}, nil
 }
 
-func (s *BattleSimulator) initializeEntities(fighters []roster.Fighter, mapSize float64) []*combat.Entity {
+func (s *BattleSimulator) initializeEntities(fighters []roster.Fighter, options BattleOptions) []*combat.Entity {
 	entities := make([]*combat.Entity, len(fighters))
 	for i, f := range fighters {
-		maxHP := 100 + (f.Vitality * 12) // Slightly buffed vitality scaling
+		stats, weaponType := combat.CompileStats(f, options.Gear[f.ID])
+		elemental := options.Attunements[f.ID]
+		stats.CritChance += int(math.Round(elemental.Precision))
+		fighterSkills := options.Skills[f.ID]
+		ultimateCharge := 0
+		if fighterSkills != nil {
+			ultimateCharge = fighterSkills.UltimateCharge
+		}
+		maxHP := combat.MaxHealth(stats)
+		var teamID *string
+		if id, ok := options.Teams[f.ID]; ok {
+			teamID = &id
+		}
 		entities[i] = &combat.Entity{
-			ID:           f.ID,
-			Name:         f.Name,
-			Level:        f.Level,
-			MaxHP:        maxHP,
-			CurrentHP:    maxHP,
-			AttunementID: f.AttunementID,
-			X:            s.rng.Float64() * mapSize,
-			Y:            s.rng.Float64() * mapSize,
-			Stats: combat.Stats{
-				Power:          f.Power,
-				ConditionPower: f.ConditionPower,
-				Precision:      f.Precision,
-				Ferocity:       f.Ferocity,
-				Accuracy:       f.Accuracy,
-				Agility:        f.Agility,
-				Armor:          f.Armor,
-				Vitality:       f.Vitality,
-				ParryChance:    f.ParryChance,
-				HealingPower:   f.HealingPower,
-				Speed:          f.Speed,
-				Vision:         f.Vision,
-			},
-			Momentum: 1.0, // Start with neutral momentum
+			TeamID:         teamID,
+			ID:             f.ID,
+			Name:           f.Name,
+			Level:          f.Level,
+			MaxHP:          maxHP,
+			CurrentHP:      maxHP,
+			AttunementID:   f.AttunementID,
+			Elemental:      elemental,
+			X:              s.rng.Float64() * options.MapSize,
+			Y:              s.rng.Float64() * options.MapSize,
+			Stats:          stats,
+			Momentum:       1.0, // Start with neutral momentum
+			IsBot:          f.IsBot,
+			WeaponType:     weaponType,
+			Loadout:        combat.CompileLoadout(fighterSkills, f.Level, weaponType),
+			Mana:           combat.StartingMana,
+			MaxMana:        combat.DefaultMaxMana,
+			Cooldowns:      make(map[string]int),
+			UltimateCharge: ultimateCharge,
 		}
 	}
 	return entities
@@ -141,7 +195,7 @@ func (s *BattleSimulator) initializeEntities(fighters []roster.Fighter, mapSize
 func (s *BattleSimulator) generateSpawnTicks(entities []*combat.Entity) []combat.Tick {
 	ticks := make([]combat.Tick, len(entities))
 	for i, e := range entities {
-		spawn := combat.EventSpawn{FighterID: e.ID, X: e.X, Y: e.Y, HP: e.CurrentHP}
+		spawn := combat.EventSpawn{FighterID: e.ID, Name: e.Name, IsBot: e.IsBot, TeamID: e.TeamID, Weapon: e.WeaponType, X: e.X, Y: e.Y, HP: e.CurrentHP}
 		p, _ := json.Marshal(spawn)
 		ticks[i] = combat.Tick{Type: "spawn", Payload: p}
 	}
@@ -158,19 +212,71 @@ func (s *BattleSimulator) getAlive(entities []*combat.Entity) []*combat.Entity {
 	return alive
 }
 
+func (s *BattleSimulator) countFactions(alive []*combat.Entity) int {
+	factions := make(map[string]struct{})
+	for _, e := range alive {
+		factions[e.Faction()] = struct{}{}
+	}
+	return len(factions)
+}
+
+// determineWinners returns the fighters of the winning side. If several sides
+// survive the round limit, the side with the most remaining HP wins; an exact
+// tie is a draw.
+func (s *BattleSimulator) determineWinners(entities []*combat.Entity) ([]string, *string) {
+	remainingHP := make(map[string]int)
+	for _, e := range entities {
+		if e.CurrentHP > 0 {
+			remainingHP[e.Faction()] += e.CurrentHP
+		}
+	}
+
+	winner := ""
+	best := 0
+	tie := false
+	for faction, hp := range remainingHP {
+		switch {
+		case hp > best:
+			winner, best, tie = faction, hp, false
+		case hp == best:
+			tie = true
+		}
+	}
+	if winner == "" || tie {
+		return nil, nil
+	}
+
+	var winnerIDs []string
+	var winnerTeamID *string
+	for _, e := range entities {
+		if e.Faction() != winner {
+			continue
+		}
+		winnerIDs = append(winnerIDs, e.ID)
+		winnerTeamID = e.TeamID
+	}
+	return winnerIDs, winnerTeamID
+}
+
 func (s *BattleSimulator) sortByInitiative(entities []*combat.Entity) {
-	sort.Slice(entities, func(i, j int) bool {
-		initI := entities[i].Stats.Speed + entities[i].Stats.Agility + s.rng.Intn(10)
-		initJ := entities[j].Stats.Speed + entities[j].Stats.Agility + s.rng.Intn(10)
-		return initI > initJ
+	// Roll once per entity so the order only depends on the seed, not on how
+	// often the sort algorithm happens to compare a pair.
+	initiative := make(map[string]int, len(entities))
+	for _, e := range entities {
+		base := float64(e.Stats.Speed+e.Stats.Agility) * (1 + e.Elemental.Speed/100)
+		initiative[e.ID] = int(base) + s.rng.Intn(10)
+	}
+	sort.SliceStable(entities, func(i, j int) bool {
+		return initiative[entities[i].ID] > initiative[entities[j].ID]
 	})
 }
 
+// findNearestTarget returns the closest enemy within the attacker's vision.
 func (s *BattleSimulator) findNearestTarget(attacker *combat.Entity, alive []*combat.Entity) *combat.Entity {
 	var nearest *combat.Entity
 	minDist := math.MaxFloat64
 	for _, e := range alive {
-		if e.ID == attacker.ID {
+		if e.ID == attacker.ID || e.IsAllyOf(attacker) || !attacker.CanSee(e) {
 			continue
 		}
 		d := s.distance(attacker, e)
@@ -187,28 +293,112 @@ func (s *BattleSimulator) distance(a, b *combat.Entity) float64 {
 }
 
 func (s *BattleSimulator) selectSkill(e *combat.Entity) combat.Skill {
-	// Simple logic: pick weapon based on highest stat
-	weaponID := "Greatsword"
-	if e.Stats.Precision > e.Stats.Power {
-		weaponID = "Bow"
-	} else if e.Stats.Agility > e.Stats.Power {
-		weaponID = "Dagger"
-	}
-
-	skills := combat.GetSkillsByWeapon(weaponID)
+	skills := combat.GetSkillsByWeapon(e.WeaponType)
 	if len(skills) > 0 {
 		return skills[0]
 	}
-	return combat.NewGreatswordBlow()
+	return combat.NewUnarmedStrike()
+}
+
+// castSkill casts the entity's ultimate once fully charged, otherwise the
+// first loadout skill that is off cooldown, affordable, in range and worth
+// casting. It reports whether a skill replaced the basic attack.
+func (s *BattleSimulator) castSkill(caster *combat.Entity, target *combat.Entity, alive []*combat.Entity) ([]combat.Tick, bool) {
+	if ult := caster.Loadout.Ultimate; ult != nil && caster.UltimateCharge >= skills.UltimateThreshold {
+		caster.UltimateCharge = 0
+		return s.executeSkill("ultimate", caster, ult, target, alive), true
+	}
+
+	dist := s.distance(caster, target)
+	for _, skill := range caster.Loadout.Actives {
+		if caster.Mana < skill.ManaCost() || caster.Cooldowns[skill.ID()] > 0 || dist > skill.Range() || !skill.ShouldCast(caster, target) {
+			continue
+		}
+		caster.Mana -= skill.ManaCost()
+		caster.Cooldowns[skill.ID()] = skill.Cooldown()
+		return s.executeSkill("skillCast", caster, skill, target, alive), true
+	}
+	return nil, false
 }
 
-func (s *BattleSimulator) moveTowards(attacker *combat.Entity, target *combat.Entity) []combat.Tick {
+func (s *BattleSimulator) executeSkill(tickType string, caster *combat.Entity, skill combat.ActiveSkill, target *combat.Entity, alive []*combat.Entity) []combat.Tick {
+	cast := combat.EventSkillCast{CasterID: caster.ID, TargetID: target.ID, SkillID: skill.ID(), Mana: caster.Mana}
+	p, _ := json.Marshal(cast)
+	ticks := []combat.Tick{{Type: tickType, Payload: p}}
+
+	var effectTicks []combat.Tick
+	var err error
+	if area, ok := skill.(combat.AreaSkill); ok {
+		var enemies []*combat.Entity
+		for _, e := range alive {
+			if e.CurrentHP > 0 && !e.IsAllyOf(caster) {
+				enemies = append(enemies, e)
+			}
+		}
+		effectTicks, err = area.ExecuteArea(caster, enemies, s.rng)
+	} else {
+		effectTicks, err = skill.Execute(caster, target, s.rng)
+	}
+	if err != nil {
+		return ticks
+	}
+	return append(ticks, effectTicks...)
+}
+
+// mend heals the most wounded ally in range, the healer included, if the
+// healer has HealingPower and Mend is off cooldown.
+func (s *BattleSimulator) mend(healer *combat.Entity, alive []*combat.Entity) []combat.Tick {
+	mend := combat.NewMend()
+	if healer.Stats.HealingPower <= 0 || healer.Cooldowns[mend.ID()] > 0 {
+		return nil
+	}
+
+	var target *combat.Entity
+	for _, e := range alive {
+		if !e.IsAllyOf(healer) || !mend.ShouldCast(e) || s.distance(healer, e) > mend.Range() {
+			continue
+		}
+		if target == nil || e.CurrentHP*target.MaxHP < target.CurrentHP*e.MaxHP {
+			target = e
+		}
+	}
+	if target == nil {
+		return nil
+	}
+
+	ticks, err := mend.Execute(healer, target, s.rng)
+	if err != nil {
+		return nil
+	}
+	healer.Cooldowns[mend.ID()] = combat.MendCooldown
+	return ticks
+}
+
+// chargeAttacks adds the entity's attack speed to its charge and returns how
+// many attacks it gets this round. Fast weapons occasionally strike twice,
+// slow ones occasionally skip a round.
+func (s *BattleSimulator) chargeAttacks(e *combat.Entity) int {
+	e.AttackCharge += e.Stats.AttackSpeed * e.SpeedMultiplier()
+	attacks := int(e.AttackCharge)
+	e.AttackCharge -= float64(attacks)
+	return attacks
+}
+
+func (s *BattleSimulator) moveTowards(attacker *combat.Entity, x, y float64) []combat.Tick {
 	fromX, fromY := attacker.X, attacker.Y
-	
+
 	// Speed-based movement distance
-	moveDist := 3.0 + (float64(attacker.Stats.Speed) / 8.0)
-	
-	angle := math.Atan2(target.Y-attacker.Y, target.X-attacker.X)
+	moveDist := combat.MoveDistance(attacker.Stats) * attacker.SpeedMultiplier()
+
+	remaining := math.Hypot(x-attacker.X, y-attacker.Y)
+	if remaining == 0 {
+		return nil
+	}
+	if moveDist > remaining {
+		moveDist = remaining
+	}
+
+	angle := math.Atan2(y-attacker.Y, x-attacker.X)
 	attacker.X += math.Cos(angle) * moveDist
 	attacker.Y += math.Sin(angle) * moveDist
 
@@ -227,11 +417,3 @@ func (s *BattleSimulator) moveTowards(attacker *combat.Entity, target *combat.En
 	p, _ := json.Marshal(move)
 	return []combat.Tick{{Type: "move", Payload: p}}
 }
-
-func (s *BattleSimulator) finalizeScores(scoreMap map[string]*combat.FighterScore) []combat.FighterScore {
-	scores := make([]combat.FighterScore, 0, len(scoreMap))
-	for _, s := range scoreMap {
-		scores = append(scores, *s)
-	}
-	return scores
-}
@@ -1,9 +1,14 @@
 package matches
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
+	"empoweredpixels/internal/domain/combat"
 	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/skills"
+	"empoweredpixels/internal/domain/weapons"
 	"github.com/google/uuid"
 )
 
@@ -58,3 +63,246 @@ func TestBattleSimulator_Run(t *testing.T) {
 		}
 	}
 }
+
+func TestBattleSimulator_RunIsDeterministicForSeed(t *testing.T) {
+	fighters := []roster.Fighter{
+		{ID: "a", Name: "Warrior", Level: 5, Power: 15, Armor: 10, Vitality: 12, Speed: 5},
+		{ID: "b", Name: "Ranger", Level: 5, Power: 8, Precision: 18, Agility: 15, Speed: 12, Vitality: 8},
+		{ID: "c", Name: "Rogue", Level: 5, Power: 10, Agility: 20, Speed: 14, Vitality: 6},
+	}
+	options := BattleOptions{MaxRounds: 60, MapSize: 25.0, Seed: 42}
+
+	first, err := NewBattleSimulator().Run("match", fighters, options)
+	if err != nil {
+		t.Fatalf("first run failed: %v", err)
+	}
+	second, err := NewBattleSimulator().Run("match", fighters, options)
+	if err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+
+	a, _ := json.Marshal(first.RoundTicks)
+	b, _ := json.Marshal(second.RoundTicks)
+	if !bytes.Equal(a, b) {
+		t.Fatal("expected identical round ticks for the same seed")
+	}
+	if first.Seed != 42 {
+		t.Errorf("expected seed 42 on result, got %d", first.Seed)
+	}
+
+	options.Seed = 43
+	third, err := NewBattleSimulator().Run("match", fighters, options)
+	if err != nil {
+		t.Fatalf("third run failed: %v", err)
+	}
+	c, _ := json.Marshal(third.RoundTicks)
+	if bytes.Equal(a, c) {
+		t.Error("expected a different seed to produce a different battle")
+	}
+}
+
+func TestBattleSimulator_RunRespectsTeams(t *testing.T) {
+	fighters := []roster.Fighter{
+		{ID: "red-1", Name: "Red One", Power: 12, Vitality: 10, Speed: 8},
+		{ID: "red-2", Name: "Red Two", Power: 12, Vitality: 10, Speed: 8},
+		{ID: "blue-1", Name: "Blue One", Power: 12, Vitality: 10, Speed: 8},
+		{ID: "blue-2", Name: "Blue Two", Power: 12, Vitality: 10, Speed: 8},
+	}
+	teams := map[string]string{"red-1": "red", "red-2": "red", "blue-1": "blue", "blue-2": "blue"}
+	options := BattleOptions{MaxRounds: 500, MapSize: 10.0, Seed: 3, Teams: teams}
+
+	result, err := NewBattleSimulator().Run("match", fighters, options)
+	if err != nil {
+		t.Fatalf("Failed to run simulation: %v", err)
+	}
+
+	for _, round := range result.RoundTicks {
+		for _, tick := range round.Ticks {
+			if tick.Type != "attack" {
+				continue
+			}
+			var attack combat.EventAttack
+			_ = json.Unmarshal(tick.Payload, &attack)
+			if teams[attack.AttackerID] == teams[attack.TargetID] {
+				t.Fatalf("round %d: %s attacked ally %s", round.Round, attack.AttackerID, attack.TargetID)
+			}
+		}
+	}
+
+	if result.WinnerTeamID == nil {
+		t.Fatal("expected a winning team")
+	}
+	if len(result.WinnerIDs) != 2 {
+		t.Fatalf("expected both members of the winning team, got %v", result.WinnerIDs)
+	}
+	for _, id := range result.WinnerIDs {
+		if teams[id] != *result.WinnerTeamID {
+			t.Errorf("winner %s is not on team %s", id, *result.WinnerTeamID)
+		}
+	}
+}
+
+func TestBattleSimulator_RunUsesEquippedWeapon(t *testing.T) {
+	sim := NewBattleSimulator()
+
+	archer := roster.Fighter{ID: "a", Name: "Archer", Vitality: 10}
+	brawler := roster.Fighter{ID: "b", Name: "Brawler", Vitality: 10}
+	bow, ok := weapons.GetWeaponByID("wpn_bow_short_001")
+	if !ok {
+		t.Fatal("bow definition missing")
+	}
+
+	result, err := sim.Run("match", []roster.Fighter{archer, brawler}, BattleOptions{
+		MaxRounds: 30,
+		Seed:      7,
+		Gear:      map[string]combat.Gear{archer.ID: {Weapon: bow}},
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	skillsByAttacker := make(map[string]string)
+	for _, round := range result.RoundTicks {
+		for _, tick := range round.Ticks {
+			if tick.Type != "attack" {
+				continue
+			}
+			var attack combat.EventAttack
+			if err := json.Unmarshal(tick.Payload, &attack); err != nil {
+				t.Fatalf("decode attack: %v", err)
+			}
+			skillsByAttacker[attack.AttackerID] = attack.SkillID
+		}
+	}
+
+	if skillsByAttacker[archer.ID] != combat.NewBowShot().ID() {
+		t.Errorf("expected archer to shoot, got %q", skillsByAttacker[archer.ID])
+	}
+	if skill, ok := skillsByAttacker[brawler.ID]; ok && skill != combat.NewUnarmedStrike().ID() {
+		t.Errorf("expected brawler to strike unarmed, got %q", skill)
+	}
+}
+
+func TestBattleSimulator_RunCastsLoadoutSkills(t *testing.T) {
+	sim := NewBattleSimulator()
+
+	caster := roster.Fighter{ID: "a", Name: "Caster", Level: 5, Vitality: 10}
+	dummy := roster.Fighter{ID: "b", Name: "Dummy", Level: 5, Vitality: 10}
+
+	result, err := sim.Run("match", []roster.Fighter{caster, dummy}, BattleOptions{
+		MaxRounds: 40,
+		Seed:      3,
+		Skills: map[string]*skills.FighterSkills{
+			caster.ID: {
+				AllocatedPoints: map[string]int{skills.SkillPowerStrike: 1},
+				Loadout:         []string{skills.SkillPowerStrike},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	casts := 0
+	for _, round := range result.RoundTicks {
+		for _, tick := range round.Ticks {
+			if tick.Type != "skillCast" {
+				continue
+			}
+			var cast combat.EventSkillCast
+			if err := json.Unmarshal(tick.Payload, &cast); err != nil {
+				t.Fatalf("decode cast: %v", err)
+			}
+			if cast.CasterID != caster.ID || cast.SkillID != skills.SkillPowerStrike {
+				t.Errorf("unexpected cast %+v", cast)
+			}
+			casts++
+		}
+	}
+	if casts == 0 {
+		t.Fatal("expected the loadout skill to be cast")
+	}
+}
+
+func TestBattleSimulator_RunTicksConditions(t *testing.T) {
+	sim := NewBattleSimulator()
+
+	fire := "fire"
+	bleeder := roster.Fighter{ID: "a", Name: "Bleeder", Vitality: 20, ConditionPower: 10, AttunementID: &fire}
+	dummy := roster.Fighter{ID: "b", Name: "Dummy", Vitality: 20}
+
+	result, err := sim.Run("match", []roster.Fighter{bleeder, dummy}, BattleOptions{
+		MaxRounds: 60,
+		Seed:      11,
+		Skills: map[string]*skills.FighterSkills{
+			bleeder.ID: {AllocatedPoints: map[string]int{skills.SkillBleed: 3}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	seen := make(map[string]int)
+	for _, round := range result.RoundTicks {
+		for _, tick := range round.Ticks {
+			seen[tick.Type]++
+		}
+	}
+	for _, tickType := range []string{"conditionApplied", "conditionTick", "conditionExpired"} {
+		if seen[tickType] == 0 {
+			t.Errorf("expected %s ticks, got %v", tickType, seen)
+		}
+	}
+
+	kills, deaths := 0, 0
+	for _, score := range result.Scores {
+		kills += score.Kills
+		deaths += score.Deaths
+	}
+	if kills != deaths {
+		t.Errorf("expected every death to be credited, got %d kills for %d deaths", kills, deaths)
+	}
+}
+
+func TestBattleSimulator_FindNearestTargetWithinVision(t *testing.T) {
+	sim := NewBattleSimulator()
+	scout := &combat.Entity{ID: "scout", CurrentHP: 10}
+	near := &combat.Entity{ID: "near", CurrentHP: 10, X: combat.BaseVision + 4}
+	far := &combat.Entity{ID: "far", CurrentHP: 10, X: combat.BaseVision + 20}
+	alive := []*combat.Entity{scout, near, far}
+
+	if target := sim.findNearestTarget(scout, alive); target != nil {
+		t.Fatalf("expected nobody in sight, got %s", target.ID)
+	}
+	scout.Stats.Vision = 10
+	if target := sim.findNearestTarget(scout, alive); target != near {
+		t.Fatalf("expected Vision to reveal the near enemy, got %v", target)
+	}
+}
+
+func TestBattleSimulator_MendHealsMostWoundedAlly(t *testing.T) {
+	sim := NewBattleSimulator()
+	team := "blue"
+	healer := &combat.Entity{ID: "healer", TeamID: &team, MaxHP: 100, CurrentHP: 45, Cooldowns: map[string]int{}}
+	healer.Stats.HealingPower = 5
+	ally := &combat.Entity{ID: "ally", TeamID: &team, MaxHP: 100, CurrentHP: 20, X: 2}
+	enemy := &combat.Entity{ID: "enemy", MaxHP: 100, CurrentHP: 5, X: 1}
+
+	ticks := sim.mend(healer, []*combat.Entity{healer, ally, enemy})
+	if len(ticks) != 1 || ticks[0].Type != "heal" {
+		t.Fatalf("expected a heal tick, got %v", ticks)
+	}
+	var event combat.EventHeal
+	if err := json.Unmarshal(ticks[0].Payload, &event); err != nil {
+		t.Fatalf("decode heal: %v", err)
+	}
+	if event.TargetID != ally.ID || ally.CurrentHP != 35 {
+		t.Fatalf("expected the ally to be healed for 15, got %+v (HP %d)", event, ally.CurrentHP)
+	}
+	if healer.Cooldowns[combat.NewMend().ID()] != combat.MendCooldown {
+		t.Fatal("expected Mend to go on cooldown")
+	}
+	if ticks := sim.mend(healer, []*combat.Entity{healer, ally, enemy}); ticks != nil {
+		t.Fatal("expected no heal while Mend is on cooldown")
+	}
+}
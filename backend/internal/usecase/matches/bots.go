@@ -0,0 +1,120 @@
+package matches
+
+import (
+	"fmt"
+	"math/rand"
+
+	"empoweredpixels/internal/domain/attunement"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/weapons"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxBotCount caps the bots a single match may spawn.
+	MaxBotCount = 16
+	// DefaultBotPowerlevel is used when a match asks for bots without a powerlevel.
+	DefaultBotPowerlevel = 10
+)
+
+var (
+	botPrefixes = []string{"Rusty", "Hollow", "Feral", "Cinder", "Grim", "Iron", "Vile", "Silent"}
+	botNouns    = []string{"Automaton", "Marauder", "Golem", "Revenant", "Stalker", "Brute", "Wisp", "Sentinel"}
+)
+
+// botArchetype weights how a bot's powerlevel is spread over its stats.
+type botArchetype struct {
+	power, precision, accuracy, agility, armor, vitality, speed float64
+}
+
+var botArchetypes = []botArchetype{
+	{power: 1.0, precision: 0.3, accuracy: 0.5, agility: 0.3, armor: 0.8, vitality: 1.0, speed: 0.4}, // brute
+	{power: 0.6, precision: 1.0, accuracy: 0.9, agility: 0.5, armor: 0.3, vitality: 0.6, speed: 0.7}, // marksman
+	{power: 0.7, precision: 0.5, accuracy: 0.6, agility: 1.0, armor: 0.3, vitality: 0.5, speed: 1.0}, // skirmisher
+}
+
+// GenerateBots builds count synthetic fighters scaled to powerlevel. Bots are
+// not persisted: they only exist in the simulation input, so they never
+// receive rewards and never show up on fighter based leaderboards. All
+// randomness comes from rng so bots are part of a match's replayable input.
+func GenerateBots(count int, powerlevel int, rng *rand.Rand) []roster.Fighter {
+	if count <= 0 {
+		return nil
+	}
+	if count > MaxBotCount {
+		count = MaxBotCount
+	}
+	if powerlevel <= 0 {
+		powerlevel = DefaultBotPowerlevel
+	}
+
+	bots := make([]roster.Fighter, 0, count)
+	for i := 0; i < count; i++ {
+		archetype := botArchetypes[rng.Intn(len(botArchetypes))]
+		// +-10% variance so bots of one match are not identical
+		budget := float64(powerlevel) * (0.9 + rng.Float64()*0.2)
+		stat := func(weight float64) int {
+			return int(budget * weight)
+		}
+
+		id, _ := uuid.NewRandomFromReader(rng)
+		level := powerlevel / 2
+		if level < 1 {
+			level = 1
+		}
+
+		bot := roster.Fighter{
+			ID:        id.String(),
+			Name:      fmt.Sprintf("%s %s", botPrefixes[rng.Intn(len(botPrefixes))], botNouns[rng.Intn(len(botNouns))]),
+			Level:     level,
+			Power:     stat(archetype.power),
+			Precision: stat(archetype.precision),
+			Accuracy:  stat(archetype.accuracy),
+			Agility:   stat(archetype.agility),
+			Armor:     stat(archetype.armor),
+			Vitality:  stat(archetype.vitality),
+			Speed:     stat(archetype.speed),
+			IsBot:     true,
+		}
+
+		// Half of the bots are attuned to an element
+		if rng.Intn(2) == 0 {
+			element := string(attunement.AllElements[rng.Intn(len(attunement.AllElements))])
+			bot.AttunementID = &element
+		}
+		if weapon := botWeapon(powerlevel, rng); weapon != nil {
+			bot.WeaponID = &weapon.ID
+		}
+
+		bots = append(bots, bot)
+	}
+	return bots
+}
+
+// botWeapon picks a weapon definition whose rarity fits the powerlevel, or
+// nil for an unarmed bot.
+func botWeapon(powerlevel int, rng *rand.Rand) *weapons.Weapon {
+	if rng.Intn(3) == 0 {
+		return nil
+	}
+
+	rarity := weapons.Common
+	switch {
+	case powerlevel >= 80:
+		rarity = weapons.Legendary
+	case powerlevel >= 50:
+		rarity = weapons.Epic
+	case powerlevel >= 30:
+		rarity = weapons.Rare
+	case powerlevel >= 15:
+		rarity = weapons.Uncommon
+	}
+
+	candidates := weapons.GetWeaponsByRarity(rarity)
+	if len(candidates) == 0 {
+		return nil
+	}
+	weapon := candidates[rng.Intn(len(candidates))]
+	return &weapon
+}
@@ -0,0 +1,69 @@
+package matches
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/roster"
+)
+
+func TestGenerateBots(t *testing.T) {
+	bots := GenerateBots(3, 20, rand.New(rand.NewSource(1)))
+	if len(bots) != 3 {
+		t.Fatalf("expected 3 bots, got %d", len(bots))
+	}
+
+	again := GenerateBots(3, 20, rand.New(rand.NewSource(1)))
+	for i, bot := range bots {
+		if !bot.IsBot {
+			t.Errorf("bot %d is not flagged as bot", i)
+		}
+		if bot.Name == "" || bot.ID == "" {
+			t.Errorf("bot %d is missing name or id", i)
+		}
+		if bot.Power < 10 || bot.Power > 22 {
+			t.Errorf("bot %d power %d not scaled to powerlevel 20", i, bot.Power)
+		}
+		if bot.ID != again[i].ID || bot.Name != again[i].Name {
+			t.Errorf("bot %d differs for the same seed", i)
+		}
+	}
+
+	if capped := GenerateBots(MaxBotCount+5, 10, rand.New(rand.NewSource(1))); len(capped) != MaxBotCount {
+		t.Errorf("expected bot count to be capped at %d, got %d", MaxBotCount, len(capped))
+	}
+}
+
+func TestBattleSimulator_RunWithBots(t *testing.T) {
+	solo := roster.Fighter{ID: "solo", Name: "Solo", Power: 15, Vitality: 12, Speed: 8}
+	participants := append([]roster.Fighter{solo}, GenerateBots(3, 10, rand.New(rand.NewSource(5)))...)
+
+	result, err := NewBattleSimulator().Run("match", participants, BattleOptions{MaxRounds: 80, MapSize: 15.0, Seed: 5})
+	if err != nil {
+		t.Fatalf("Failed to run simulation: %v", err)
+	}
+
+	spawnedBots := 0
+	for _, tick := range result.RoundTicks[0].Ticks {
+		var spawn combat.EventSpawn
+		_ = json.Unmarshal(tick.Payload, &spawn)
+		if spawn.IsBot {
+			spawnedBots++
+		}
+	}
+	if spawnedBots != 3 {
+		t.Errorf("expected 3 bot spawns, got %d", spawnedBots)
+	}
+
+	botScores := 0
+	for _, score := range result.Scores {
+		if score.IsBot {
+			botScores++
+		}
+	}
+	if botScores != 3 {
+		t.Errorf("expected 3 bot scores, got %d", botScores)
+	}
+}
@@ -0,0 +1,76 @@
+package matches
+
+import (
+	"context"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/matches"
+)
+
+// Page sizes of combat log queries
+const (
+	DefaultCombatLogPageSize = 100
+	MaxCombatLogPageSize     = 500
+)
+
+// CombatLog returns one page of a match's ticks matching filter, in battle
+// order, and the total number of matching ticks. The filter's Limit and
+// Offset are derived from page and pageSize.
+func (s *Service) CombatLog(ctx context.Context, matchID string, filter matches.CombatLogFilter, page int, pageSize int) ([]matches.CombatLogEntry, int, error) {
+	if s.combatLogs == nil {
+		return nil, 0, ErrCombatLogDisabled
+	}
+	if withheld, err := s.withheld(ctx, matchID); err != nil || withheld {
+		return nil, 0, liveErr(err)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultCombatLogPageSize
+	}
+	if pageSize > MaxCombatLogPageSize {
+		pageSize = MaxCombatLogPageSize
+	}
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+	return s.combatLogs.ListLogs(ctx, matchID, filter)
+}
+
+// BattleSummary returns the stored outcome of a finished match.
+func (s *Service) BattleSummary(ctx context.Context, matchID string) (*matches.BattleSummary, error) {
+	if s.combatLogs == nil {
+		return nil, ErrCombatLogDisabled
+	}
+	if withheld, err := s.withheld(ctx, matchID); err != nil || withheld {
+		return nil, liveErr(err)
+	}
+	summary, err := s.combatLogs.GetSummary(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		return nil, ErrInvalidMatch
+	}
+	return summary, nil
+}
+
+func newBattleSummary(result *combat.MatchResult) *matches.BattleSummary {
+	summary := &matches.BattleSummary{
+		MatchID:      result.MatchID,
+		WinnerTeamID: result.WinnerTeamID,
+		WinnerIDs:    result.WinnerIDs,
+		Scores:       result.Scores,
+	}
+	if len(result.WinnerIDs) == 1 && result.WinnerTeamID == nil {
+		summary.WinnerID = &result.WinnerIDs[0]
+	}
+	if n := len(result.RoundTicks); n > 0 {
+		summary.TotalRounds = result.RoundTicks[n-1].Round
+	}
+	for _, score := range result.Scores {
+		summary.TotalDamage += score.DamageDealt
+		summary.TotalHealed += score.Healed
+	}
+	return summary
+}
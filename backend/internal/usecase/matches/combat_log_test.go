@@ -0,0 +1,153 @@
+package matches
+
+import (
+	"context"
+	"testing"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/roster"
+)
+
+// memoryCombatLogRepo mirrors the filtering of the Postgres combat log.
+type memoryCombatLogRepo struct {
+	entries   map[string][]matches.CombatLogEntry
+	fighters  map[string][][]string
+	summaries map[string]*matches.BattleSummary
+}
+
+func newMemoryCombatLogRepo() *memoryCombatLogRepo {
+	return &memoryCombatLogRepo{
+		entries:   make(map[string][]matches.CombatLogEntry),
+		fighters:  make(map[string][][]string),
+		summaries: make(map[string]*matches.BattleSummary),
+	}
+}
+
+func (m *memoryCombatLogRepo) SaveLogs(ctx context.Context, matchID string, roundTicks []combat.RoundTick) error {
+	m.entries[matchID], m.fighters[matchID] = nil, nil
+	for _, rt := range roundTicks {
+		for i, tick := range rt.Ticks {
+			m.entries[matchID] = append(m.entries[matchID], matches.CombatLogEntry{MatchID: matchID, Round: rt.Round, Tick: i, EventType: tick.Type, Payload: tick.Payload})
+			m.fighters[matchID] = append(m.fighters[matchID], tick.FighterIDs())
+		}
+	}
+	return nil
+}
+
+func (m *memoryCombatLogRepo) ListLogs(ctx context.Context, matchID string, filter matches.CombatLogFilter) ([]matches.CombatLogEntry, int, error) {
+	var matching []matches.CombatLogEntry
+	for i, entry := range m.entries[matchID] {
+		if filter.FighterID != "" && !contains(m.fighters[matchID][i], filter.FighterID) {
+			continue
+		}
+		if len(filter.EventTypes) > 0 && !contains(filter.EventTypes, entry.EventType) {
+			continue
+		}
+		if (filter.FromRound != nil && entry.Round < *filter.FromRound) || (filter.ToRound != nil && entry.Round > *filter.ToRound) {
+			continue
+		}
+		matching = append(matching, entry)
+	}
+	total := len(matching)
+	if filter.Offset > total {
+		return nil, total, nil
+	}
+	matching = matching[filter.Offset:]
+	if len(matching) > filter.Limit {
+		matching = matching[:filter.Limit]
+	}
+	return matching, total, nil
+}
+
+func (m *memoryCombatLogRepo) SaveSummary(ctx context.Context, summary *matches.BattleSummary) error {
+	m.summaries[summary.MatchID] = summary
+	return nil
+}
+
+func (m *memoryCombatLogRepo) GetSummary(ctx context.Context, matchID string) (*matches.BattleSummary, error) {
+	return m.summaries[matchID], nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestService_CombatLogFilters(t *testing.T) {
+	fighters := []roster.Fighter{
+		{ID: "a", Name: "Warrior", Power: 15, Armor: 10, Vitality: 12, Speed: 5},
+		{ID: "b", Name: "Ranger", Power: 8, Precision: 18, Agility: 15, Speed: 12, Vitality: 8},
+		{ID: "c", Name: "Rogue", Power: 10, Agility: 20, Speed: 14, Vitality: 6},
+	}
+	result, err := NewBattleSimulator().Run("match", fighters, BattleOptions{MaxRounds: 60, MapSize: 20.0, Seed: 5})
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	repo := newMemoryCombatLogRepo()
+	if err := repo.SaveLogs(context.Background(), "match", result.RoundTicks); err != nil {
+		t.Fatalf("save logs: %v", err)
+	}
+	svc := &Service{matches: &memoryMatchRepo{}, combatLogs: repo}
+
+	all, total, err := svc.CombatLog(context.Background(), "match", matches.CombatLogFilter{}, 1, 10)
+	if err != nil {
+		t.Fatalf("combat log: %v", err)
+	}
+	if len(all) != 10 || total <= 10 {
+		t.Fatalf("expected a full first page of a longer log, got %d of %d", len(all), total)
+	}
+
+	from, to := 2, 4
+	attacks, _, err := svc.CombatLog(context.Background(), "match", matches.CombatLogFilter{
+		FighterID:  "b",
+		EventTypes: []string{"attack"},
+		FromRound:  &from,
+		ToRound:    &to,
+	}, 1, MaxCombatLogPageSize+1)
+	if err != nil {
+		t.Fatalf("combat log: %v", err)
+	}
+	for _, entry := range attacks {
+		tick := combat.Tick{Type: entry.EventType, Payload: entry.Payload}
+		if entry.EventType != "attack" || entry.Round < from || entry.Round > to || !contains(tick.FighterIDs(), "b") {
+			t.Errorf("entry outside the filter: round %d %s %s", entry.Round, entry.EventType, entry.Payload)
+		}
+	}
+}
+
+func TestService_BattleSummary(t *testing.T) {
+	repo := newMemoryCombatLogRepo()
+	svc := &Service{matches: &memoryMatchRepo{}, combatLogs: repo}
+	if _, err := svc.BattleSummary(context.Background(), "missing"); err != ErrInvalidMatch {
+		t.Fatalf("expected ErrInvalidMatch, got %v", err)
+	}
+
+	result := &combat.MatchResult{
+		MatchID:    "match",
+		RoundTicks: []combat.RoundTick{{Round: 0}, {Round: 7}},
+		Scores: []combat.FighterScore{
+			{FighterID: "a", DamageDealt: 40, Healed: 5},
+			{FighterID: "b", DamageDealt: 25},
+		},
+		WinnerIDs: []string{"a"},
+	}
+	if err := repo.SaveSummary(context.Background(), newBattleSummary(result)); err != nil {
+		t.Fatalf("save summary: %v", err)
+	}
+
+	summary, err := svc.BattleSummary(context.Background(), "match")
+	if err != nil {
+		t.Fatalf("battle summary: %v", err)
+	}
+	if summary.WinnerID == nil || *summary.WinnerID != "a" || summary.TotalRounds != 7 {
+		t.Errorf("unexpected outcome %+v", summary)
+	}
+	if summary.TotalDamage != 65 || summary.TotalHealed != 5 {
+		t.Errorf("expected 65 damage and 5 healed, got %d and %d", summary.TotalDamage, summary.TotalHealed)
+	}
+}
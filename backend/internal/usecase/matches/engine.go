@@ -0,0 +1,81 @@
+package matches
+
+import (
+	"context"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/roster"
+)
+
+// Combat engines a match can be resolved by
+const (
+	EngineLocal  = "local"
+	EngineRemote = "remote"
+)
+
+// CombatEngine resolves a battle between the participants of a match.
+type CombatEngine interface {
+	Run(ctx context.Context, matchID string, fighters []roster.Fighter, options BattleOptions) (*combat.MatchResult, error)
+}
+
+// LocalEngine runs battles in process with the BattleSimulator.
+type LocalEngine struct{}
+
+func NewLocalEngine() *LocalEngine {
+	return &LocalEngine{}
+}
+
+func (e *LocalEngine) Run(ctx context.Context, matchID string, fighters []roster.Fighter, options BattleOptions) (*combat.MatchResult, error) {
+	// A simulator holds the state of one battle, so every run gets its own
+	return NewBattleSimulator().Run(matchID, fighters, options)
+}
+
+// SetDefaultEngine selects the engine for matches that do not name one. An
+// unknown name keeps the current default.
+func (s *Service) SetDefaultEngine(name string) {
+	if _, ok := s.engines[name]; ok {
+		s.defaultEngine = name
+	}
+}
+
+// Engines returns the names of the engines matches can choose from.
+func (s *Service) Engines() []string {
+	names := []string{EngineLocal}
+	if _, ok := s.engines[EngineRemote]; ok {
+		names = append(names, EngineRemote)
+	}
+	return names
+}
+
+func (s *Service) validEngine(name string) bool {
+	if name == "" {
+		return true
+	}
+	_, ok := s.engines[name]
+	return ok
+}
+
+// runBattle resolves the battle with the engine the match asks for, or the
+// default one. When any engine but the local one fails, the battle falls back
+// to the local simulator. It returns the name of the engine that produced the
+// result.
+func (s *Service) runBattle(ctx context.Context, matchID string, fighters []roster.Fighter, battleOptions BattleOptions, options MatchOptions) (*combat.MatchResult, string, error) {
+	name := options.Engine
+	if name == "" {
+		name = s.defaultEngine
+	}
+	engine, ok := s.engines[name]
+	if !ok {
+		name, engine = EngineLocal, NewLocalEngine()
+	}
+
+	result, err := engine.Run(ctx, matchID, fighters, battleOptions)
+	if err != nil && name != EngineLocal {
+		result, err = NewLocalEngine().Run(ctx, matchID, fighters, battleOptions)
+		name = EngineLocal
+	}
+	if err != nil {
+		return nil, name, err
+	}
+	return result, name, nil
+}
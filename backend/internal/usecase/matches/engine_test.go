@@ -0,0 +1,94 @@
+package matches
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/roster"
+)
+
+type fakeEngine struct {
+	calls  int
+	err    error
+	result *combat.MatchResult
+}
+
+func (f *fakeEngine) Run(ctx context.Context, matchID string, fighters []roster.Fighter, options BattleOptions) (*combat.MatchResult, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.result, nil
+}
+
+func engineFighters() []roster.Fighter {
+	return []roster.Fighter{
+		{ID: "a", Name: "Warrior", Power: 15, Vitality: 12, Speed: 5},
+		{ID: "b", Name: "Ranger", Power: 8, Agility: 15, Speed: 12},
+	}
+}
+
+func TestRunBattle_SelectsEngine(t *testing.T) {
+	remote := &fakeEngine{result: &combat.MatchResult{MatchID: "remote"}}
+	svc := NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, remote, nil, nil)
+	options := BattleOptions{MaxRounds: 20, MapSize: 20, Seed: 7}
+
+	if _, name, _ := svc.runBattle(context.Background(), "m", engineFighters(), options, MatchOptions{}); name != EngineLocal || remote.calls != 0 {
+		t.Fatalf("expected the local default, got %s with %d remote calls", name, remote.calls)
+	}
+
+	result, name, err := svc.runBattle(context.Background(), "m", engineFighters(), options, MatchOptions{Engine: EngineRemote})
+	if err != nil || name != EngineRemote || result.MatchID != "remote" {
+		t.Fatalf("expected the remote engine per match, got %s (%v)", name, err)
+	}
+
+	svc.SetDefaultEngine(EngineRemote)
+	if _, name, _ := svc.runBattle(context.Background(), "m", engineFighters(), options, MatchOptions{}); name != EngineRemote {
+		t.Fatalf("expected the configured default, got %s", name)
+	}
+}
+
+func TestRunBattle_FallsBackToLocal(t *testing.T) {
+	remote := &fakeEngine{err: errors.New("engine down")}
+	svc := NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, remote, nil, nil)
+	svc.SetDefaultEngine(EngineRemote)
+	options := BattleOptions{MaxRounds: 20, MapSize: 20, Seed: 7}
+
+	result, name, err := svc.runBattle(context.Background(), "m", engineFighters(), options, MatchOptions{})
+	if err != nil {
+		t.Fatalf("expected the local engine to take over: %v", err)
+	}
+	if name != EngineLocal || remote.calls != 1 {
+		t.Fatalf("expected a fallback after one remote call, got %s after %d", name, remote.calls)
+	}
+
+	expected, _ := NewBattleSimulator().Run("m", engineFighters(), options)
+	got, _ := json.Marshal(result.RoundTicks)
+	want, _ := json.Marshal(expected.RoundTicks)
+	if string(got) != string(want) {
+		t.Fatal("expected the fallback to be the deterministic local simulation")
+	}
+}
+
+func TestCreateMatch_RejectsUnknownEngine(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	if _, err := svc.CreateMatch(context.Background(), 1, MatchOptions{Engine: EngineRemote}); err != ErrUnknownEngine {
+		t.Fatalf("expected the unconfigured remote engine to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyReplay_RemoteResultsAreNotReplayable(t *testing.T) {
+	input, _ := json.Marshal(BattleInput{Fighters: engineFighters(), Engine: EngineRemote})
+	repo := &memoryResultRepo{results: map[string]*matches.MatchResult{
+		"m": {MatchID: "m", RoundTicks: []byte("[]"), BattleInput: input},
+	}}
+	svc := &Service{matches: &memoryMatchRepo{}, results: repo}
+
+	if _, err := svc.VerifyReplay(context.Background(), "m"); err != ErrReplayUnavailable {
+		t.Fatalf("expected ErrReplayUnavailable, got %v", err)
+	}
+}
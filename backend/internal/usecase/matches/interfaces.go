@@ -2,15 +2,25 @@ package matches
 
 import (
 	"context"
+	"time"
 
+	"empoweredpixels/internal/domain/attunement"
+
+	"empoweredpixels/internal/domain/combat"
 	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/rating"
 	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/skills"
+	"empoweredpixels/internal/domain/weapons"
+	attunementusecase "empoweredpixels/internal/usecase/attunement"
 )
 
 type MatchRepository interface {
 	Create(ctx context.Context, match *matches.Match) error
 	GetByID(ctx context.Context, id string) (*matches.Match, error)
 	Update(ctx context.Context, match *matches.Match) error
+	MarkRunning(ctx context.Context, id string, started time.Time) (bool, error)
+	Reveal(ctx context.Context, id string, at time.Time) error
 	ListOpen(ctx context.Context, limit int, offset int) ([]matches.Match, error)
 	ListByStatus(ctx context.Context, status string, limit int, offset int) ([]matches.Match, error)
 	GetCurrentMatch(ctx context.Context, userID int64) (*matches.Match, error)
@@ -42,9 +52,70 @@ type ScoreRepository interface {
 	Upsert(ctx context.Context, scores []matches.MatchScoreFighter) error
 }
 
+type CombatLogRepository interface {
+	SaveLogs(ctx context.Context, matchID string, roundTicks []combat.RoundTick) error
+	ListLogs(ctx context.Context, matchID string, filter matches.CombatLogFilter) ([]matches.CombatLogEntry, int, error)
+	SaveSummary(ctx context.Context, summary *matches.BattleSummary) error
+	GetSummary(ctx context.Context, matchID string) (*matches.BattleSummary, error)
+}
+
 type FighterRepository interface {
 	GetByID(ctx context.Context, id string) (*roster.Fighter, error)
 	GetByUserAndID(ctx context.Context, userID int64, id string) (*roster.Fighter, error)
 	ListByUser(ctx context.Context, userID int64) ([]roster.Fighter, error)
 	ListByMatch(ctx context.Context, matchID string) ([]roster.Fighter, error)
+	RecordMatch(ctx context.Context, fighterID string, won bool, damageDealt, damageTaken int64) error
+}
+
+type WeaponProvider interface {
+	GetFighterWeapon(ctx context.Context, fighterID string) (*weapons.UserWeapon, *weapons.Weapon, error)
+}
+
+type SkillRepository interface {
+	GetFighterSkills(ctx context.Context, fighterID string) (*skills.FighterSkills, error)
+	UpdateUltimateCharge(ctx context.Context, fighterID string, charge int) error
+}
+
+type AttunementService interface {
+	GetAllElementsBonus(ctx context.Context, userID int) (*attunementusecase.AggregatedBonuses, error)
+	AwardXP(ctx context.Context, userID int, element attunement.Element, source string) (levelUp bool, newLevel int, xpAwarded int, err error)
+}
+
+// RunQueue persists the queued executions of matches.
+type RunQueue interface {
+	Enqueue(ctx context.Context, run *matches.MatchRun) (bool, error)
+	Claim(ctx context.Context, now time.Time, leaseUntil time.Time) (*matches.MatchRun, error)
+	Complete(ctx context.Context, id string, finishedAt time.Time) error
+	Retry(ctx context.Context, id string, availableAt time.Time, lastError string) error
+	Fail(ctx context.Context, id string, finishedAt time.Time, lastError string) error
+	ReleaseExpired(ctx context.Context, now time.Time) ([]matches.MatchRun, error)
+	ListOrphanedMatches(ctx context.Context, startedBefore time.Time) ([]string, error)
+	Stats(ctx context.Context) (matches.MatchRunStats, error)
+}
+
+// UnitOfWork runs fn in a transaction. Repositories called with the context
+// fn receives take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type RatingRepository interface {
+	ListByFighters(ctx context.Context, fighterIDs []string) ([]rating.Rating, error)
+	Save(ctx context.Context, rating *rating.Rating) error
+}
+
+// Ladder places rated fighters on the ranked ladder. Only the fighters a
+// ranked match rated are recorded, so ladder points and season rewards can't
+// come from lobbies players make themselves.
+type Ladder interface {
+	RecordMatch(ctx context.Context, userID int64, updated rating.Rating, score float64) error
+}
+
+type SettlementRepository interface {
+	Claim(ctx context.Context, matchID string, settledAt time.Time) (bool, error)
+}
+
+// Notifier tells users how their fighters advanced in a match.
+type Notifier interface {
+	FighterLeveledUp(ctx context.Context, levelUp roster.LevelUp) error
 }
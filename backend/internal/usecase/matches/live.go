@@ -0,0 +1,179 @@
+package matches
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"empoweredpixels/internal/domain/combat"
+)
+
+// Pacing of live matches
+const (
+	DefaultRoundInterval = time.Second
+	MinRoundInterval     = 100 * time.Millisecond
+	MaxRoundInterval     = 10 * time.Second
+	MaxSpectatorDelay    = time.Minute
+	// liveFinishTimeout bounds revealing the outcome of a stream cut short
+	// by shutdown.
+	liveFinishTimeout = 5 * time.Second
+)
+
+// livePace returns the real time between two streamed rounds and the delay
+// before the first one for a live match.
+func livePace(options MatchOptions) (interval time.Duration, delay time.Duration) {
+	interval = DefaultRoundInterval
+	if options.RoundIntervalMs != nil {
+		interval = time.Duration(*options.RoundIntervalMs) * time.Millisecond
+	}
+	if interval < MinRoundInterval {
+		interval = MinRoundInterval
+	}
+	if interval > MaxRoundInterval {
+		interval = MaxRoundInterval
+	}
+
+	if options.SpectatorDelayMs != nil {
+		delay = time.Duration(*options.SpectatorDelayMs) * time.Millisecond
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > MaxSpectatorDelay {
+		delay = MaxSpectatorDelay
+	}
+	return interval, delay
+}
+
+// liveDuration is how long streaming rounds of a live match takes at most:
+// the spectator delay and an interval per round.
+func liveDuration(options MatchOptions, rounds int) time.Duration {
+	interval, delay := livePace(options)
+	return delay + time.Duration(rounds)*interval
+}
+
+// liveStreams holds the rounds streamed so far of every match currently
+// being streamed.
+type liveStreams struct {
+	mu     sync.RWMutex
+	rounds map[string][]combat.RoundTick
+}
+
+func newLiveStreams() *liveStreams {
+	return &liveStreams{rounds: make(map[string][]combat.RoundTick)}
+}
+
+func (l *liveStreams) start(matchID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rounds[matchID] = []combat.RoundTick{}
+}
+
+func (l *liveStreams) append(matchID string, round combat.RoundTick) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rounds[matchID] = append(l.rounds[matchID], round)
+}
+
+func (l *liveStreams) stop(matchID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.rounds, matchID)
+}
+
+func (l *liveStreams) get(matchID string) ([]combat.RoundTick, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	rounds, ok := l.rounds[matchID]
+	if !ok {
+		return nil, false
+	}
+	return append([]combat.RoundTick(nil), rounds...), true
+}
+
+// LiveCatchUp returns the message that brings a subscriber joining a live
+// match up to date: every round streamed so far. Rounds streamed after the
+// catch-up carry higher round numbers; clients skip any they already have.
+// It reports false when the match is not being streamed.
+func (s *Service) LiveCatchUp(matchID string) (any, bool) {
+	if s.live == nil {
+		return nil, false
+	}
+	rounds, ok := s.live.get(matchID)
+	if !ok {
+		return nil, false
+	}
+	return map[string]any{"type": "matchCatchUp", "matchId": matchID, "rounds": rounds}, true
+}
+
+// withheld reports whether the outcome of a match is kept from readers:
+// while this process streams it, or while another process does until its
+// stream ends or would have.
+func (s *Service) withheld(ctx context.Context, matchID string) (bool, error) {
+	if _, ok := s.streamed(matchID); ok {
+		return true, nil
+	}
+	match, err := s.matches.GetByID(ctx, matchID)
+	if err != nil || match == nil {
+		return false, err
+	}
+	return match.Withheld(s.now()), nil
+}
+
+// streamed returns the rounds streamed so far of a match being streamed.
+func (s *Service) streamed(matchID string) ([]combat.RoundTick, bool) {
+	if s.live == nil {
+		return nil, false
+	}
+	return s.live.get(matchID)
+}
+
+// streamMatch broadcasts the rounds of a simulated match one at a time in
+// real time, then releases the match and calls finish to reveal its outcome.
+// The match has to be held with live.start before its result is committed.
+// It returns immediately; the stream runs in the background, and a shutdown
+// running out of time skips the rounds left. Once shut down, finish is called
+// with ctx right away.
+func (s *Service) streamMatch(ctx context.Context, matchID string, roundTicks []combat.RoundTick, options MatchOptions, finish func(ctx context.Context)) {
+	interval, delay := livePace(options)
+
+	started := s.background.run(func(ctx context.Context) {
+		pause := delay
+		for _, round := range roundTicks {
+			if !s.wait(ctx, pause) {
+				break
+			}
+			pause = interval
+			s.live.append(matchID, round)
+			s.hub.Broadcast(matchID, map[string]any{
+				"type":    "matchRound",
+				"matchId": matchID,
+				"round":   round.Round,
+				"ticks":   round.Ticks,
+			})
+		}
+
+		if ctx.Err() != nil {
+			// The outcome is revealed even when shutdown cut the stream short
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(context.Background(), liveFinishTimeout)
+			defer cancel()
+		}
+		s.live.stop(matchID)
+		finish(ctx)
+	})
+	if !started {
+		s.live.stop(matchID)
+		finish(ctx)
+	}
+}
+
+// wait blocks for d or until ctx is done. It reports whether d passed.
+func (s *Service) wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-s.after(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,179 @@
+package matches
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/combat"
+)
+
+type recordingHub struct {
+	mu       sync.Mutex
+	messages []map[string]any
+	ended    chan struct{}
+}
+
+func (h *recordingHub) Broadcast(matchID string, payload any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	message := payload.(map[string]any)
+	h.messages = append(h.messages, message)
+	if message["type"] == "matchEnded" {
+		close(h.ended)
+	}
+}
+
+func TestLivePace(t *testing.T) {
+	interval, delay := livePace(MatchOptions{})
+	if interval != DefaultRoundInterval || delay != 0 {
+		t.Fatalf("unexpected defaults %v / %v", interval, delay)
+	}
+
+	fast, long := 1, int(time.Hour/time.Millisecond)
+	interval, delay = livePace(MatchOptions{RoundIntervalMs: &fast, SpectatorDelayMs: &long})
+	if interval != MinRoundInterval || delay != MaxSpectatorDelay {
+		t.Fatalf("expected pace to be clamped, got %v / %v", interval, delay)
+	}
+}
+
+func TestService_StreamMatchPacesRoundsAndCatchesUp(t *testing.T) {
+	hub := &recordingHub{ended: make(chan struct{})}
+	// Every wait blocks until the test releases it
+	release := make(chan time.Time)
+	var waits []time.Duration
+	var waitsMu sync.Mutex
+	svc := &Service{hub: hub, live: newLiveStreams(), background: newBackground(), after: func(d time.Duration) <-chan time.Time {
+		waitsMu.Lock()
+		waits = append(waits, d)
+		waitsMu.Unlock()
+		return release
+	}}
+
+	rounds := []combat.RoundTick{{Round: 0}, {Round: 1}, {Round: 2}}
+	interval, delay := 500, 2000
+	svc.live.start("match")
+	svc.streamMatch(context.Background(), "match", rounds, MatchOptions{Live: true, RoundIntervalMs: &interval, SpectatorDelayMs: &delay}, func(ctx context.Context) {
+		hub.Broadcast("match", map[string]any{"type": "matchEnded"})
+	})
+
+	catchUp, ok := svc.LiveCatchUp("match")
+	if !ok {
+		t.Fatal("expected the match to be streaming")
+	}
+	if streamed := catchUp.(map[string]any)["rounds"].([]combat.RoundTick); len(streamed) != 0 {
+		t.Fatalf("expected no rounds during the spectator delay, got %d", len(streamed))
+	}
+
+	release <- time.Time{} // spectator delay
+	release <- time.Time{} // round 0 -> 1
+	catchUp, _ = svc.LiveCatchUp("match")
+	if streamed := catchUp.(map[string]any)["rounds"].([]combat.RoundTick); len(streamed) < 1 {
+		t.Fatalf("expected a late joiner to catch up on streamed rounds, got %d", len(streamed))
+	}
+	if _, err := svc.FighterScores(context.Background(), "match"); err != ErrMatchLive {
+		t.Fatalf("expected scores to be withheld while streaming, got %v", err)
+	}
+	data, err := svc.RoundTicks(context.Background(), "match")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sofar []combat.RoundTick
+	if err := json.Unmarshal(data, &sofar); err != nil || len(sofar) == len(rounds) {
+		t.Fatalf("expected only the rounds streamed so far, got %s", data)
+	}
+	release <- time.Time{} // round 1 -> 2
+
+	select {
+	case <-hub.ended:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end")
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	var types []string
+	for _, message := range hub.messages {
+		types = append(types, message["type"].(string))
+	}
+	got, _ := json.Marshal(types)
+	if string(got) != `["matchRound","matchRound","matchRound","matchEnded"]` {
+		t.Fatalf("unexpected message order %s", got)
+	}
+	if waits[0] != 2*time.Second || waits[1] != 500*time.Millisecond {
+		t.Fatalf("expected the delay then the round interval, got %v", waits)
+	}
+	if _, ok := svc.LiveCatchUp("match"); ok {
+		t.Fatal("expected the stream to be gone once ended")
+	}
+}
+
+func TestService_ShutdownDrainsStreams(t *testing.T) {
+	hub := &recordingHub{ended: make(chan struct{})}
+	pace := make(chan time.Time)
+	svc := &Service{hub: hub, live: newLiveStreams(), background: newBackground(), after: func(time.Duration) <-chan time.Time {
+		return pace
+	}}
+
+	finished := make(chan error, 1)
+	finish := func(ctx context.Context) { finished <- ctx.Err() }
+	svc.live.start("match")
+	svc.streamMatch(context.Background(), "match", []combat.RoundTick{{Round: 0}}, MatchOptions{Live: true}, finish)
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- svc.Shutdown(context.Background()) }()
+	// Shutdown waits for the stream to play out
+	pace <- time.Now()
+	if err := <-shutdown; err != nil {
+		t.Fatalf("expected the stream to drain, got %v", err)
+	}
+	if err := <-finished; err != nil {
+		t.Fatalf("unexpected finish error %v", err)
+	}
+	if len(hub.messages) != 1 || hub.messages[0]["type"] != "matchRound" {
+		t.Fatalf("expected the stream to broadcast its round, got %+v", hub.messages)
+	}
+}
+
+func TestService_ShutdownCutsStreamsShortAndFinishes(t *testing.T) {
+	hub := &recordingHub{ended: make(chan struct{})}
+	never := make(chan time.Time)
+	svc := &Service{hub: hub, live: newLiveStreams(), background: newBackground(), after: func(time.Duration) <-chan time.Time {
+		return never
+	}}
+
+	finished := make(chan error, 2)
+	finish := func(ctx context.Context) { finished <- ctx.Err() }
+	rounds := []combat.RoundTick{{Round: 0}, {Round: 1}}
+	svc.live.start("match")
+	svc.streamMatch(context.Background(), "match", rounds, MatchOptions{Live: true}, finish)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := svc.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the stream cut short at the deadline, got %v", err)
+	}
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Fatalf("expected the outcome to be revealed with a live context, got %v", err)
+		}
+	default:
+		t.Fatal("expected the stream to finish before Shutdown returned")
+	}
+	if _, ok := svc.LiveCatchUp("match"); ok {
+		t.Fatal("expected the match to be released")
+	}
+
+	// Streams started after shutdown reveal the outcome right away
+	svc.live.start("late")
+	svc.streamMatch(context.Background(), "late", rounds, MatchOptions{Live: true}, finish)
+	if err := <-finished; err != nil {
+		t.Fatalf("unexpected finish error %v", err)
+	}
+	if _, ok := svc.LiveCatchUp("late"); ok {
+		t.Fatal("expected the late match to be released")
+	}
+}
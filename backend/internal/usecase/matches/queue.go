@@ -0,0 +1,354 @@
+package matches
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"empoweredpixels/internal/domain/matches"
+
+	"github.com/google/uuid"
+)
+
+// Match run queue settings
+const (
+	// DefaultMaxAttempts is how often a run is tried before the match is
+	// cancelled.
+	DefaultMaxAttempts = 3
+	// QueueLease is how long a worker may hold a run. A run still leased
+	// after that belongs to a dead worker and is released by the sweep.
+	QueueLease = 2 * time.Minute
+	// RetryBackoff is the delay before the first retry, doubled after every
+	// further attempt.
+	RetryBackoff = 5 * time.Second
+)
+
+// MatchFinishedHook is called after a queued match completed.
+type MatchFinishedHook func(ctx context.Context, matchID string)
+
+// QueueMetrics describes the depth of the match run queue and, since the
+// process started, the outcome and latency of the runs it processed.
+type QueueMetrics struct {
+	Pending            int   `json:"pending"`
+	Running            int   `json:"running"`
+	Failed             int   `json:"failed"`
+	OldestPendingAgeMs int64 `json:"oldestPendingAgeMs"`
+	Completed          int64 `json:"completed"`
+	Retried            int64 `json:"retried"`
+	Abandoned          int64 `json:"abandoned"`
+	Recovered          int64 `json:"recovered"`
+	AvgWaitMs          int64 `json:"avgWaitMs"`
+	MaxWaitMs          int64 `json:"maxWaitMs"`
+	AvgRunMs           int64 `json:"avgRunMs"`
+	MaxRunMs           int64 `json:"maxRunMs"`
+}
+
+type queueCounters struct {
+	mu        sync.Mutex
+	completed int64
+	retried   int64
+	abandoned int64
+	recovered int64
+	runs      int64
+	totalWait time.Duration
+	maxWait   time.Duration
+	totalRun  time.Duration
+	maxRun    time.Duration
+}
+
+func (c *queueCounters) observe(wait time.Duration, run time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if wait < 0 {
+		wait = 0
+	}
+	c.runs++
+	c.totalWait += wait
+	c.totalRun += run
+	if wait > c.maxWait {
+		c.maxWait = wait
+	}
+	if run > c.maxRun {
+		c.maxRun = run
+	}
+}
+
+func (c *queueCounters) add(counter *int64, n int64) {
+	c.mu.Lock()
+	*counter += n
+	c.mu.Unlock()
+}
+
+// SetQueue installs the persistent run queue. Without one, started matches
+// run in a goroutine of the process that started them, which Shutdown
+// cancels.
+func (s *Service) SetQueue(queue RunQueue) {
+	s.queue = queue
+}
+
+// OnMatchFinished registers a hook called after a started match completed
+// and its outcome was revealed, which for a live match is when its stream
+// ended. Hooks may be called again for the same match after a crash and must
+// be idempotent.
+func (s *Service) OnMatchFinished(hook MatchFinishedHook) {
+	s.finishedHooks = append(s.finishedHooks, hook)
+}
+
+// StartMatch checks that the lobby can start and queues its execution. A
+// match that is already queued is not queued again.
+func (s *Service) StartMatch(ctx context.Context, matchID string) error {
+	match, err := s.matches.GetByID(ctx, matchID)
+	if err != nil {
+		return err
+	}
+	if match == nil {
+		return ErrInvalidMatch
+	}
+	if match.Status != matches.MatchStatusLobby {
+		return ErrMatchNotLobby
+	}
+
+	fighters, err := s.fighters.ListByMatch(ctx, matchID)
+	if err != nil {
+		return err
+	}
+	var options MatchOptions
+	_ = json.Unmarshal(match.Options, &options)
+	if len(fighters)+botCount(options) < 2 {
+		return ErrNotEnoughFighters
+	}
+
+	if s.queue == nil {
+		started := s.background.run(func(ctx context.Context) {
+			_ = s.ExecuteMatch(ctx, matchID)
+		})
+		if !started {
+			return ErrServiceStopped
+		}
+		return nil
+	}
+	return s.enqueue(ctx, matchID)
+}
+
+func (s *Service) enqueue(ctx context.Context, matchID string) error {
+	now := s.now()
+	_, err := s.queue.Enqueue(ctx, &matches.MatchRun{
+		ID:          uuid.NewString(),
+		MatchID:     matchID,
+		Status:      matches.RunStatusPending,
+		MaxAttempts: DefaultMaxAttempts,
+		AvailableAt: now,
+		EnqueuedAt:  now,
+	})
+	return err
+}
+
+// ProcessNext claims the next available run and executes its match. It
+// reports whether there was a run to process.
+func (s *Service) ProcessNext(ctx context.Context) (bool, error) {
+	if s.queue == nil {
+		return false, nil
+	}
+
+	claimedAt := s.now()
+	run, err := s.queue.Claim(ctx, claimedAt, claimedAt.Add(QueueLease))
+	if err != nil || run == nil {
+		return false, err
+	}
+
+	// The run has to end before its lease does, or the sweep hands the match
+	// to another worker while this one still writes its result
+	runCtx, cancel := context.WithTimeout(ctx, QueueLease)
+	execErr := s.ExecuteMatch(runCtx, run.MatchID)
+	cancel()
+
+	s.queueStats.observe(claimedAt.Sub(run.AvailableAt), s.now().Sub(claimedAt))
+	return true, s.settleRun(ctx, run, execErr)
+}
+
+// settleRun records the outcome of an attempt. A match is settled in a single
+// transaction and only once, so an attempt that follows a crashed one starts
+// over and one that overlaps a slow one leaves the result to it.
+func (s *Service) settleRun(ctx context.Context, run *matches.MatchRun, execErr error) error {
+	switch {
+	case execErr == nil, errors.Is(execErr, ErrMatchSettled):
+		// A settled match was completed by this or an overlapping attempt
+		return s.completeRun(ctx, run)
+	case errors.Is(execErr, ErrInvalidMatch), errors.Is(execErr, ErrNotEnoughFighters):
+		s.queueStats.add(&s.queueStats.abandoned, 1)
+		return s.queue.Fail(ctx, run.ID, s.now(), execErr.Error())
+	case errors.Is(execErr, ErrMatchNotLobby):
+		match, err := s.matches.GetByID(ctx, run.MatchID)
+		if err != nil {
+			return s.retryOrFail(ctx, run, err)
+		}
+		switch {
+		case match == nil:
+			return s.queue.Fail(ctx, run.ID, s.now(), ErrInvalidMatch.Error())
+		case match.Status == matches.MatchStatusCompleted:
+			// An earlier attempt finished the match but died before the run,
+			// maybe before calling the hooks too
+			if err := s.completeRun(ctx, run); err != nil {
+				return err
+			}
+			s.matchFinished(ctx, run.MatchID)
+			return nil
+		case match.Status == matches.MatchStatusCancelled:
+			s.queueStats.add(&s.queueStats.abandoned, 1)
+			return s.queue.Fail(ctx, run.ID, s.now(), "match cancelled")
+		default:
+			return s.retryOrFail(ctx, run, execErr)
+		}
+	default:
+		return s.retryOrFail(ctx, run, execErr)
+	}
+}
+
+func (s *Service) completeRun(ctx context.Context, run *matches.MatchRun) error {
+	if err := s.queue.Complete(ctx, run.ID, s.now()); err != nil {
+		return err
+	}
+	s.queueStats.add(&s.queueStats.completed, 1)
+	return nil
+}
+
+// retryOrFail puts the match back into the lobby and queues another attempt.
+// When the run is out of attempts the match is cancelled instead.
+func (s *Service) retryOrFail(ctx context.Context, run *matches.MatchRun, cause error) error {
+	if run.Attempts < run.MaxAttempts {
+		if err := s.resetToLobby(ctx, run.MatchID); err != nil {
+			return err
+		}
+		s.queueStats.add(&s.queueStats.retried, 1)
+		backoff := RetryBackoff << uint(run.Attempts-1)
+		return s.queue.Retry(ctx, run.ID, s.now().Add(backoff), cause.Error())
+	}
+
+	s.queueStats.add(&s.queueStats.abandoned, 1)
+	if err := s.queue.Fail(ctx, run.ID, s.now(), cause.Error()); err != nil {
+		return err
+	}
+	return s.abandonMatch(ctx, run.MatchID)
+}
+
+// RecoverStuckMatches releases the runs of dead workers and requeues matches
+// that are running without a run, such as matches of a process that died
+// mid-match. A released run out of attempts cancels its match. It returns
+// the number of recovered matches.
+func (s *Service) RecoverStuckMatches(ctx context.Context) (int, error) {
+	if s.queue == nil {
+		return 0, nil
+	}
+
+	now := s.now()
+	released, err := s.queue.ReleaseExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	recovered := 0
+	for _, run := range released {
+		if run.Status == matches.RunStatusPending {
+			err = s.resetToLobby(ctx, run.MatchID)
+		} else {
+			err = s.abandonMatch(ctx, run.MatchID)
+		}
+		if err == nil {
+			recovered++
+		}
+	}
+
+	// A match running for longer than any lease has no live worker
+	orphaned, err := s.queue.ListOrphanedMatches(ctx, now.Add(-QueueLease))
+	if err != nil {
+		return recovered, err
+	}
+	for _, matchID := range orphaned {
+		if err := s.resetToLobby(ctx, matchID); err != nil {
+			continue
+		}
+		if err := s.enqueue(ctx, matchID); err == nil {
+			recovered++
+		}
+	}
+
+	s.queueStats.add(&s.queueStats.recovered, int64(recovered))
+	return recovered, nil
+}
+
+// QueueMetrics returns the current queue depth and the run statistics of
+// this process.
+func (s *Service) QueueMetrics(ctx context.Context) (*QueueMetrics, error) {
+	metrics := &QueueMetrics{}
+	if s.queue != nil {
+		stats, err := s.queue.Stats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		metrics.Pending = stats.Pending
+		metrics.Running = stats.Running
+		metrics.Failed = stats.Failed
+		if stats.OldestPending != nil {
+			metrics.OldestPendingAgeMs = s.now().Sub(*stats.OldestPending).Milliseconds()
+		}
+	}
+
+	c := &s.queueStats
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	metrics.Completed = c.completed
+	metrics.Retried = c.retried
+	metrics.Abandoned = c.abandoned
+	metrics.Recovered = c.recovered
+	metrics.MaxWaitMs = c.maxWait.Milliseconds()
+	metrics.MaxRunMs = c.maxRun.Milliseconds()
+	if c.runs > 0 {
+		metrics.AvgWaitMs = (c.totalWait / time.Duration(c.runs)).Milliseconds()
+		metrics.AvgRunMs = (c.totalRun / time.Duration(c.runs)).Milliseconds()
+	}
+	return metrics, nil
+}
+
+// resetToLobby puts a match left running by a failed attempt back into the
+// lobby so it can be executed again.
+func (s *Service) resetToLobby(ctx context.Context, matchID string) error {
+	match, err := s.matches.GetByID(ctx, matchID)
+	if err != nil {
+		return err
+	}
+	if match == nil || match.Status != matches.MatchStatusRunning {
+		return nil
+	}
+	match.Status = matches.MatchStatusLobby
+	match.Started = nil
+	return s.matches.Update(ctx, match)
+}
+
+// abandonMatch cancels a match that could not be executed.
+func (s *Service) abandonMatch(ctx context.Context, matchID string) error {
+	if err := s.resetToLobby(ctx, matchID); err != nil {
+		return err
+	}
+	err := s.CancelMatch(ctx, matchID)
+	if errors.Is(err, ErrMatchNotLobby) {
+		return nil
+	}
+	return err
+}
+
+func (s *Service) matchFinished(ctx context.Context, matchID string) {
+	for _, hook := range s.finishedHooks {
+		hook(ctx, matchID)
+	}
+}
+
+func botCount(options MatchOptions) int {
+	if options.BotCount == nil {
+		return 0
+	}
+	if *options.BotCount > MaxBotCount {
+		return MaxBotCount
+	}
+	return *options.BotCount
+}
@@ -0,0 +1,273 @@
+package matches
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/roster"
+)
+
+type memoryMatchRepo struct {
+	MatchRepository
+	matches map[string]*matches.Match
+}
+
+func (m *memoryMatchRepo) GetByID(ctx context.Context, id string) (*matches.Match, error) {
+	match, ok := m.matches[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *match
+	return &copied, nil
+}
+
+func (m *memoryMatchRepo) Update(ctx context.Context, match *matches.Match) error {
+	copied := *match
+	m.matches[match.ID] = &copied
+	return nil
+}
+
+type memoryFighterRepo struct {
+	FighterRepository
+	byMatch map[string][]roster.Fighter
+}
+
+func (m *memoryFighterRepo) ListByMatch(ctx context.Context, matchID string) ([]roster.Fighter, error) {
+	return m.byMatch[matchID], nil
+}
+
+type memoryRunQueue struct {
+	runs     map[string]*matches.MatchRun
+	orphaned []string
+}
+
+func newMemoryRunQueue() *memoryRunQueue {
+	return &memoryRunQueue{runs: make(map[string]*matches.MatchRun)}
+}
+
+func (q *memoryRunQueue) Enqueue(ctx context.Context, run *matches.MatchRun) (bool, error) {
+	for _, existing := range q.runs {
+		if existing.MatchID == run.MatchID && (existing.Status == matches.RunStatusPending || existing.Status == matches.RunStatusRunning) {
+			return false, nil
+		}
+	}
+	copied := *run
+	q.runs[run.ID] = &copied
+	return true, nil
+}
+
+func (q *memoryRunQueue) Claim(ctx context.Context, now time.Time, leaseUntil time.Time) (*matches.MatchRun, error) {
+	for _, run := range q.runs {
+		if run.Status == matches.RunStatusPending && !run.AvailableAt.After(now) {
+			run.Status = matches.RunStatusRunning
+			run.Attempts++
+			run.LeaseUntil = &leaseUntil
+			copied := *run
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (q *memoryRunQueue) Complete(ctx context.Context, id string, finishedAt time.Time) error {
+	q.runs[id].Status = matches.RunStatusDone
+	q.runs[id].FinishedAt = &finishedAt
+	return nil
+}
+
+func (q *memoryRunQueue) Retry(ctx context.Context, id string, availableAt time.Time, lastError string) error {
+	q.runs[id].Status = matches.RunStatusPending
+	q.runs[id].AvailableAt = availableAt
+	q.runs[id].LastError = &lastError
+	return nil
+}
+
+func (q *memoryRunQueue) Fail(ctx context.Context, id string, finishedAt time.Time, lastError string) error {
+	q.runs[id].Status = matches.RunStatusFailed
+	q.runs[id].FinishedAt = &finishedAt
+	q.runs[id].LastError = &lastError
+	return nil
+}
+
+func (q *memoryRunQueue) ReleaseExpired(ctx context.Context, now time.Time) ([]matches.MatchRun, error) {
+	var released []matches.MatchRun
+	for _, run := range q.runs {
+		if run.Status != matches.RunStatusRunning || run.LeaseUntil == nil || !run.LeaseUntil.Before(now) {
+			continue
+		}
+		run.Status = matches.RunStatusFailed
+		if run.Attempts < run.MaxAttempts {
+			run.Status = matches.RunStatusPending
+		}
+		run.LeaseUntil = nil
+		released = append(released, *run)
+	}
+	return released, nil
+}
+
+func (q *memoryRunQueue) ListOrphanedMatches(ctx context.Context, startedBefore time.Time) ([]string, error) {
+	return q.orphaned, nil
+}
+
+func (q *memoryRunQueue) Stats(ctx context.Context) (matches.MatchRunStats, error) {
+	var stats matches.MatchRunStats
+	for _, run := range q.runs {
+		switch run.Status {
+		case matches.RunStatusPending:
+			stats.Pending++
+		case matches.RunStatusRunning:
+			stats.Running++
+		case matches.RunStatusFailed:
+			stats.Failed++
+		}
+	}
+	return stats, nil
+}
+
+func newQueueService(queue *memoryRunQueue, now time.Time, stored ...matches.Match) (*Service, *memoryMatchRepo) {
+	repo := &memoryMatchRepo{matches: make(map[string]*matches.Match)}
+	for i := range stored {
+		repo.matches[stored[i].ID] = &stored[i]
+	}
+	fighters := &memoryFighterRepo{byMatch: map[string][]roster.Fighter{
+		"m": {{ID: "a"}, {ID: "b"}},
+	}}
+	svc := NewService(repo, nil, nil, nil, nil, nil, fighters, nil, nil, nil, nil, nil, nil, nil, nil, func() time.Time { return now })
+	svc.SetQueue(queue)
+	return svc, repo
+}
+
+func TestService_StartMatchQueuesOnce(t *testing.T) {
+	queue := newMemoryRunQueue()
+	svc, _ := newQueueService(queue, time.Now(),
+		matches.Match{ID: "m", Status: matches.MatchStatusLobby},
+		matches.Match{ID: "alone", Status: matches.MatchStatusLobby},
+	)
+
+	for i := 0; i < 2; i++ {
+		if err := svc.StartMatch(context.Background(), "m"); err != nil {
+			t.Fatalf("start %d: %v", i, err)
+		}
+	}
+	if len(queue.runs) != 1 {
+		t.Fatalf("expected one queued run, got %d", len(queue.runs))
+	}
+
+	if err := svc.StartMatch(context.Background(), "alone"); err != ErrNotEnoughFighters {
+		t.Fatalf("expected ErrNotEnoughFighters, got %v", err)
+	}
+}
+
+func TestService_StartMatchWithoutQueueStopsWithService(t *testing.T) {
+	svc, _ := newQueueService(nil, time.Now(), matches.Match{ID: "m", Status: matches.MatchStatusLobby})
+	svc.queue = nil
+
+	if err := svc.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.StartMatch(context.Background(), "m"); err != ErrServiceStopped {
+		t.Fatalf("expected ErrServiceStopped, got %v", err)
+	}
+}
+
+func TestService_SettleRunRetriesThenCancels(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	queue := newMemoryRunQueue()
+	svc, repo := newQueueService(queue, now, matches.Match{ID: "m", Status: matches.MatchStatusRunning, Started: &now})
+	run := &matches.MatchRun{ID: "r", MatchID: "m", Status: matches.RunStatusRunning, Attempts: 1, MaxAttempts: 2}
+	queue.runs["r"] = run
+
+	if err := svc.settleRun(context.Background(), run, errors.New("db down")); err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != matches.RunStatusPending || !run.AvailableAt.Equal(now.Add(RetryBackoff)) {
+		t.Fatalf("expected a retry after the backoff, got %s at %v", run.Status, run.AvailableAt)
+	}
+	if match := repo.matches["m"]; match.Status != matches.MatchStatusLobby || match.Started != nil {
+		t.Fatalf("expected the match back in the lobby, got %s", match.Status)
+	}
+
+	repo.matches["m"].Status = matches.MatchStatusRunning
+	run.Status, run.Attempts = matches.RunStatusRunning, 2
+	if err := svc.settleRun(context.Background(), run, errors.New("db down")); err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != matches.RunStatusFailed {
+		t.Fatalf("expected the last attempt to fail the run, got %s", run.Status)
+	}
+	if match := repo.matches["m"]; match.Status != matches.MatchStatusCancelled {
+		t.Fatalf("expected the match to be cancelled, got %s", match.Status)
+	}
+
+	metrics, _ := svc.QueueMetrics(context.Background())
+	if metrics.Retried != 1 || metrics.Abandoned != 1 || metrics.Failed != 1 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestService_SettleRunFinishesCompletedMatch(t *testing.T) {
+	now := time.Now()
+	queue := newMemoryRunQueue()
+	svc, _ := newQueueService(queue, now, matches.Match{ID: "m", Status: matches.MatchStatusCompleted})
+	run := &matches.MatchRun{ID: "r", MatchID: "m", Status: matches.RunStatusRunning, Attempts: 2, MaxAttempts: 3}
+	queue.runs["r"] = run
+
+	var finished []string
+	svc.OnMatchFinished(func(ctx context.Context, matchID string) {
+		finished = append(finished, matchID)
+	})
+
+	// A retry of a match an earlier attempt already completed
+	if err := svc.settleRun(context.Background(), run, ErrMatchNotLobby); err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != matches.RunStatusDone {
+		t.Fatalf("expected the run to be done, got %s", run.Status)
+	}
+	if len(finished) != 1 || finished[0] != "m" {
+		t.Fatalf("expected the finished hook once, got %v", finished)
+	}
+}
+
+func TestService_RecoverStuckMatches(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-time.Hour)
+	expired := now.Add(-time.Minute)
+	queue := newMemoryRunQueue()
+	svc, repo := newQueueService(queue, now,
+		matches.Match{ID: "leased", Status: matches.MatchStatusRunning, Started: &started},
+		matches.Match{ID: "exhausted", Status: matches.MatchStatusRunning, Started: &started},
+		matches.Match{ID: "m", Status: matches.MatchStatusRunning, Started: &started},
+	)
+	queue.runs["r1"] = &matches.MatchRun{ID: "r1", MatchID: "leased", Status: matches.RunStatusRunning, Attempts: 1, MaxAttempts: 3, LeaseUntil: &expired}
+	queue.runs["r2"] = &matches.MatchRun{ID: "r2", MatchID: "exhausted", Status: matches.RunStatusRunning, Attempts: 3, MaxAttempts: 3, LeaseUntil: &expired}
+	queue.orphaned = []string{"m"}
+
+	recovered, err := svc.RecoverStuckMatches(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered != 3 {
+		t.Fatalf("expected 3 recovered matches, got %d", recovered)
+	}
+
+	if queue.runs["r1"].Status != matches.RunStatusPending || repo.matches["leased"].Status != matches.MatchStatusLobby {
+		t.Fatal("expected the leased run to be requeued with its match in the lobby")
+	}
+	if repo.matches["exhausted"].Status != matches.MatchStatusCancelled {
+		t.Fatalf("expected the exhausted match to be cancelled, got %s", repo.matches["exhausted"].Status)
+	}
+	if repo.matches["m"].Status != matches.MatchStatusLobby {
+		t.Fatalf("expected the orphaned match back in the lobby, got %s", repo.matches["m"].Status)
+	}
+	requeued := false
+	for _, run := range queue.runs {
+		requeued = requeued || (run.MatchID == "m" && run.Status == matches.RunStatusPending)
+	}
+	if !requeued {
+		t.Fatal("expected the orphaned match to be queued")
+	}
+}
@@ -0,0 +1,90 @@
+package matches
+
+import (
+	"context"
+
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/roster"
+)
+
+// SetRatings makes settlement rate the registered fighters of ranked matches.
+func (s *Service) SetRatings(ratings RatingRepository) {
+	s.ratings = ratings
+}
+
+// SetLadder makes settlement move the fighters ranked matches rate on the
+// ranked ladder.
+func (s *Service) SetLadder(ladder Ladder) {
+	s.ladder = ladder
+}
+
+// rateMatch updates the ratings of the registered fighters of a ranked match.
+// Every winner beat every loser; fighters on the same side or of the same
+// user don't rate each other, and a match without winners is a draw between
+// everyone. Bots are not rated, nor are fighters left without an opponent.
+// Rated fighters move on the ladder with the same result.
+func (s *Service) rateMatch(ctx context.Context, fighters []roster.Fighter, winners map[string]bool) error {
+	if s.ratings == nil || len(fighters) < 2 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(fighters))
+	for _, f := range fighters {
+		ids = append(ids, f.ID)
+	}
+	stored, err := s.ratings.ListByFighters(ctx, ids)
+	if err != nil {
+		return err
+	}
+	before := make(map[string]rating.Rating, len(fighters))
+	for _, r := range stored {
+		before[r.FighterID] = r
+	}
+	for _, id := range ids {
+		if _, ok := before[id]; !ok {
+			before[id] = rating.New(id)
+		}
+	}
+
+	now := s.now()
+	for _, f := range fighters {
+		score := rating.Draw
+		var outcomes []rating.Outcome
+		for _, opponent := range fighters {
+			if opponent.ID == f.ID || opponent.UserID == f.UserID {
+				continue
+			}
+			switch {
+			case len(winners) == 0:
+				outcomes = append(outcomes, rating.Outcome{Opponent: before[opponent.ID], Score: rating.Draw})
+			case winners[f.ID] && !winners[opponent.ID]:
+				outcomes = append(outcomes, rating.Outcome{Opponent: before[opponent.ID], Score: rating.Win})
+			case !winners[f.ID] && winners[opponent.ID]:
+				outcomes = append(outcomes, rating.Outcome{Opponent: before[opponent.ID], Score: rating.Loss})
+			}
+		}
+		if len(outcomes) == 0 {
+			continue
+		}
+
+		updated := before[f.ID].Update(outcomes)
+		updated.Matches++
+		if winners[f.ID] {
+			updated.Wins++
+			score = rating.Win
+		} else if len(winners) > 0 {
+			updated.Losses++
+			score = rating.Loss
+		}
+		updated.Updated = now
+		if err := s.ratings.Save(ctx, &updated); err != nil {
+			return err
+		}
+		if s.ladder != nil {
+			if err := s.ladder.RecordMatch(ctx, f.UserID, updated, score); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
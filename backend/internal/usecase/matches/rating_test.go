@@ -0,0 +1,145 @@
+package matches
+
+import (
+	"context"
+	"testing"
+
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/roster"
+)
+
+type memoryRatingRepo struct {
+	ratings map[string]rating.Rating
+}
+
+func (m *memoryRatingRepo) ListByFighters(ctx context.Context, fighterIDs []string) ([]rating.Rating, error) {
+	var result []rating.Rating
+	for _, id := range fighterIDs {
+		if r, ok := m.ratings[id]; ok {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryRatingRepo) Save(ctx context.Context, r *rating.Rating) error {
+	m.ratings[r.FighterID] = *r
+	return nil
+}
+
+type recordingLadder struct {
+	scores map[string]float64
+}
+
+func (l *recordingLadder) RecordMatch(ctx context.Context, userID int64, updated rating.Rating, score float64) error {
+	l.scores[updated.FighterID] = score
+	return nil
+}
+
+func TestService_RateMatch(t *testing.T) {
+	ratings := &memoryRatingRepo{ratings: map[string]rating.Rating{
+		"a": {FighterID: "a", Rating: 1600, Deviation: 80, Volatility: 0.06, Matches: 10, Wins: 6, Losses: 4},
+	}}
+	svc := NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	svc.SetRatings(ratings)
+	ladder := &recordingLadder{scores: make(map[string]float64)}
+	svc.SetLadder(ladder)
+	fighters := []roster.Fighter{{ID: "a", UserID: 1}, {ID: "b", UserID: 2}, {ID: "c", UserID: 3}}
+
+	if err := svc.rateMatch(context.Background(), fighters, map[string]bool{"b": true}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b, c := ratings.ratings["a"], ratings.ratings["b"], ratings.ratings["c"]
+	if b.Rating <= rating.DefaultRating || b.Wins != 1 || b.Matches != 1 {
+		t.Fatalf("expected the winner to gain, got %+v", b)
+	}
+	if a.Rating >= 1600 || a.Losses != 5 || a.Matches != 11 {
+		t.Fatalf("expected the favourite to lose rating, got %+v", a)
+	}
+	if c.Rating >= rating.DefaultRating || c.Losses != 1 {
+		t.Fatalf("expected the other loser to lose rating, got %+v", c)
+	}
+	// Losing against an unrated winner costs a well-known fighter less than a newcomer
+	if 1600-a.Rating >= rating.DefaultRating-c.Rating {
+		t.Fatalf("expected the certain rating to move less, got %.1f and %.1f", 1600-a.Rating, rating.DefaultRating-c.Rating)
+	}
+	if ladder.scores["a"] != rating.Loss || ladder.scores["b"] != rating.Win || len(ladder.scores) != 3 {
+		t.Fatalf("expected every fighter on the ladder with their result, got %v", ladder.scores)
+	}
+}
+
+func TestService_RateMatchSkipsFightersOfOneUser(t *testing.T) {
+	ratings := &memoryRatingRepo{ratings: make(map[string]rating.Rating)}
+	svc := NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	svc.SetRatings(ratings)
+	fighters := []roster.Fighter{{ID: "a", UserID: 1}, {ID: "b", UserID: 1}}
+
+	if err := svc.rateMatch(context.Background(), fighters, map[string]bool{"a": true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ratings.ratings) != 0 {
+		t.Fatalf("expected fighters of one user not to rate each other, got %v", ratings.ratings)
+	}
+}
+
+func TestService_ExecuteMatchRatesRankedMatchesOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		options string
+		rated   bool
+	}{
+		{"private lobby", `{"isPrivate":true}`, false},
+		{"ranked", `{"ranked":true}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fighters := &recordingFighterRepo{}
+			svc, repo, _ := newSettlementService(fighters)
+			repo.matches["m"].Options = []byte(tt.options)
+			rated := engineFighters()
+			rated[0].UserID, rated[1].UserID = 1, 2
+			fighters.byMatch["m"] = rated
+			ratings := &memoryRatingRepo{ratings: make(map[string]rating.Rating)}
+			svc.SetRatings(ratings)
+
+			if err := svc.ExecuteMatch(context.Background(), "m"); err != nil {
+				t.Fatal(err)
+			}
+			if repo.matches["m"].Status != matches.MatchStatusCompleted {
+				t.Fatalf("expected the match completed, got %s", repo.matches["m"].Status)
+			}
+			if got := len(ratings.ratings) == 2; got != tt.rated {
+				t.Fatalf("expected rated %v, got ratings %v", tt.rated, ratings.ratings)
+			}
+		})
+	}
+}
+
+func TestService_ExecuteMatchKeepsUnrankedMatchesOffTheLadder(t *testing.T) {
+	fighters := &recordingFighterRepo{}
+	svc, repo, _ := newSettlementService(fighters)
+	repo.matches["m"].Options = []byte(`{"isPrivate":true}`)
+	recorded := engineFighters()
+	recorded[0].UserID, recorded[1].UserID = 1, 2
+	fighters.byMatch["m"] = recorded
+	svc.SetRatings(&memoryRatingRepo{ratings: make(map[string]rating.Rating)})
+	ladder := &recordingLadder{scores: make(map[string]float64)}
+	svc.SetLadder(ladder)
+
+	if err := svc.ExecuteMatch(context.Background(), "m"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ladder.scores) != 0 {
+		t.Fatalf("expected an unranked match to leave the ladder alone, got %v", ladder.scores)
+	}
+
+	// Fighters of one user in a ranked match don't move on the ladder either
+	if err := svc.rateMatch(context.Background(), []roster.Fighter{{ID: "a", UserID: 1}, {ID: "b", UserID: 1}}, map[string]bool{"a": true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ladder.scores) != 0 {
+		t.Fatalf("expected fighters of one user off the ladder, got %v", ladder.scores)
+	}
+}
@@ -0,0 +1,118 @@
+package matches
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/roster"
+)
+
+// BattleInput is the complete simulator input of a match. It is stored with
+// the match result so the battle can be re-run later from its seed, even after
+// the participating fighters have changed. Engine names the engine that
+// resolved the battle; only local battles can be replayed.
+type BattleInput struct {
+	Fighters []roster.Fighter `json:"fighters"`
+	Options  BattleOptions    `json:"options"`
+	Engine   string           `json:"engine,omitempty"`
+}
+
+// ReplayVerification reports whether re-running a stored match reproduced the
+// stored RoundTicks exactly.
+type ReplayVerification struct {
+	MatchID            string `json:"matchId"`
+	Seed               int64  `json:"seed"`
+	Matches            bool   `json:"matches"`
+	StoredRounds       int    `json:"storedRounds"`
+	ReplayedRounds     int    `json:"replayedRounds"`
+	FirstMismatchRound *int   `json:"firstMismatchRound"`
+}
+
+// VerifyReplay re-runs a stored match from its persisted seed and input and
+// compares the regenerated RoundTicks with the stored ones round by round.
+func (s *Service) VerifyReplay(ctx context.Context, matchID string) (*ReplayVerification, error) {
+	if withheld, err := s.withheld(ctx, matchID); err != nil || withheld {
+		return nil, liveErr(err)
+	}
+	result, err := s.results.GetByMatch(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ErrInvalidMatch
+	}
+	if len(result.BattleInput) == 0 {
+		return nil, ErrReplayUnavailable
+	}
+
+	var input BattleInput
+	if err := json.Unmarshal(result.BattleInput, &input); err != nil {
+		return nil, err
+	}
+	if input.Engine != "" && input.Engine != EngineLocal {
+		return nil, ErrReplayUnavailable
+	}
+
+	replayed, err := NewBattleSimulator().Run(matchID, input.Fighters, input.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []json.RawMessage
+	if err := json.Unmarshal(result.RoundTicks, &stored); err != nil {
+		return nil, err
+	}
+
+	verification := &ReplayVerification{
+		MatchID:        matchID,
+		Seed:           input.Options.Seed,
+		StoredRounds:   len(stored),
+		ReplayedRounds: len(replayed.RoundTicks),
+	}
+
+	for i := 0; i < len(stored) || i < len(replayed.RoundTicks); i++ {
+		if i >= len(replayed.RoundTicks) {
+			var rt combat.RoundTick
+			_ = json.Unmarshal(stored[i], &rt)
+			verification.FirstMismatchRound = &rt.Round
+			break
+		}
+		round := replayed.RoundTicks[i].Round
+		if i >= len(stored) {
+			verification.FirstMismatchRound = &round
+			break
+		}
+
+		data, err := json.Marshal(replayed.RoundTicks[i])
+		if err != nil {
+			return nil, err
+		}
+		a, err := canonicalJSON(stored[i])
+		if err != nil {
+			return nil, err
+		}
+		b, err := canonicalJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(a, b) {
+			verification.FirstMismatchRound = &round
+			break
+		}
+	}
+	verification.Matches = verification.FirstMismatchRound == nil
+
+	return verification, nil
+}
+
+// canonicalJSON normalizes key order and whitespace. Postgres jsonb does not
+// preserve the original bytes, so both sides are compared in canonical form.
+func canonicalJSON(data []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
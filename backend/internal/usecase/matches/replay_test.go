@@ -0,0 +1,91 @@
+package matches
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/roster"
+)
+
+type memoryResultRepo struct {
+	results map[string]*matches.MatchResult
+}
+
+func (m *memoryResultRepo) GetByMatch(ctx context.Context, matchID string) (*matches.MatchResult, error) {
+	return m.results[matchID], nil
+}
+
+func (m *memoryResultRepo) Upsert(ctx context.Context, result *matches.MatchResult) error {
+	m.results[result.MatchID] = result
+	return nil
+}
+
+func storeSimulatedResult(t *testing.T, repo *memoryResultRepo, matchID string, seed int64) {
+	t.Helper()
+	fighters := []roster.Fighter{
+		{ID: "a", Name: "Warrior", Power: 15, Armor: 10, Vitality: 12, Speed: 5},
+		{ID: "b", Name: "Ranger", Power: 8, Precision: 18, Agility: 15, Speed: 12, Vitality: 8},
+	}
+	options := BattleOptions{MaxRounds: 40, MapSize: 20.0, Seed: seed}
+	result, err := NewBattleSimulator().Run(matchID, fighters, options)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+
+	// Re-indent to mimic jsonb not preserving the original bytes.
+	ticks, _ := json.MarshalIndent(result.RoundTicks, "", "  ")
+	input, _ := json.Marshal(BattleInput{Fighters: fighters, Options: options})
+	repo.results[matchID] = &matches.MatchResult{
+		MatchID:     matchID,
+		RoundTicks:  ticks,
+		Seed:        seed,
+		BattleInput: input,
+	}
+}
+
+func TestService_VerifyReplay(t *testing.T) {
+	repo := &memoryResultRepo{results: make(map[string]*matches.MatchResult)}
+	svc := &Service{matches: &memoryMatchRepo{}, results: repo}
+	storeSimulatedResult(t, repo, "match-1", 7)
+
+	verification, err := svc.VerifyReplay(context.Background(), "match-1")
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if !verification.Matches {
+		t.Fatalf("expected replay to match, first mismatch at round %v", *verification.FirstMismatchRound)
+	}
+	if verification.Seed != 7 {
+		t.Errorf("expected seed 7, got %d", verification.Seed)
+	}
+
+	// Tamper with the stored battle: the replay must detect it.
+	var input BattleInput
+	_ = json.Unmarshal(repo.results["match-1"].BattleInput, &input)
+	input.Options.Seed = 8
+	repo.results["match-1"].BattleInput, _ = json.Marshal(input)
+
+	verification, err = svc.VerifyReplay(context.Background(), "match-1")
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if verification.Matches || verification.FirstMismatchRound == nil {
+		t.Error("expected replay with a different seed to mismatch")
+	}
+}
+
+func TestService_VerifyReplayWithoutInput(t *testing.T) {
+	repo := &memoryResultRepo{results: map[string]*matches.MatchResult{
+		"legacy": {MatchID: "legacy", RoundTicks: []byte("[]")},
+	}}
+	svc := &Service{matches: &memoryMatchRepo{}, results: repo}
+
+	if _, err := svc.VerifyReplay(context.Background(), "legacy"); err != ErrReplayUnavailable {
+		t.Errorf("expected ErrReplayUnavailable, got %v", err)
+	}
+	if _, err := svc.VerifyReplay(context.Background(), "missing"); err != ErrInvalidMatch {
+		t.Errorf("expected ErrInvalidMatch, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package matches
+
+import (
+	"encoding/json"
+	"sort"
+
+	"empoweredpixels/internal/domain/combat"
+)
+
+// AssistWindow is the number of rounds a hit keeps counting towards an
+// assist on the target's death.
+const AssistWindow = 5
+
+// scoreboard builds the fighter scores of a battle from its ticks.
+type scoreboard struct {
+	scores map[string]*combat.FighterScore
+	// lastHit maps every fighter to the last round each enemy damaged it
+	lastHit map[string]map[string]int
+}
+
+func newScoreboard(entities []*combat.Entity) *scoreboard {
+	b := &scoreboard{
+		scores:  make(map[string]*combat.FighterScore, len(entities)),
+		lastHit: make(map[string]map[string]int, len(entities)),
+	}
+	for _, e := range entities {
+		b.scores[e.ID] = &combat.FighterScore{FighterID: e.ID, IsBot: e.IsBot}
+	}
+	return b
+}
+
+// record credits damage, healing, kills and assists in ticks. Kills go to the
+// killer named in the event, which is not necessarily the acting entity
+// (Reflect, damage over time).
+func (b *scoreboard) record(round int, ticks []combat.Tick) {
+	for _, t := range ticks {
+		switch t.Type {
+		case "attack":
+			var attack combat.EventAttack
+			if json.Unmarshal(t.Payload, &attack) == nil {
+				b.damage(round, attack.AttackerID, attack.TargetID, attack.Damage)
+			}
+		case "conditionTick":
+			var condition combat.EventCondition
+			if json.Unmarshal(t.Payload, &condition) == nil {
+				b.damage(round, condition.SourceID, condition.FighterID, condition.Damage)
+			}
+		case "reflect":
+			var reflect combat.EventDamage
+			if json.Unmarshal(t.Payload, &reflect) == nil {
+				b.damage(round, reflect.SourceID, reflect.FighterID, reflect.Damage)
+			}
+		case "heal":
+			var heal combat.EventHeal
+			if json.Unmarshal(t.Payload, &heal) == nil {
+				if score, ok := b.scores[heal.HealerID]; ok {
+					score.Healed += heal.Amount
+				}
+			}
+		case "died":
+			var died combat.EventDied
+			if json.Unmarshal(t.Payload, &died) == nil {
+				b.died(round, died)
+			}
+		}
+	}
+}
+
+func (b *scoreboard) damage(round int, sourceID, targetID string, amount int) {
+	if amount <= 0 {
+		return
+	}
+	if score, ok := b.scores[sourceID]; ok {
+		score.DamageDealt += amount
+	}
+	if score, ok := b.scores[targetID]; ok {
+		score.DamageTaken += amount
+	}
+	if sourceID == "" || sourceID == targetID {
+		return
+	}
+	if b.lastHit[targetID] == nil {
+		b.lastHit[targetID] = make(map[string]int)
+	}
+	b.lastHit[targetID][sourceID] = round
+}
+
+// died credits the kill, and an assist to every other fighter that damaged
+// the victim within the AssistWindow.
+func (b *scoreboard) died(round int, died combat.EventDied) {
+	if score, ok := b.scores[died.KillerID]; ok {
+		score.Kills++
+	}
+	if score, ok := b.scores[died.FighterID]; ok {
+		score.Deaths++
+	}
+	for attackerID, lastRound := range b.lastHit[died.FighterID] {
+		if attackerID == died.KillerID || round-lastRound > AssistWindow {
+			continue
+		}
+		if score, ok := b.scores[attackerID]; ok {
+			score.Assists++
+		}
+	}
+	delete(b.lastHit, died.FighterID)
+}
+
+// finalize returns the scores ordered by fighter ID.
+func (b *scoreboard) finalize() []combat.FighterScore {
+	scores := make([]combat.FighterScore, 0, len(b.scores))
+	for _, score := range b.scores {
+		scores = append(scores, *score)
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].FighterID < scores[j].FighterID })
+	return scores
+}
@@ -0,0 +1,64 @@
+package matches
+
+import (
+	"encoding/json"
+	"testing"
+
+	"empoweredpixels/internal/domain/combat"
+)
+
+func tick(t *testing.T, tickType string, event any) combat.Tick {
+	t.Helper()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("encode %s: %v", tickType, err)
+	}
+	return combat.Tick{Type: tickType, Payload: payload}
+}
+
+func TestScoreboard_CreditsAssistsWithinWindow(t *testing.T) {
+	board := newScoreboard([]*combat.Entity{{ID: "killer"}, {ID: "helper"}, {ID: "early"}, {ID: "victim"}})
+
+	board.record(1, []combat.Tick{tick(t, "attack", combat.EventAttack{AttackerID: "early", TargetID: "victim", Damage: 10})})
+	board.record(4, []combat.Tick{tick(t, "conditionTick", combat.EventCondition{SourceID: "helper", FighterID: "victim", Damage: 5})})
+	board.record(1+AssistWindow+1, []combat.Tick{
+		tick(t, "attack", combat.EventAttack{AttackerID: "killer", TargetID: "victim", Damage: 30}),
+		tick(t, "died", combat.EventDied{FighterID: "victim", KillerID: "killer"}),
+	})
+
+	scores := make(map[string]combat.FighterScore)
+	for _, score := range board.finalize() {
+		scores[score.FighterID] = score
+	}
+	if scores["killer"].Kills != 1 || scores["killer"].Assists != 0 {
+		t.Errorf("expected the killer to get the kill only, got %+v", scores["killer"])
+	}
+	if scores["helper"].Assists != 1 {
+		t.Errorf("expected an assist for damage within the window, got %+v", scores["helper"])
+	}
+	if scores["early"].Assists != 0 {
+		t.Errorf("expected no assist for damage outside the window, got %+v", scores["early"])
+	}
+	if scores["victim"].Deaths != 1 || scores["victim"].DamageTaken != 45 {
+		t.Errorf("expected one death and 45 damage taken, got %+v", scores["victim"])
+	}
+}
+
+func TestScoreboard_TracksDamageAndHealing(t *testing.T) {
+	board := newScoreboard([]*combat.Entity{{ID: "a"}, {ID: "b"}})
+
+	board.record(1, []combat.Tick{
+		tick(t, "attack", combat.EventAttack{AttackerID: "a", TargetID: "b", Damage: 12}),
+		tick(t, "attack", combat.EventAttack{AttackerID: "a", TargetID: "b", IsDodged: true}),
+		tick(t, "reflect", combat.EventDamage{SourceID: "b", FighterID: "a", Damage: 3}),
+		tick(t, "heal", combat.EventHeal{HealerID: "b", TargetID: "b", Amount: 7}),
+	})
+
+	scores := board.finalize()
+	if a := scores[0]; a.DamageDealt != 12 || a.DamageTaken != 3 {
+		t.Errorf("unexpected totals for a: %+v", a)
+	}
+	if b := scores[1]; b.DamageDealt != 3 || b.DamageTaken != 12 || b.Healed != 7 {
+		t.Errorf("unexpected totals for b: %+v", b)
+	}
+}
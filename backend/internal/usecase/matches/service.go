@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math/rand"
+	"sort"
+	"sync"
 	"time"
 
+	"empoweredpixels/internal/domain/attunement"
 	"empoweredpixels/internal/domain/combat"
-	"empoweredpixels/internal/domain/inventory"
 	"empoweredpixels/internal/domain/matches"
-	"empoweredpixels/internal/infra/engine"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/skills"
 	inventoryusecase "empoweredpixels/internal/usecase/inventory"
 	"empoweredpixels/internal/usecase/rewards"
 	rosterusecase "empoweredpixels/internal/usecase/roster"
@@ -26,6 +30,12 @@ var (
 	ErrMatchLimit        = errors.New("match fighter limit exceeded")
 	ErrMatchNotLobby     = errors.New("match is not in lobby state")
 	ErrNotEnoughFighters = errors.New("not enough fighters")
+	ErrReplayUnavailable = errors.New("match has no replay data")
+	ErrCombatLogDisabled = errors.New("combat logs are not available")
+	ErrUnknownEngine     = errors.New("unknown combat engine")
+	ErrMatchSettled      = errors.New("match is already settled")
+	ErrMatchLive         = errors.New("match is still being streamed")
+	ErrServiceStopped    = errors.New("match service is shutting down")
 )
 
 type Hub interface {
@@ -38,13 +48,29 @@ type Service struct {
 	registrations RegistrationRepository
 	results       ResultRepository
 	scores        ScoreRepository
+	combatLogs    CombatLogRepository
 	fighters      FighterRepository
 	inventory     inventoryusecase.Service
 	rewards       *rewards.Service
 	roster        *rosterusecase.Service
-	engine        *engine.Client
+	weapons       WeaponProvider
+	skills        SkillRepository
+	attunements   AttunementService
+	engines       map[string]CombatEngine
+	defaultEngine string
 	hub           Hub
+	live          *liveStreams
+	background    *background
+	queue         RunQueue
+	queueStats    queueCounters
+	finishedHooks []MatchFinishedHook
+	uow           UnitOfWork
+	settlements   SettlementRepository
+	notifier      Notifier
+	ratings       RatingRepository
+	ladder        Ladder
 	now           func() time.Time
+	after         func(time.Duration) <-chan time.Time
 }
 
 func NewService(
@@ -53,11 +79,15 @@ func NewService(
 	registrations RegistrationRepository,
 	results ResultRepository,
 	scores ScoreRepository,
+	combatLogs CombatLogRepository,
 	fighters FighterRepository,
 	inventory inventoryusecase.Service,
 	rewards *rewards.Service,
 	roster *rosterusecase.Service,
-	engineClient *engine.Client,
+	weapons WeaponProvider,
+	skills SkillRepository,
+	attunements AttunementService,
+	remoteEngine CombatEngine,
 	hub Hub,
 	now func() time.Time,
 ) *Service {
@@ -65,20 +95,96 @@ func NewService(
 		now = time.Now
 	}
 
+	engines := map[string]CombatEngine{EngineLocal: NewLocalEngine()}
+	if remoteEngine != nil {
+		engines[EngineRemote] = remoteEngine
+	}
+
 	return &Service{
 		matches:       matches,
 		teams:         teams,
 		registrations: registrations,
 		results:       results,
 		scores:        scores,
+		combatLogs:    combatLogs,
 		fighters:      fighters,
 		inventory:     inventory,
 		rewards:       rewards,
 		roster:        roster,
-		engine:        engineClient,
+		weapons:       weapons,
+		skills:        skills,
+		attunements:   attunements,
+		engines:       engines,
+		defaultEngine: EngineLocal,
 		hub:           hub,
+		live:          newLiveStreams(),
+		background:    newBackground(),
 		now:           now,
+		after:         time.After,
+	}
+}
+
+// background runs the work a service keeps doing after the call that started
+// it returned, until Shutdown.
+type background struct {
+	mu     sync.Mutex
+	closed bool
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newBackground() *background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &background{ctx: ctx, cancel: cancel}
+}
+
+// run calls fn in a goroutine with a context cancelled by shutdown. Once shut
+// down it reports false without calling fn.
+func (b *background) run(fn func(ctx context.Context)) bool {
+	// Checking under the lock keeps shutdown from missing a goroutine
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		fn(b.ctx)
+	}()
+	return true
+}
+
+func (b *background) shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		b.cancel()
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Shutdown lets the background work of the service, such as live streams,
+// run until ctx is done and cancels what is left then. It returns once all
+// of it returned, with the error of ctx when some was cut short.
+func (s *Service) Shutdown(ctx context.Context) error {
+	if s.background == nil {
+		return nil
 	}
+	return s.background.shutdown(ctx)
 }
 
 type MatchOptions struct {
@@ -88,6 +194,17 @@ type MatchOptions struct {
 	BotCount           *int `json:"botCount"`
 	BotPowerlevel      *int `json:"botPowerlevel"`
 	AutoStart          bool `json:"autoStart"`
+	// Live matches stream one round per RoundIntervalMs to subscribers,
+	// starting SpectatorDelayMs after the match started.
+	Live             bool `json:"live"`
+	RoundIntervalMs  *int `json:"roundIntervalMs"`
+	SpectatorDelayMs *int `json:"spectatorDelayMs"`
+	// Engine names the combat engine resolving the match; empty uses the
+	// configured default.
+	Engine string `json:"engine,omitempty"`
+	// Ranked matches rate their fighters. Only the matchmaking queue creates
+	// them; lobbies players make are never ranked.
+	Ranked bool `json:"ranked,omitempty"`
 }
 
 func (s *Service) DefaultOptions() MatchOptions {
@@ -103,6 +220,11 @@ func (s *Service) BattleFieldSizes() []string {
 }
 
 func (s *Service) CreateMatch(ctx context.Context, userID int64, options MatchOptions) (*matches.Match, error) {
+	if !s.validEngine(options.Engine) {
+		return nil, ErrUnknownEngine
+	}
+	options.Ranked = false
+
 	data, err := json.Marshal(options)
 	if err != nil {
 		return nil, err
@@ -129,6 +251,67 @@ func (s *Service) CreateMatch(ctx context.Context, userID int64, options MatchOp
 	return match, nil
 }
 
+// CreateMatchFor creates a lobby without a creator and registers the
+// fighters, as the matchmaking queue does for the groups it forms.
+func (s *Service) CreateMatchFor(ctx context.Context, options MatchOptions, fighterIDs []string) (*matches.Match, error) {
+	teams := make([][]string, len(fighterIDs))
+	for i, id := range fighterIDs {
+		teams[i] = []string{id}
+	}
+	return s.CreateTeamMatchFor(ctx, options, teams)
+}
+
+// CreateTeamMatchFor creates a lobby without a creator and registers the
+// fighters of every team on a team of the match. A team of one fighter
+// fights on its own.
+func (s *Service) CreateTeamMatchFor(ctx context.Context, options MatchOptions, teams [][]string) (*matches.Match, error) {
+	if !s.validEngine(options.Engine) {
+		return nil, ErrUnknownEngine
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	match := &matches.Match{
+		ID:      uuid.NewString(),
+		Created: s.now(),
+		Status:  matches.MatchStatusLobby,
+		Options: data,
+	}
+	if err := s.matches.Create(ctx, match); err != nil {
+		return nil, err
+	}
+
+	for _, fighterIDs := range teams {
+		var teamID *string
+		if len(fighterIDs) > 1 {
+			team := &matches.MatchTeam{ID: uuid.NewString(), MatchID: match.ID}
+			if err := s.teams.Create(ctx, team); err != nil {
+				return nil, err
+			}
+			teamID = &team.ID
+		}
+		for _, fighterID := range fighterIDs {
+			registration := &matches.MatchRegistration{
+				MatchID:   match.ID,
+				FighterID: fighterID,
+				Date:      match.Created,
+				TeamID:    teamID,
+			}
+			if err := s.registrations.Upsert(ctx, registration); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if s.hub != nil {
+		s.hub.Broadcast(match.ID, map[string]any{"type": "lobbyUpdate", "matchId": match.ID})
+	}
+
+	return match, nil
+}
+
 func (s *Service) GetCurrentMatch(ctx context.Context, userID int64) (*matches.Match, error) {
 	return s.matches.GetCurrentMatch(ctx, userID)
 }
@@ -226,7 +409,8 @@ func (s *Service) Join(ctx context.Context, userID int64, matchID string, fighte
 	}
 
 	if options.AutoStart {
-		s.tryAutoStart(matchID, options)
+		// Not enough fighters yet is not an error of the join
+		_ = s.StartMatch(ctx, matchID)
 	}
 
 	return nil
@@ -334,7 +518,16 @@ func (s *Service) LeaveTeam(ctx context.Context, userID int64, matchID string, f
 	return s.registrations.Upsert(ctx, registration)
 }
 
+// RoundTicks returns the rounds of a finished match. Of a live match it
+// returns the rounds streamed so far when this process streams it, and
+// ErrMatchLive otherwise.
 func (s *Service) RoundTicks(ctx context.Context, matchID string) ([]byte, error) {
+	if rounds, ok := s.streamed(matchID); ok {
+		return json.Marshal(rounds)
+	}
+	if withheld, err := s.withheld(ctx, matchID); err != nil || withheld {
+		return nil, liveErr(err)
+	}
 	result, err := s.results.GetByMatch(ctx, matchID)
 	if err != nil {
 		return nil, err
@@ -346,6 +539,9 @@ func (s *Service) RoundTicks(ctx context.Context, matchID string) ([]byte, error
 }
 
 func (s *Service) FighterScores(ctx context.Context, matchID string) ([]matches.MatchScoreFighter, error) {
+	if withheld, err := s.withheld(ctx, matchID); err != nil || withheld {
+		return nil, liveErr(err)
+	}
 	return s.scores.ListByMatch(ctx, matchID)
 }
 
@@ -369,169 +565,249 @@ func (s *Service) ExecuteMatch(ctx context.Context, matchID string) error {
 	var options MatchOptions
 	_ = json.Unmarshal(match.Options, &options)
 
-	totalFighters := len(fighters)
-	if options.BotCount != nil {
-		totalFighters += *options.BotCount
-	}
-
-	if totalFighters < 2 {
+	botCount := botCount(options)
+	if len(fighters)+botCount < 2 {
 		return ErrNotEnoughFighters
 	}
 
+	// Of concurrent executions only the first one gets past the lobby
 	now := s.now()
-	match.Status = matches.MatchStatusRunning
-	match.Started = &now
-	if err := s.matches.Update(ctx, match); err != nil {
+	started, err := s.matches.MarkRunning(ctx, matchID, now)
+	if err != nil {
 		return err
 	}
+	if !started {
+		return ErrMatchNotLobby
+	}
+	match.Status = matches.MatchStatusRunning
+	match.Started = &now
 
 	if s.hub != nil {
-		s.hub.Broadcast(matchID, map[string]any{"type": "matchStatus", "status": matches.MatchStatusRunning, "matchId": matchID})
+		s.hub.Broadcast(matchID, map[string]any{"type": "matchStatus", "status": matches.MatchStatusRunning, "matchId": matchID, "live": options.Live})
+	}
+
+	// Load weapons and equipment for all participants
+	gear := make(map[string]combat.Gear)
+	for _, f := range fighters {
+		var fighterGear combat.Gear
+		if s.weapons != nil {
+			userWeapon, weapon, err := s.weapons.GetFighterWeapon(ctx, f.ID)
+			if err == nil && weapon != nil {
+				fighterGear.Weapon = weapon
+				fighterGear.Enhancement = userWeapon.Enhancement
+			}
+		}
+		if s.inventory != nil {
+			items, err := s.inventory.ListByFighter(ctx, f.UserID, f.ID)
+			if err == nil {
+				fighterGear.Equipment = items
+			}
+		}
+		gear[f.ID] = fighterGear
 	}
 
-	// Load equipment for all participants
-	fighterEquipment := make(map[string][]inventory.Equipment)
-	if s.inventory != nil {
+	// Load skill trees and carried-over ultimate charge
+	fighterSkills := make(map[string]*skills.FighterSkills)
+	if s.skills != nil {
 		for _, f := range fighters {
-			items, err := s.inventory.ListByFighter(ctx, f.UserID, f.ID)
-			if err == nil {
-				fighterEquipment[f.ID] = items
+			fs, err := s.skills.GetFighterSkills(ctx, f.ID)
+			if err == nil && fs != nil {
+				fighterSkills[f.ID] = fs
 			}
 		}
 	}
 
-		simulator := NewBattleSimulator()
-		// Convert MatchOptions to BattleOptions
-		battleOptions := BattleOptions{
-			MaxRounds: 100, // Default value
-			MapSize:   30.0, // Default value
+	// Attunement level bonuses belong to the player and apply to all of
+	// their fighters
+	elementalBonuses := make(map[string]combat.ElementalBonus)
+	if s.attunements != nil {
+		byUser := make(map[int64]*combat.ElementalBonus)
+		for _, f := range fighters {
+			bonus, loaded := byUser[f.UserID]
+			if !loaded {
+				if aggregated, err := s.attunements.GetAllElementsBonus(ctx, int(f.UserID)); err == nil && aggregated != nil {
+					bonus = &combat.ElementalBonus{
+						Power:     aggregated.TotalPower,
+						Defense:   aggregated.TotalDefense,
+						Speed:     aggregated.TotalSpeed,
+						Precision: aggregated.TotalPrecision,
+					}
+				}
+				byUser[f.UserID] = bonus
+			}
+			if bonus != nil {
+				elementalBonuses[f.ID] = *bonus
+			}
 		}
-		result, err := simulator.Run(matchID, fighters, battleOptions)
+	}
+
+	// Stable input order so the seed alone determines the outcome
+	sort.Slice(fighters, func(i, j int) bool { return fighters[i].ID < fighters[j].ID })
+
+	registrations, err := s.registrations.ListByMatch(ctx, matchID)
 	if err != nil {
-		match.Status = matches.MatchStatusLobby
-		match.Started = nil
-		_ = s.matches.Update(ctx, match)
-		return err
+		return s.abortExecution(ctx, matchID, err)
+	}
+	teams := make(map[string]string)
+	for _, reg := range registrations {
+		if reg.TeamID != nil {
+			teams[reg.FighterID] = *reg.TeamID
+		}
 	}
 
-	roundTicksJson, _ := json.Marshal(result.RoundTicks)
-	matchResult := &matches.MatchResult{
-		ID:         uuid.NewString(),
-		MatchID:    matchID,
-		RoundTicks: roundTicksJson,
+	// Convert MatchOptions to BattleOptions
+	battleOptions := BattleOptions{
+		MaxRounds:   100,  // Default value
+		MapSize:     30.0, // Default value
+		Seed:        s.now().UnixNano(),
+		Teams:       teams,
+		Gear:        gear,
+		Skills:      fighterSkills,
+		Attunements: elementalBonuses,
 	}
-	if err := s.results.Upsert(ctx, matchResult); err != nil {
-		return err
+
+	// Bots are derived from the match seed and stored as part of the input
+	participants := fighters
+	if botCount > 0 {
+		botPowerlevel := DefaultBotPowerlevel
+		if options.BotPowerlevel != nil {
+			botPowerlevel = *options.BotPowerlevel
+		}
+		botRng := rand.New(rand.NewSource(battleOptions.Seed))
+		participants = append(append([]roster.Fighter{}, fighters...), GenerateBots(botCount, botPowerlevel, botRng)...)
 	}
 
-	scoresMapping := make(map[string]combat.FighterScore)
-	for _, score := range result.Scores {
-		scoresMapping[score.FighterID] = score
+	result, engineName, err := s.runBattle(ctx, matchID, participants, battleOptions, options)
+	if err != nil {
+		return s.abortExecution(ctx, matchID, err)
 	}
 
-	scores := make([]matches.MatchScoreFighter, 0, len(result.Scores))
-	for _, score := range result.Scores {
-		scores = append(scores, matches.MatchScoreFighter{
-			MatchID:      matchID,
-			FighterID:    score.FighterID,
-			TotalKills:   score.Kills,
-			TotalDeaths:  score.Deaths,
-			TotalAssists: score.Assists,
-		})
+	battleInput, err := json.Marshal(BattleInput{Fighters: participants, Options: battleOptions, Engine: engineName})
+	if err != nil {
+		return s.abortExecution(ctx, matchID, err)
 	}
-	if len(scores) > 0 {
-		if err := s.scores.Upsert(ctx, scores); err != nil {
-			return err
-		}
+
+	// A live match keeps its outcome to itself until the stream ends. Other
+	// processes withhold it until the stream would have ended.
+	live := options.Live && s.hub != nil && s.live != nil
+	if live {
+		s.live.start(matchID)
+		revealAt := s.now().Add(liveDuration(options, len(result.RoundTicks)))
+		match.RevealedAt = &revealAt
 	}
 
-	completedAt := s.now()
-	match.Status = matches.MatchStatusCompleted
-	match.CompletedAt = &completedAt
-	if err := s.matches.Update(ctx, match); err != nil {
+	// Everything the match changes is written at once, and only by the
+	// execution that settles the match first
+	var settled *settlement
+	err = s.inTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		settled, err = s.settle(ctx, match, fighters, result, battleInput, options)
 		return err
+	})
+	if err != nil {
+		if live {
+			s.live.stop(matchID)
+		}
+		return s.abortExecution(ctx, matchID, err)
 	}
 
-	// Award rewards and experience to all participants
-	if s.rewards != nil {
-		rewardedUsers := make(map[int64]bool)
-
-		var winnerID string
-		maxKills := -1
+	// Ultimate charge carries over into the fighter's next match. The skills
+	// store is not part of the transaction; setting the charge twice is
+	// harmless.
+	if s.skills != nil {
 		for _, score := range result.Scores {
-			if score.Deaths == 0 && score.Kills > maxKills {
-				maxKills = score.Kills
-				winnerID = score.FighterID
+			if !score.IsBot {
+				_ = s.skills.UpdateUltimateCharge(ctx, score.FighterID, score.UltimateCharge)
 			}
 		}
+	}
 
-		// Calculate Bot difficulty bonus
-		botBonusExp := 0
-		if options.BotCount != nil && options.BotPowerlevel != nil {
-			// +1 EXP for every 5 powerlevels of bots, scaled by bot count
-			botBonusExp = (*options.BotPowerlevel / 5) * (*options.BotCount / 2)
+	ended := map[string]any{
+		"type":         "matchEnded",
+		"matchId":      matchID,
+		"status":       matches.MatchStatusCompleted,
+		"winnerIds":    result.WinnerIDs,
+		"winnerTeamId": result.WinnerTeamID,
+	}
+	finish := func(ctx context.Context) {
+		if live {
+			_ = s.matches.Reveal(ctx, matchID, s.now())
 		}
-
-		for _, f := range fighters {
-			// Award Loot (per User)
-			if !rewardedUsers[f.UserID] {
-				pool := "match_participation"
-				if f.ID == winnerID {
-					pool = "match_win"
-				}
-
-				if _, err := s.rewards.IssueReward(ctx, f.UserID, pool); err != nil {
-					// log error but don't fail the match execution
-				}
-				rewardedUsers[f.UserID] = true
-			}
-
-			// Award Experience (per Fighter)
-			if s.roster != nil {
-				score, ok := scoresMapping[f.ID]
-				expAmount := 10 + botBonusExp // Base EXP + difficulty bonus
-				if ok {
-					expAmount += score.Kills * 5
-					if f.ID == winnerID {
-						expAmount += 20 // Winner bonus EXP
-					}
-				}
-
-				currentExp, err := s.roster.GetExperience(ctx, f.ID)
-				if err == nil {
-					currentExp.Experience += expAmount
-					_ = s.roster.UpdateExperience(ctx, currentExp)
-				}
+		for _, reward := range settled.rewards {
+			s.rewards.AnnounceReward(ctx, reward)
+		}
+		if s.notifier != nil {
+			for _, levelUp := range settled.levelUps {
+				_ = s.notifier.FighterLeveledUp(ctx, levelUp)
 			}
 		}
+		if s.hub != nil {
+			s.hub.Broadcast(matchID, ended)
+		}
+		s.matchFinished(ctx, matchID)
 	}
-
-	if s.hub != nil {
-		s.hub.Broadcast(matchID, map[string]any{"type": "matchEnded", "matchId": matchID, "status": matches.MatchStatusCompleted})
+	if live {
+		s.streamMatch(ctx, matchID, result.RoundTicks, options, finish)
+	} else {
+		finish(ctx)
 	}
 
 	return nil
 }
 
+// liveErr returns err, or ErrMatchLive for an outcome withheld without one.
+func liveErr(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrMatchLive
+}
 
-func (s *Service) tryAutoStart(matchID string, options MatchOptions) {
-	go func() {
-		ctx := context.Background()
+// abortExecution puts a match a failed execution marked running back into
+// the lobby and returns the failure. A match another execution settled stays
+// completed.
+func (s *Service) abortExecution(ctx context.Context, matchID string, err error) error {
+	_ = s.resetToLobby(ctx, matchID)
+	return err
+}
 
-		fighters, err := s.fighters.ListByMatch(ctx, matchID)
-		if err != nil {
-			return
-		}
+// awardAttunementXP grants XP to the element each fighter is attuned to: a
+// win for winners, element use for everyone else. A player earns XP once per
+// element and match.
+func (s *Service) awardAttunementXP(ctx context.Context, fighters []roster.Fighter, winnerIDs []string) error {
+	if s.attunements == nil {
+		return nil
+	}
+	winners := make(map[string]bool, len(winnerIDs))
+	for _, id := range winnerIDs {
+		winners[id] = true
+	}
 
-		totalFighters := len(fighters)
-		if options.BotCount != nil {
-			totalFighters += *options.BotCount
+	sources := make(map[int64]map[attunement.Element]string)
+	for _, f := range fighters {
+		attuned := combat.GetAttunement(f.AttunementID)
+		if attuned == nil {
+			continue
+		}
+		element := attunement.Element(attuned.ID())
+		if sources[f.UserID] == nil {
+			sources[f.UserID] = make(map[attunement.Element]string)
 		}
+		if winners[f.ID] {
+			sources[f.UserID][element] = "match_win"
+		} else if _, ok := sources[f.UserID][element]; !ok {
+			sources[f.UserID][element] = "element_use"
+		}
+	}
 
-		if totalFighters >= 2 {
-			_ = s.ExecuteMatch(ctx, matchID)
+	for userID, elements := range sources {
+		for element, source := range elements {
+			if _, _, _, err := s.attunements.AwardXP(ctx, int(userID), element, source); err != nil {
+				return err
+			}
 		}
-	}()
+	}
+	return nil
 }
 
 func (s *Service) CancelMatch(ctx context.Context, matchID string) error {
@@ -619,12 +895,9 @@ func (s *Service) QuickJoin(ctx context.Context, userID int64, fighterID string)
 			continue
 		}
 
-		// Found a suitable match - auto-join
-		registration := &matches.MatchRegistration{
-			MatchID:   match.ID,
-			FighterID: fighterID,
-		}
-		if err := s.registrations.Upsert(ctx, registration); err != nil {
+		// Found a suitable match - auto-join like a regular join, which
+		// dates the registration and announces it to the lobby
+		if err := s.Join(ctx, userID, match.ID, fighterID); err != nil {
 			continue
 		}
 
@@ -0,0 +1,200 @@
+package matches
+
+import (
+	"context"
+	"encoding/json"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/matches"
+	rewardsdomain "empoweredpixels/internal/domain/rewards"
+	"empoweredpixels/internal/domain/roster"
+
+	"github.com/google/uuid"
+)
+
+// SetSettlement makes match settlement atomic: every write of a finished
+// match runs in one unit of work, and the settlement claim makes sure a match
+// is settled once even when it was executed twice.
+func (s *Service) SetSettlement(uow UnitOfWork, settlements SettlementRepository) {
+	s.uow = uow
+	s.settlements = settlements
+}
+
+// SetNotifier installs the notifier told about the level ups of settled
+// matches.
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// settlement is what settling a match granted and is announced once the
+// settlement committed.
+type settlement struct {
+	rewards  []*rewardsdomain.Reward
+	levelUps []roster.LevelUp
+}
+
+// settle writes the outcome of a battle: the result, combat logs and scores,
+// the completed match, the fighters' statistics and ratings, rewards and
+// experience. It claims the match first and returns ErrMatchSettled when it
+// was settled before.
+func (s *Service) settle(ctx context.Context, match *matches.Match, fighters []roster.Fighter, result *combat.MatchResult, battleInput []byte, options MatchOptions) (*settlement, error) {
+	matchID := match.ID
+	if s.settlements != nil {
+		claimed, err := s.settlements.Claim(ctx, matchID, s.now())
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			return nil, ErrMatchSettled
+		}
+	}
+
+	settled := &settlement{}
+
+	roundTicksJson, _ := json.Marshal(result.RoundTicks)
+	matchResult := &matches.MatchResult{
+		ID:           uuid.NewString(),
+		MatchID:      matchID,
+		RoundTicks:   roundTicksJson,
+		Seed:         result.Seed,
+		BattleInput:  battleInput,
+		WinnerTeamID: result.WinnerTeamID,
+	}
+	if err := s.results.Upsert(ctx, matchResult); err != nil {
+		return nil, err
+	}
+
+	// Per-tick rows and the summary let clients query large battles in pages
+	if s.combatLogs != nil {
+		if err := s.combatLogs.SaveLogs(ctx, matchID, result.RoundTicks); err != nil {
+			return nil, err
+		}
+		if err := s.combatLogs.SaveSummary(ctx, newBattleSummary(result)); err != nil {
+			return nil, err
+		}
+	}
+
+	scoresMapping := make(map[string]combat.FighterScore)
+	for _, score := range result.Scores {
+		scoresMapping[score.FighterID] = score
+	}
+
+	scores := make([]matches.MatchScoreFighter, 0, len(result.Scores))
+	for _, score := range result.Scores {
+		scores = append(scores, matches.MatchScoreFighter{
+			MatchID:          matchID,
+			FighterID:        score.FighterID,
+			IsBot:            score.IsBot,
+			TotalKills:       score.Kills,
+			TotalDeaths:      score.Deaths,
+			TotalAssists:     score.Assists,
+			TotalDamageDealt: score.DamageDealt,
+			TotalDamageTaken: score.DamageTaken,
+			TotalHealed:      score.Healed,
+		})
+	}
+	if len(scores) > 0 {
+		if err := s.scores.Upsert(ctx, scores); err != nil {
+			return nil, err
+		}
+	}
+
+	completedAt := s.now()
+	match.Status = matches.MatchStatusCompleted
+	match.CompletedAt = &completedAt
+	if err := s.matches.Update(ctx, match); err != nil {
+		return nil, err
+	}
+
+	// Every member of the winning side counts as a winner
+	winners := make(map[string]bool, len(result.WinnerIDs))
+	for _, id := range result.WinnerIDs {
+		winners[id] = true
+	}
+
+	// Lifetime statistics of the registered fighters
+	for _, f := range fighters {
+		score := scoresMapping[f.ID]
+		if err := s.fighters.RecordMatch(ctx, f.ID, winners[f.ID], int64(score.DamageDealt), int64(score.DamageTaken)); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Ranked {
+		if err := s.rateMatch(ctx, fighters, winners); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.awardAttunementXP(ctx, fighters, result.WinnerIDs); err != nil {
+		return nil, err
+	}
+
+	// Award rewards and experience to all participants
+	if s.rewards != nil {
+		rewardedUsers := make(map[int64]bool)
+
+		// A user wins if any of their fighters did
+		winningUsers := make(map[int64]bool)
+		for _, f := range fighters {
+			if winners[f.ID] {
+				winningUsers[f.UserID] = true
+			}
+		}
+
+		// Calculate Bot difficulty bonus
+		botBonusExp := 0
+		if options.BotCount != nil && options.BotPowerlevel != nil {
+			// +1 EXP for every 5 powerlevels of bots, scaled by bot count
+			botBonusExp = (*options.BotPowerlevel / 5) * (*options.BotCount / 2)
+		}
+
+		// Only registered fighters are rewarded, bots never are
+		for _, f := range fighters {
+			// Award Loot (per User)
+			if !rewardedUsers[f.UserID] {
+				pool := "match_participation"
+				if winningUsers[f.UserID] {
+					pool = "match_win"
+				}
+
+				reward, err := s.rewards.GrantReward(ctx, f.UserID, pool)
+				if err != nil {
+					return nil, err
+				}
+				settled.rewards = append(settled.rewards, reward)
+				rewardedUsers[f.UserID] = true
+			}
+
+			// Award Experience (per Fighter)
+			if s.roster != nil {
+				score, ok := scoresMapping[f.ID]
+				expAmount := 10 + botBonusExp // Base EXP + difficulty bonus
+				if ok {
+					expAmount += score.Kills * 5
+					if winners[f.ID] {
+						expAmount += 20 // Winner bonus EXP
+					}
+				}
+
+				levelUp, err := s.roster.AddExperience(ctx, f.ID, expAmount)
+				if err != nil {
+					return nil, err
+				}
+				if levelUp != nil {
+					settled.levelUps = append(settled.levelUps, *levelUp)
+				}
+			}
+		}
+	}
+
+	return settled, nil
+}
+
+// inTransaction runs fn in the unit of work, if the service has one.
+func (s *Service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
+	}
+	return s.uow.Do(ctx, fn)
+}
@@ -0,0 +1,209 @@
+package matches
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/roster"
+)
+
+func (m *memoryMatchRepo) MarkRunning(ctx context.Context, id string, started time.Time) (bool, error) {
+	match, ok := m.matches[id]
+	if !ok || match.Status != matches.MatchStatusLobby {
+		return false, nil
+	}
+	match.Status = matches.MatchStatusRunning
+	match.Started = &started
+	return true, nil
+}
+
+func (m *memoryMatchRepo) Reveal(ctx context.Context, id string, at time.Time) error {
+	if match, ok := m.matches[id]; ok && match.RevealedAt != nil && match.RevealedAt.After(at) {
+		match.RevealedAt = &at
+	}
+	return nil
+}
+
+type recordingFighterRepo struct {
+	memoryFighterRepo
+	recorded  []string
+	recordErr error
+}
+
+func (r *recordingFighterRepo) RecordMatch(ctx context.Context, fighterID string, won bool, damageDealt, damageTaken int64) error {
+	if r.recordErr != nil {
+		return r.recordErr
+	}
+	r.recorded = append(r.recorded, fighterID)
+	return nil
+}
+
+type memoryRegistrationRepo struct {
+	RegistrationRepository
+}
+
+func (m *memoryRegistrationRepo) ListByMatch(ctx context.Context, matchID string) ([]matches.MatchRegistration, error) {
+	return nil, nil
+}
+
+type failingRegistrationRepo struct {
+	RegistrationRepository
+}
+
+func (f *failingRegistrationRepo) ListByMatch(ctx context.Context, matchID string) ([]matches.MatchRegistration, error) {
+	return nil, errors.New("db down")
+}
+
+type memoryScoreRepo struct {
+	ScoreRepository
+	scores map[string][]matches.MatchScoreFighter
+}
+
+func (m *memoryScoreRepo) Upsert(ctx context.Context, scores []matches.MatchScoreFighter) error {
+	for _, score := range scores {
+		m.scores[score.MatchID] = append(m.scores[score.MatchID], score)
+	}
+	return nil
+}
+
+func (m *memoryScoreRepo) ListByMatch(ctx context.Context, matchID string) ([]matches.MatchScoreFighter, error) {
+	return m.scores[matchID], nil
+}
+
+type memorySettlements struct {
+	settled map[string]bool
+}
+
+func (m *memorySettlements) Claim(ctx context.Context, matchID string, settledAt time.Time) (bool, error) {
+	if m.settled[matchID] {
+		return false, nil
+	}
+	m.settled[matchID] = true
+	return true, nil
+}
+
+// recordingUnitOfWork keeps the error each unit of work ended with, which a
+// transaction would have rolled back on.
+type recordingUnitOfWork struct {
+	outcomes []error
+}
+
+func (u *recordingUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	u.outcomes = append(u.outcomes, err)
+	return err
+}
+
+func newSettlementService(fighters *recordingFighterRepo) (*Service, *memoryMatchRepo, *recordingUnitOfWork) {
+	repo := &memoryMatchRepo{matches: map[string]*matches.Match{
+		"m": {ID: "m", Status: matches.MatchStatusLobby, Options: []byte("{}")},
+	}}
+	fighters.byMatch = map[string][]roster.Fighter{"m": engineFighters()}
+	results := &memoryResultRepo{results: make(map[string]*matches.MatchResult)}
+	scores := &memoryScoreRepo{scores: make(map[string][]matches.MatchScoreFighter)}
+	svc := NewService(repo, nil, &memoryRegistrationRepo{}, results, scores, nil, fighters, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	uow := &recordingUnitOfWork{}
+	svc.SetSettlement(uow, &memorySettlements{settled: make(map[string]bool)})
+	return svc, repo, uow
+}
+
+func TestService_ExecuteMatchSettlesOnce(t *testing.T) {
+	fighters := &recordingFighterRepo{}
+	svc, repo, _ := newSettlementService(fighters)
+
+	if err := svc.ExecuteMatch(context.Background(), "m"); err != nil {
+		t.Fatal(err)
+	}
+	if repo.matches["m"].Status != matches.MatchStatusCompleted || len(fighters.recorded) != 2 {
+		t.Fatalf("expected a completed match with 2 recorded fighters, got %s and %d", repo.matches["m"].Status, len(fighters.recorded))
+	}
+
+	// A second execution, such as one of a worker whose lease ran out
+	repo.matches["m"].Status = matches.MatchStatusLobby
+	if err := svc.ExecuteMatch(context.Background(), "m"); err != ErrMatchSettled {
+		t.Fatalf("expected ErrMatchSettled, got %v", err)
+	}
+	if len(fighters.recorded) != 2 {
+		t.Fatalf("expected the fighters to be recorded once, got %d", len(fighters.recorded))
+	}
+}
+
+func TestService_ExecuteMatchFailsSettlementAsAWhole(t *testing.T) {
+	fighters := &recordingFighterRepo{recordErr: errors.New("db down")}
+	svc, _, uow := newSettlementService(fighters)
+
+	if err := svc.ExecuteMatch(context.Background(), "m"); err == nil {
+		t.Fatal("expected the failed write to fail the execution")
+	}
+	if len(uow.outcomes) != 1 || uow.outcomes[0] == nil {
+		t.Fatalf("expected the unit of work to end with the error, got %v", uow.outcomes)
+	}
+}
+
+func TestService_ExecuteMatchFailureResetsToLobby(t *testing.T) {
+	svc, repo, _ := newSettlementService(&recordingFighterRepo{})
+	svc.registrations = &failingRegistrationRepo{}
+
+	if err := svc.ExecuteMatch(context.Background(), "m"); err == nil {
+		t.Fatal("expected the failed read to fail the execution")
+	}
+	if match := repo.matches["m"]; match.Status != matches.MatchStatusLobby || match.Started != nil {
+		t.Fatalf("expected the match back in the lobby, got %s", match.Status)
+	}
+}
+
+func TestService_LiveMatchRevealsWhenStreamEnds(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newSettlementService(&recordingFighterRepo{})
+	repo.matches["m"].Options = []byte(`{"live":true}`)
+	hub := &recordingHub{ended: make(chan struct{})}
+	svc.hub = hub
+	pace := make(chan time.Time)
+	svc.after = func(time.Duration) <-chan time.Time { return pace }
+	finished := make(chan string, 1)
+	svc.OnMatchFinished(func(ctx context.Context, matchID string) {
+		finished <- matchID
+	})
+
+	if err := svc.ExecuteMatch(ctx, "m"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process sharing the database doesn't stream the match
+	other := &Service{matches: repo, scores: svc.scores, results: svc.results, live: newLiveStreams(), now: time.Now}
+	if _, err := other.FighterScores(ctx, "m"); err != ErrMatchLive {
+		t.Fatalf("expected the scores withheld on another process, got %v", err)
+	}
+	if _, err := other.RoundTicks(ctx, "m"); err != ErrMatchLive {
+		t.Fatalf("expected the rounds withheld on another process, got %v", err)
+	}
+	select {
+	case <-finished:
+		t.Fatal("expected the hooks to wait for the stream")
+	default:
+	}
+
+	go func() {
+		for {
+			select {
+			case pace <- time.Now():
+			case <-hub.ended:
+				return
+			}
+		}
+	}()
+	select {
+	case matchID := <-finished:
+		if matchID != "m" {
+			t.Fatalf("unexpected finished match %s", matchID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the hooks once the stream ended")
+	}
+	if _, err := other.FighterScores(ctx, "m"); err != nil {
+		t.Fatalf("expected the scores revealed, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package matchmaking
+
+import (
+	"context"
+	"time"
+
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/matchmaking"
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/roster"
+	matchesusecase "empoweredpixels/internal/usecase/matches"
+)
+
+type TicketRepository interface {
+	Enqueue(ctx context.Context, ticket *matchmaking.Ticket) (bool, error)
+	GetByUser(ctx context.Context, userID int64) (*matchmaking.Ticket, error)
+	Cancel(ctx context.Context, userID int64) (bool, error)
+	ListWaiting(ctx context.Context) ([]matchmaking.Ticket, error)
+	MarkMatched(ctx context.Context, fighterIDs []string, matchID string, matchedAt time.Time) error
+	AverageWait(ctx context.Context, since time.Time) (time.Duration, error)
+}
+
+type RatingRepository interface {
+	Get(ctx context.Context, fighterID string) (*rating.Rating, error)
+}
+
+type FighterRepository interface {
+	GetByUserAndID(ctx context.Context, userID int64, id string) (*roster.Fighter, error)
+}
+
+// MatchService creates and starts the matches of the groups the queue forms.
+type MatchService interface {
+	DefaultOptions() matchesusecase.MatchOptions
+	CreateMatchFor(ctx context.Context, options matchesusecase.MatchOptions, fighterIDs []string) (*matches.Match, error)
+	StartMatch(ctx context.Context, matchID string) error
+}
+
+// UnitOfWork runs fn in a transaction. Repositories called with the context
+// fn receives take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Pusher delivers a message to the live connections of a user.
+type Pusher interface {
+	SendToUser(userID int64, payload any)
+}
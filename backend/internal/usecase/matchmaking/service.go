@@ -0,0 +1,275 @@
+package matchmaking
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"empoweredpixels/internal/domain/matchmaking"
+	"empoweredpixels/internal/domain/rating"
+)
+
+var (
+	ErrInvalidFighter = errors.New("invalid fighter")
+	ErrAlreadyQueued  = errors.New("fighter is already queued")
+	ErrNotQueued      = errors.New("not queued")
+)
+
+// RecentWaitWindow is how far back matched tickets count towards the
+// average wait used for estimates.
+const RecentWaitWindow = 30 * time.Minute
+
+// Status is where a user's latest ticket stands.
+type Status struct {
+	Ticket   matchmaking.Ticket
+	Waited   time.Duration
+	Estimate time.Duration
+	Window   matchmaking.Window
+	// Waiting is the number of tickets in the queue.
+	Waiting int
+}
+
+type Service struct {
+	tickets  TicketRepository
+	ratings  RatingRepository
+	fighters FighterRepository
+	matches  MatchService
+	uow      UnitOfWork
+	pusher   Pusher
+	now      func() time.Time
+}
+
+func NewService(
+	tickets TicketRepository,
+	ratings RatingRepository,
+	fighters FighterRepository,
+	matches MatchService,
+	uow UnitOfWork,
+	pusher Pusher,
+	now func() time.Time,
+) *Service {
+	if now == nil {
+		now = time.Now
+	}
+	return &Service{
+		tickets:  tickets,
+		ratings:  ratings,
+		fighters: fighters,
+		matches:  matches,
+		uow:      uow,
+		pusher:   pusher,
+		now:      now,
+	}
+}
+
+// Rating returns the fighter's rating, the default one before its first
+// rated match.
+func (s *Service) Rating(ctx context.Context, fighterID string) (*rating.Rating, error) {
+	stored, err := s.ratings.Get(ctx, fighterID)
+	if err != nil || stored != nil {
+		return stored, err
+	}
+	initial := rating.New(fighterID)
+	return &initial, nil
+}
+
+// Enqueue puts the user's fighter in the matchmaking queue with its current
+// rating and power.
+func (s *Service) Enqueue(ctx context.Context, userID int64, fighterID string) (*Status, error) {
+	fighter, err := s.fighters.GetByUserAndID(ctx, userID, fighterID)
+	if err != nil {
+		return nil, err
+	}
+	if fighter == nil {
+		return nil, ErrInvalidFighter
+	}
+	current, err := s.Rating(ctx, fighterID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := &matchmaking.Ticket{
+		FighterID: fighter.ID,
+		UserID:    userID,
+		Rating:    current.Rating,
+		Power:     fighter.Power,
+		Status:    matchmaking.TicketStatusWaiting,
+		QueuedAt:  s.now(),
+	}
+	queued, err := s.tickets.Enqueue(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+	if !queued {
+		return nil, ErrAlreadyQueued
+	}
+
+	status, err := s.Status(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if status != nil {
+		s.pushStatus(*status)
+	}
+	return status, nil
+}
+
+// Cancel takes the user out of the queue.
+func (s *Service) Cancel(ctx context.Context, userID int64) error {
+	cancelled, err := s.tickets.Cancel(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrNotQueued
+	}
+	s.push(userID, map[string]any{"type": "matchmaking", "status": matchmaking.TicketStatusCancelled})
+	return nil
+}
+
+// Status returns the user's latest ticket with an estimate of the remaining
+// wait, or nil when the user never queued.
+func (s *Service) Status(ctx context.Context, userID int64) (*Status, error) {
+	ticket, err := s.tickets.GetByUser(ctx, userID)
+	if err != nil || ticket == nil {
+		return nil, err
+	}
+	if ticket.Status != matchmaking.TicketStatusWaiting {
+		return &Status{Ticket: *ticket}, nil
+	}
+
+	waiting, err := s.tickets.ListWaiting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recentWait, err := s.tickets.AverageWait(ctx, s.now().Add(-RecentWaitWindow))
+	if err != nil {
+		return nil, err
+	}
+	status := s.waitingStatus(*ticket, waiting, recentWait)
+	return &status, nil
+}
+
+// matchedGroup is a group Tick formed a match for.
+type matchedGroup struct {
+	matchID string
+	tickets []matchmaking.Ticket
+}
+
+// Tick forms matches from the waiting tickets and starts them, then tells
+// everyone still waiting how long they are expected to wait. It returns the
+// number of matches formed.
+func (s *Service) Tick(ctx context.Context) (int, error) {
+	now := s.now()
+	var groups []matchedGroup
+	var waiting []matchmaking.Ticket
+
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		groups, waiting = nil, nil
+		tickets, err := s.tickets.ListWaiting(ctx)
+		if err != nil {
+			return err
+		}
+
+		matched := make(map[string]bool)
+		for _, group := range matchmaking.FormGroups(tickets, now) {
+			fighterIDs := make([]string, 0, len(group))
+			for _, ticket := range group {
+				fighterIDs = append(fighterIDs, ticket.FighterID)
+				matched[ticket.FighterID] = true
+			}
+
+			options := s.matches.DefaultOptions()
+			options.IsPrivate = true
+			options.Ranked = true
+			match, err := s.matches.CreateMatchFor(ctx, options, fighterIDs)
+			if err != nil {
+				return err
+			}
+			if err := s.tickets.MarkMatched(ctx, fighterIDs, match.ID, now); err != nil {
+				return err
+			}
+			groups = append(groups, matchedGroup{matchID: match.ID, tickets: group})
+		}
+
+		for _, ticket := range tickets {
+			if !matched[ticket.FighterID] {
+				waiting = append(waiting, ticket)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Matches start once their lobby is committed
+	var startErr error
+	for _, group := range groups {
+		if err := s.matches.StartMatch(ctx, group.matchID); err != nil && startErr == nil {
+			startErr = err
+		}
+		for _, ticket := range group.tickets {
+			s.push(ticket.UserID, map[string]any{
+				"type":      "matchmaking",
+				"status":    matchmaking.TicketStatusMatched,
+				"fighterId": ticket.FighterID,
+				"matchId":   group.matchID,
+			})
+		}
+	}
+
+	if len(waiting) > 0 && s.pusher != nil {
+		recentWait, _ := s.tickets.AverageWait(ctx, now.Add(-RecentWaitWindow))
+		for _, ticket := range waiting {
+			s.pushStatus(s.waitingStatus(ticket, waiting, recentWait))
+		}
+	}
+
+	return len(groups), startErr
+}
+
+func (s *Service) waitingStatus(ticket matchmaking.Ticket, waiting []matchmaking.Ticket, recentWait time.Duration) Status {
+	now := s.now()
+	count := len(waiting)
+	found := false
+	for _, other := range waiting {
+		found = found || other.FighterID == ticket.FighterID
+	}
+	if !found {
+		// Locked by a concurrent pass over the queue
+		count++
+	}
+	return Status{
+		Ticket:   ticket,
+		Waited:   ticket.Waited(now),
+		Estimate: matchmaking.EstimateWait(ticket, waiting, now, recentWait),
+		Window:   matchmaking.SearchWindow(ticket.Waited(now)),
+		Waiting:  count,
+	}
+}
+
+func (s *Service) pushStatus(status Status) {
+	s.push(status.Ticket.UserID, map[string]any{
+		"type":          "matchmaking",
+		"status":        status.Ticket.Status,
+		"fighterId":     status.Ticket.FighterID,
+		"waitedSeconds": int(status.Waited.Seconds()),
+		"etaSeconds":    int(status.Estimate.Seconds()),
+		"waiting":       status.Waiting,
+	})
+}
+
+func (s *Service) push(userID int64, payload map[string]any) {
+	if s.pusher == nil {
+		return
+	}
+	s.pusher.SendToUser(userID, payload)
+}
+
+func (s *Service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
+	}
+	return s.uow.Do(ctx, fn)
+}
@@ -0,0 +1,226 @@
+package matchmaking
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/matchmaking"
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/roster"
+	matchesusecase "empoweredpixels/internal/usecase/matches"
+)
+
+type memoryTicketRepo struct {
+	tickets map[string]*matchmaking.Ticket
+}
+
+func (m *memoryTicketRepo) Enqueue(ctx context.Context, ticket *matchmaking.Ticket) (bool, error) {
+	if existing, ok := m.tickets[ticket.FighterID]; ok && existing.Status == matchmaking.TicketStatusWaiting {
+		return false, nil
+	}
+	copied := *ticket
+	m.tickets[ticket.FighterID] = &copied
+	return true, nil
+}
+
+func (m *memoryTicketRepo) GetByUser(ctx context.Context, userID int64) (*matchmaking.Ticket, error) {
+	for _, ticket := range m.tickets {
+		if ticket.UserID == userID {
+			copied := *ticket
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryTicketRepo) Cancel(ctx context.Context, userID int64) (bool, error) {
+	cancelled := false
+	for _, ticket := range m.tickets {
+		if ticket.UserID == userID && ticket.Status == matchmaking.TicketStatusWaiting {
+			ticket.Status = matchmaking.TicketStatusCancelled
+			cancelled = true
+		}
+	}
+	return cancelled, nil
+}
+
+func (m *memoryTicketRepo) ListWaiting(ctx context.Context) ([]matchmaking.Ticket, error) {
+	var result []matchmaking.Ticket
+	for _, ticket := range m.tickets {
+		if ticket.Status == matchmaking.TicketStatusWaiting {
+			result = append(result, *ticket)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryTicketRepo) MarkMatched(ctx context.Context, fighterIDs []string, matchID string, matchedAt time.Time) error {
+	for _, id := range fighterIDs {
+		m.tickets[id].Status = matchmaking.TicketStatusMatched
+		m.tickets[id].MatchID = &matchID
+		m.tickets[id].MatchedAt = &matchedAt
+	}
+	return nil
+}
+
+func (m *memoryTicketRepo) AverageWait(ctx context.Context, since time.Time) (time.Duration, error) {
+	return 0, nil
+}
+
+type memoryRatingRepo map[string]rating.Rating
+
+func (m memoryRatingRepo) Get(ctx context.Context, fighterID string) (*rating.Rating, error) {
+	r, ok := m[fighterID]
+	if !ok {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+type memoryFighterRepo map[string]roster.Fighter
+
+func (m memoryFighterRepo) GetByUserAndID(ctx context.Context, userID int64, id string) (*roster.Fighter, error) {
+	f, ok := m[id]
+	if !ok || f.UserID != userID {
+		return nil, nil
+	}
+	return &f, nil
+}
+
+type recordingMatchService struct {
+	created [][]string
+	started []string
+}
+
+func (r *recordingMatchService) DefaultOptions() matchesusecase.MatchOptions {
+	return matchesusecase.MatchOptions{}
+}
+
+func (r *recordingMatchService) CreateMatchFor(ctx context.Context, options matchesusecase.MatchOptions, fighterIDs []string) (*matches.Match, error) {
+	if !options.IsPrivate || !options.Ranked {
+		return nil, fmt.Errorf("expected a private ranked match")
+	}
+	r.created = append(r.created, fighterIDs)
+	return &matches.Match{ID: fmt.Sprintf("m%d", len(r.created))}, nil
+}
+
+func (r *recordingMatchService) StartMatch(ctx context.Context, matchID string) error {
+	r.started = append(r.started, matchID)
+	return nil
+}
+
+type recordingPusher struct {
+	pushed map[int64][]map[string]any
+}
+
+func (r *recordingPusher) SendToUser(userID int64, payload any) {
+	r.pushed[userID] = append(r.pushed[userID], payload.(map[string]any))
+}
+
+func (r *recordingPusher) last(userID int64) map[string]any {
+	messages := r.pushed[userID]
+	if len(messages) == 0 {
+		return nil
+	}
+	return messages[len(messages)-1]
+}
+
+type queueFixture struct {
+	svc     *Service
+	tickets *memoryTicketRepo
+	matches *recordingMatchService
+	pusher  *recordingPusher
+	now     *time.Time
+}
+
+func newQueueFixture() *queueFixture {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	f := &queueFixture{
+		tickets: &memoryTicketRepo{tickets: make(map[string]*matchmaking.Ticket)},
+		matches: &recordingMatchService{},
+		pusher:  &recordingPusher{pushed: make(map[int64][]map[string]any)},
+		now:     &now,
+	}
+	fighters := memoryFighterRepo{
+		"a": {ID: "a", UserID: 1, Power: 100},
+		"b": {ID: "b", UserID: 2, Power: 105},
+		"c": {ID: "c", UserID: 3, Power: 100},
+	}
+	ratings := memoryRatingRepo{
+		"a": {FighterID: "a", Rating: 1500},
+		"b": {FighterID: "b", Rating: 1560},
+		"c": {FighterID: "c", Rating: 2200},
+	}
+	f.svc = NewService(f.tickets, ratings, fighters, f.matches, nil, f.pusher, func() time.Time { return *f.now })
+	return f
+}
+
+func TestService_EnqueueOnce(t *testing.T) {
+	f := newQueueFixture()
+	ctx := context.Background()
+
+	status, err := f.svc.Enqueue(ctx, 1, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Ticket.Rating != 1500 || status.Estimate != matchmaking.DefaultWaitEstimate || status.Waiting != 1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if f.pusher.last(1)["etaSeconds"] != 30 {
+		t.Fatalf("expected the estimate to be pushed, got %v", f.pusher.last(1))
+	}
+	if _, err := f.svc.Enqueue(ctx, 1, "a"); err != ErrAlreadyQueued {
+		t.Fatalf("expected ErrAlreadyQueued, got %v", err)
+	}
+	if _, err := f.svc.Enqueue(ctx, 1, "b"); err != ErrInvalidFighter {
+		t.Fatalf("expected another user's fighter to be rejected, got %v", err)
+	}
+
+	if err := f.svc.Cancel(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.svc.Cancel(ctx, 1); err != ErrNotQueued {
+		t.Fatalf("expected ErrNotQueued, got %v", err)
+	}
+}
+
+func TestService_TickFormsAndStartsMatches(t *testing.T) {
+	f := newQueueFixture()
+	ctx := context.Background()
+	for userID, fighterID := range map[int64]string{1: "a", 2: "b", 3: "c"} {
+		if _, err := f.svc.Enqueue(ctx, userID, fighterID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The close pair waits for a fuller group first
+	if formed, err := f.svc.Tick(ctx); err != nil || formed != 0 {
+		t.Fatalf("expected no match yet, got %d (%v)", formed, err)
+	}
+	if f.pusher.last(3)["status"] != matchmaking.TicketStatusWaiting {
+		t.Fatalf("expected waiting tickets to get an update, got %v", f.pusher.last(3))
+	}
+
+	*f.now = f.now.Add(matchmaking.FillTimeout)
+	formed, err := f.svc.Tick(ctx)
+	if err != nil || formed != 1 {
+		t.Fatalf("expected one match, got %d (%v)", formed, err)
+	}
+	if len(f.matches.created[0]) != 2 || len(f.matches.started) != 1 || f.matches.started[0] != "m1" {
+		t.Fatalf("expected the pair's match to be created and started, got %v and %v", f.matches.created, f.matches.started)
+	}
+	if f.pusher.last(1)["matchId"] != "m1" || f.pusher.last(2)["matchId"] != "m1" {
+		t.Fatalf("expected both users to be told about their match, got %v", f.pusher.pushed)
+	}
+
+	status, err := f.svc.Status(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Ticket.Status != matchmaking.TicketStatusWaiting || status.Waiting != 1 {
+		t.Fatalf("expected the far rated fighter to keep waiting alone, got %+v", status)
+	}
+}
@@ -0,0 +1,22 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"empoweredpixels/internal/domain/notifications"
+)
+
+type Repository interface {
+	Create(ctx context.Context, notification *notifications.Notification) (bool, error)
+	CreateForAllUsers(ctx context.Context, notification notifications.Notification) ([]notifications.Notification, error)
+	List(ctx context.Context, userID int64, unreadOnly bool, limit int, offset int) ([]notifications.Notification, int, error)
+	CountUnread(ctx context.Context, userID int64) (int, error)
+	MarkRead(ctx context.Context, userID int64, id string, readAt time.Time) (bool, error)
+	MarkAllRead(ctx context.Context, userID int64, readAt time.Time) (int, error)
+}
+
+// Pusher delivers a message to the live connections of a user.
+type Pusher interface {
+	SendToUser(userID int64, payload any)
+}
@@ -0,0 +1,249 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"empoweredpixels/internal/domain/daily"
+	"empoweredpixels/internal/domain/events"
+	"empoweredpixels/internal/domain/leaderboard"
+	"empoweredpixels/internal/domain/notifications"
+	"empoweredpixels/internal/domain/rewards"
+	"empoweredpixels/internal/domain/roster"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// Page sizes of inbox listings
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Service keeps the users' inboxes and pushes every new notification to the
+// user's live connections.
+type Service struct {
+	repo   Repository
+	pusher Pusher
+	now    func() time.Time
+}
+
+func NewService(repo Repository, pusher Pusher, now func() time.Time) *Service {
+	if now == nil {
+		now = time.Now
+	}
+	return &Service{
+		repo:   repo,
+		pusher: pusher,
+		now:    now,
+	}
+}
+
+// List returns one page of the user's inbox, newest first, and the total
+// number of notifications listed.
+func (s *Service) List(ctx context.Context, userID int64, unreadOnly bool, page int, pageSize int) ([]notifications.Notification, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return s.repo.List(ctx, userID, unreadOnly, pageSize, (page-1)*pageSize)
+}
+
+func (s *Service) UnreadCount(ctx context.Context, userID int64) (int, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+func (s *Service) MarkRead(ctx context.Context, userID int64, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrNotificationNotFound
+	}
+	found, err := s.repo.MarkRead(ctx, userID, id, s.now())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks the whole inbox as read and returns the number of
+// notifications that were unread.
+func (s *Service) MarkAllRead(ctx context.Context, userID int64) (int, error) {
+	return s.repo.MarkAllRead(ctx, userID, s.now())
+}
+
+// Notify stores the notification in the user's inbox and pushes it. A
+// notification whose key the user already has is dropped.
+func (s *Service) Notify(ctx context.Context, notification notifications.Notification) error {
+	notification.ID = uuid.NewString()
+	notification.Created = s.now()
+	notification.ReadAt = nil
+
+	created, err := s.repo.Create(ctx, &notification)
+	if err != nil || !created {
+		return err
+	}
+	s.push(notification)
+	return nil
+}
+
+// NotifyAll stores the notification in every user's inbox and pushes it to
+// everyone online.
+func (s *Service) NotifyAll(ctx context.Context, notification notifications.Notification) error {
+	notification.Created = s.now()
+	notification.ReadAt = nil
+
+	created, err := s.repo.CreateForAllUsers(ctx, notification)
+	if err != nil {
+		return err
+	}
+	for _, n := range created {
+		s.push(n)
+	}
+	return nil
+}
+
+func (s *Service) push(notification notifications.Notification) {
+	if s.pusher == nil {
+		return
+	}
+	s.pusher.SendToUser(notification.UserID, map[string]any{
+		"type": "notification",
+		"notification": map[string]any{
+			"id":      notification.ID,
+			"type":    notification.Type,
+			"title":   notification.Title,
+			"message": notification.Message,
+			"data":    json.RawMessage(dataOrEmpty(notification.Data)),
+			"read":    false,
+			"created": notification.Created,
+		},
+	})
+}
+
+// RewardIssued tells the user about a reward that landed in their vault.
+func (s *Service) RewardIssued(ctx context.Context, reward *rewards.Reward) error {
+	return s.Notify(ctx, notifications.Notification{
+		UserID:  reward.UserID,
+		Type:    notifications.TypeReward,
+		Title:   "Reward received",
+		Message: fmt.Sprintf("Your %s reward is waiting in your vault.", reward.RewardPoolID),
+		Data:    encode(map[string]any{"rewardId": reward.ID, "poolId": reward.RewardPoolID}),
+		Key:     key("reward", reward.ID),
+	})
+}
+
+// AchievementCompleted tells the user about an achievement they completed.
+func (s *Service) AchievementCompleted(ctx context.Context, userID int64, achievement leaderboard.Achievement) error {
+	return s.Notify(ctx, notifications.Notification{
+		UserID:  userID,
+		Type:    notifications.TypeAchievement,
+		Title:   "Achievement unlocked",
+		Message: achievement.Name,
+		Data: encode(map[string]any{
+			"achievementId": achievement.ID,
+			"key":           achievement.Key,
+			"rewardGold":    achievement.RewardGold,
+		}),
+		Key: key("achievement", achievement.ID),
+	})
+}
+
+// LeagueMatchFinished tells the user about a league match their fighters
+// took part in.
+func (s *Service) LeagueMatchFinished(ctx context.Context, userID int64, leagueID int, leagueName string, matchID string, won bool) error {
+	message := fmt.Sprintf("%s: your fighters were defeated.", leagueName)
+	if won {
+		message = fmt.Sprintf("%s: your fighters won!", leagueName)
+	}
+	return s.Notify(ctx, notifications.Notification{
+		UserID:  userID,
+		Type:    notifications.TypeLeagueMatch,
+		Title:   "League match finished",
+		Message: message,
+		Data:    encode(map[string]any{"leagueId": leagueID, "matchId": matchID, "won": won}),
+		Key:     key("league", matchID),
+	})
+}
+
+// DailyRewardClaimable tells the user their daily reward is ready. The user
+// is told at most once per day.
+func (s *Service) DailyRewardClaimable(ctx context.Context, userID int64, reward daily.Reward) error {
+	return s.Notify(ctx, notifications.Notification{
+		UserID:  userID,
+		Type:    notifications.TypeDailyReward,
+		Title:   "Daily reward ready",
+		Message: fmt.Sprintf("Day %d: %s is ready to claim.", reward.Day, reward.Name),
+		Data:    encode(map[string]any{"day": reward.Day, "name": reward.Name, "type": reward.Type}),
+		Key:     key("daily", s.now().Format("2006-01-02")),
+	})
+}
+
+// FighterLeveledUp tells the user about a fighter of theirs that reached a
+// new level.
+func (s *Service) FighterLeveledUp(ctx context.Context, levelUp roster.LevelUp) error {
+	return s.Notify(ctx, notifications.Notification{
+		UserID:  levelUp.UserID,
+		Type:    notifications.TypeLevelUp,
+		Title:   "Level up",
+		Message: fmt.Sprintf("Your fighter reached level %d.", levelUp.ToLevel),
+		Data: encode(map[string]any{
+			"fighterId": levelUp.FighterID,
+			"fromLevel": levelUp.FromLevel,
+			"toLevel":   levelUp.ToLevel,
+			"gains": map[string]int{
+				"power":    levelUp.Gains.Power,
+				"vitality": levelUp.Gains.Vitality,
+				"accuracy": levelUp.Gains.Accuracy,
+				"agility":  levelUp.Gains.Agility,
+				"armor":    levelUp.Gains.Armor,
+			},
+		}),
+		Key: key("level", fmt.Sprintf("%s:%d", levelUp.FighterID, levelUp.ToLevel)),
+	})
+}
+
+// WeekendEventStarted tells every user about an event that just started.
+func (s *Service) WeekendEventStarted(ctx context.Context, event events.ActiveEvent) error {
+	notification := notifications.Notification{
+		Type:  notifications.TypeWeekendEvent,
+		Title: "Event started",
+		Data:  encode(map[string]any{"activeEventId": event.ID, "eventId": event.EventID, "endsAt": event.EndsAt}),
+		Key:   key("event", event.ID),
+	}
+	if event.Event != nil {
+		notification.Title = event.Event.Name
+		notification.Message = event.Event.Description
+	}
+	return s.NotifyAll(ctx, notification)
+}
+
+func key(kind string, id string) *string {
+	value := kind + ":" + id
+	return &value
+}
+
+func encode(data map[string]any) []byte {
+	encoded, _ := json.Marshal(data)
+	return encoded
+}
+
+func dataOrEmpty(data []byte) []byte {
+	if len(data) == 0 {
+		return []byte("{}")
+	}
+	return data
+}
@@ -0,0 +1,219 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/daily"
+	"empoweredpixels/internal/domain/events"
+	"empoweredpixels/internal/domain/notifications"
+	"empoweredpixels/internal/domain/rewards"
+)
+
+type memoryRepo struct {
+	items []notifications.Notification
+	users []int64
+}
+
+func (m *memoryRepo) hasKey(userID int64, key *string) bool {
+	if key == nil {
+		return false
+	}
+	for _, n := range m.items {
+		if n.UserID == userID && n.Key != nil && *n.Key == *key {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memoryRepo) Create(ctx context.Context, notification *notifications.Notification) (bool, error) {
+	if m.hasKey(notification.UserID, notification.Key) {
+		return false, nil
+	}
+	m.items = append(m.items, *notification)
+	return true, nil
+}
+
+func (m *memoryRepo) CreateForAllUsers(ctx context.Context, notification notifications.Notification) ([]notifications.Notification, error) {
+	var created []notifications.Notification
+	for _, userID := range m.users {
+		if m.hasKey(userID, notification.Key) {
+			continue
+		}
+		n := notification
+		n.ID = "generated"
+		n.UserID = userID
+		m.items = append(m.items, n)
+		created = append(created, n)
+	}
+	return created, nil
+}
+
+func (m *memoryRepo) List(ctx context.Context, userID int64, unreadOnly bool, limit int, offset int) ([]notifications.Notification, int, error) {
+	var matching []notifications.Notification
+	for i := len(m.items) - 1; i >= 0; i-- {
+		n := m.items[i]
+		if n.UserID == userID && (!unreadOnly || !n.Read()) {
+			matching = append(matching, n)
+		}
+	}
+	total := len(matching)
+	if offset >= total {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return matching[offset:end], total, nil
+}
+
+func (m *memoryRepo) CountUnread(ctx context.Context, userID int64) (int, error) {
+	count := 0
+	for _, n := range m.items {
+		if n.UserID == userID && !n.Read() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryRepo) MarkRead(ctx context.Context, userID int64, id string, readAt time.Time) (bool, error) {
+	for i := range m.items {
+		if m.items[i].ID == id && m.items[i].UserID == userID {
+			if m.items[i].ReadAt == nil {
+				m.items[i].ReadAt = &readAt
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryRepo) MarkAllRead(ctx context.Context, userID int64, readAt time.Time) (int, error) {
+	count := 0
+	for i := range m.items {
+		if m.items[i].UserID == userID && m.items[i].ReadAt == nil {
+			m.items[i].ReadAt = &readAt
+			count++
+		}
+	}
+	return count, nil
+}
+
+type pushed struct {
+	userID  int64
+	payload map[string]any
+}
+
+type recordingPusher struct {
+	messages []pushed
+}
+
+func (p *recordingPusher) SendToUser(userID int64, payload any) {
+	p.messages = append(p.messages, pushed{userID: userID, payload: payload.(map[string]any)})
+}
+
+func newTestService() (*Service, *memoryRepo, *recordingPusher) {
+	repo := &memoryRepo{}
+	pusher := &recordingPusher{}
+	now := time.Date(2026, 2, 7, 12, 0, 0, 0, time.UTC)
+	return NewService(repo, pusher, func() time.Time { return now }), repo, pusher
+}
+
+func TestRewardIssued_StoresAndPushes(t *testing.T) {
+	service, repo, pusher := newTestService()
+	reward := &rewards.Reward{ID: "reward-1", UserID: 7, RewardPoolID: "match_win"}
+
+	if err := service.RewardIssued(context.Background(), reward); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.items) != 1 || repo.items[0].Type != notifications.TypeReward || repo.items[0].UserID != 7 {
+		t.Fatalf("expected a stored reward notification, got %+v", repo.items)
+	}
+	if len(pusher.messages) != 1 || pusher.messages[0].userID != 7 {
+		t.Fatalf("expected one push to user 7, got %+v", pusher.messages)
+	}
+	if pusher.messages[0].payload["type"] != "notification" {
+		t.Fatalf("unexpected payload %v", pusher.messages[0].payload)
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(repo.items[0].Data, &data); err != nil || data["rewardId"] != "reward-1" {
+		t.Fatalf("unexpected data %s", repo.items[0].Data)
+	}
+}
+
+func TestNotify_SameKeyIsStoredOnce(t *testing.T) {
+	service, repo, pusher := newTestService()
+	reward := daily.GetRewardForDay(3)
+
+	_ = service.DailyRewardClaimable(context.Background(), 7, reward)
+	_ = service.DailyRewardClaimable(context.Background(), 7, reward)
+	_ = service.DailyRewardClaimable(context.Background(), 8, reward)
+
+	if len(repo.items) != 2 {
+		t.Fatalf("expected one notification per user, got %d", len(repo.items))
+	}
+	if len(pusher.messages) != 2 {
+		t.Fatalf("expected duplicates not to be pushed, got %d pushes", len(pusher.messages))
+	}
+}
+
+func TestWeekendEventStarted_NotifiesEveryUser(t *testing.T) {
+	service, repo, pusher := newTestService()
+	repo.users = []int64{1, 2, 3}
+	event := events.ActiveEvent{ID: "active-1", EventID: "event-1", Event: &events.WeekendEvent{Name: "XP Frenzy"}}
+
+	_ = service.WeekendEventStarted(context.Background(), event)
+	_ = service.WeekendEventStarted(context.Background(), event)
+
+	if len(repo.items) != 3 || len(pusher.messages) != 3 {
+		t.Fatalf("expected one notification per user, got %d stored and %d pushed", len(repo.items), len(pusher.messages))
+	}
+	if repo.items[0].Title != "XP Frenzy" {
+		t.Fatalf("expected the event name as title, got %q", repo.items[0].Title)
+	}
+}
+
+func TestInbox_ListAndMarkRead(t *testing.T) {
+	service, repo, _ := newTestService()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		_ = service.LeagueMatchFinished(ctx, 7, 1, "Arena", string(rune('a'+i)), i%2 == 0)
+	}
+	_ = service.LeagueMatchFinished(ctx, 8, 1, "Arena", "other", true)
+
+	items, total, err := service.List(ctx, 7, false, 2, 2)
+	if err != nil || total != 5 || len(items) != 2 {
+		t.Fatalf("expected page 2 of 5 notifications, got %d of %d (%v)", len(items), total, err)
+	}
+
+	if err := service.MarkRead(ctx, 7, repo.items[0].ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.MarkRead(ctx, 7, repo.items[5].ID); err != ErrNotificationNotFound {
+		t.Fatalf("expected another user's notification to be hidden, got %v", err)
+	}
+	if err := service.MarkRead(ctx, 7, "not-a-uuid"); err != ErrNotificationNotFound {
+		t.Fatalf("expected an invalid id to be not found, got %v", err)
+	}
+	if count, _ := service.UnreadCount(ctx, 7); count != 4 {
+		t.Fatalf("expected 4 unread, got %d", count)
+	}
+
+	unread, total, _ := service.List(ctx, 7, true, 1, 10)
+	if total != 4 || len(unread) != 4 {
+		t.Fatalf("expected 4 unread notifications listed, got %d", total)
+	}
+
+	if marked, _ := service.MarkAllRead(ctx, 7); marked != 4 {
+		t.Fatalf("expected 4 notifications marked, got %d", marked)
+	}
+	if count, _ := service.UnreadCount(ctx, 8); count != 1 {
+		t.Fatalf("expected the other inbox untouched, got %d unread", count)
+	}
+}
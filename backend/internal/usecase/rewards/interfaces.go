@@ -23,3 +23,8 @@ type ItemRepository interface {
 type EquipmentRepository interface {
 	Create(ctx context.Context, equipment *inventory.Equipment) error
 }
+
+// Notifier tells users about the rewards they receive.
+type Notifier interface {
+	RewardIssued(ctx context.Context, reward *rewards.Reward) error
+}
@@ -8,6 +8,8 @@ import (
 
 	"empoweredpixels/internal/domain/inventory"
 	"empoweredpixels/internal/domain/rewards"
+	"empoweredpixels/internal/domain/seasons"
+	"empoweredpixels/internal/domain/tournaments"
 
 	"github.com/google/uuid"
 )
@@ -20,6 +22,7 @@ type Service struct {
 	rewards   RewardRepository
 	items     ItemRepository
 	equipment EquipmentRepository
+	notifier  Notifier
 	now       func() time.Time
 }
 
@@ -35,6 +38,11 @@ func NewService(rewards RewardRepository, items ItemRepository, equipment Equipm
 	}
 }
 
+// SetNotifier installs the notifier told about every issued reward.
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
 type RewardContent struct {
 	Items     []inventory.Item
 	Equipment []inventory.Equipment
@@ -45,6 +53,17 @@ func (s *Service) List(ctx context.Context, userID int64) ([]rewards.Reward, err
 }
 
 func (s *Service) IssueReward(ctx context.Context, userID int64, poolID string) (*rewards.Reward, error) {
+	reward, err := s.GrantReward(ctx, userID, poolID)
+	if err != nil {
+		return nil, err
+	}
+	s.AnnounceReward(ctx, reward)
+	return reward, nil
+}
+
+// GrantReward issues the reward without telling the user, for callers that
+// issue it inside a transaction and announce it once that committed.
+func (s *Service) GrantReward(ctx context.Context, userID int64, poolID string) (*rewards.Reward, error) {
 	reward := &rewards.Reward{
 		ID:           uuid.NewString(),
 		UserID:       userID,
@@ -71,6 +90,13 @@ func (s *Service) IssueReward(ctx context.Context, userID int64, poolID string)
 	return reward, nil
 }
 
+// AnnounceReward tells the user about an issued reward.
+func (s *Service) AnnounceReward(ctx context.Context, reward *rewards.Reward) {
+	if s.notifier != nil {
+		_ = s.notifier.RewardIssued(ctx, reward)
+	}
+}
+
 func (s *Service) Claim(ctx context.Context, userID int64, rewardID string, poolID string) (*RewardContent, error) {
 	reward, err := s.rewards.GetUnclaimed(ctx, userID, rewardID, poolID)
 	if err != nil {
@@ -128,6 +154,26 @@ func (s *Service) ClaimAll(ctx context.Context, userID int64) (*RewardContent, e
 	return &all, nil
 }
 
+// rewardTier is what a ranked reward holds: the end-of-season reward of a
+// division or the reward of a tournament placement.
+type rewardTier struct {
+	particles   int
+	tokenRarity int
+}
+
+var rewardTiers = map[string]rewardTier{
+	seasons.DivisionBronze.RewardPool():   {particles: 50, tokenRarity: inventory.ItemRarityCommon},
+	seasons.DivisionSilver.RewardPool():   {particles: 100, tokenRarity: inventory.ItemRarityCommon},
+	seasons.DivisionGold.RewardPool():     {particles: 200, tokenRarity: inventory.ItemRarityRare},
+	seasons.DivisionPlatinum.RewardPool(): {particles: 350, tokenRarity: inventory.ItemRarityRare},
+	seasons.DivisionDiamond.RewardPool():  {particles: 500, tokenRarity: inventory.ItemRarityFabled},
+	seasons.DivisionMaster.RewardPool():   {particles: 750, tokenRarity: inventory.ItemRarityMythic},
+	seasons.DivisionMythic.RewardPool():   {particles: 1000, tokenRarity: inventory.ItemRarityLegendary},
+	tournaments.RewardPool(1):             {particles: 400, tokenRarity: inventory.ItemRarityFabled},
+	tournaments.RewardPool(2):             {particles: 250, tokenRarity: inventory.ItemRarityRare},
+	tournaments.RewardPool(3):             {particles: 150, tokenRarity: inventory.ItemRarityRare},
+}
+
 func (s *Service) generateRewards(userID int64, poolID string) RewardContent {
 	items := make([]inventory.Item, 0, 10)
 	equipment := make([]inventory.Equipment, 0)
@@ -189,18 +235,37 @@ func (s *Service) generateRewards(userID int64, poolID string) RewardContent {
 		equipment = append(equipment, inventory.Equipment{
 			ID:      uuid.NewString(),
 			UserID:  userID,
-			ItemID:  "9C46EB15-4D04-4F90-B8A1-D4BD0A5A82B1", // Basic Sword
+			ItemID:  inventory.BasicSwordID,
 			Level:   1,
 			Rarity:  inventory.ItemRarityCommon,
 			Created: s.now(),
 		}, inventory.Equipment{
 			ID:      uuid.NewString(),
 			UserID:  userID,
-			ItemID:  "E98E3A82-C2B1-4A2D-A8C6-29BAE0D6A5A6", // Basic Vest
+			ItemID:  inventory.BasicVestID,
 			Level:   1,
 			Rarity:  inventory.ItemRarityCommon,
 			Created: s.now(),
 		})
+	} else if tier, ok := rewardTiers[poolID]; ok {
+		// End of season or tournament: particles and a token scaled by the
+		// division or placement
+		for i := 0; i < tier.particles; i++ {
+			items = append(items, inventory.Item{
+				ID:      uuid.NewString(),
+				UserID:  userID,
+				ItemID:  inventory.EmpoweredParticleID,
+				Rarity:  inventory.ItemRarityBasic,
+				Created: s.now(),
+			})
+		}
+		items = append(items, inventory.Item{
+			ID:      uuid.NewString(),
+			UserID:  userID,
+			ItemID:  tokenIDForRarity(tier.tokenRarity),
+			Rarity:  tier.tokenRarity,
+			Created: s.now(),
+		})
 	}
 
 	return RewardContent{
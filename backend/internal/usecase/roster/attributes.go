@@ -0,0 +1,219 @@
+package roster
+
+import (
+	"context"
+	"errors"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/shop"
+)
+
+var (
+	ErrInvalidAllocation = errors.New("invalid attribute allocation")
+	ErrNotEnoughPoints   = errors.New("not enough attribute points")
+	ErrAttributeCap      = errors.New("attribute cap exceeded")
+	ErrNothingToRespec   = errors.New("no attribute points to reset")
+	ErrInsufficientGold  = errors.New("insufficient gold")
+)
+
+// AttributeSheet is a fighter's attribute budget and what its stats do in
+// combat.
+type AttributeSheet struct {
+	Fighter    roster.Fighter
+	Allocation roster.AttributeAllocation
+	Points     int
+	Available  int
+	Cap        int
+	RespecCost int
+	Derived    combat.DerivedStats
+}
+
+// SetAttributes installs the repositories attribute allocation needs. Respecs
+// are paid from the player's gold and recorded in the shop ledger, in one
+// unit of work with the reset.
+func (s *Service) SetAttributes(attributes AttributeRepository, gold GoldRepository, transactions TransactionRepository, uow UnitOfWork) {
+	s.attributes = attributes
+	s.gold = gold
+	s.transactions = transactions
+	s.uow = uow
+}
+
+// Attributes returns the attribute sheet of the user's fighter.
+func (s *Service) Attributes(ctx context.Context, userID int64, fighterID string) (*AttributeSheet, error) {
+	fighter, err := s.fighters.GetByUserAndID(ctx, userID, fighterID)
+	if err != nil || fighter == nil {
+		return nil, err
+	}
+	allocation, err := s.attributes.Get(ctx, fighterID)
+	if err != nil {
+		return nil, err
+	}
+	if allocation == nil {
+		allocation = &roster.AttributeAllocation{FighterID: fighterID}
+	}
+	return newAttributeSheet(*fighter, *allocation), nil
+}
+
+// PreviewAttributes validates spending points on the user's fighter and
+// returns the sheet the fighter would have, without saving it.
+func (s *Service) PreviewAttributes(ctx context.Context, userID int64, fighterID string, points roster.Attributes) (*AttributeSheet, error) {
+	sheet, err := s.Attributes(ctx, userID, fighterID)
+	if err != nil || sheet == nil {
+		return nil, err
+	}
+	if err := validateAllocation(sheet.Fighter, sheet.Allocation.Points, points); err != nil {
+		return nil, err
+	}
+	sheet.Fighter.Allocate(points)
+	sheet.Allocation.Points = sheet.Allocation.Points.Plus(points)
+	return newAttributeSheet(sheet.Fighter, sheet.Allocation), nil
+}
+
+// AllocateAttributes spends points on the user's fighter's stats.
+func (s *Service) AllocateAttributes(ctx context.Context, userID int64, fighterID string, points roster.Attributes) (*AttributeSheet, error) {
+	var sheet *AttributeSheet
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		fighter, allocation, err := s.lockAttributes(ctx, userID, fighterID)
+		if err != nil || fighter == nil {
+			return err
+		}
+		if err := validateAllocation(*fighter, allocation.Points, points); err != nil {
+			return err
+		}
+
+		fighter.Allocate(points)
+		allocation.Points = allocation.Points.Plus(points)
+		allocation.Updated = s.now()
+		if err := s.fighters.Update(ctx, fighter); err != nil {
+			return err
+		}
+		if err := s.attributes.Save(ctx, allocation); err != nil {
+			return err
+		}
+		sheet = newAttributeSheet(*fighter, *allocation)
+		return nil
+	})
+	return sheet, err
+}
+
+// RespecAttributes takes back every point spent on the user's fighter for
+// gold. Each respec costs more than the last, see roster.RespecCost.
+func (s *Service) RespecAttributes(ctx context.Context, userID int64, fighterID string) (*AttributeSheet, error) {
+	var sheet *AttributeSheet
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		fighter, allocation, err := s.lockAttributes(ctx, userID, fighterID)
+		if err != nil || fighter == nil {
+			return err
+		}
+		if allocation.Points.Total() == 0 {
+			return ErrNothingToRespec
+		}
+
+		cost := roster.RespecCost(allocation.Respecs)
+		if err := s.chargeRespec(ctx, fighter, cost); err != nil {
+			return err
+		}
+
+		fighter.Allocate(allocation.Points.Negated())
+		allocation.Points = roster.Attributes{}
+		allocation.Respecs++
+		allocation.Updated = s.now()
+		if err := s.fighters.Update(ctx, fighter); err != nil {
+			return err
+		}
+		if err := s.attributes.Save(ctx, allocation); err != nil {
+			return err
+		}
+		sheet = newAttributeSheet(*fighter, *allocation)
+		return nil
+	})
+	return sheet, err
+}
+
+func (s *Service) chargeRespec(ctx context.Context, fighter *roster.Fighter, cost int) error {
+	userID := int(fighter.UserID)
+	balance, err := s.gold.GetPlayerGold(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if balance == nil || balance.Balance < cost {
+		return ErrInsufficientGold
+	}
+	if err := s.gold.SpendGold(ctx, userID, cost); err != nil {
+		return err
+	}
+	_, err = s.transactions.CreateTransaction(ctx, &shop.Transaction{
+		UserID:        userID,
+		ItemType:      shop.ItemTypeRespec,
+		ItemName:      "Attribute respec: " + fighter.Name,
+		PriceAmount:   cost,
+		PriceCurrency: shop.CurrencyGold,
+		GoldChange:    -cost,
+		Status:        "completed",
+		Metadata:      map[string]interface{}{"fighter_id": fighter.ID},
+	})
+	return err
+}
+
+// lockAttributes loads the user's fighter with its allocation locked for the
+// rest of the unit of work, so concurrent allocations can't overspend.
+func (s *Service) lockAttributes(ctx context.Context, userID int64, fighterID string) (*roster.Fighter, *roster.AttributeAllocation, error) {
+	fighter, err := s.fighters.GetByUserAndID(ctx, userID, fighterID)
+	if err != nil || fighter == nil {
+		return nil, nil, err
+	}
+	allocation, err := s.attributes.Lock(ctx, fighterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if allocation == nil {
+		allocation = &roster.AttributeAllocation{FighterID: fighterID}
+	}
+	// Reread the fighter now that its allocation is locked
+	fighter, err = s.fighters.GetByUserAndID(ctx, userID, fighterID)
+	if err != nil || fighter == nil {
+		return nil, nil, err
+	}
+	return fighter, allocation, nil
+}
+
+func (s *Service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
+	}
+	return s.uow.Do(ctx, fn)
+}
+
+// validateAllocation checks that spending points on top of spent stays within
+// the fighter's budget and caps.
+func validateAllocation(fighter roster.Fighter, spent roster.Attributes, points roster.Attributes) error {
+	if points.Min() < 0 || points.Total() == 0 {
+		return ErrInvalidAllocation
+	}
+	if spent.Total()+points.Total() > roster.AttributePoints(fighter.Level) {
+		return ErrNotEnoughPoints
+	}
+	if spent.Plus(points).Max() > roster.AttributeCap(fighter.Level) {
+		return ErrAttributeCap
+	}
+	// Parry chance is a percentage that stops counting at the combat cap
+	if points.ParryChance > 0 && fighter.ParryChance+points.ParryChance > combat.MaxParryChance {
+		return ErrAttributeCap
+	}
+	return nil
+}
+
+func newAttributeSheet(fighter roster.Fighter, allocation roster.AttributeAllocation) *AttributeSheet {
+	points := roster.AttributePoints(fighter.Level)
+	stats, _ := combat.CompileStats(fighter, combat.Gear{})
+	return &AttributeSheet{
+		Fighter:    fighter,
+		Allocation: allocation,
+		Points:     points,
+		Available:  points - allocation.Points.Total(),
+		Cap:        roster.AttributeCap(fighter.Level),
+		RespecCost: roster.RespecCost(allocation.Respecs),
+		Derived:    combat.Derive(stats),
+	}
+}
@@ -0,0 +1,147 @@
+package roster
+
+import (
+	"context"
+	"testing"
+
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/shop"
+)
+
+func (m *memoryFighterRepo) GetByUserAndID(ctx context.Context, userID int64, id string) (*roster.Fighter, error) {
+	fighter, err := m.GetByID(ctx, id)
+	if err != nil || fighter == nil || fighter.UserID != userID {
+		return nil, err
+	}
+	return fighter, nil
+}
+
+type memoryAttributeRepo struct {
+	allocations map[string]roster.AttributeAllocation
+}
+
+func (m *memoryAttributeRepo) Get(ctx context.Context, fighterID string) (*roster.AttributeAllocation, error) {
+	allocation, ok := m.allocations[fighterID]
+	if !ok {
+		return nil, nil
+	}
+	return &allocation, nil
+}
+
+func (m *memoryAttributeRepo) Lock(ctx context.Context, fighterID string) (*roster.AttributeAllocation, error) {
+	return m.Get(ctx, fighterID)
+}
+
+func (m *memoryAttributeRepo) Save(ctx context.Context, allocation *roster.AttributeAllocation) error {
+	m.allocations[allocation.FighterID] = *allocation
+	return nil
+}
+
+type memoryGoldRepo struct {
+	balances map[int]int
+	ledger   []shop.Transaction
+}
+
+func (m *memoryGoldRepo) GetPlayerGold(ctx context.Context, userID int) (*shop.PlayerGold, error) {
+	return &shop.PlayerGold{UserID: userID, Balance: m.balances[userID]}, nil
+}
+
+func (m *memoryGoldRepo) SpendGold(ctx context.Context, userID int, amount int) error {
+	m.balances[userID] -= amount
+	return nil
+}
+
+func (m *memoryGoldRepo) CreateTransaction(ctx context.Context, tx *shop.Transaction) (int, error) {
+	m.ledger = append(m.ledger, *tx)
+	return len(m.ledger), nil
+}
+
+func newAttributeService(level int, gold int) (*Service, *memoryFighterRepo, *memoryGoldRepo) {
+	fighters := &memoryFighterRepo{fighters: map[string]*roster.Fighter{
+		"f": {ID: "f", UserID: 7, Name: "Pix", Level: level, Power: 10, Vitality: 4},
+	}}
+	golds := &memoryGoldRepo{balances: map[int]int{7: gold}}
+	svc := NewService(fighters, nil, nil, nil, nil, nil)
+	svc.SetAttributes(&memoryAttributeRepo{allocations: map[string]roster.AttributeAllocation{}}, golds, golds, nil)
+	return svc, fighters, golds
+}
+
+func TestService_AllocateAttributes(t *testing.T) {
+	svc, fighters, _ := newAttributeService(10, 0)
+	ctx := context.Background()
+
+	sheet, err := svc.AllocateAttributes(ctx, 7, "f", roster.Attributes{Vitality: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Available != 10 || sheet.Derived.MaxHP != 100+24*12 || fighters.fighters["f"].Vitality != 24 {
+		t.Fatalf("unexpected sheet after allocating: %+v", sheet)
+	}
+
+	cases := []struct {
+		points roster.Attributes
+		err    error
+	}{
+		{roster.Attributes{}, ErrInvalidAllocation},
+		{roster.Attributes{Power: 2, Speed: -1}, ErrInvalidAllocation},
+		{roster.Attributes{Power: 11}, ErrNotEnoughPoints},
+		{roster.Attributes{Vitality: 6}, ErrAttributeCap},
+	}
+	for _, c := range cases {
+		if _, err := svc.AllocateAttributes(ctx, 7, "f", c.points); err != c.err {
+			t.Errorf("%+v: expected %v, got %v", c.points, c.err, err)
+		}
+	}
+
+	if sheet, err := svc.AllocateAttributes(ctx, 8, "f", roster.Attributes{Power: 1}); err != nil || sheet != nil {
+		t.Fatalf("expected another user's fighter to be missing, got %+v (%v)", sheet, err)
+	}
+}
+
+func TestService_PreviewAttributesDoesNotSave(t *testing.T) {
+	svc, fighters, _ := newAttributeService(2, 0)
+
+	sheet, err := svc.PreviewAttributes(context.Background(), 7, "f", roster.Attributes{Speed: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Fighter.Speed != 6 || sheet.Derived.MoveDistance != 3.75 || sheet.Available != 0 {
+		t.Fatalf("unexpected preview %+v", sheet)
+	}
+	if fighters.fighters["f"].Speed != 0 {
+		t.Fatal("expected the preview not to change the fighter")
+	}
+}
+
+func TestService_RespecAttributes(t *testing.T) {
+	svc, fighters, golds := newAttributeService(3, roster.RespecBaseCost+100)
+	ctx := context.Background()
+
+	if _, err := svc.RespecAttributes(ctx, 7, "f"); err != ErrNothingToRespec {
+		t.Fatalf("expected ErrNothingToRespec, got %v", err)
+	}
+	if _, err := svc.AllocateAttributes(ctx, 7, "f", roster.Attributes{Power: 4, Armor: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet, err := svc.RespecAttributes(ctx, 7, "f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := fighters.fighters["f"]; f.Power != 10 || f.Armor != 0 || sheet.Available != 9 {
+		t.Fatalf("expected the points back, got %+v", f)
+	}
+	if golds.balances[7] != 100 || len(golds.ledger) != 1 || golds.ledger[0].GoldChange != -roster.RespecBaseCost {
+		t.Fatalf("expected the respec to be paid and recorded, got %d and %+v", golds.balances[7], golds.ledger)
+	}
+	if sheet.RespecCost != 2*roster.RespecBaseCost {
+		t.Fatalf("expected the next respec to cost more, got %d", sheet.RespecCost)
+	}
+
+	if _, err := svc.AllocateAttributes(ctx, 7, "f", roster.Attributes{Power: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RespecAttributes(ctx, 7, "f"); err != ErrInsufficientGold {
+		t.Fatalf("expected ErrInsufficientGold, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/shop"
 )
 
 type FighterRepository interface {
@@ -20,6 +21,35 @@ type FighterRepository interface {
 type ExperienceRepository interface {
 	GetByFighterID(ctx context.Context, fighterID string) (*roster.FighterExperience, error)
 	Upsert(ctx context.Context, experience *roster.FighterExperience) error
+	Add(ctx context.Context, fighterID string, amount int) (int, error)
+}
+
+type LevelHistoryRepository interface {
+	Record(ctx context.Context, levelUp *roster.LevelUp) error
+	ListByFighter(ctx context.Context, fighterID string, limit int) ([]roster.LevelUp, error)
+}
+
+type AttributeRepository interface {
+	Get(ctx context.Context, fighterID string) (*roster.AttributeAllocation, error)
+	Lock(ctx context.Context, fighterID string) (*roster.AttributeAllocation, error)
+	Save(ctx context.Context, allocation *roster.AttributeAllocation) error
+}
+
+// GoldRepository is the player gold balance respecs are paid from.
+type GoldRepository interface {
+	GetPlayerGold(ctx context.Context, userID int) (*shop.PlayerGold, error)
+	SpendGold(ctx context.Context, userID int, amount int) error
+}
+
+// TransactionRepository is the shop ledger respecs are recorded in.
+type TransactionRepository interface {
+	CreateTransaction(ctx context.Context, tx *shop.Transaction) (int, error)
+}
+
+// UnitOfWork runs fn in a transaction. Repositories called with the context
+// fn receives take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type ConfigurationRepository interface {
@@ -20,6 +20,11 @@ type Service struct {
 	fighters       FighterRepository
 	experiences    ExperienceRepository
 	configurations ConfigurationRepository
+	levels         LevelHistoryRepository
+	attributes     AttributeRepository
+	gold           GoldRepository
+	transactions   TransactionRepository
+	uow            UnitOfWork
 	SquadService   *SquadService
 	now            func() time.Time
 }
@@ -29,6 +34,7 @@ func NewService(
 	experiences ExperienceRepository,
 	configurations ConfigurationRepository,
 	squads SquadRepository,
+	levels LevelHistoryRepository,
 	now func() time.Time,
 ) *Service {
 	if now == nil {
@@ -39,6 +45,7 @@ func NewService(
 		fighters:       fighters,
 		experiences:    experiences,
 		configurations: configurations,
+		levels:         levels,
 		SquadService:   NewSquadService(squads),
 		now:            now,
 	}
@@ -131,35 +138,68 @@ func (s *Service) UpdateExperience(ctx context.Context, experience *roster.Fight
 	if err := s.experiences.Upsert(ctx, experience); err != nil {
 		return err
 	}
+	_, err := s.applyLevel(ctx, experience.FighterID, experience.Experience)
+	return err
+}
 
-	// Check for Level Up
-	fighter, err := s.fighters.GetByID(ctx, experience.FighterID)
-	if err != nil || fighter == nil {
-		return err
+// AddExperience grants the fighter experience with an atomic increment, so
+// concurrent grants never overwrite each other, and advances the fighter to
+// the level the new total is worth. It returns the level up, or nil when the
+// fighter stayed on its level.
+func (s *Service) AddExperience(ctx context.Context, fighterID string, amount int) (*roster.LevelUp, error) {
+	total, err := s.experiences.Add(ctx, fighterID, amount)
+	if err != nil {
+		return nil, err
 	}
+	return s.applyLevel(ctx, fighterID, total)
+}
 
-	newLevel := 1
-	// Simple progressive leveling: level 2 at 100 exp, level 3 at 300, 4 at 600, etc. (Level * Level * 50)
-	for {
-		nextLevelExp := newLevel * newLevel * 50
-		if experience.Experience < nextLevelExp {
-			break
-		}
-		newLevel++
+// LevelHistory returns the fighter's most recent level ups, newest first.
+func (s *Service) LevelHistory(ctx context.Context, fighterID string, limit int) ([]roster.LevelUp, error) {
+	if s.levels == nil {
+		return nil, nil
 	}
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	return s.levels.ListByFighter(ctx, fighterID, limit)
+}
 
-	if newLevel > fighter.Level {
-		levelsGained := newLevel - fighter.Level
-		fighter.Level = newLevel
-		// Basic stat growth: +2 to all main combat stats per level
-		fighter.Power += levelsGained * 2
-		fighter.Vitality += levelsGained * 2
-		fighter.Accuracy += levelsGained * 2
-		fighter.Agility += levelsGained * 2
-		fighter.Armor += levelsGained * 1
+// applyLevel stores the fighter's progress within its level. Levels gained
+// grow the fighter's stats and are recorded. A fighter never loses levels.
+func (s *Service) applyLevel(ctx context.Context, fighterID string, experience int) (*roster.LevelUp, error) {
+	fighter, err := s.fighters.GetByID(ctx, fighterID)
+	if err != nil || fighter == nil {
+		return nil, err
+	}
 
-		return s.fighters.Update(ctx, fighter)
+	level, current, next := roster.LevelForExperience(experience)
+	fighter.XP = current
+	fighter.XPToNextLevel = next
+
+	var levelUp *roster.LevelUp
+	if level > fighter.Level {
+		gains := roster.StatGrowthPerLevel.Times(level - fighter.Level)
+		levelUp = &roster.LevelUp{
+			FighterID:  fighter.ID,
+			UserID:     fighter.UserID,
+			FromLevel:  fighter.Level,
+			ToLevel:    level,
+			Experience: experience,
+			Gains:      gains,
+			Created:    s.now(),
+		}
+		fighter.Level = level
+		fighter.Grow(gains)
 	}
 
-	return nil
+	if err := s.fighters.Update(ctx, fighter); err != nil {
+		return nil, err
+	}
+	if levelUp != nil && s.levels != nil {
+		if err := s.levels.Record(ctx, levelUp); err != nil {
+			return nil, err
+		}
+	}
+	return levelUp, nil
 }
@@ -0,0 +1,100 @@
+package roster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/roster"
+)
+
+type memoryFighterRepo struct {
+	FighterRepository
+	fighters map[string]*roster.Fighter
+}
+
+func (m *memoryFighterRepo) GetByID(ctx context.Context, id string) (*roster.Fighter, error) {
+	fighter, ok := m.fighters[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *fighter
+	return &copied, nil
+}
+
+func (m *memoryFighterRepo) Update(ctx context.Context, fighter *roster.Fighter) error {
+	copied := *fighter
+	m.fighters[fighter.ID] = &copied
+	return nil
+}
+
+type memoryExperienceRepo struct {
+	ExperienceRepository
+	totals map[string]int
+}
+
+func (m *memoryExperienceRepo) Add(ctx context.Context, fighterID string, amount int) (int, error) {
+	m.totals[fighterID] += amount
+	return m.totals[fighterID], nil
+}
+
+type memoryLevelRepo struct {
+	recorded []roster.LevelUp
+}
+
+func (m *memoryLevelRepo) Record(ctx context.Context, levelUp *roster.LevelUp) error {
+	m.recorded = append(m.recorded, *levelUp)
+	return nil
+}
+
+func (m *memoryLevelRepo) ListByFighter(ctx context.Context, fighterID string, limit int) ([]roster.LevelUp, error) {
+	return m.recorded, nil
+}
+
+func TestService_AddExperienceLevelsUp(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fighters := &memoryFighterRepo{fighters: map[string]*roster.Fighter{
+		"f": {ID: "f", UserID: 7, Level: 1, Power: 5},
+	}}
+	levels := &memoryLevelRepo{}
+	svc := NewService(fighters, &memoryExperienceRepo{totals: map[string]int{}}, nil, nil, levels, func() time.Time { return now })
+
+	levelUp, err := svc.AddExperience(context.Background(), "f", 60)
+	if err != nil || levelUp != nil {
+		t.Fatalf("expected no level up below 100 experience, got %+v (%v)", levelUp, err)
+	}
+	if f := fighters.fighters["f"]; f.XP != 60 || f.XPToNextLevel != 100 {
+		t.Fatalf("expected the progress to be stored, got %d/%d", f.XP, f.XPToNextLevel)
+	}
+
+	// 60 + 250 experience is worth level 3
+	levelUp, err = svc.AddExperience(context.Background(), "f", 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if levelUp == nil || levelUp.FromLevel != 1 || levelUp.ToLevel != 3 || levelUp.UserID != 7 {
+		t.Fatalf("expected a level up from 1 to 3, got %+v", levelUp)
+	}
+	f := fighters.fighters["f"]
+	if f.Level != 3 || f.Power != 9 || f.XP != 10 || f.XPToNextLevel != 300 {
+		t.Fatalf("unexpected fighter after the level up: %+v", f)
+	}
+	if len(levels.recorded) != 1 || !levels.recorded[0].Created.Equal(now) {
+		t.Fatalf("expected the level up to be recorded, got %v", levels.recorded)
+	}
+}
+
+func TestService_AddExperienceStopsAtCap(t *testing.T) {
+	fighters := &memoryFighterRepo{fighters: map[string]*roster.Fighter{
+		"f": {ID: "f", Level: roster.MaxLevel},
+	}}
+	svc := NewService(fighters, &memoryExperienceRepo{totals: map[string]int{"f": roster.ExperienceForLevel(roster.MaxLevel)}}, nil, nil, &memoryLevelRepo{}, nil)
+
+	levelUp, err := svc.AddExperience(context.Background(), "f", 10000)
+	if err != nil || levelUp != nil {
+		t.Fatalf("expected no level beyond the cap, got %+v (%v)", levelUp, err)
+	}
+	if f := fighters.fighters["f"]; f.Level != roster.MaxLevel || f.XPToNextLevel != 0 {
+		t.Fatalf("unexpected fighter at the cap: %+v", f)
+	}
+}
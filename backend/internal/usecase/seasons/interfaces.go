@@ -2,10 +2,50 @@ package seasons
 
 import (
 	"context"
+	"time"
 
+	"empoweredpixels/internal/domain/identity"
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/rewards"
 	"empoweredpixels/internal/domain/seasons"
 )
 
 type SummaryRepository interface {
 	ListByUser(ctx context.Context, userID int64, limit int, offset int) ([]seasons.SeasonSummary, error)
+	Create(ctx context.Context, summary *seasons.SeasonSummary) error
+}
+
+type SeasonRepository interface {
+	Current(ctx context.Context) (*seasons.Season, error)
+	LastSeasonID(ctx context.Context) (int, error)
+	Create(ctx context.Context, season *seasons.Season) error
+	Complete(ctx context.Context, id int64, completed time.Time) (bool, error)
+}
+
+type LadderRepository interface {
+	Get(ctx context.Context, fighterID string) (*seasons.Placement, error)
+	ListTop(ctx context.Context, limit int, offset int) ([]seasons.Placement, error)
+	ListAll(ctx context.Context) ([]seasons.Placement, error)
+	ListIdle(ctx context.Context, before time.Time) ([]seasons.Placement, error)
+	Save(ctx context.Context, entry *seasons.LadderEntry) error
+}
+
+type RatingRepository interface {
+	Save(ctx context.Context, rating *rating.Rating) error
+}
+
+type UserRepository interface {
+	ListAll(ctx context.Context) ([]identity.User, error)
+}
+
+// RewardService issues the end-of-season rewards.
+type RewardService interface {
+	GrantReward(ctx context.Context, userID int64, poolID string) (*rewards.Reward, error)
+	AnnounceReward(ctx context.Context, reward *rewards.Reward)
+}
+
+// UnitOfWork runs fn in a transaction. Repositories called with the context
+// fn receives take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
 }
@@ -0,0 +1,243 @@
+package seasons
+
+import (
+	"context"
+	"errors"
+
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/rewards"
+	"empoweredpixels/internal/domain/seasons"
+)
+
+var (
+	ErrNoSeason = errors.New("no season running")
+)
+
+// Page sizes of the ladder.
+const (
+	DefaultLadderPageSize = 50
+	MaxLadderPageSize     = 100
+)
+
+// SetLadder enables the ranked ladder: rated fighters are placed in
+// divisions, idle ones decay and seasons roll over.
+func (s *Service) SetLadder(seasonRepo SeasonRepository, ladder LadderRepository, ratings RatingRepository, users UserRepository, rewardService RewardService, uow UnitOfWork) {
+	s.seasons = seasonRepo
+	s.ladder = ladder
+	s.ratings = ratings
+	s.users = users
+	s.rewards = rewardService
+	s.uow = uow
+}
+
+// CurrentSeason returns the running season.
+func (s *Service) CurrentSeason(ctx context.Context) (*seasons.Season, error) {
+	if s.seasons == nil {
+		return nil, ErrNoSeason
+	}
+	season, err := s.seasons.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if season == nil {
+		return nil, ErrNoSeason
+	}
+	return season, nil
+}
+
+// StartSeason starts the first season, or the next one after a season was
+// completed without a successor. A running season is returned as it is.
+func (s *Service) StartSeason(ctx context.Context) (*seasons.Season, error) {
+	if s.seasons == nil {
+		return nil, ErrNoSeason
+	}
+
+	var season *seasons.Season
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.seasons.Current(ctx)
+		if err != nil || current != nil {
+			season = current
+			return err
+		}
+		last, err := s.seasons.LastSeasonID(ctx)
+		if err != nil {
+			return err
+		}
+		season, err = s.createSeason(ctx, last+1)
+		return err
+	})
+	return season, err
+}
+
+func (s *Service) createSeason(ctx context.Context, seasonID int) (*seasons.Season, error) {
+	start := s.now()
+	season := &seasons.Season{
+		SeasonID:  seasonID,
+		StartDate: start,
+		EndDate:   start.Add(seasons.SeasonLength),
+	}
+	if err := s.seasons.Create(ctx, season); err != nil {
+		return nil, err
+	}
+	return season, nil
+}
+
+// RecordMatch moves a fighter on the ladder after a ranked match they ended
+// with score and the rating it left them with. It runs in the settlement of
+// the match.
+func (s *Service) RecordMatch(ctx context.Context, userID int64, updated rating.Rating, score float64) error {
+	if s.ladder == nil {
+		return nil
+	}
+
+	placement, err := s.ladder.Get(ctx, updated.FighterID)
+	if err != nil {
+		return err
+	}
+	if placement == nil {
+		return s.ladder.Save(ctx, seasons.NewLadderEntry(updated.FighterID, userID, updated, s.now()))
+	}
+	entry := placement.Entry
+	entry.Record(updated, score, s.now())
+	return s.ladder.Save(ctx, &entry)
+}
+
+// Placement returns a fighter's place on the ladder, or nil for fighters
+// without rated matches.
+func (s *Service) Placement(ctx context.Context, fighterID string) (*seasons.Placement, error) {
+	if s.ladder == nil {
+		return nil, ErrNoSeason
+	}
+	return s.ladder.Get(ctx, fighterID)
+}
+
+// Ladder returns a page of the ladder, best placed fighters first.
+func (s *Service) Ladder(ctx context.Context, page int, pageSize int) ([]seasons.Placement, error) {
+	if s.ladder == nil {
+		return nil, ErrNoSeason
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > MaxLadderPageSize {
+		pageSize = DefaultLadderPageSize
+	}
+	return s.ladder.ListTop(ctx, pageSize, (page-1)*pageSize)
+}
+
+// Decay decays the ratings of fighters who stayed away from ranked matches
+// for longer than the grace and returns how many decayed.
+func (s *Service) Decay(ctx context.Context) (int, error) {
+	if s.ladder == nil {
+		return 0, nil
+	}
+
+	decayed := 0
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		now := s.now()
+		idle, err := s.ladder.ListIdle(ctx, now.Add(-seasons.DecayGrace))
+		if err != nil {
+			return err
+		}
+		for _, placement := range idle {
+			entry := placement.Entry
+			r, ok := entry.Decay(placement.Rating, now)
+			if !ok {
+				continue
+			}
+			if err := s.ratings.Save(ctx, &r); err != nil {
+				return err
+			}
+			if err := s.ladder.Save(ctx, &entry); err != nil {
+				return err
+			}
+			decayed++
+		}
+		return nil
+	})
+	return decayed, err
+}
+
+// Rollover ends the running season once it is over: every user gets their
+// final position, users with ranked fighters the reward of their best
+// division, and every rating is soft reset for the next season, which it
+// returns. It returns nil while the season is still running.
+func (s *Service) Rollover(ctx context.Context) (*seasons.Season, error) {
+	if s.seasons == nil {
+		return nil, nil
+	}
+
+	var next *seasons.Season
+	var issued []*rewards.Reward
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		next, issued = nil, nil
+		now := s.now()
+
+		season, err := s.seasons.Current(ctx)
+		if err != nil || season == nil || now.Before(season.EndDate) {
+			return err
+		}
+		claimed, err := s.seasons.Complete(ctx, season.ID, now)
+		if err != nil || !claimed {
+			return err
+		}
+
+		users, err := s.users.ListAll(ctx)
+		if err != nil {
+			return err
+		}
+		placements, err := s.ladder.ListAll(ctx)
+		if err != nil {
+			return err
+		}
+		userIDs := make([]int64, 0, len(users))
+		for _, user := range users {
+			userIDs = append(userIDs, user.ID)
+		}
+
+		for _, standing := range seasons.Standings(userIDs, placements) {
+			summary := &seasons.SeasonSummary{UserID: standing.UserID, SeasonID: season.SeasonID, Position: standing.Position}
+			if err := s.summaries.Create(ctx, summary); err != nil {
+				return err
+			}
+			if standing.Division == "" || s.rewards == nil {
+				continue
+			}
+			reward, err := s.rewards.GrantReward(ctx, standing.UserID, standing.Division.RewardPool())
+			if err != nil {
+				return err
+			}
+			issued = append(issued, reward)
+		}
+
+		for _, placement := range placements {
+			reset := seasons.SoftReset(placement.Rating)
+			if err := s.ratings.Save(ctx, &reset); err != nil {
+				return err
+			}
+			entry := placement.Entry
+			entry.Reset(reset, now)
+			if err := s.ladder.Save(ctx, &entry); err != nil {
+				return err
+			}
+		}
+
+		next, err = s.createSeason(ctx, season.SeasonID+1)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reward := range issued {
+		s.rewards.AnnounceReward(ctx, reward)
+	}
+	return next, nil
+}
+
+func (s *Service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
+	}
+	return s.uow.Do(ctx, fn)
+}
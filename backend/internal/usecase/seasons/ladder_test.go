@@ -0,0 +1,245 @@
+package seasons
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/identity"
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/rewards"
+	"empoweredpixels/internal/domain/seasons"
+)
+
+type memorySummaryRepo struct {
+	SummaryRepository
+	summaries []seasons.SeasonSummary
+}
+
+func (m *memorySummaryRepo) Create(ctx context.Context, summary *seasons.SeasonSummary) error {
+	m.summaries = append(m.summaries, *summary)
+	return nil
+}
+
+type memorySeasonRepo struct {
+	seasons []*seasons.Season
+}
+
+func (m *memorySeasonRepo) Current(ctx context.Context) (*seasons.Season, error) {
+	for i := len(m.seasons) - 1; i >= 0; i-- {
+		if m.seasons[i].Completed == nil {
+			copied := *m.seasons[i]
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memorySeasonRepo) LastSeasonID(ctx context.Context) (int, error) {
+	if len(m.seasons) == 0 {
+		return 0, nil
+	}
+	return m.seasons[len(m.seasons)-1].SeasonID, nil
+}
+
+func (m *memorySeasonRepo) Create(ctx context.Context, season *seasons.Season) error {
+	season.ID = int64(len(m.seasons) + 1)
+	copied := *season
+	m.seasons = append(m.seasons, &copied)
+	return nil
+}
+
+func (m *memorySeasonRepo) Complete(ctx context.Context, id int64, completed time.Time) (bool, error) {
+	for _, season := range m.seasons {
+		if season.ID == id && season.Completed == nil {
+			season.Completed = &completed
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// memoryLadder keeps the entries and the ratings they are joined with.
+type memoryLadder struct {
+	entries map[string]seasons.LadderEntry
+	ratings map[string]rating.Rating
+}
+
+func (m *memoryLadder) Get(ctx context.Context, fighterID string) (*seasons.Placement, error) {
+	entry, ok := m.entries[fighterID]
+	if !ok {
+		return nil, nil
+	}
+	return &seasons.Placement{Entry: entry, Rating: m.ratings[fighterID]}, nil
+}
+
+func (m *memoryLadder) ListTop(ctx context.Context, limit int, offset int) ([]seasons.Placement, error) {
+	return m.ListAll(ctx)
+}
+
+func (m *memoryLadder) ListAll(ctx context.Context) ([]seasons.Placement, error) {
+	var result []seasons.Placement
+	for id, entry := range m.entries {
+		result = append(result, seasons.Placement{Entry: entry, Rating: m.ratings[id]})
+	}
+	return result, nil
+}
+
+func (m *memoryLadder) ListIdle(ctx context.Context, before time.Time) ([]seasons.Placement, error) {
+	var result []seasons.Placement
+	for id, entry := range m.entries {
+		if entry.LastMatch.Before(before) {
+			result = append(result, seasons.Placement{Entry: entry, Rating: m.ratings[id]})
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryLadder) Save(ctx context.Context, entry *seasons.LadderEntry) error {
+	m.entries[entry.FighterID] = *entry
+	return nil
+}
+
+func (m *memoryLadder) SaveRating(ctx context.Context, r *rating.Rating) error {
+	m.ratings[r.FighterID] = *r
+	return nil
+}
+
+type ladderRatings struct {
+	ladder *memoryLadder
+}
+
+func (r ladderRatings) Save(ctx context.Context, stored *rating.Rating) error {
+	return r.ladder.SaveRating(ctx, stored)
+}
+
+type memoryUserRepo struct {
+	ids []int64
+}
+
+func (m *memoryUserRepo) ListAll(ctx context.Context) ([]identity.User, error) {
+	var users []identity.User
+	for _, id := range m.ids {
+		users = append(users, identity.User{ID: id})
+	}
+	return users, nil
+}
+
+type recordingRewards struct {
+	granted   map[int64]string
+	announced int
+}
+
+func (r *recordingRewards) GrantReward(ctx context.Context, userID int64, poolID string) (*rewards.Reward, error) {
+	r.granted[userID] = poolID
+	return &rewards.Reward{UserID: userID, RewardPoolID: poolID}, nil
+}
+
+func (r *recordingRewards) AnnounceReward(ctx context.Context, reward *rewards.Reward) {
+	r.announced++
+}
+
+func newLadderService(now time.Time, userIDs ...int64) (*Service, *memorySeasonRepo, *memoryLadder, *memorySummaryRepo, *recordingRewards) {
+	seasonRepo := &memorySeasonRepo{}
+	ladder := &memoryLadder{entries: make(map[string]seasons.LadderEntry), ratings: make(map[string]rating.Rating)}
+	summaries := &memorySummaryRepo{}
+	rewardService := &recordingRewards{granted: make(map[int64]string)}
+
+	svc := NewService(summaries)
+	svc.now = func() time.Time { return now }
+	svc.SetLadder(seasonRepo, ladder, ladderRatings{ladder}, &memoryUserRepo{ids: userIDs}, rewardService, nil)
+	return svc, seasonRepo, ladder, summaries, rewardService
+}
+
+func TestService_RecordMatchPlacesAndPromotes(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc, _, ladder, _, _ := newLadderService(now)
+	ctx := context.Background()
+
+	newcomer := rating.Rating{FighterID: "a", Rating: 1550, Deviation: 300}
+	ladder.ratings["a"] = newcomer
+	if err := svc.RecordMatch(ctx, 1, newcomer, rating.Win); err != nil {
+		t.Fatal(err)
+	}
+	if entry := ladder.entries["a"]; entry.Division != seasons.DivisionBronze || entry.UserID != 1 || entry.Series != nil {
+		t.Fatalf("expected the newcomer placed in Bronze, got %+v", entry)
+	}
+
+	settled := rating.Rating{FighterID: "a", Rating: 1400, Deviation: 100}
+	ladder.ratings["a"] = settled
+	for i := 0; i < 3; i++ {
+		if err := svc.RecordMatch(ctx, 1, settled, rating.Win); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if entry := ladder.entries["a"]; entry.Division != seasons.DivisionSilver || entry.Series != nil {
+		t.Fatalf("expected the won series to promote to Silver, got %+v", entry)
+	}
+}
+
+func TestService_Rollover(t *testing.T) {
+	end := time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)
+	svc, seasonRepo, ladder, summaries, rewardService := newLadderService(end.Add(time.Minute), 1, 2, 3)
+	ctx := context.Background()
+	_ = seasonRepo.Create(ctx, &seasons.Season{SeasonID: 4, StartDate: end.Add(-seasons.SeasonLength), EndDate: end})
+
+	ladder.entries["a"] = seasons.LadderEntry{FighterID: "a", UserID: 1, Division: seasons.DivisionGold}
+	ladder.ratings["a"] = rating.Rating{FighterID: "a", Rating: 1500, Deviation: 80}
+	ladder.entries["b"] = seasons.LadderEntry{FighterID: "b", UserID: 2, Division: seasons.DivisionMythic, Series: &seasons.Series{Kind: seasons.SeriesDemotion}}
+	ladder.ratings["b"] = rating.Rating{FighterID: "b", Rating: 2300, Deviation: 40}
+
+	next, err := svc.Rollover(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == nil || next.SeasonID != 5 {
+		t.Fatalf("expected season 5 to start, got %+v", next)
+	}
+
+	positions := make(map[int64]int)
+	for _, summary := range summaries.summaries {
+		if summary.SeasonID != 4 {
+			t.Fatalf("expected summaries of season 4, got %+v", summary)
+		}
+		positions[summary.UserID] = summary.Position
+	}
+	if positions[2] != 1 || positions[1] != 2 || positions[3] != 3 {
+		t.Fatalf("expected every user ranked, got %v", positions)
+	}
+
+	if rewardService.granted[2] != "season_mythic" || rewardService.granted[1] != "season_gold" || len(rewardService.granted) != 2 {
+		t.Fatalf("expected rewards by division for ranked users, got %v", rewardService.granted)
+	}
+	if rewardService.announced != 2 {
+		t.Fatalf("expected the rewards announced, got %d", rewardService.announced)
+	}
+
+	if b := ladder.ratings["b"]; b.Rating != 1900 || b.Deviation != seasons.SoftResetDeviation {
+		t.Fatalf("expected a soft reset rating, got %+v", b)
+	}
+	if b := ladder.entries["b"]; b.Division != seasons.DivisionPlatinum || b.Series != nil {
+		t.Fatalf("expected the entry placed by the reset rating, got %+v", b)
+	}
+
+	// The next season is still running
+	if again, err := svc.Rollover(ctx); err != nil || again != nil {
+		t.Fatalf("expected no second rollover, got %+v, %v", again, err)
+	}
+}
+
+func TestService_StartSeason(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc, seasonRepo, _, _, _ := newLadderService(now)
+
+	first, err := svc.StartSeason(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.SeasonID != 1 || !first.EndDate.Equal(now.Add(seasons.SeasonLength)) {
+		t.Fatalf("unexpected first season %+v", first)
+	}
+	again, _ := svc.StartSeason(context.Background())
+	if again.ID != first.ID || len(seasonRepo.seasons) != 1 {
+		t.Fatal("expected the running season to be kept")
+	}
+}
@@ -2,16 +2,24 @@ package seasons
 
 import (
 	"context"
+	"time"
 
 	"empoweredpixels/internal/domain/seasons"
 )
 
 type Service struct {
 	summaries SummaryRepository
+	seasons   SeasonRepository
+	ladder    LadderRepository
+	ratings   RatingRepository
+	users     UserRepository
+	rewards   RewardService
+	uow       UnitOfWork
+	now       func() time.Time
 }
 
 func NewService(summaries SummaryRepository) *Service {
-	return &Service{summaries: summaries}
+	return &Service{summaries: summaries, now: time.Now}
 }
 
 func (s *Service) SummaryPage(ctx context.Context, userID int64, page int, pageSize int) ([]seasons.SeasonSummary, error) {
@@ -0,0 +1,50 @@
+package standings
+
+import (
+	"context"
+	"time"
+
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/seasons"
+)
+
+type StandingRepository interface {
+	Add(ctx context.Context, standing *leagues.Standing) error
+	AddResult(ctx context.Context, result *leagues.MatchResult) error
+	ListByLeague(ctx context.Context, leagueID int, season int) ([]leagues.Standing, error)
+	Highscores(ctx context.Context, leagueID int, lastMatches int) ([]leagues.Standing, error)
+	ListSeasons(ctx context.Context, leagueID int) ([]int, error)
+	PointsByUser(ctx context.Context, season int) ([]leagues.UserPoints, error)
+}
+
+type LeagueRepository interface {
+	GetByID(ctx context.Context, id int) (*leagues.League, error)
+}
+
+// LeagueMatchRepository finds the league of a match and claims scoring it.
+type LeagueMatchRepository interface {
+	GetByMatch(ctx context.Context, matchID string) (*leagues.LeagueMatch, error)
+	ClaimScoring(ctx context.Context, leagueID int, matchID string, at time.Time) (bool, error)
+}
+
+type FighterRepository interface {
+	GetByID(ctx context.Context, id string) (*roster.Fighter, error)
+}
+
+// MatchService reads the results of finished matches.
+type MatchService interface {
+	BattleSummary(ctx context.Context, matchID string) (*matches.BattleSummary, error)
+}
+
+// SeasonService tells the ranked season standings are kept for.
+type SeasonService interface {
+	CurrentSeason(ctx context.Context) (*seasons.Season, error)
+}
+
+// UnitOfWork runs fn in a transaction. Repositories called with the context
+// fn receives take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
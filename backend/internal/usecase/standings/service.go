@@ -0,0 +1,240 @@
+package standings
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"empoweredpixels/internal/domain/leagues"
+	seasonsusecase "empoweredpixels/internal/usecase/seasons"
+)
+
+var ErrInvalidLeague = errors.New("invalid league")
+
+// DefaultPageSize is the page size of a table requested without one.
+const DefaultPageSize = 25
+
+type Service struct {
+	standings     StandingRepository
+	leagues       LeagueRepository
+	leagueMatches LeagueMatchRepository
+	fighters      FighterRepository
+	matches       MatchService
+	seasons       SeasonService
+	uow           UnitOfWork
+	now           func() time.Time
+}
+
+func NewService(
+	standings StandingRepository,
+	leagues LeagueRepository,
+	leagueMatches LeagueMatchRepository,
+	fighters FighterRepository,
+	matchService MatchService,
+	uow UnitOfWork,
+	now func() time.Time,
+) *Service {
+	if now == nil {
+		now = time.Now
+	}
+	return &Service{
+		standings:     standings,
+		leagues:       leagues,
+		leagueMatches: leagueMatches,
+		fighters:      fighters,
+		matches:       matchService,
+		uow:           uow,
+		now:           now,
+	}
+}
+
+// SetSeasons keeps the standings per ranked season. Without it every match
+// counts towards season 0.
+func (s *Service) SetSeasons(seasons SeasonService) {
+	s.seasons = seasons
+}
+
+// Table is a page of the standings of a league season.
+type Table struct {
+	Season     int
+	TotalCount int
+	Standings  []leagues.Standing
+}
+
+// MatchFinished adds the result of a finished league match to the standings
+// of the current season. Matches outside leagues are ignored and every match
+// is scored once.
+func (s *Service) MatchFinished(ctx context.Context, matchID string) {
+	_ = s.score(ctx, matchID)
+}
+
+func (s *Service) score(ctx context.Context, matchID string) error {
+	leagueMatch, err := s.leagueMatches.GetByMatch(ctx, matchID)
+	if err != nil || leagueMatch == nil {
+		return err
+	}
+	league, err := s.leagues.GetByID(ctx, leagueMatch.LeagueID)
+	if err != nil || league == nil {
+		return err
+	}
+	summary, err := s.matches.BattleSummary(ctx, matchID)
+	if err != nil {
+		return err
+	}
+	season, err := s.currentSeason(ctx)
+	if err != nil {
+		return err
+	}
+
+	results := leagues.Results(league.Config, summary.WinnerIDs, summary.Scores)
+	owners := make(map[string]int64, len(results))
+	for _, r := range results {
+		fighter, err := s.fighters.GetByID(ctx, r.FighterID)
+		if err != nil {
+			return err
+		}
+		if fighter != nil {
+			owners[r.FighterID] = fighter.UserID
+		}
+	}
+
+	now := s.now()
+	return s.inTransaction(ctx, func(ctx context.Context) error {
+		claimed, err := s.leagueMatches.ClaimScoring(ctx, league.ID, matchID, now)
+		if err != nil || !claimed {
+			return err
+		}
+		for _, r := range results {
+			userID, ok := owners[r.FighterID]
+			if !ok {
+				continue
+			}
+			result := leagues.MatchResult{LeagueID: league.ID, MatchID: matchID, UserID: userID, Result: r}
+			if err := s.standings.AddResult(ctx, &result); err != nil {
+				return err
+			}
+			standing := leagues.NewStanding(league.ID, season, userID, r, now)
+			if err := s.standings.Add(ctx, &standing); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// currentSeason returns the running ranked season, or 0 when none runs.
+func (s *Service) currentSeason(ctx context.Context) (int, error) {
+	if s.seasons == nil {
+		return 0, nil
+	}
+	season, err := s.seasons.CurrentSeason(ctx)
+	if errors.Is(err, seasonsusecase.ErrNoSeason) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return season.SeasonID, nil
+}
+
+// Table returns a page of the ranked standings of a league season, of the
+// current season when season is nil.
+func (s *Service) Table(ctx context.Context, leagueID int, season *int, page int, pageSize int) (*Table, error) {
+	league, err := s.leagues.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if league == nil {
+		return nil, ErrInvalidLeague
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	table := Table{}
+	if season != nil {
+		table.Season = *season
+	} else if table.Season, err = s.currentSeason(ctx); err != nil {
+		return nil, err
+	}
+
+	standings, err := s.standings.ListByLeague(ctx, leagueID, table.Season)
+	if err != nil {
+		return nil, err
+	}
+	leagues.Rank(standings)
+	table.TotalCount = len(standings)
+
+	from := (page - 1) * pageSize
+	if from > len(standings) {
+		from = len(standings)
+	}
+	to := from + pageSize
+	if to > len(standings) {
+		to = len(standings)
+	}
+	table.Standings = standings[from:to]
+	return &table, nil
+}
+
+// Seasons returns the seasons a league has standings for, latest first.
+func (s *Service) Seasons(ctx context.Context, leagueID int) ([]int, error) {
+	league, err := s.leagues.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if league == nil {
+		return nil, ErrInvalidLeague
+	}
+	return s.standings.ListSeasons(ctx, leagueID)
+}
+
+// Highscores ranks the fighters of a league by what they scored over its
+// latest lastMatches matches, or by the table of the current season when
+// lastMatches is 0.
+func (s *Service) Highscores(ctx context.Context, leagueID int, lastMatches int, limit int) ([]leagues.Standing, error) {
+	if lastMatches <= 0 {
+		table, err := s.Table(ctx, leagueID, nil, 1, limit)
+		if err != nil {
+			return nil, err
+		}
+		return table.Standings, nil
+	}
+
+	league, err := s.leagues.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if league == nil {
+		return nil, ErrInvalidLeague
+	}
+	standings, err := s.standings.Highscores(ctx, leagueID, lastMatches)
+	if err != nil {
+		return nil, err
+	}
+	leagues.Rank(standings)
+	if limit > 0 && len(standings) > limit {
+		standings = standings[:limit]
+	}
+	return standings, nil
+}
+
+// UserPoints returns the league points every user scored over all leagues
+// in the current season, most first.
+func (s *Service) UserPoints(ctx context.Context) ([]leagues.UserPoints, error) {
+	season, err := s.currentSeason(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.standings.PointsByUser(ctx, season)
+}
+
+func (s *Service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
+	}
+	return s.uow.Do(ctx, fn)
+}
@@ -0,0 +1,180 @@
+package standings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"empoweredpixels/internal/domain/combat"
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/seasons"
+	seasonsusecase "empoweredpixels/internal/usecase/seasons"
+)
+
+type standingKey struct {
+	league int
+	season int
+	id     string
+}
+
+type memoryStandings struct {
+	StandingRepository
+	lines   map[standingKey]*leagues.Standing
+	results []leagues.MatchResult
+}
+
+func (m *memoryStandings) Add(ctx context.Context, s *leagues.Standing) error {
+	key := standingKey{s.LeagueID, s.Season, s.FighterID}
+	line, ok := m.lines[key]
+	if !ok {
+		copied := *s
+		m.lines[key] = &copied
+		return nil
+	}
+	line.Points += s.Points
+	line.Matches += s.Matches
+	line.Wins += s.Wins
+	line.Kills += s.Kills
+	line.Deaths += s.Deaths
+	line.DamageDealt += s.DamageDealt
+	line.Updated = s.Updated
+	return nil
+}
+
+func (m *memoryStandings) AddResult(ctx context.Context, r *leagues.MatchResult) error {
+	m.results = append(m.results, *r)
+	return nil
+}
+
+func (m *memoryStandings) ListByLeague(ctx context.Context, leagueID int, season int) ([]leagues.Standing, error) {
+	var result []leagues.Standing
+	for key, line := range m.lines {
+		if key.league == leagueID && key.season == season {
+			result = append(result, *line)
+		}
+	}
+	return result, nil
+}
+
+type memoryLeagues struct{}
+
+func (memoryLeagues) GetByID(ctx context.Context, id int) (*leagues.League, error) {
+	if id != 1 {
+		return nil, nil
+	}
+	return &leagues.League{ID: 1, Config: leagues.Config{PlacementPoints: []int{5, 3}, KillPoints: 1}}, nil
+}
+
+type memoryLeagueMatches struct {
+	scored map[string]bool
+}
+
+func (m *memoryLeagueMatches) GetByMatch(ctx context.Context, matchID string) (*leagues.LeagueMatch, error) {
+	if matchID == "friendly" {
+		return nil, nil
+	}
+	return &leagues.LeagueMatch{LeagueID: 1, MatchID: matchID}, nil
+}
+
+func (m *memoryLeagueMatches) ClaimScoring(ctx context.Context, leagueID int, matchID string, at time.Time) (bool, error) {
+	if m.scored[matchID] {
+		return false, nil
+	}
+	m.scored[matchID] = true
+	return true, nil
+}
+
+type memoryFighters struct{}
+
+func (memoryFighters) GetByID(ctx context.Context, id string) (*roster.Fighter, error) {
+	owners := map[string]int64{"a": 1, "b": 2, "c": 3}
+	return &roster.Fighter{ID: id, UserID: owners[id]}, nil
+}
+
+type memoryMatches struct {
+	summaries map[string]*matches.BattleSummary
+}
+
+func (m *memoryMatches) BattleSummary(ctx context.Context, matchID string) (*matches.BattleSummary, error) {
+	return m.summaries[matchID], nil
+}
+
+type memorySeasons struct {
+	season *seasons.Season
+}
+
+func (m *memorySeasons) CurrentSeason(ctx context.Context) (*seasons.Season, error) {
+	if m.season == nil {
+		return nil, seasonsusecase.ErrNoSeason
+	}
+	return m.season, nil
+}
+
+func summary(winner string, kills map[string]int) *matches.BattleSummary {
+	s := &matches.BattleSummary{WinnerIDs: []string{winner}}
+	for _, id := range []string{"a", "b", "c"} {
+		s.Scores = append(s.Scores, combat.FighterScore{FighterID: id, Kills: kills[id]})
+	}
+	return s
+}
+
+func TestMatchFinished_ScoresOncePerSeason(t *testing.T) {
+	ctx := context.Background()
+	standings := &memoryStandings{lines: map[standingKey]*leagues.Standing{}}
+	matchService := &memoryMatches{summaries: map[string]*matches.BattleSummary{
+		"m1": summary("a", map[string]int{"a": 2}),
+		"m2": summary("b", map[string]int{"b": 1, "c": 1}),
+		"m3": summary("c", nil),
+	}}
+	seasonService := &memorySeasons{season: &seasons.Season{SeasonID: 4}}
+	service := NewService(standings, memoryLeagues{}, &memoryLeagueMatches{scored: map[string]bool{}}, memoryFighters{}, matchService, nil, nil)
+	service.SetSeasons(seasonService)
+
+	service.MatchFinished(ctx, "m1")
+	service.MatchFinished(ctx, "m1")
+	service.MatchFinished(ctx, "m2")
+	service.MatchFinished(ctx, "friendly")
+
+	table, err := service.Table(ctx, 1, nil, 1, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// a: 5+2 and 0, b: 3 and 5+1, c: 0 and 3+1
+	want := []struct {
+		id      string
+		points  int
+		matches int
+	}{{"b", 9, 2}, {"a", 7, 2}, {"c", 4, 2}}
+	if table.Season != 4 || table.TotalCount != 3 {
+		t.Fatalf("expected three fighters in season 4, got %+v", table)
+	}
+	for i, w := range want {
+		s := table.Standings[i]
+		if s.FighterID != w.id || s.Points != w.points || s.Matches != w.matches || s.Position != i+1 {
+			t.Fatalf("expected %s with %d points over %d matches at %d, got %+v", w.id, w.points, w.matches, i+1, table.Standings)
+		}
+	}
+	if len(standings.results) != 6 {
+		t.Fatalf("expected the results of two matches, got %+v", standings.results)
+	}
+
+	// Without a running season the match counts towards season 0
+	seasonService.season = nil
+	service.MatchFinished(ctx, "m3")
+	history := 4
+	if table, _ = service.Table(ctx, 1, &history, 1, 0); table.Standings[2].Points != 4 {
+		t.Fatalf("expected season 4 left alone, got %+v", table.Standings)
+	}
+	if table, _ = service.Table(ctx, 1, nil, 1, 1); table.Season != 0 || table.TotalCount != 3 || len(table.Standings) != 1 || table.Standings[0].FighterID != "c" {
+		t.Fatalf("expected the first page of season 0 led by c, got %+v", table)
+	}
+}
+
+func TestTable_InvalidLeague(t *testing.T) {
+	service := NewService(&memoryStandings{}, memoryLeagues{}, nil, nil, nil, nil, nil)
+	if _, err := service.Table(context.Background(), 2, nil, 1, 0); err != ErrInvalidLeague {
+		t.Fatalf("expected ErrInvalidLeague, got %v", err)
+	}
+}
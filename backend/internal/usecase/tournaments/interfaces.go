@@ -0,0 +1,73 @@
+package tournaments
+
+import (
+	"context"
+
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/rewards"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/tournaments"
+	matchesusecase "empoweredpixels/internal/usecase/matches"
+)
+
+type TournamentRepository interface {
+	Create(ctx context.Context, t *tournaments.Tournament) error
+	Get(ctx context.Context, id string) (*tournaments.Tournament, error)
+	Lock(ctx context.Context, id string) (*tournaments.Tournament, error)
+	Update(ctx context.Context, t *tournaments.Tournament) error
+	GetRunningByLeague(ctx context.Context, leagueID int) (*tournaments.Tournament, error)
+	ListByLeague(ctx context.Context, leagueID int, limit int, offset int) ([]tournaments.Tournament, error)
+	CreateEntrants(ctx context.Context, entrants []tournaments.Entrant) error
+	ListEntrants(ctx context.Context, tournamentID string) ([]tournaments.Entrant, error)
+	UpdateEntrant(ctx context.Context, entrant *tournaments.Entrant) error
+	CreatePairing(ctx context.Context, pairing *tournaments.Pairing) error
+	UpdatePairing(ctx context.Context, pairing *tournaments.Pairing) error
+	ListPairings(ctx context.Context, tournamentID string) ([]tournaments.Pairing, error)
+	GetPairingByMatch(ctx context.Context, matchID string) (*tournaments.Pairing, error)
+	ListRunningPairings(ctx context.Context) ([]tournaments.Pairing, error)
+}
+
+type LeagueRepository interface {
+	GetByID(ctx context.Context, id int) (*leagues.League, error)
+}
+
+type SubscriptionRepository interface {
+	ListByLeague(ctx context.Context, leagueID int) ([]leagues.LeagueSubscription, error)
+}
+
+// LeagueMatchRepository records tournament matches as matches of their
+// league.
+type LeagueMatchRepository interface {
+	Create(ctx context.Context, leagueID int, matchID string) error
+}
+
+type FighterRepository interface {
+	GetByID(ctx context.Context, id string) (*roster.Fighter, error)
+}
+
+type RatingRepository interface {
+	Get(ctx context.Context, fighterID string) (*rating.Rating, error)
+}
+
+// MatchService creates and runs the matches of the pairings.
+type MatchService interface {
+	DefaultOptions() matchesusecase.MatchOptions
+	CreateTeamMatchFor(ctx context.Context, options matchesusecase.MatchOptions, teams [][]string) (*matches.Match, error)
+	StartMatch(ctx context.Context, matchID string) error
+	GetMatch(ctx context.Context, id string) (*matches.Match, error)
+	BattleSummary(ctx context.Context, matchID string) (*matches.BattleSummary, error)
+}
+
+// RewardService issues the placement rewards.
+type RewardService interface {
+	GrantReward(ctx context.Context, userID int64, poolID string) (*rewards.Reward, error)
+	AnnounceReward(ctx context.Context, reward *rewards.Reward)
+}
+
+// UnitOfWork runs fn in a transaction. Repositories called with the context
+// fn receives take part in it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,461 @@
+package tournaments
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/rewards"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/tournaments"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidLeague     = errors.New("invalid league")
+	ErrInvalidFormat     = errors.New("invalid tournament format")
+	ErrInvalidTournament = errors.New("invalid tournament")
+	ErrTournamentRunning = errors.New("league already runs a tournament")
+	ErrNotEnoughEntrants = errors.New("not enough fighters for a tournament")
+)
+
+type Service struct {
+	tournaments   TournamentRepository
+	leagues       LeagueRepository
+	subscriptions SubscriptionRepository
+	leagueMatches LeagueMatchRepository
+	fighters      FighterRepository
+	ratings       RatingRepository
+	matches       MatchService
+	rewards       RewardService
+	uow           UnitOfWork
+	now           func() time.Time
+}
+
+func NewService(
+	tournamentRepo TournamentRepository,
+	leagues LeagueRepository,
+	subscriptions SubscriptionRepository,
+	leagueMatches LeagueMatchRepository,
+	fighters FighterRepository,
+	ratings RatingRepository,
+	matchService MatchService,
+	rewardService RewardService,
+	uow UnitOfWork,
+	now func() time.Time,
+) *Service {
+	if now == nil {
+		now = time.Now
+	}
+	return &Service{
+		tournaments:   tournamentRepo,
+		leagues:       leagues,
+		subscriptions: subscriptions,
+		leagueMatches: leagueMatches,
+		fighters:      fighters,
+		ratings:       ratings,
+		matches:       matchService,
+		rewards:       rewardService,
+		uow:           uow,
+		now:           now,
+	}
+}
+
+// Bracket is the state of a tournament: its entrants by seed and every
+// pairing so far in bracket order.
+type Bracket struct {
+	Tournament tournaments.Tournament
+	Entrants   []tournaments.Entrant
+	Pairings   []tournaments.Pairing
+}
+
+// Start starts a tournament between the fighters subscribed to the league,
+// seeded by their rating, and starts the matches of its first round.
+func (s *Service) Start(ctx context.Context, leagueID int, format tournaments.Format) (*tournaments.Tournament, error) {
+	if !format.Valid() {
+		return nil, ErrInvalidFormat
+	}
+
+	var t *tournaments.Tournament
+	var started []string
+	var issued []*rewards.Reward
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		t, started, issued = nil, nil, nil
+
+		league, err := s.leagues.GetByID(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		if league == nil {
+			return ErrInvalidLeague
+		}
+		running, err := s.tournaments.GetRunningByLeague(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		if running != nil {
+			return ErrTournamentRunning
+		}
+
+		entrants, err := s.entrants(ctx, *league)
+		if err != nil {
+			return err
+		}
+		minimum := tournaments.MinEntrants
+		if league.Config.MinFighters > minimum {
+			minimum = league.Config.MinFighters
+		}
+		if len(entrants) < minimum {
+			return ErrNotEnoughEntrants
+		}
+
+		t = &tournaments.Tournament{
+			ID:       uuid.NewString(),
+			LeagueID: leagueID,
+			Format:   format,
+			Status:   tournaments.StatusRunning,
+			Created:  s.now(),
+		}
+		if format == tournaments.FormatSwiss {
+			t.Rounds = tournaments.SwissRounds(len(entrants))
+		}
+		tournaments.Seed(entrants)
+		for i := range entrants {
+			entrants[i].TournamentID = t.ID
+		}
+
+		if err := s.tournaments.Create(ctx, t); err != nil {
+			return err
+		}
+		if err := s.tournaments.CreateEntrants(ctx, entrants); err != nil {
+			return err
+		}
+		started, issued, err = s.advance(ctx, t, entrants, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.afterCommit(ctx, started, issued)
+	return t, nil
+}
+
+// entrants returns the teams of the league's subscribed fighters with their
+// conservative rating. A team is entered by its first fighter, keeps its
+// members for the whole tournament and is rated by the average of its
+// fighters; without teams every fighter enters alone.
+func (s *Service) entrants(ctx context.Context, league leagues.League) ([]tournaments.Entrant, error) {
+	fighters, err := s.subscribed(ctx, league.ID)
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string]int64, len(fighters))
+	for _, f := range fighters {
+		users[f.ID] = f.UserID
+	}
+
+	var entrants []tournaments.Entrant
+	for _, team := range league.Config.Teams(fighters) {
+		total := 0.0
+		for _, fighterID := range team {
+			current := rating.New(fighterID)
+			if s.ratings != nil {
+				stored, err := s.ratings.Get(ctx, fighterID)
+				if err != nil {
+					return nil, err
+				}
+				if stored != nil {
+					current = *stored
+				}
+			}
+			total += current.Conservative()
+		}
+		entrants = append(entrants, tournaments.Entrant{
+			FighterID: team[0],
+			UserID:    users[team[0]],
+			Rating:    total / float64(len(team)),
+			Members:   team,
+		})
+	}
+	return entrants, nil
+}
+
+// subscribed returns the subscribed fighters of a league that still exist,
+// in the order they subscribed.
+func (s *Service) subscribed(ctx context.Context, leagueID int) ([]roster.Fighter, error) {
+	subs, err := s.subscriptions.ListByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fighters []roster.Fighter
+	for _, sub := range subs {
+		fighter, err := s.fighters.GetByID(ctx, sub.FighterID)
+		if err != nil {
+			return nil, err
+		}
+		if fighter == nil || fighter.IsDeleted {
+			continue
+		}
+		fighters = append(fighters, *fighter)
+	}
+	return fighters, nil
+}
+
+// advance pairs the next round once the current one is done. Rounds of
+// nothing but byes are counted right away. A decided tournament is completed
+// instead. It returns the matches to start and the rewards to announce once
+// the transaction committed.
+func (s *Service) advance(ctx context.Context, t *tournaments.Tournament, entrants []tournaments.Entrant, played []tournaments.Pairing) ([]string, []*rewards.Reward, error) {
+	byID := make(map[string]*tournaments.Entrant, len(entrants))
+	for i := range entrants {
+		byID[entrants[i].FighterID] = &entrants[i]
+	}
+
+	options := s.matches.DefaultOptions()
+	options.IsPrivate = true
+
+	var started []string
+	for {
+		round := tournaments.Pair(*t, entrants, played)
+		if round == nil {
+			issued, err := s.complete(ctx, t, entrants, played)
+			return started, issued, err
+		}
+		t.Round++
+
+		for i := range round {
+			p := &round[i]
+			p.ID = uuid.NewString()
+			p.TournamentID = t.ID
+
+			if p.Status == tournaments.PairingDone {
+				tournaments.Apply(t.Format, *p, byID)
+				if err := s.tournaments.UpdateEntrant(ctx, byID[p.FighterA]); err != nil {
+					return nil, nil, err
+				}
+			} else {
+				match, err := s.matches.CreateTeamMatchFor(ctx, options, [][]string{byID[p.FighterA].Team(), byID[*p.FighterB].Team()})
+				if err != nil {
+					return nil, nil, err
+				}
+				if err := s.leagueMatches.Create(ctx, t.LeagueID, match.ID); err != nil {
+					return nil, nil, err
+				}
+				p.MatchID = &match.ID
+				started = append(started, match.ID)
+			}
+
+			if err := s.tournaments.CreatePairing(ctx, p); err != nil {
+				return nil, nil, err
+			}
+			played = append(played, *p)
+		}
+
+		if len(started) > 0 {
+			return started, nil, s.tournaments.Update(ctx, t)
+		}
+	}
+}
+
+// complete places the entrants and rewards the best placed ones.
+func (s *Service) complete(ctx context.Context, t *tournaments.Tournament, entrants []tournaments.Entrant, played []tournaments.Pairing) ([]*rewards.Reward, error) {
+	tournaments.Place(t.Format, entrants, played)
+
+	var issued []*rewards.Reward
+	for i := range entrants {
+		e := &entrants[i]
+		if err := s.tournaments.UpdateEntrant(ctx, e); err != nil {
+			return nil, err
+		}
+		pool := tournaments.RewardPool(e.Placement)
+		if pool == "" || s.rewards == nil {
+			continue
+		}
+		reward, err := s.rewards.GrantReward(ctx, e.UserID, pool)
+		if err != nil {
+			return nil, err
+		}
+		issued = append(issued, reward)
+	}
+
+	completed := s.now()
+	t.Status = tournaments.StatusCompleted
+	t.Completed = &completed
+	return issued, s.tournaments.Update(ctx, t)
+}
+
+// MatchFinished counts the result of a finished tournament match and
+// advances its tournament. Matches of no tournament are ignored. Results
+// that can't be counted now are picked up by Sweep.
+func (s *Service) MatchFinished(ctx context.Context, matchID string) {
+	pairing, err := s.tournaments.GetPairingByMatch(ctx, matchID)
+	if err != nil || pairing == nil || pairing.Status == tournaments.PairingDone {
+		return
+	}
+	summary, err := s.matches.BattleSummary(ctx, matchID)
+	if err != nil {
+		return
+	}
+	_ = s.settle(ctx, pairing.TournamentID, pairing.ID, summary.WinnerIDs)
+}
+
+// Sweep settles the pairings whose matches finished without the result
+// being counted, scores cancelled matches without winners and starts the
+// matches a crash left in their lobby. It returns how many pairings it
+// settled.
+func (s *Service) Sweep(ctx context.Context) (int, error) {
+	pairings, err := s.tournaments.ListRunningPairings(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, p := range pairings {
+		var match *matches.Match
+		if p.MatchID != nil {
+			if match, err = s.matches.GetMatch(ctx, *p.MatchID); err != nil {
+				return settled, err
+			}
+		}
+
+		switch {
+		case match == nil || match.Status == matches.MatchStatusCancelled:
+			// Without a match, elimination goes to the better seed and
+			// Swiss scores a draw
+			if err := s.settle(ctx, p.TournamentID, p.ID, nil); err != nil {
+				return settled, err
+			}
+			settled++
+		case match.Status == matches.MatchStatusCompleted:
+			summary, err := s.matches.BattleSummary(ctx, match.ID)
+			if err != nil {
+				return settled, err
+			}
+			if err := s.settle(ctx, p.TournamentID, p.ID, summary.WinnerIDs); err != nil {
+				return settled, err
+			}
+			settled++
+		case match.Status == matches.MatchStatusLobby:
+			_ = s.matches.StartMatch(ctx, match.ID)
+		}
+	}
+	return settled, nil
+}
+
+// settle counts the result of a pairing and advances the tournament once
+// its round is done. Settling a pairing again changes nothing.
+func (s *Service) settle(ctx context.Context, tournamentID string, pairingID string, winners []string) error {
+	var started []string
+	var issued []*rewards.Reward
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		started, issued = nil, nil
+
+		t, err := s.tournaments.Lock(ctx, tournamentID)
+		if err != nil || t == nil || t.Status != tournaments.StatusRunning {
+			return err
+		}
+		played, err := s.tournaments.ListPairings(ctx, tournamentID)
+		if err != nil {
+			return err
+		}
+		var pairing *tournaments.Pairing
+		for i := range played {
+			if played[i].ID == pairingID {
+				pairing = &played[i]
+			}
+		}
+		if pairing == nil || pairing.Status == tournaments.PairingDone {
+			return nil
+		}
+
+		entrants, err := s.tournaments.ListEntrants(ctx, tournamentID)
+		if err != nil {
+			return err
+		}
+		byID := make(map[string]*tournaments.Entrant, len(entrants))
+		for i := range entrants {
+			byID[entrants[i].FighterID] = &entrants[i]
+		}
+
+		pairing.WinnerID = tournaments.Decide(t.Format, *pairing, winners, byID)
+		pairing.Status = tournaments.PairingDone
+		tournaments.Apply(t.Format, *pairing, byID)
+		if err := s.tournaments.UpdatePairing(ctx, pairing); err != nil {
+			return err
+		}
+		for _, id := range []string{pairing.FighterA, *pairing.FighterB} {
+			if err := s.tournaments.UpdateEntrant(ctx, byID[id]); err != nil {
+				return err
+			}
+		}
+
+		for _, p := range played {
+			if p.Round == t.Round && p.Status != tournaments.PairingDone {
+				return nil
+			}
+		}
+		started, issued, err = s.advance(ctx, t, entrants, played)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.afterCommit(ctx, started, issued)
+	return nil
+}
+
+// afterCommit starts the matches of a new round and announces the rewards of
+// a completed tournament. A match that fails to start is started by Sweep.
+func (s *Service) afterCommit(ctx context.Context, started []string, issued []*rewards.Reward) {
+	for _, matchID := range started {
+		_ = s.matches.StartMatch(ctx, matchID)
+	}
+	for _, reward := range issued {
+		s.rewards.AnnounceReward(ctx, reward)
+	}
+}
+
+// Bracket returns the state of a tournament.
+func (s *Service) Bracket(ctx context.Context, tournamentID string) (*Bracket, error) {
+	t, err := s.tournaments.Get(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrInvalidTournament
+	}
+	entrants, err := s.tournaments.ListEntrants(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	pairings, err := s.tournaments.ListPairings(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return &Bracket{Tournament: *t, Entrants: entrants, Pairings: pairings}, nil
+}
+
+// History returns a page of the league's tournaments, the latest first.
+func (s *Service) History(ctx context.Context, leagueID int, page int, pageSize int) ([]tournaments.Tournament, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	return s.tournaments.ListByLeague(ctx, leagueID, pageSize, (page-1)*pageSize)
+}
+
+func (s *Service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
+	}
+	return s.uow.Do(ctx, fn)
+}
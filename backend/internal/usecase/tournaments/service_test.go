@@ -0,0 +1,437 @@
+package tournaments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"empoweredpixels/internal/domain/leagues"
+	"empoweredpixels/internal/domain/matches"
+	"empoweredpixels/internal/domain/rating"
+	"empoweredpixels/internal/domain/rewards"
+	"empoweredpixels/internal/domain/roster"
+	"empoweredpixels/internal/domain/tournaments"
+	matchesusecase "empoweredpixels/internal/usecase/matches"
+)
+
+type mockTournamentRepo struct {
+	tournaments map[string]tournaments.Tournament
+	entrants    map[string][]tournaments.Entrant
+	pairings    []tournaments.Pairing
+}
+
+func newMockTournamentRepo() *mockTournamentRepo {
+	return &mockTournamentRepo{
+		tournaments: make(map[string]tournaments.Tournament),
+		entrants:    make(map[string][]tournaments.Entrant),
+	}
+}
+
+func (m *mockTournamentRepo) Create(ctx context.Context, t *tournaments.Tournament) error {
+	m.tournaments[t.ID] = *t
+	return nil
+}
+
+func (m *mockTournamentRepo) Get(ctx context.Context, id string) (*tournaments.Tournament, error) {
+	t, ok := m.tournaments[id]
+	if !ok {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (m *mockTournamentRepo) Lock(ctx context.Context, id string) (*tournaments.Tournament, error) {
+	return m.Get(ctx, id)
+}
+
+func (m *mockTournamentRepo) Update(ctx context.Context, t *tournaments.Tournament) error {
+	m.tournaments[t.ID] = *t
+	return nil
+}
+
+func (m *mockTournamentRepo) GetRunningByLeague(ctx context.Context, leagueID int) (*tournaments.Tournament, error) {
+	for _, t := range m.tournaments {
+		if t.LeagueID == leagueID && t.Status == tournaments.StatusRunning {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockTournamentRepo) ListByLeague(ctx context.Context, leagueID int, limit int, offset int) ([]tournaments.Tournament, error) {
+	var result []tournaments.Tournament
+	for _, t := range m.tournaments {
+		if t.LeagueID == leagueID {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTournamentRepo) CreateEntrants(ctx context.Context, entrants []tournaments.Entrant) error {
+	for _, e := range entrants {
+		m.entrants[e.TournamentID] = append(m.entrants[e.TournamentID], e)
+	}
+	return nil
+}
+
+func (m *mockTournamentRepo) ListEntrants(ctx context.Context, tournamentID string) ([]tournaments.Entrant, error) {
+	return append([]tournaments.Entrant(nil), m.entrants[tournamentID]...), nil
+}
+
+func (m *mockTournamentRepo) UpdateEntrant(ctx context.Context, entrant *tournaments.Entrant) error {
+	for i, e := range m.entrants[entrant.TournamentID] {
+		if e.FighterID == entrant.FighterID {
+			m.entrants[entrant.TournamentID][i] = *entrant
+		}
+	}
+	return nil
+}
+
+func (m *mockTournamentRepo) CreatePairing(ctx context.Context, pairing *tournaments.Pairing) error {
+	m.pairings = append(m.pairings, *pairing)
+	return nil
+}
+
+func (m *mockTournamentRepo) UpdatePairing(ctx context.Context, pairing *tournaments.Pairing) error {
+	for i, p := range m.pairings {
+		if p.ID == pairing.ID {
+			m.pairings[i] = *pairing
+		}
+	}
+	return nil
+}
+
+func (m *mockTournamentRepo) ListPairings(ctx context.Context, tournamentID string) ([]tournaments.Pairing, error) {
+	var result []tournaments.Pairing
+	for _, p := range m.pairings {
+		if p.TournamentID == tournamentID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTournamentRepo) GetPairingByMatch(ctx context.Context, matchID string) (*tournaments.Pairing, error) {
+	for _, p := range m.pairings {
+		if p.MatchID != nil && *p.MatchID == matchID {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockTournamentRepo) ListRunningPairings(ctx context.Context) ([]tournaments.Pairing, error) {
+	var result []tournaments.Pairing
+	for _, p := range m.pairings {
+		if p.Status == tournaments.PairingRunning {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+type mockLeagueRepo struct {
+	config leagues.Config
+}
+
+func (m mockLeagueRepo) GetByID(ctx context.Context, id int) (*leagues.League, error) {
+	if id != 1 {
+		return nil, nil
+	}
+	return &leagues.League{ID: id, Config: m.config}, nil
+}
+
+type mockSubscriptionRepo struct {
+	fighterIDs []string
+}
+
+func (m *mockSubscriptionRepo) ListByLeague(ctx context.Context, leagueID int) ([]leagues.LeagueSubscription, error) {
+	var subs []leagues.LeagueSubscription
+	for _, id := range m.fighterIDs {
+		subs = append(subs, leagues.LeagueSubscription{LeagueID: leagueID, FighterID: id})
+	}
+	return subs, nil
+}
+
+type mockLeagueMatchRepo struct {
+	matchIDs []string
+}
+
+func (m *mockLeagueMatchRepo) Create(ctx context.Context, leagueID int, matchID string) error {
+	m.matchIDs = append(m.matchIDs, matchID)
+	return nil
+}
+
+type mockFighterRepo struct{}
+
+func (mockFighterRepo) GetByID(ctx context.Context, id string) (*roster.Fighter, error) {
+	var userID int64
+	fmt.Sscanf(id, "f%d", &userID)
+	return &roster.Fighter{ID: id, UserID: userID}, nil
+}
+
+// mockRatingRepo rates fighter fN at 1000+10N, so the last subscribed
+// fighter is the top seed.
+type mockRatingRepo struct{}
+
+func (mockRatingRepo) Get(ctx context.Context, fighterID string) (*rating.Rating, error) {
+	var n int
+	fmt.Sscanf(fighterID, "f%d", &n)
+	r := rating.New(fighterID)
+	r.Rating = float64(1000 + 10*n)
+	return &r, nil
+}
+
+// mockMatches keeps the matches it created with the first fighter of each
+// side and their teams, and the winners they finished with.
+type mockMatches struct {
+	matches  map[string]*matches.Match
+	fighters map[string][]string
+	teams    map[string][][]string
+	winners  map[string][]string
+	started  []string
+}
+
+func newMockMatches() *mockMatches {
+	return &mockMatches{
+		matches:  make(map[string]*matches.Match),
+		fighters: make(map[string][]string),
+		teams:    make(map[string][][]string),
+		winners:  make(map[string][]string),
+	}
+}
+
+func (f *mockMatches) DefaultOptions() matchesusecase.MatchOptions {
+	return matchesusecase.MatchOptions{}
+}
+
+func (f *mockMatches) CreateTeamMatchFor(ctx context.Context, options matchesusecase.MatchOptions, teams [][]string) (*matches.Match, error) {
+	if !options.IsPrivate {
+		return nil, errors.New("expected a private match")
+	}
+	id := fmt.Sprintf("m%d", len(f.matches)+1)
+	f.matches[id] = &matches.Match{ID: id, Status: matches.MatchStatusLobby}
+	f.fighters[id] = []string{teams[0][0], teams[1][0]}
+	f.teams[id] = teams
+	return f.matches[id], nil
+}
+
+func (f *mockMatches) StartMatch(ctx context.Context, matchID string) error {
+	f.matches[matchID].Status = matches.MatchStatusRunning
+	f.started = append(f.started, matchID)
+	return nil
+}
+
+func (f *mockMatches) GetMatch(ctx context.Context, id string) (*matches.Match, error) {
+	return f.matches[id], nil
+}
+
+func (f *mockMatches) BattleSummary(ctx context.Context, matchID string) (*matches.BattleSummary, error) {
+	return &matches.BattleSummary{WinnerIDs: f.winners[matchID]}, nil
+}
+
+// finish completes a running match with the fighter with the higher number
+// winning.
+func (f *mockMatches) finish(matchID string) {
+	a, b := f.fighters[matchID][0], f.fighters[matchID][1]
+	winner := a
+	if b > a {
+		winner = b
+	}
+	f.winners[matchID] = []string{winner}
+	f.matches[matchID].Status = matches.MatchStatusCompleted
+}
+
+type mockRewards struct {
+	pools     map[int64]string
+	announced int
+}
+
+func (m *mockRewards) GrantReward(ctx context.Context, userID int64, poolID string) (*rewards.Reward, error) {
+	m.pools[userID] = poolID
+	return &rewards.Reward{UserID: userID, RewardPoolID: poolID}, nil
+}
+
+func (m *mockRewards) AnnounceReward(ctx context.Context, reward *rewards.Reward) {
+	m.announced++
+}
+
+type fixture struct {
+	service *Service
+	repo    *mockTournamentRepo
+	subs    *mockSubscriptionRepo
+	matches *mockMatches
+	rewards *mockRewards
+}
+
+// newFixture subscribes the fighters to a league with config. Fighter
+// fN and its namesakes such as fNb belong to user N.
+func newFixture(config leagues.Config, fighterIDs ...string) fixture {
+	f := fixture{
+		repo:    newMockTournamentRepo(),
+		subs:    &mockSubscriptionRepo{fighterIDs: fighterIDs},
+		matches: newMockMatches(),
+		rewards: &mockRewards{pools: make(map[int64]string)},
+	}
+	f.service = NewService(f.repo, mockLeagueRepo{config: config}, f.subs, &mockLeagueMatchRepo{}, mockFighterRepo{}, mockRatingRepo{}, f.matches, f.rewards, nil, nil)
+	return f
+}
+
+// finishRunning finishes every running match and reports it finished.
+func (f fixture) finishRunning(ctx context.Context) {
+	for _, id := range append([]string(nil), f.matches.started...) {
+		if f.matches.matches[id].Status == matches.MatchStatusRunning {
+			f.matches.finish(id)
+			f.service.MatchFinished(ctx, id)
+		}
+	}
+}
+
+func TestStart_SingleElimination(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(leagues.Config{}, "f1", "f2", "f3", "f4")
+
+	started, err := f.service.Start(ctx, 1, tournaments.FormatSingleElimination)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.matches.started) != 2 {
+		t.Fatalf("expected the two semifinals to start, got %v", f.matches.started)
+	}
+
+	f.finishRunning(ctx)
+	if len(f.matches.started) != 3 {
+		t.Fatalf("expected the final to start, got %v", f.matches.started)
+	}
+	f.finishRunning(ctx)
+
+	bracket, err := f.service.Bracket(ctx, started.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bracket.Tournament.Status != tournaments.StatusCompleted || bracket.Tournament.Completed == nil {
+		t.Fatalf("expected the tournament completed, got %+v", bracket.Tournament)
+	}
+	if f.rewards.pools[4] != tournaments.RewardPool(1) || f.rewards.pools[3] != tournaments.RewardPool(2) {
+		t.Fatalf("expected the top seeds rewarded first and second, got %v", f.rewards.pools)
+	}
+	if len(f.rewards.pools) != 3 || f.rewards.announced != 3 {
+		t.Fatalf("expected three placements rewarded and announced, got %v", f.rewards.pools)
+	}
+}
+
+func TestStart_Teams(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(leagues.Config{MaxFightersPerUser: 2, TeamSize: 2}, "f1", "f2", "f1b", "f2b")
+
+	if _, err := f.service.Start(ctx, 1, tournaments.FormatSingleElimination); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.matches.started) != 1 {
+		t.Fatalf("expected one match between the two teams, got %v", f.matches.started)
+	}
+	teams := fmt.Sprint(f.matches.teams[f.matches.started[0]])
+	if teams != "[[f2 f2b] [f1 f1b]]" {
+		t.Fatalf("expected every user's fighters on one side, got %s", teams)
+	}
+
+	f.finishRunning(ctx)
+	if f.rewards.pools[2] != tournaments.RewardPool(1) || len(f.rewards.pools) != 2 {
+		t.Fatalf("expected the teams placed by user, got %v", f.rewards.pools)
+	}
+}
+
+func TestStart_TeamsKeepTheirRoster(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(leagues.Config{MaxFightersPerUser: 2, TeamSize: 2}, "f1", "f2", "f3", "f4", "f1b", "f2b", "f3b", "f4b")
+
+	if _, err := f.service.Start(ctx, 1, tournaments.FormatSingleElimination); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Subscriptions changing mid-tournament leave the teams as they started
+	f.subs.fighterIDs = []string{"f1", "f2", "f3", "f4", "f3c", "f4c"}
+
+	f.finishRunning(ctx)
+	if len(f.matches.started) != 3 {
+		t.Fatalf("expected the final to start, got %v", f.matches.started)
+	}
+	teams := fmt.Sprint(f.matches.teams[f.matches.started[2]])
+	if teams != "[[f4 f4b] [f3 f3b]]" {
+		t.Fatalf("expected the final between the started teams, got %s", teams)
+	}
+}
+
+func TestStart_Rejects(t *testing.T) {
+	ctx := context.Background()
+
+	f := newFixture(leagues.Config{}, "f1")
+	if _, err := f.service.Start(ctx, 1, tournaments.FormatSwiss); !errors.Is(err, ErrNotEnoughEntrants) {
+		t.Fatalf("expected ErrNotEnoughEntrants, got %v", err)
+	}
+	if _, err := f.service.Start(ctx, 2, tournaments.FormatSwiss); !errors.Is(err, ErrInvalidLeague) {
+		t.Fatalf("expected ErrInvalidLeague, got %v", err)
+	}
+	if _, err := f.service.Start(ctx, 1, "knockout"); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+
+	f = newFixture(leagues.Config{}, "f1", "f2")
+	if _, err := f.service.Start(ctx, 1, tournaments.FormatSwiss); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.service.Start(ctx, 1, tournaments.FormatSwiss); !errors.Is(err, ErrTournamentRunning) {
+		t.Fatalf("expected ErrTournamentRunning, got %v", err)
+	}
+}
+
+func TestMatchFinished_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(leagues.Config{}, "f1", "f2", "f3")
+
+	if _, err := f.service.Start(ctx, 1, tournaments.FormatSingleElimination); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.finishRunning(ctx)
+	first := f.matches.started[0]
+	f.service.MatchFinished(ctx, first)
+
+	if len(f.matches.started) != 2 {
+		t.Fatalf("expected a single final, got %v", f.matches.started)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(leagues.Config{}, "f1", "f2")
+
+	started, err := f.service.Start(ctx, 1, tournaments.FormatDoubleElimination)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The first match is cancelled, so the better seed advances
+	f.matches.matches[f.matches.started[0]].Status = matches.MatchStatusCancelled
+
+	settled, err := f.service.Sweep(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settled != 1 || len(f.matches.started) != 2 {
+		t.Fatalf("expected the walkover settled and the final started, got %d and %v", settled, f.matches.started)
+	}
+
+	// A finished match whose hook never ran is picked up as well
+	f.matches.finish(f.matches.started[1])
+	if settled, err = f.service.Sweep(ctx); err != nil || settled != 1 {
+		t.Fatalf("expected the finished final settled, got %d and %v", settled, err)
+	}
+
+	bracket, _ := f.service.Bracket(ctx, started.ID)
+	if bracket.Tournament.Status != tournaments.StatusCompleted {
+		t.Fatalf("expected the tournament completed, got %+v", bracket.Tournament)
+	}
+	if bracket.Entrants[0].FighterID != "f2" || bracket.Entrants[0].Placement != 1 {
+		t.Fatalf("expected the top seed to win, got %+v", bracket.Entrants)
+	}
+}